@@ -26,105 +26,273 @@ import (
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/labstack/echo/v4"
-	"oransc.org/nonrtric/capifcore/internal/common29122"
+	"oransc.org/nonrtric/capifcore/internal/accesscontrolpolicyapi"
+	"oransc.org/nonrtric/capifcore/internal/aefsecurityapi"
+	"oransc.org/nonrtric/capifcore/internal/auditingapi"
+	"oransc.org/nonrtric/capifcore/internal/certauthority"
+	"oransc.org/nonrtric/capifcore/internal/clientauth"
 	"oransc.org/nonrtric/capifcore/internal/discoverserviceapi"
 	"oransc.org/nonrtric/capifcore/internal/eventsapi"
 	"oransc.org/nonrtric/capifcore/internal/invokermanagementapi"
+	"oransc.org/nonrtric/capifcore/internal/loggingapi"
 	"oransc.org/nonrtric/capifcore/internal/providermanagementapi"
+	"oransc.org/nonrtric/capifcore/internal/routinginfoapi"
 	"oransc.org/nonrtric/capifcore/internal/securityapi"
 
-	"github.com/deepmap/oapi-codegen/pkg/middleware"
 	echomiddleware "github.com/labstack/echo/v4/middleware"
 	log "github.com/sirupsen/logrus"
 	"oransc.org/nonrtric/capifcore/internal/helmmanagement"
 
+	"oransc.org/nonrtric/capifcore/internal/accesscontrolpolicyservice"
+	"oransc.org/nonrtric/capifcore/internal/accessrequestservice"
+	"oransc.org/nonrtric/capifcore/internal/auditingservice"
 	"oransc.org/nonrtric/capifcore/internal/discoverservice"
 	"oransc.org/nonrtric/capifcore/internal/eventservice"
 	"oransc.org/nonrtric/capifcore/internal/invokermanagement"
+	"oransc.org/nonrtric/capifcore/internal/loggingservice"
 	"oransc.org/nonrtric/capifcore/internal/providermanagement"
 	"oransc.org/nonrtric/capifcore/internal/publishservice"
 	"oransc.org/nonrtric/capifcore/internal/publishserviceapi"
+	"oransc.org/nonrtric/capifcore/internal/routinginfoservice"
 	security "oransc.org/nonrtric/capifcore/internal/securityservice"
 	"oransc.org/nonrtric/capifcore/internal/keycloak"
+	"oransc.org/nonrtric/capifcore/internal/storage"
+	"oransc.org/nonrtric/capifcore/internal/websocketnotifier"
+	"oransc.org/nonrtric/capifcore/problemdetails"
+	"oransc.org/nonrtric/capifcore/validation"
 )
 
-func RegisterHandlers(e *echo.Echo, helmManager helmmanagement.HelmManager, km *keycloak.KeycloakManager) {
+// The CAPIF core function. It holds the services of the CAPIF APIs, and thereby all registered providers, published
+// APIs, onboarded invokers, event subscriptions and security contexts. The APIs are served by the listeners that the
+// core is mounted on, which all share the same state.
+type CapifCore struct {
+	notifier                   *websocketnotifier.WebSocketNotifier
+	eventService               *eventservice.EventService
+	providerManager            *providermanagement.ProviderManager
+	publishService             *publishservice.PublishService
+	accessControlPolicyService *accesscontrolpolicyservice.AccessControlPolicyService
+	invokerManager             *invokermanagement.InvokerManager
+	discoverService            *discoverservice.DiscoverService
+	securityService            *security.Security
+	loggingService             *loggingservice.LoggingService
+	auditingService            *auditingservice.AuditingService
+	routingInfoService         *routinginfoservice.RoutingInfoService
+	accessRequestService       *accessrequestservice.AccessRequestService
+}
+
+// Creates the CAPIF core function. The registries are kept in the provided store, if it is nil they are only kept in
+// memory. The invokers are clients in the provided realm of the authorization server.
+func NewCapifCore(helmManager helmmanagement.HelmManager, km keycloak.AccessManagement, invokerRealm string, ca certauthority.CertificateIssuer, store storage.Store) *CapifCore {
+	if store == nil {
+		store = storage.NewMemoryStore()
+	}
+
+	// Notifications are pushed over websockets to the clients that request it
+	notifier := websocketnotifier.NewWebSocketNotifier()
+	eventService := eventservice.NewEventService(&http.Client{}, notifier, store)
+	eventChannel := eventService.GetNotificationChannel()
+	providerManager := providermanagement.NewProviderManager(ca, eventChannel, store)
+	publishService := publishservice.NewPublishService(providerManager, helmManager, eventChannel, store)
+	providerManager.SetServicePublisher(publishService)
+	accessControlPolicyService := accesscontrolpolicyservice.NewAccessControlPolicyService(publishService, eventChannel, store)
+	invokerManager := invokermanagement.NewInvokerManager(publishService, accessControlPolicyService, km, invokerRealm, ca, &http.Client{}, notifier, eventChannel, store)
+	securityService := security.NewSecurity(providerManager, publishService, invokerManager, accessControlPolicyService, km, invokerRealm, &http.Client{}, notifier, eventChannel, store)
+	invokerManager.AddOffboardingHandler(securityService)
+	invokerManager.AddOffboardingHandler(eventService)
+	accessRequestService := accessrequestservice.NewAccessRequestService(invokerManager, publishService, providerManager, eventChannel, store)
+	accessRequestService.AddAccessGrantHandler(invokerManager)
+	accessRequestService.AddAccessGrantHandler(securityService)
+	invokerManager.AddAccessRequester(accessRequestService)
+	invokerManager.AddOffboardingHandler(accessRequestService)
+	routingInfoService := routinginfoservice.NewRoutingInfoService(publishService, eventChannel, store)
+	publishService.AddUnpublishHandler(routingInfoService)
+	loggingService := loggingservice.NewLoggingService(providerManager, publishService, invokerManager, eventChannel, store)
+
+	return &CapifCore{
+		notifier:                   notifier,
+		eventService:               eventService,
+		providerManager:            providerManager,
+		publishService:             publishService,
+		accessControlPolicyService: accessControlPolicyService,
+		invokerManager:             invokerManager,
+		discoverService:            discoverservice.NewDiscoverService(invokerManager, publishService, providerManager),
+		securityService:            securityService,
+		loggingService:             loggingService,
+		auditingService:            auditingservice.NewAuditingService(loggingService),
+		routingInfoService:         routingInfoService,
+		accessRequestService:       accessRequestService,
+	}
+}
+
+// Creates a CAPIF core function and registers its APIs. The registries are kept in the provided store, if it is nil
+// they are only kept in memory. The invokers are clients in the default invoker realm of the authorization server.
+// To serve the same core on several listeners, use NewCapifCore and register each listener with
+// CapifCore.RegisterHandlers instead.
+func RegisterHandlers(e *echo.Echo, helmManager helmmanagement.HelmManager, km keycloak.AccessManagement, ca certauthority.CertificateIssuer, store storage.Store, requireClientCerts bool) {
+	NewCapifCore(helmManager, km, keycloak.DefaultInvokerRealm, ca, store).RegisterHandlers(e, requireClientCerts, validation.ResponseValidationOff)
+}
+
+// Registers the CAPIF APIs on the provided listener. A core can be registered on any number of listeners.
+// If client certificates are required, the provider management, publish service, access control policy, security,
+// routing info and access request APIs only accept requests with a verified client certificate belonging to the owner
+// of the accessed resource.
+// The requests are validated against the specifications of the APIs, and the responses depending on the provided
+// response validation mode.
+func (c *CapifCore) RegisterHandlers(e *echo.Echo, requireClientCerts bool, responseValidation validation.ResponseValidation) {
 	// Log all requests
 	e.Use(echomiddleware.Logger())
 
-	var group *echo.Group
-	// Register ProviderManagement
-	providerManagerSwagger, err := providermanagementapi.GetSwagger()
-	if err != nil {
-		log.Fatalf("Error loading ProviderManagement swagger spec\n: %s", err)
+	if requireClientCerts {
+		e.Use(clientauth.NewClientAuthenticator(c.providerManager, c.publishService, c.invokerManager, c.securityService).Authenticate)
 	}
-	providerManagerSwagger.Servers = nil
-	providerManager := providermanagement.NewProviderManager()
-	group = e.Group("/api-provider-management/v1")
-	group.Use(middleware.OapiRequestValidator(providerManagerSwagger))
-	providermanagementapi.RegisterHandlersWithBaseURL(e, providerManager, "/api-provider-management/v1")
 
+	c.notifier.RegisterHandler(e)
+
+	var group *echo.Group
 	// Register EventService
 	eventServiceSwagger, err := eventsapi.GetSwagger()
 	if err != nil {
 		log.Fatalf("Error loading EventService swagger spec\n: %s", err)
 	}
-	eventServiceSwagger.Servers = nil
-	eventService := eventservice.NewEventService(&http.Client{})
-	group = e.Group("/capif-events/v1")
-	group.Use(middleware.OapiRequestValidator(eventServiceSwagger))
-	eventsapi.RegisterHandlersWithBaseURL(e, eventService, "/capif-events/v1")
-	eventChannel := eventService.GetNotificationChannel()
+	eventservice.AddSubscriberOperations(eventServiceSwagger)
+	group = validation.NewGroup(e, eventServiceSwagger, "/capif-events/v1", responseValidation)
+	eventsapi.RegisterHandlers(group, c.eventService)
+	group.GET("/:subscriberId/subscriptions", c.eventService.GetSubscriberIdSubscriptions)
+	group.GET("/:subscriberId/subscriptions/:subscriptionId", c.eventService.GetSubscriberIdSubscriptionsSubscriptionId)
+	group.PUT("/:subscriberId/subscriptions/:subscriptionId", c.eventService.PutSubscriberIdSubscriptionsSubscriptionId)
+	group.PATCH("/:subscriberId/subscriptions/:subscriptionId", c.eventService.PatchSubscriberIdSubscriptionsSubscriptionId)
+
+	// Register ProviderManagement
+	providerManagerSwagger, err := providermanagementapi.GetSwagger()
+	if err != nil {
+		log.Fatalf("Error loading ProviderManagement swagger spec\n: %s", err)
+	}
+	group = validation.NewGroup(e, providerManagerSwagger, "/api-provider-management/v1", responseValidation)
+	providermanagementapi.RegisterHandlers(group, c.providerManager)
 
 	// Register PublishService
 	publishServiceSwagger, err := publishserviceapi.GetSwagger()
 	if err != nil {
 		log.Fatalf("Error loading PublishService swagger spec\n: %s", err)
 	}
-	publishServiceSwagger.Servers = nil
-	publishService := publishservice.NewPublishService(providerManager, helmManager, eventChannel)
-	group = e.Group("/published-apis/v1")
-	group.Use(middleware.OapiRequestValidator(publishServiceSwagger))
-	publishserviceapi.RegisterHandlersWithBaseURL(e, publishService, "/published-apis/v1")
+	publishservice.AddVisibilityOperations(publishServiceSwagger)
+	group = validation.NewGroup(e, publishServiceSwagger, "/published-apis/v1", responseValidation)
+	publishserviceapi.RegisterHandlers(group, c.publishService)
+	group.GET("/:apfId/service-apis/:serviceApiId/visibility", c.publishService.GetServiceApiVisibility)
+	group.PUT("/:apfId/service-apis/:serviceApiId/visibility", c.publishService.PutServiceApiVisibility)
+
+	// Register AccessControlPolicy
+	accessControlPolicySwagger, err := accesscontrolpolicyapi.GetSwagger()
+	if err != nil {
+		log.Fatalf("Error loading AccessControlPolicy swagger spec\n: %s", err)
+	}
+	accesscontrolpolicyservice.AddProviderOperations(accessControlPolicySwagger)
+	group = validation.NewGroup(e, accessControlPolicySwagger, "/access-control-policy/v1", responseValidation)
+	accesscontrolpolicyapi.RegisterHandlers(group, c.accessControlPolicyService)
+	group.PUT("/accessControlPolicyList/:serviceApiId/apiInvokerPolicies/:apiInvokerId", c.accessControlPolicyService.PutApiInvokerPolicy)
 
 	// Register InvokerManagement
 	invokerManagerSwagger, err := invokermanagementapi.GetSwagger()
 	if err != nil {
 		log.Fatalf("Error loading InvokerManagement swagger spec\n: %s", err)
 	}
-	invokerManagerSwagger.Servers = nil
-	invokerManager := invokermanagement.NewInvokerManager(publishService, km, eventChannel)
-	group = e.Group("/api-invoker-management/v1")
-	group.Use(middleware.OapiRequestValidator(invokerManagerSwagger))
-	invokermanagementapi.RegisterHandlersWithBaseURL(e, invokerManager, "/api-invoker-management/v1")
+	group = validation.NewGroup(e, invokerManagerSwagger, "/api-invoker-management/v1", responseValidation)
+	invokermanagementapi.RegisterHandlers(group, c.invokerManager)
 
 	// Register DiscoverService
 	discoverServiceSwagger, err := discoverserviceapi.GetSwagger()
 	if err != nil {
 		log.Fatalf("Error loading DiscoverService swagger spec\n: %s", err)
 	}
-	discoverServiceSwagger.Servers = nil
-	discoverService := discoverservice.NewDiscoverService(invokerManager)
-	group = e.Group("/service-apis/v1")
-	group.Use(middleware.OapiRequestValidator(discoverServiceSwagger))
-	discoverserviceapi.RegisterHandlersWithBaseURL(e, discoverService, "/service-apis/v1")
+	group = validation.NewGroup(e, discoverServiceSwagger, "/service-apis/v1", responseValidation)
+	discoverserviceapi.RegisterHandlers(group, c.discoverService)
 
 	// Register Security
 	securitySwagger, err := securityapi.GetSwagger()
 	if err != nil {
 		log.Fatalf("Error loading Security swagger spec\n: %s", err)
 	}
-	securitySwagger.Servers = nil
-	securityService := security.NewSecurity(providerManager, publishService, invokerManager, km)
-	group = e.Group("/capif-security/v1")
-	group.Use(middleware.OapiRequestValidator(securitySwagger))
-	securityapi.RegisterHandlersWithBaseURL(e, securityService, "/capif-security/v1")
+	group = validation.NewGroup(e, securitySwagger, "/capif-security/v1", responseValidation)
+	securityapi.RegisterHandlers(group, c.securityService)
+	// The token introspection of RFC 7662 is not part of the specification, its form encoded request is checked by the
+	// handler, so it is not validated
+	if requireClientCerts {
+		e.POST("/capif-security/v1/introspect", c.securityService.PostIntrospect)
+	} else {
+		// Without client certificates, the API exposing functions authenticate with the registration secret of their
+		// provider, as the introspection endpoint must be protected, see RFC 7662
+		e.POST("/capif-security/v1/introspect", c.securityService.PostIntrospect, echomiddleware.BasicAuth(func(functionId, secret string, _ echo.Context) (bool, error) {
+			return c.providerManager.VerifyFunctionSecret(functionId, secret), nil
+		}))
+	}
+	// The key set takes no input and is not part of any CAPIF API, so it is not validated
+	e.GET("/.well-known/jwks.json", c.securityService.GetJwks)
+
+	// Register AefSecurity
+	aefSecuritySwagger, err := aefsecurityapi.GetSwagger()
+	if err != nil {
+		log.Fatalf("Error loading AefSecurity swagger spec\n: %s", err)
+	}
+	group = validation.NewGroup(e, aefSecuritySwagger, "/aef-security/v1", responseValidation)
+	aefsecurityapi.RegisterHandlers(group, c.securityService)
+
+	// Register Logging
+	loggingSwagger, err := loggingapi.GetSwagger()
+	if err != nil {
+		log.Fatalf("Error loading Logging swagger spec\n: %s", err)
+	}
+	group = validation.NewGroup(e, loggingSwagger, "/api-invocation-logs/v1", responseValidation)
+	loggingapi.RegisterHandlers(group, c.loggingService)
+
+	// Register Auditing
+	auditingSwagger, err := auditingapi.GetSwagger()
+	if err != nil {
+		log.Fatalf("Error loading Auditing swagger spec\n: %s", err)
+	}
+	group = validation.NewGroup(e, auditingSwagger, "/logs/v1", responseValidation)
+	auditingapi.RegisterHandlers(group, c.auditingService)
+
+	// Register RoutingInfo
+	routingInfoSwagger, err := routinginfoapi.GetSwagger()
+	if err != nil {
+		log.Fatalf("Error loading RoutingInfo swagger spec\n: %s", err)
+	}
+	routinginfoservice.AddProviderOperations(routingInfoSwagger)
+	group = validation.NewGroup(e, routingInfoSwagger, "/capif-routing-info/v1", responseValidation)
+	routinginfoapi.RegisterHandlers(group, c.routingInfoService)
+	group.PUT("/service-apis/:serviceApiId", c.routingInfoService.PutServiceApisServiceApiId)
+	group.DELETE("/service-apis/:serviceApiId", c.routingInfoService.DeleteServiceApisServiceApiId)
+
+	// Register AccessRequest, which is not part of the 3GPP APIs
+	accessRequestSwagger, err := accessrequestservice.GetSwagger()
+	if err != nil {
+		log.Fatalf("Error loading AccessRequest swagger spec\n: %s", err)
+	}
+	group = validation.NewGroup(e, accessRequestSwagger, "/access-requests/v1", responseValidation)
+	group.POST("/invokers/:apiInvokerId/requests", c.accessRequestService.PostInvokerAccessRequests)
+	group.GET("/invokers/:apiInvokerId/requests", c.accessRequestService.GetInvokerAccessRequests)
+	group.GET("/invokers/:apiInvokerId/requests/:requestId", c.accessRequestService.GetInvokerAccessRequest)
+	group.GET("/providers/:apfId/requests", c.accessRequestService.GetProviderAccessRequests)
+	group.POST("/providers/:apfId/requests/:requestId/approve", c.accessRequestService.ApproveAccessRequest)
+	group.POST("/providers/:apfId/requests/:requestId/reject", c.accessRequestService.RejectAccessRequest)
 
 	e.GET("/", hello)
 
 	e.GET("/swagger/:apiName", getSwagger)
 }
 
+// Registers the administration routes of the CAPIF core on the provided listener. They are not authorized, so the
+// listener must only be reachable by the operator of CAPIF core.
+func (c *CapifCore) RegisterAdminHandlers(e *echo.Echo) {
+	// Log all requests
+	e.Use(echomiddleware.Logger())
+
+	e.GET("/capif-events/v1/dead-letters", c.eventService.GetDeadLetters)
+	e.GET("/capif-events/v1/dead-letters/:deadLetterId", c.eventService.GetDeadLetter)
+	e.POST("/capif-events/v1/dead-letters/:deadLetterId/replay", c.eventService.ReplayDeadLetter)
+	e.DELETE("/capif-events/v1/dead-letters/:deadLetterId", c.eventService.DeleteDeadLetter)
+}
+
 func hello(c echo.Context) error {
 	return c.String(http.StatusOK, "Hello, World!")
 }
@@ -137,26 +305,43 @@ func getSwagger(c echo.Context) error {
 		swagger, err = providermanagementapi.GetSwagger()
 	case "publish":
 		swagger, err = publishserviceapi.GetSwagger()
+		if err == nil {
+			publishservice.AddVisibilityOperations(swagger)
+		}
 	case "invoker":
 		swagger, err = invokermanagementapi.GetSwagger()
 	case "discover":
 		swagger, err = discoverserviceapi.GetSwagger()
 	case "events":
 		swagger, err = eventsapi.GetSwagger()
+		if err == nil {
+			eventservice.AddSubscriberOperations(swagger)
+		}
 	case "security":
 		swagger, err = securityapi.GetSwagger()
+	case "logging":
+		swagger, err = loggingapi.GetSwagger()
+	case "auditing":
+		swagger, err = auditingapi.GetSwagger()
+	case "accesscontrol":
+		swagger, err = accesscontrolpolicyapi.GetSwagger()
+		if err == nil {
+			accesscontrolpolicyservice.AddProviderOperations(swagger)
+		}
+	case "aefsecurity":
+		swagger, err = aefsecurityapi.GetSwagger()
+	case "routinginfo":
+		swagger, err = routinginfoapi.GetSwagger()
+		if err == nil {
+			routinginfoservice.AddProviderOperations(swagger)
+		}
+	case "accessrequests":
+		swagger, err = accessrequestservice.GetSwagger()
 	default:
-		return c.JSON(http.StatusBadRequest, getProblemDetails("Invalid API name "+api, http.StatusBadRequest))
+		return problemdetails.Send(c, http.StatusBadRequest, problemdetails.CauseInvalidApi, "Invalid API name "+api)
 	}
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, getProblemDetails("Unable to get swagger for API", http.StatusInternalServerError))
+		return problemdetails.Send(c, http.StatusInternalServerError, problemdetails.CauseSystemFailure, "Unable to get swagger for API")
 	}
 	return c.JSON(http.StatusOK, swagger)
 }
-
-func getProblemDetails(cause string, status int) common29122.ProblemDetails {
-	return common29122.ProblemDetails{
-		Cause:  &cause,
-		Status: &status,
-	}
-}
@@ -22,19 +22,27 @@
 package main
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"flag"
 	"fmt"
 	"net/http"
+	"os"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"helm.sh/helm/v3/pkg/cli"
 	log "github.com/sirupsen/logrus"
 
 	"oransc.org/nonrtric/capifcore/internal/helmmanagement"
+	"oransc.org/nonrtric/capifcore/internal/certauthority"
 	config "oransc.org/nonrtric/capifcore/internal/config"
+	"oransc.org/nonrtric/capifcore/internal/jwtauth"
 	"oransc.org/nonrtric/capifcore/internal/keycloak"
+	"oransc.org/nonrtric/capifcore/internal/storage"
 
 	"oransc.org/nonrtric/capifcore"
+	"oransc.org/nonrtric/capifcore/validation"
 )
 
 func main() {
@@ -44,11 +52,22 @@ func main() {
 
 	var port = flag.Int("port", 8090, "Port for CAPIF Core Function HTTP server")
 	var secPort = flag.Int("secPort", 4433, "Port for CAPIF Core Function HTTPS server")
+	var adminPort = flag.Int("adminPort", 0, "Port for the CAPIF Core Function administration HTTP server, listening on localhost only, if 0 the administration API is not served")
 	flag.StringVar(&url, "chartMuseumUrl", "", "ChartMuseum URL")
 	flag.StringVar(&repoName, "repoName", "capifcore", "Repository name")
 	var logLevelStr = flag.String("loglevel", "Info", "Log level")
 	var certPath = flag.String("certPath", "certs/cert.pem", "Path for server certificate")
 	var keyPath = flag.String("keyPath", "certs/key.pem", "Path for server private key")
+	var storageBackend = flag.String("storage", storage.BackendMemory, "Storage backend for the registries, memory or bolt")
+	var storagePath = flag.String("storagePath", "capifcore.db", "Path for the storage file when using a file based storage backend")
+	var tokenKeyPath = flag.String("tokenKeyPath", "", "Path for the private key signing access tokens, if provided the built-in authorization server is used instead of Keycloak")
+	var tokenLifetime = flag.Duration("tokenLifetime", time.Hour, "Lifetime of the access tokens issued by the built-in authorization server")
+	var invokerRealm = flag.String("invokerRealm", keycloak.DefaultInvokerRealm, "Key of the invoker realm in the realms of the Keycloak configuration file, not used by the built-in authorization server")
+	var caCertPath = flag.String("caCertPath", "", "Path for the CA certificate, if provided together with caKeyPath certificates are issued for invokers and provider functions")
+	var caKeyPath = flag.String("caKeyPath", "", "Path for the CA private key")
+	var clientCaPath = flag.String("clientCaPath", "", "Path for the CA bundle verifying client certificates, if provided the provider management, publish service and security APIs require client certificates and the HTTP server is not started")
+	var responseValidationStr = flag.String("responseValidation", string(validation.ResponseValidationOff), "Validation of the responses against the API specifications, off, log or reject")
+	var certValidity = flag.Duration("certValidity", 365*24*time.Hour, "Validity of the certificates issued by the CA")
 
 	flag.Parse()
 
@@ -63,22 +82,68 @@ func main() {
 		log.Warnf("No Helm repo added due to: %s", err.Error())
 	}
 
-	// Read configuration file
-	cfg, err := config.ReadKeycloakConfigFile("configs")
+	store, err := storage.NewStore(*storageBackend, *storagePath)
 	if err != nil {
-		log.Fatalf("Error loading configuration file\n: %s", err)
+		log.Fatalf("Error opening storage\n: %s", err)
+	}
+	defer store.Close()
+
+	var km keycloak.AccessManagement
+	if *tokenKeyPath != "" {
+		km, err = jwtauth.NewJwtManager(*tokenKeyPath, *tokenLifetime, store)
+		if err != nil {
+			log.Fatalf("Error loading token signing key\n: %s", err)
+		}
+	} else {
+		// Read configuration file
+		cfg, err := config.ReadKeycloakConfigFile("configs")
+		if err != nil {
+			log.Fatalf("Error loading configuration file\n: %s", err)
+		}
+		km = keycloak.NewKeycloakManager(cfg, &http.Client{})
+	}
+
+	var ca certauthority.CertificateIssuer
+	if *caCertPath != "" && *caKeyPath != "" {
+		ca, err = certauthority.NewCertificateAuthority(*caCertPath, *caKeyPath, *certValidity)
+		if err != nil {
+			log.Fatalf("Error loading CA certificate\n: %s", err)
+		}
+	}
+
+	responseValidation, err := validation.ParseResponseValidation(*responseValidationStr)
+	if err != nil {
+		log.Fatalf("Error parsing response validation\n: %s", err)
 	}
-	km := keycloak.NewKeycloakManager(cfg, &http.Client{})
 
-	eWeb := echo.New()
-	capifcore.RegisterHandlers(eWeb, helmManager, km)
-	go startWebServer(eWeb, *port)
+	core := capifcore.NewCapifCore(helmManager, km, *invokerRealm, ca, store)
+	requireClientCerts := *clientCaPath != ""
+
+	// Clients cannot present certificates over plain HTTP, so the HTTP server is only started when no client
+	// certificates are required
+	if requireClientCerts {
+		log.Info("HTTP server not started as client certificates are required")
+	} else {
+		eWeb := echo.New()
+		core.RegisterHandlers(eWeb, false, responseValidation)
+		go startWebServer(eWeb, *port)
+		log.Info("Server started and listening on port: ", *port)
+	}
 
 	eHttpsWeb := echo.New()
-	capifcore.RegisterHandlers(eHttpsWeb, helmManager, km)
-	go startHttpsWebServer(eHttpsWeb, *secPort, *certPath, *keyPath)
+	core.RegisterHandlers(eHttpsWeb, requireClientCerts, responseValidation)
+	tlsConfig, err := getTlsConfig(*certPath, *keyPath, *clientCaPath)
+	if err != nil {
+		log.Fatalf("Error loading TLS configuration\n: %s", err)
+	}
+	go startHttpsWebServer(eHttpsWeb, *secPort, tlsConfig)
+
+	if *adminPort != 0 {
+		eAdmin := echo.New()
+		core.RegisterAdminHandlers(eAdmin)
+		go startAdminServer(eAdmin, *adminPort)
+	}
 
-	log.Info("Server started and listening on port: ", *port)
 	keepServerAlive()
 }
 
@@ -86,8 +151,42 @@ func startWebServer(e *echo.Echo, port int) {
 	e.Logger.Fatal(e.Start(fmt.Sprintf("0.0.0.0:%d", port)))
 }
 
-func startHttpsWebServer(e *echo.Echo, port int, certPath string, keyPath string) {
-	e.Logger.Fatal(e.StartTLS(fmt.Sprintf("0.0.0.0:%d", port), certPath, keyPath))
+// The administration API is not authorized, so it is only served to local clients.
+func startAdminServer(e *echo.Echo, port int) {
+	e.Logger.Fatal(e.Start(fmt.Sprintf("127.0.0.1:%d", port)))
+}
+
+func startHttpsWebServer(e *echo.Echo, port int, tlsConfig *tls.Config) {
+	s := &http.Server{
+		Addr:      fmt.Sprintf("0.0.0.0:%d", port),
+		TLSConfig: tlsConfig,
+	}
+	e.Logger.Fatal(e.StartServer(s))
+}
+
+// Gets the configuration of the HTTPS server. If a client CA bundle is provided, client certificates are verified
+// against it. A client certificate is not required by the server, the APIs that need one reject requests without it.
+func getTlsConfig(certPath, keyPath, clientCaPath string) (*tls.Config, error) {
+	certificate, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+	}
+	if clientCaPath != "" {
+		caBundle, err := os.ReadFile(clientCaPath)
+		if err != nil {
+			return nil, err
+		}
+		clientCAs := x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(caBundle) {
+			return nil, fmt.Errorf("no certificates found in %s", clientCaPath)
+		}
+		tlsConfig.ClientCAs = clientCAs
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return tlsConfig, nil
 }
 
 func keepServerAlive() {
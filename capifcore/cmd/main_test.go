@@ -22,10 +22,21 @@
 package main
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
 	"fmt"
 	"io"
+	"math/big"
 	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -35,11 +46,14 @@ import (
 	"github.com/stretchr/testify/assert"
 	"oransc.org/nonrtric/capifcore"
 	"oransc.org/nonrtric/capifcore/internal/common29122"
+	"oransc.org/nonrtric/capifcore/internal/providermanagementapi"
+	_ "oransc.org/nonrtric/capifcore/problemdetails/problemdetailstest"
+	"oransc.org/nonrtric/capifcore/validation"
 )
 
 func Test_routing(t *testing.T) {
 	e := echo.New()
-	capifcore.RegisterHandlers(e, nil, nil)
+	capifcore.RegisterHandlers(e, nil, nil, nil, nil, false)
 
 	type args struct {
 		url          string
@@ -94,7 +108,7 @@ func Test_routing(t *testing.T) {
 			name: "Event path",
 			args: args{
 				url:          "/capif-events/v1/subscriberId/subscriptions/subId",
-				returnStatus: http.StatusNoContent,
+				returnStatus: http.StatusNotFound,
 				method:       "DELETE",
 			},
 		},
@@ -123,7 +137,7 @@ func Test_routing(t *testing.T) {
 
 func TestGetSwagger(t *testing.T) {
 	e := echo.New()
-	capifcore.RegisterHandlers(e, nil, nil)
+	capifcore.RegisterHandlers(e, nil, nil, nil, nil, false)
 
 	type args struct {
 		apiPath string
@@ -175,6 +189,48 @@ func TestGetSwagger(t *testing.T) {
 				apiName: "Security",
 			},
 		},
+		{
+			name: "Logging api",
+			args: args{
+				apiPath: "logging",
+				apiName: "Logging",
+			},
+		},
+		{
+			name: "Auditing api",
+			args: args{
+				apiPath: "auditing",
+				apiName: "Auditing",
+			},
+		},
+		{
+			name: "Access control policy api",
+			args: args{
+				apiPath: "accesscontrol",
+				apiName: "Access_Control_Policy",
+			},
+		},
+		{
+			name: "AEF security api",
+			args: args{
+				apiPath: "aefsecurity",
+				apiName: "AEF_Security",
+			},
+		},
+		{
+			name: "Routing info api",
+			args: args{
+				apiPath: "routinginfo",
+				apiName: "Routing_Info",
+			},
+		},
+		{
+			name: "Access requests api",
+			args: args{
+				apiPath: "accessrequests",
+				apiName: "Access_Requests",
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -192,16 +248,120 @@ func TestGetSwagger(t *testing.T) {
 	var errorResponse common29122.ProblemDetails
 	err := result.UnmarshalJsonToObject(&errorResponse)
 	assert.Nil(t, err)
-	assert.Contains(t, *errorResponse.Cause, "Invalid API")
-	assert.Contains(t, *errorResponse.Cause, invalidApi)
+	assert.Contains(t, *errorResponse.Detail, "Invalid API")
+	assert.Contains(t, *errorResponse.Detail, invalidApi)
+}
+
+func TestListenersShareCore(t *testing.T) {
+	core := capifcore.NewCapifCore(nil, nil, "invokerrealm", nil, nil)
+	eWeb := echo.New()
+	core.RegisterHandlers(eWeb, false, validation.ResponseValidationReject)
+	eOther := echo.New()
+	core.RegisterHandlers(eOther, false, validation.ResponseValidationReject)
+
+	// Register a provider through one listener
+	funcInfo := "rApp as APF"
+	provider := providermanagementapi.APIProviderEnrolmentDetails{
+		RegSec: "sec",
+		ApiProvFuncs: &[]providermanagementapi.APIProviderFunctionDetails{
+			{
+				ApiProvFuncInfo: &funcInfo,
+				ApiProvFuncRole: providermanagementapi.ApiProviderFuncRoleAPF,
+				RegInfo: providermanagementapi.RegistrationInformation{
+					ApiProvPubKey: "key",
+				},
+			},
+		},
+	}
+	result := testutil.NewRequest().Post("/api-provider-management/v1/registrations").WithJsonBody(provider).Go(t, eWeb)
+	assert.Equal(t, http.StatusCreated, result.Code())
+	err := result.UnmarshalBodyToObject(&provider)
+	assert.NoError(t, err)
+
+	// The provider is registered for the other listener
+	result = testutil.NewRequest().Put("/api-provider-management/v1/registrations/"+*provider.ApiProvDomId).WithJsonBody(provider).Go(t, eOther)
+	assert.Equal(t, http.StatusOK, result.Code())
+}
+
+func TestRequestsAreValidated(t *testing.T) {
+	e := echo.New()
+	capifcore.RegisterHandlers(e, nil, nil, nil, nil, false)
+
+	result := testutil.NewRequest().Post("/api-provider-management/v1/registrations").WithJsonBody(map[string]interface{}{"regSec": 1}).Go(t, e)
+
+	assert.Equal(t, http.StatusBadRequest, result.Code())
+	var problemDetails common29122.ProblemDetails
+	err := result.UnmarshalBodyToObject(&problemDetails)
+	assert.NoError(t, err)
+	assert.Equal(t, "/regSec", (*problemDetails.InvalidParams)[0].Param)
+
+	// Routes that are not part of the 3GPP APIs are validated against the specifications of CAPIF Core
+	result = testutil.NewRequest().Post("/access-requests/v1/invokers/invokerId/requests").WithJsonBody(map[string]interface{}{"apiId": 1}).Go(t, e)
+	assert.Equal(t, http.StatusBadRequest, result.Code())
+	err = result.UnmarshalBodyToObject(&problemDetails)
+	assert.NoError(t, err)
+	assert.Equal(t, "/apiId", (*problemDetails.InvalidParams)[0].Param)
+}
+
+func TestAdminRoutesAreOnlyServedOnAdminListener(t *testing.T) {
+	core := capifcore.NewCapifCore(nil, nil, "invokerrealm", nil, nil)
+	e := echo.New()
+	core.RegisterHandlers(e, false, validation.ResponseValidationOff)
+	eAdmin := echo.New()
+	core.RegisterAdminHandlers(eAdmin)
+
+	result := testutil.NewRequest().Get("/capif-events/v1/dead-letters").Go(t, e)
+	assert.Equal(t, http.StatusNotFound, result.Code())
+
+	result = testutil.NewRequest().Get("/capif-events/v1/dead-letters").Go(t, eAdmin)
+	assert.Equal(t, http.StatusOK, result.Code())
+	result = testutil.NewRequest().Get("/api-provider-management/v1/registrations/provider").Go(t, eAdmin)
+	assert.Equal(t, http.StatusNotFound, result.Code())
+}
+
+func TestIntrospectionRequiresProviderAuthentication(t *testing.T) {
+	e := echo.New()
+	capifcore.RegisterHandlers(e, nil, nil, nil, nil, false)
+	funcInfo := "rApp as AEF"
+	provider := providermanagementapi.APIProviderEnrolmentDetails{
+		RegSec: "sec",
+		ApiProvFuncs: &[]providermanagementapi.APIProviderFunctionDetails{
+			{
+				ApiProvFuncInfo: &funcInfo,
+				ApiProvFuncRole: providermanagementapi.ApiProviderFuncRoleAEF,
+				RegInfo: providermanagementapi.RegistrationInformation{
+					ApiProvPubKey: "key",
+				},
+			},
+		},
+	}
+	result := testutil.NewRequest().Post("/api-provider-management/v1/registrations").WithJsonBody(provider).Go(t, e)
+	assert.Equal(t, http.StatusCreated, result.Code())
+	err := result.UnmarshalBodyToObject(&provider)
+	assert.NoError(t, err)
+	aefId := *(*provider.ApiProvFuncs)[0].ApiProvFuncId
+
+	introspect := func(credentials string) int {
+		request := testutil.NewRequest().Post("/capif-security/v1/introspect").WithContentType("application/x-www-form-urlencoded").WithBody([]byte("token=token"))
+		if credentials != "" {
+			request = request.WithHeader(echo.HeaderAuthorization, "Basic "+base64.StdEncoding.EncodeToString([]byte(credentials)))
+		}
+		return request.Go(t, e).Code()
+	}
+	assert.Equal(t, http.StatusUnauthorized, introspect(""))
+	assert.Equal(t, http.StatusUnauthorized, introspect(aefId+":wrongSec"))
+	assert.Equal(t, http.StatusUnauthorized, introspect("unknownAefId:sec"))
+	assert.Equal(t, http.StatusOK, introspect(aefId+":sec"))
 }
 
 func TestHTTPSServer(t *testing.T) {
 	e := echo.New()
-	capifcore.RegisterHandlers(e, nil, nil)
+	capifcore.RegisterHandlers(e, nil, nil, nil, nil, false)
 
 	var port = 44333
-	go startHttpsWebServer(e, 44333, "../certs/cert.pem", "../certs/key.pem") //"certs/test/cert.pem", "certs/test/key.pem"
+	tlsConfig, err := getTlsConfig("../certs/cert.pem", "../certs/key.pem", "")
+	assert.NoError(t, err)
+	go startHttpsWebServer(e, 44333, tlsConfig)
 
 	time.Sleep(100 * time.Millisecond)
 
@@ -226,3 +386,77 @@ func TestHTTPSServer(t *testing.T) {
 	expected := []byte("Hello, World!")
 	assert.Equal(t, expected, body)
 }
+
+func TestHTTPSServerRequiresClientCertificates(t *testing.T) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	caTemplate := x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Client CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caCert, err := x509.CreateCertificate(rand.Reader, &caTemplate, &caTemplate, caKey.Public(), caKey)
+	assert.NoError(t, err)
+	caPath := filepath.Join(t.TempDir(), "ca.pem")
+	err = os.WriteFile(caPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caCert}), 0600)
+	assert.NoError(t, err)
+	clientKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	clientTemplate := x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "rApp"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	clientCert, err := x509.CreateCertificate(rand.Reader, &clientTemplate, &caTemplate, clientKey.Public(), caKey)
+	assert.NoError(t, err)
+
+	e := echo.New()
+	capifcore.RegisterHandlers(e, nil, nil, nil, nil, true)
+	tlsConfig, err := getTlsConfig("../certs/cert.pem", "../certs/key.pem", caPath)
+	assert.NoError(t, err)
+	var port = 44334
+	go startHttpsWebServer(e, port, tlsConfig)
+
+	time.Sleep(100 * time.Millisecond)
+
+	provider := `{"regSec":"sec","apiProvDomInfo":"rApp domain","apiProvFuncs":[{"apiProvFuncRole":"APF","apiProvFuncInfo":"rApp as APF","regInfo":{"apiProvPubKey":"key"}}]}`
+	url := fmt.Sprintf("https://localhost:%d/api-provider-management/v1/registrations", port)
+
+	// Register a provider without client certificate, should get 401
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	res, err := client.Post(url, "application/json", strings.NewReader(provider))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+	// Register a provider with a client certificate trusted by the CA, should get 201
+	client = &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+		InsecureSkipVerify: true,
+		Certificates:       []tls.Certificate{{Certificate: [][]byte{clientCert}, PrivateKey: clientKey}},
+	}}}
+	res, err = client.Post(url, "application/json", strings.NewReader(provider))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+
+	// Deregister the provider with a certificate not belonging to any of its functions, should get 403
+	req, err := http.NewRequest(http.MethodDelete, res.Header.Get(echo.HeaderLocation), nil)
+	assert.NoError(t, err)
+	res, err = client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	assert.Equal(t, http.StatusForbidden, res.StatusCode)
+}
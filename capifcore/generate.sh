@@ -87,6 +87,20 @@ mv temp.yaml TS29222_CAPIF_Publish_Service_API.yaml
 sed '/oneOf.*/,+2d' TS29222_CAPIF_Security_API.yaml >temp.yaml
 mv temp.yaml TS29222_CAPIF_Security_API.yaml
 
+# Add the API provider events from release 18 of the specification.
+sed -e 's/^\( *\)- API_TOPOLOGY_HIDING_REVOKED$/&\n\1- API_PROVIDER_REGISTERED\n\1- API_PROVIDER_UPDATED\n\1- API_PROVIDER_DEREGISTERED/' \
+    -e 's/^\( *\)- API_TOPOLOGY_HIDING_REVOKED: .*$/&\n\1- API_PROVIDER_REGISTERED: Events related to the registration of an API provider domain to CAPIF.\n\1- API_PROVIDER_UPDATED: Events related to the update of an API provider domain registered to CAPIF.\n\1- API_PROVIDER_DEREGISTERED: Events related to the deregistration of an API provider domain from CAPIF./' \
+    -e "/^ *apiTopoHide:$/{N;s/^\( *\)apiTopoHide:\n\( *\).*$/&\n\1apiProviderDomInfo:\n\2\$ref: 'TS29222_CAPIF_API_Provider_Management_API.yaml#\/components\/schemas\/APIProviderEnrolmentDetails'/}" \
+    TS29222_CAPIF_Events_API.yaml >temp.yaml
+mv temp.yaml TS29222_CAPIF_Events_API.yaml
+
+# CAPIF Core extension, not part of 3GPP. Add the events of the access requests, where providers approve the access of
+# invokers to their APIs.
+sed -e 's/^\( *\)- API_PROVIDER_DEREGISTERED$/&\n\1- API_ACCESS_REQUESTED\n\1- API_ACCESS_APPROVED\n\1- API_ACCESS_REJECTED/' \
+    -e 's/^\( *\)- API_PROVIDER_DEREGISTERED: .*$/&\n\1- API_ACCESS_REQUESTED: Events related to a request of an API invoker for access to a service API.\n\1- API_ACCESS_APPROVED: Events related to the approval of a request for access to a service API by the API provider.\n\1- API_ACCESS_REJECTED: Events related to the rejection of a request for access to a service API by the API provider./' \
+    TS29222_CAPIF_Events_API.yaml >temp.yaml
+mv temp.yaml TS29222_CAPIF_Events_API.yaml
+
 # Replace references to external specs that are collected to the common spec by the commoncollector
 # <replacements_start>
 cat TS29122_CommonData.yaml | sed 's/TS29572_Nlmf_Location/CommonData/g' > temp.yaml
@@ -0,0 +1,58 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package accesscontrolpolicyapi
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+func (p ApiInvokerPolicy) Validate() error {
+	if len(strings.TrimSpace(p.ApiInvokerId)) == 0 {
+		return errors.New("ApiInvokerPolicy missing required apiInvokerId")
+	}
+
+	if p.AllowedTotalInvocations != nil && *p.AllowedTotalInvocations < 0 {
+		return errors.New("ApiInvokerPolicy has negative allowedTotalInvocations")
+	}
+
+	if p.AllowedInvocationsPerSecond != nil && *p.AllowedInvocationsPerSecond < 0 {
+		return errors.New("ApiInvokerPolicy has negative allowedInvocationsPerSecond")
+	}
+
+	if p.AllowedInvocationTimeRangeList != nil {
+		for i, timeRange := range *p.AllowedInvocationTimeRangeList {
+			if err := timeRange.Validate(); err != nil {
+				return fmt.Errorf("ApiInvokerPolicy has invalid time range at index %d, err=%s", i, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (tr TimeRangeList) Validate() error {
+	if tr.StartTime != nil && tr.StopTime != nil && time.Time(*tr.StopTime).Before(time.Time(*tr.StartTime)) {
+		return errors.New("TimeRangeList stopTime is before startTime")
+	}
+	return nil
+}
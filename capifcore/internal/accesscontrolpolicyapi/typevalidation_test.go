@@ -0,0 +1,77 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package accesscontrolpolicyapi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"oransc.org/nonrtric/capifcore/internal/common29122"
+)
+
+func TestValidateApiInvokerPolicy(t *testing.T) {
+	policyUnderTest := ApiInvokerPolicy{}
+
+	err := policyUnderTest.Validate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "missing")
+		assert.Contains(t, err.Error(), "apiInvokerId")
+	}
+
+	policyUnderTest.ApiInvokerId = "invokerId"
+	negative := -1
+	policyUnderTest.AllowedTotalInvocations = &negative
+	err = policyUnderTest.Validate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "negative")
+		assert.Contains(t, err.Error(), "allowedTotalInvocations")
+	}
+
+	total := 100
+	policyUnderTest.AllowedTotalInvocations = &total
+	policyUnderTest.AllowedInvocationsPerSecond = &negative
+	err = policyUnderTest.Validate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "negative")
+		assert.Contains(t, err.Error(), "allowedInvocationsPerSecond")
+	}
+
+	perSecond := 10
+	policyUnderTest.AllowedInvocationsPerSecond = &perSecond
+	start := common29122.DateTime(time.Date(2026, time.January, 2, 0, 0, 0, 0, time.UTC))
+	stop := common29122.DateTime(time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC))
+	policyUnderTest.AllowedInvocationTimeRangeList = &[]TimeRangeList{
+		{
+			StartTime: &start,
+			StopTime:  &stop,
+		},
+	}
+	err = policyUnderTest.Validate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "invalid time range at index 0")
+		assert.Contains(t, err.Error(), "stopTime is before startTime")
+	}
+
+	(*policyUnderTest.AllowedInvocationTimeRangeList)[0].StartTime = &stop
+	(*policyUnderTest.AllowedInvocationTimeRangeList)[0].StopTime = &start
+	assert.Nil(t, policyUnderTest.Validate())
+}
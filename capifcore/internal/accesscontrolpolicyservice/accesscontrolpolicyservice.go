@@ -0,0 +1,333 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package accesscontrolpolicyservice
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	echo "github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+
+	acpapi "oransc.org/nonrtric/capifcore/internal/accesscontrolpolicyapi"
+	"oransc.org/nonrtric/capifcore/internal/eventsapi"
+	"oransc.org/nonrtric/capifcore/internal/publishservice"
+	"oransc.org/nonrtric/capifcore/internal/storage"
+	"oransc.org/nonrtric/capifcore/problemdetails"
+)
+
+const accessControlPoliciesBucket = "accessControlPolicies"
+
+//go:generate mockery --name AccessControlPolicyRegister
+type AccessControlPolicyRegister interface {
+	// Adds a policy for the invoker to the access control policy list of the API exposed by the AEF.
+	// Nothing is changed if the invoker already has a policy in the list.
+	AddInvokerPolicy(apiId, aefId, invokerId string)
+	// Removes the invoker's policy from the access control policy list of the API exposed by the AEF.
+	RemoveInvokerPolicy(apiId, aefId, invokerId string)
+	// Removes the invoker's policies from all access control policy lists.
+	RemoveInvoker(invokerId string)
+}
+
+type AccessControlPolicyService struct {
+	// Access control policy lists per API id and AEF id.
+	policies        map[string]map[string]acpapi.AccessControlPolicyList
+	publishRegister publishservice.PublishRegister
+	eventChannel    chan<- eventsapi.EventNotification
+	store           storage.Store
+	lock            sync.Mutex
+}
+
+// Creates a service that implements both the AccessControlPolicyRegister and the accesscontrolpolicyapi.ServerInterface
+// interfaces.
+// Access control policy lists kept in the provided store are loaded at creation.
+func NewAccessControlPolicyService(publishRegister publishservice.PublishRegister, eventChannel chan<- eventsapi.EventNotification, store storage.Store) *AccessControlPolicyService {
+	acps := &AccessControlPolicyService{
+		policies:        make(map[string]map[string]acpapi.AccessControlPolicyList),
+		publishRegister: publishRegister,
+		eventChannel:    eventChannel,
+		store:           store,
+	}
+	if err := storage.Load(store, accessControlPoliciesBucket, acps.policies); err != nil {
+		log.Errorf("Unable to load access control policies due to %s", err)
+	}
+	return acps
+}
+
+func (acps *AccessControlPolicyService) AddInvokerPolicy(apiId, aefId, invokerId string) {
+	acps.lock.Lock()
+	defer acps.lock.Unlock()
+
+	policyList := acps.policies[apiId][aefId]
+	if getInvokerPolicy(policyList, invokerId) != nil {
+		return
+	}
+	policies := append(getInvokerPolicies(policyList), acpapi.ApiInvokerPolicy{ApiInvokerId: invokerId})
+	policyList.ApiInvokerPolicies = &policies
+	acps.setPolicyList(apiId, aefId, policyList)
+
+	go acps.sendEvent(apiId, invokerId, policyList, eventsapi.CAPIFEventACCESSCONTROLPOLICYUPDATE)
+}
+
+func (acps *AccessControlPolicyService) RemoveInvokerPolicy(apiId, aefId, invokerId string) {
+	acps.lock.Lock()
+	defer acps.lock.Unlock()
+
+	acps.removeInvokerPolicy(apiId, aefId, invokerId)
+}
+
+func (acps *AccessControlPolicyService) RemoveInvoker(invokerId string) {
+	acps.lock.Lock()
+	defer acps.lock.Unlock()
+
+	for apiId, aefPolicies := range acps.policies {
+		for aefId := range aefPolicies {
+			acps.removeInvokerPolicy(apiId, aefId, invokerId)
+		}
+	}
+}
+
+// Must be called with the lock held.
+func (acps *AccessControlPolicyService) removeInvokerPolicy(apiId, aefId, invokerId string) {
+	policyList, ok := acps.policies[apiId][aefId]
+	if !ok || getInvokerPolicy(policyList, invokerId) == nil {
+		return
+	}
+
+	remainingPolicies := []acpapi.ApiInvokerPolicy{}
+	for _, policy := range getInvokerPolicies(policyList) {
+		if policy.ApiInvokerId != invokerId {
+			remainingPolicies = append(remainingPolicies, policy)
+		}
+	}
+	policyList.ApiInvokerPolicies = &remainingPolicies
+
+	if len(remainingPolicies) == 0 {
+		acps.deletePolicyList(apiId, aefId)
+		go acps.sendEvent(apiId, invokerId, policyList, eventsapi.CAPIFEventACCESSCONTROLPOLICYUNAVAILABLE)
+	} else {
+		acps.setPolicyList(apiId, aefId, policyList)
+		go acps.sendEvent(apiId, invokerId, policyList, eventsapi.CAPIFEventACCESSCONTROLPOLICYUPDATE)
+	}
+}
+
+// Retrieves the access control policy list of the API exposed by the AEF.
+func (acps *AccessControlPolicyService) GetAccessControlPolicyListServiceApiId(ctx echo.Context, serviceApiId string, params acpapi.GetAccessControlPolicyListServiceApiIdParams) error {
+	errMsg := "Unable to get access control policy list due to %s"
+
+	acps.lock.Lock()
+	policyList, ok := acps.policies[serviceApiId][params.AefId]
+	acps.lock.Unlock()
+
+	if !ok {
+		return problemdetails.Send(ctx, http.StatusNotFound, problemdetails.CauseContextNotFound, fmt.Sprintf(errMsg, fmt.Sprintf("no access control policy list for api %s and aef %s", serviceApiId, params.AefId)))
+	}
+
+	if params.ApiInvokerId != nil {
+		invokerPolicy := getInvokerPolicy(policyList, *params.ApiInvokerId)
+		if invokerPolicy == nil {
+			return problemdetails.Send(ctx, http.StatusNotFound, problemdetails.CauseContextNotFound, fmt.Sprintf(errMsg, fmt.Sprintf("no policy for invoker %s", *params.ApiInvokerId)))
+		}
+		policyList = acpapi.AccessControlPolicyList{
+			ApiInvokerPolicies: &[]acpapi.ApiInvokerPolicy{*invokerPolicy},
+		}
+	}
+
+	err := ctx.JSON(http.StatusOK, policyList)
+	if err != nil {
+		// Something really bad happened, tell Echo that our handler failed
+		return err
+	}
+
+	return nil
+}
+
+// Updates the policy of an invoker in the access control policy list of the API exposed by the AEF given by the
+// "aef-id" query parameter. This operation is not part of the 3GPP API, it lets the provider of the API adjust the
+// policies created when invokers are granted access. The API publishing function making the update is given by the
+// "apf-id" query parameter, and must be the one that published the API.
+func (acps *AccessControlPolicyService) PutApiInvokerPolicy(ctx echo.Context) error {
+	errMsg := "Unable to update access control policy due to %s"
+
+	serviceApiId := ctx.Param("serviceApiId")
+	apiInvokerId := ctx.Param("apiInvokerId")
+	aefId := ctx.QueryParam("aef-id")
+	if aefId == "" {
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseMandatoryQueryParamMissing, fmt.Sprintf(errMsg, "missing required query parameter aef-id"))
+	}
+	apfId := ctx.QueryParam("apf-id")
+	if apfId == "" {
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseMandatoryQueryParamMissing, fmt.Sprintf(errMsg, "missing required query parameter apf-id"))
+	}
+
+	if acps.publishRegister.GetPublishingFunction(serviceApiId) != apfId {
+		return problemdetails.Send(ctx, http.StatusForbidden, problemdetails.CauseRequestNotAuthorized, fmt.Sprintf(errMsg, fmt.Sprintf("api %s is not published by %s", serviceApiId, apfId)))
+	}
+
+	var updatedPolicy acpapi.ApiInvokerPolicy
+	if err := ctx.Bind(&updatedPolicy); err != nil {
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseInvalidMsgFormat, fmt.Sprintf(errMsg, "invalid format for api invoker policy"))
+	}
+
+	if err := updatedPolicy.Validate(); err != nil {
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseMandatoryIeIncorrect, fmt.Sprintf(errMsg, err))
+	}
+
+	if updatedPolicy.ApiInvokerId != apiInvokerId {
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseMandatoryIeIncorrect, fmt.Sprintf(errMsg, "ApiInvokerPolicy apiInvokerId doesn't match path parameter"))
+	}
+
+	if err := acps.updateInvokerPolicy(serviceApiId, aefId, updatedPolicy); err != nil {
+		return problemdetails.Send(ctx, http.StatusNotFound, problemdetails.CauseContextNotFound, fmt.Sprintf(errMsg, err))
+	}
+
+	err := ctx.JSON(http.StatusOK, updatedPolicy)
+	if err != nil {
+		// Something really bad happened, tell Echo that our handler failed
+		return err
+	}
+
+	return nil
+}
+
+// Adds the operation that updates the policy of an invoker to the specification of the API, so that its requests are
+// validated like the requests of the 3GPP operation.
+func AddProviderOperations(swagger *openapi3.T) {
+	pathItem := swagger.Paths["/accessControlPolicyList/{serviceApiId}"]
+	policySchema := swagger.Components.Schemas["ApiInvokerPolicy"]
+	if pathItem == nil || pathItem.Get == nil || policySchema == nil {
+		return
+	}
+	parameters := openapi3.Parameters{}
+	for _, parameter := range pathItem.Get.Parameters {
+		if parameter.Value != nil && parameter.Value.In == openapi3.ParameterInPath {
+			parameters = append(parameters, parameter)
+		}
+	}
+	parameters = append(parameters,
+		&openapi3.ParameterRef{Value: openapi3.NewPathParameter("apiInvokerId").WithSchema(openapi3.NewStringSchema())},
+		&openapi3.ParameterRef{Value: openapi3.NewQueryParameter("aef-id").WithRequired(true).WithSchema(openapi3.NewStringSchema())},
+		&openapi3.ParameterRef{Value: openapi3.NewQueryParameter("apf-id").WithRequired(true).WithSchema(openapi3.NewStringSchema())})
+	responses := openapi3.Responses{}
+	for status, response := range pathItem.Get.Responses {
+		if status != "200" {
+			responses[status] = response
+		}
+	}
+	responses["200"] = &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("OK.").WithJSONSchemaRef(policySchema)}
+
+	swagger.Paths["/accessControlPolicyList/{serviceApiId}/apiInvokerPolicies/{apiInvokerId}"] = &openapi3.PathItem{
+		Put: &openapi3.Operation{
+			Description: "Updates the policy of an invoker in the access control policy list of an AEF.",
+			OperationID: "PutApiInvokerPolicy",
+			Parameters:  parameters,
+			RequestBody: &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().WithRequired(true).WithJSONSchemaRef(policySchema)},
+			Responses:   responses,
+		},
+	}
+}
+
+func (acps *AccessControlPolicyService) updateInvokerPolicy(apiId, aefId string, updatedPolicy acpapi.ApiInvokerPolicy) error {
+	acps.lock.Lock()
+	defer acps.lock.Unlock()
+
+	policyList, ok := acps.policies[apiId][aefId]
+	if !ok || getInvokerPolicy(policyList, updatedPolicy.ApiInvokerId) == nil {
+		return fmt.Errorf("invoker %s has not been granted access to api %s on aef %s", updatedPolicy.ApiInvokerId, apiId, aefId)
+	}
+
+	policies := []acpapi.ApiInvokerPolicy{}
+	for _, policy := range getInvokerPolicies(policyList) {
+		if policy.ApiInvokerId == updatedPolicy.ApiInvokerId {
+			policy = updatedPolicy
+		}
+		policies = append(policies, policy)
+	}
+	policyList.ApiInvokerPolicies = &policies
+	acps.setPolicyList(apiId, aefId, policyList)
+
+	go acps.sendEvent(apiId, updatedPolicy.ApiInvokerId, policyList, eventsapi.CAPIFEventACCESSCONTROLPOLICYUPDATE)
+	return nil
+}
+
+// Must be called with the lock held.
+func (acps *AccessControlPolicyService) setPolicyList(apiId, aefId string, policyList acpapi.AccessControlPolicyList) {
+	if _, ok := acps.policies[apiId]; !ok {
+		acps.policies[apiId] = make(map[string]acpapi.AccessControlPolicyList)
+	}
+	acps.policies[apiId][aefId] = policyList
+	acps.storePolicies(apiId)
+}
+
+// Must be called with the lock held.
+func (acps *AccessControlPolicyService) deletePolicyList(apiId, aefId string) {
+	delete(acps.policies[apiId], aefId)
+	if len(acps.policies[apiId]) == 0 {
+		delete(acps.policies, apiId)
+		if err := acps.store.Delete(accessControlPoliciesBucket, apiId); err != nil {
+			log.Errorf("Unable to remove stored access control policies for %s due to %s", apiId, err)
+		}
+		return
+	}
+	acps.storePolicies(apiId)
+}
+
+// Must be called with the lock held.
+func (acps *AccessControlPolicyService) storePolicies(apiId string) {
+	if err := acps.store.Put(accessControlPoliciesBucket, apiId, acps.policies[apiId]); err != nil {
+		log.Errorf("Unable to store access control policies for %s due to %s", apiId, err)
+	}
+}
+
+func (acps *AccessControlPolicyService) sendEvent(apiId, invokerId string, policyList acpapi.AccessControlPolicyList, eventType eventsapi.CAPIFEvent) {
+	apiIds := []string{apiId}
+	invokerIds := []string{invokerId}
+	event := eventsapi.EventNotification{
+		EventDetail: &eventsapi.CAPIFEventDetail{
+			AccCtrlPolList: &eventsapi.AccessControlPolicyListExt{
+				AccessControlPolicyList: policyList,
+				ApiId:                   &apiId,
+			},
+			ApiIds:        &apiIds,
+			ApiInvokerIds: &invokerIds,
+		},
+		Events: eventType,
+	}
+	acps.eventChannel <- event
+}
+
+func getInvokerPolicies(policyList acpapi.AccessControlPolicyList) []acpapi.ApiInvokerPolicy {
+	if policyList.ApiInvokerPolicies == nil {
+		return []acpapi.ApiInvokerPolicy{}
+	}
+	return *policyList.ApiInvokerPolicies
+}
+
+func getInvokerPolicy(policyList acpapi.AccessControlPolicyList, invokerId string) *acpapi.ApiInvokerPolicy {
+	for _, policy := range getInvokerPolicies(policyList) {
+		if policy.ApiInvokerId == invokerId {
+			return &policy
+		}
+	}
+	return nil
+}
@@ -0,0 +1,301 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package accesscontrolpolicyservice
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/deepmap/oapi-codegen/pkg/middleware"
+	"github.com/deepmap/oapi-codegen/pkg/testutil"
+	"github.com/labstack/echo/v4"
+	echomiddleware "github.com/labstack/echo/v4/middleware"
+	"github.com/stretchr/testify/assert"
+
+	acpapi "oransc.org/nonrtric/capifcore/internal/accesscontrolpolicyapi"
+	"oransc.org/nonrtric/capifcore/internal/common29122"
+	"oransc.org/nonrtric/capifcore/internal/eventsapi"
+	publishmocks "oransc.org/nonrtric/capifcore/internal/publishservice/mocks"
+	"oransc.org/nonrtric/capifcore/internal/storage/storagetest"
+	"oransc.org/nonrtric/capifcore/validation"
+)
+
+const (
+	apiId = "apiId"
+	aefId = "aefId"
+	apfId = "apfId"
+)
+
+func TestAddInvokerPolicy(t *testing.T) {
+	requestHandler, acpsUnderTest, eventChannel := getEcho()
+
+	acpsUnderTest.AddInvokerPolicy(apiId, aefId, "invokerId1")
+
+	assertPolicyListEvent(t, eventChannel, eventsapi.CAPIFEventACCESSCONTROLPOLICYUPDATE, "invokerId1", []acpapi.ApiInvokerPolicy{{ApiInvokerId: "invokerId1"}})
+
+	acpsUnderTest.AddInvokerPolicy(apiId, aefId, "invokerId2")
+
+	assertPolicyListEvent(t, eventChannel, eventsapi.CAPIFEventACCESSCONTROLPOLICYUPDATE, "invokerId2", []acpapi.ApiInvokerPolicy{{ApiInvokerId: "invokerId1"}, {ApiInvokerId: "invokerId2"}})
+
+	// Adding an invoker that already has a policy changes nothing
+	acpsUnderTest.AddInvokerPolicy(apiId, aefId, "invokerId1")
+	if _, timedOut := waitForEvent(eventChannel, 100*time.Millisecond); !timedOut {
+		assert.Fail(t, "Event sent for unchanged policy list")
+	}
+
+	result := testutil.NewRequest().Get("/accessControlPolicyList/"+apiId+"?aef-id="+aefId).Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusOK, result.Code())
+	var resultList acpapi.AccessControlPolicyList
+	err := result.UnmarshalJsonToObject(&resultList)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, []acpapi.ApiInvokerPolicy{{ApiInvokerId: "invokerId1"}, {ApiInvokerId: "invokerId2"}}, *resultList.ApiInvokerPolicies)
+
+	result = testutil.NewRequest().Get("/accessControlPolicyList/"+apiId+"?aef-id="+aefId+"&api-invoker-id=invokerId2").Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusOK, result.Code())
+	err = result.UnmarshalJsonToObject(&resultList)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, []acpapi.ApiInvokerPolicy{{ApiInvokerId: "invokerId2"}}, *resultList.ApiInvokerPolicies)
+}
+
+func TestGetMissingPolicyList(t *testing.T) {
+	requestHandler, acpsUnderTest, eventChannel := getEcho()
+	acpsUnderTest.AddInvokerPolicy(apiId, aefId, "invokerId")
+	waitForEvent(eventChannel, 1*time.Second)
+
+	result := testutil.NewRequest().Get("/accessControlPolicyList/"+apiId+"?aef-id=otherAefId").Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusNotFound, result.Code())
+	var problemDetails common29122.ProblemDetails
+	err := result.UnmarshalJsonToObject(&problemDetails)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Contains(t, *problemDetails.Detail, "no access control policy list for api apiId and aef otherAefId")
+
+	result = testutil.NewRequest().Get("/accessControlPolicyList/"+apiId+"?aef-id="+aefId+"&api-invoker-id=otherInvokerId").Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusNotFound, result.Code())
+	err = result.UnmarshalJsonToObject(&problemDetails)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Contains(t, *problemDetails.Detail, "no policy for invoker otherInvokerId")
+}
+
+func TestRemoveInvokerPolicy(t *testing.T) {
+	requestHandler, acpsUnderTest, eventChannel := getEcho()
+	acpsUnderTest.AddInvokerPolicy(apiId, aefId, "invokerId1")
+	waitForEvent(eventChannel, 1*time.Second)
+	acpsUnderTest.AddInvokerPolicy(apiId, aefId, "invokerId2")
+	waitForEvent(eventChannel, 1*time.Second)
+
+	acpsUnderTest.RemoveInvokerPolicy(apiId, aefId, "invokerId1")
+
+	assertPolicyListEvent(t, eventChannel, eventsapi.CAPIFEventACCESSCONTROLPOLICYUPDATE, "invokerId1", []acpapi.ApiInvokerPolicy{{ApiInvokerId: "invokerId2"}})
+
+	acpsUnderTest.RemoveInvoker("invokerId2")
+
+	assertPolicyListEvent(t, eventChannel, eventsapi.CAPIFEventACCESSCONTROLPOLICYUNAVAILABLE, "invokerId2", []acpapi.ApiInvokerPolicy{})
+	result := testutil.NewRequest().Get("/accessControlPolicyList/"+apiId+"?aef-id="+aefId).Go(t, requestHandler)
+	assert.Equal(t, http.StatusNotFound, result.Code())
+	assert.Empty(t, acpsUnderTest.policies)
+}
+
+func TestPutApiInvokerPolicy(t *testing.T) {
+	requestHandler, acpsUnderTest, eventChannel := getEcho()
+	acpsUnderTest.AddInvokerPolicy(apiId, aefId, "invokerId")
+	waitForEvent(eventChannel, 1*time.Second)
+
+	allowedTotalInvocations := 10
+	policy := acpapi.ApiInvokerPolicy{
+		AllowedTotalInvocations: &allowedTotalInvocations,
+		ApiInvokerId:            "invokerId",
+	}
+	result := testutil.NewRequest().Put("/accessControlPolicyList/"+apiId+"/apiInvokerPolicies/invokerId?aef-id="+aefId+"&apf-id="+apfId).WithJsonBody(policy).Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusOK, result.Code())
+	var resultPolicy acpapi.ApiInvokerPolicy
+	err := result.UnmarshalJsonToObject(&resultPolicy)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, policy, resultPolicy)
+	assert.Equal(t, []acpapi.ApiInvokerPolicy{policy}, *acpsUnderTest.policies[apiId][aefId].ApiInvokerPolicies)
+	assertPolicyListEvent(t, eventChannel, eventsapi.CAPIFEventACCESSCONTROLPOLICYUPDATE, "invokerId", []acpapi.ApiInvokerPolicy{policy})
+}
+
+func TestPutApiInvokerPolicyFailures(t *testing.T) {
+	requestHandler, acpsUnderTest, eventChannel := getEcho()
+	acpsUnderTest.AddInvokerPolicy(apiId, aefId, "invokerId")
+	waitForEvent(eventChannel, 1*time.Second)
+
+	negativeInvocations := -1
+	type args struct {
+		url    string
+		policy acpapi.ApiInvokerPolicy
+	}
+	tests := []struct {
+		name      string
+		args      args
+		wantCode  int
+		wantCause string
+	}{
+		{
+			name:      "Missing aef-id",
+			args:      args{url: "/accessControlPolicyList/" + apiId + "/apiInvokerPolicies/invokerId", policy: acpapi.ApiInvokerPolicy{ApiInvokerId: "invokerId"}},
+			wantCode:  http.StatusBadRequest,
+			wantCause: "missing required query parameter aef-id",
+		},
+		{
+			name:      "Missing apf-id",
+			args:      args{url: "/accessControlPolicyList/" + apiId + "/apiInvokerPolicies/invokerId?aef-id=" + aefId, policy: acpapi.ApiInvokerPolicy{ApiInvokerId: "invokerId"}},
+			wantCode:  http.StatusBadRequest,
+			wantCause: "missing required query parameter apf-id",
+		},
+		{
+			name:      "API not published by the APF",
+			args:      args{url: "/accessControlPolicyList/" + apiId + "/apiInvokerPolicies/invokerId?aef-id=" + aefId + "&apf-id=otherApfId", policy: acpapi.ApiInvokerPolicy{ApiInvokerId: "invokerId"}},
+			wantCode:  http.StatusForbidden,
+			wantCause: "api apiId is not published by otherApfId",
+		},
+		{
+			name:      "Invalid policy",
+			args:      args{url: "/accessControlPolicyList/" + apiId + "/apiInvokerPolicies/invokerId?aef-id=" + aefId + "&apf-id=" + apfId, policy: acpapi.ApiInvokerPolicy{AllowedTotalInvocations: &negativeInvocations, ApiInvokerId: "invokerId"}},
+			wantCode:  http.StatusBadRequest,
+			wantCause: "ApiInvokerPolicy has negative allowedTotalInvocations",
+		},
+		{
+			name:      "Invoker not matching path",
+			args:      args{url: "/accessControlPolicyList/" + apiId + "/apiInvokerPolicies/invokerId?aef-id=" + aefId + "&apf-id=" + apfId, policy: acpapi.ApiInvokerPolicy{ApiInvokerId: "otherInvokerId"}},
+			wantCode:  http.StatusBadRequest,
+			wantCause: "ApiInvokerPolicy apiInvokerId doesn't match path parameter",
+		},
+		{
+			name:      "Invoker without access",
+			args:      args{url: "/accessControlPolicyList/" + apiId + "/apiInvokerPolicies/otherInvokerId?aef-id=" + aefId + "&apf-id=" + apfId, policy: acpapi.ApiInvokerPolicy{ApiInvokerId: "otherInvokerId"}},
+			wantCode:  http.StatusNotFound,
+			wantCause: "invoker otherInvokerId has not been granted access to api apiId on aef aefId",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := testutil.NewRequest().Put(tt.args.url).WithJsonBody(tt.args.policy).Go(t, requestHandler)
+
+			assert.Equal(t, tt.wantCode, result.Code())
+			var problemDetails common29122.ProblemDetails
+			err := result.UnmarshalJsonToObject(&problemDetails)
+			assert.NoError(t, err, "error unmarshaling response")
+			assert.Contains(t, *problemDetails.Detail, tt.wantCause)
+		})
+	}
+}
+
+func TestProviderOperationsAreValidated(t *testing.T) {
+	swagger, err := acpapi.GetSwagger()
+	assert.NoError(t, err)
+	AddProviderOperations(swagger)
+	publishRegisterMock := publishmocks.PublishRegister{}
+	publishRegisterMock.On("GetPublishingFunction", apiId).Return(apfId)
+	eventChannel := make(chan eventsapi.EventNotification, 10)
+	acps := NewAccessControlPolicyService(&publishRegisterMock, eventChannel, storagetest.NewStore())
+	acps.AddInvokerPolicy(apiId, aefId, "invokerId")
+	e := echo.New()
+	group := validation.NewGroup(e, swagger, "/access-control-policy/v1", validation.ResponseValidationReject)
+	group.PUT("/accessControlPolicyList/:serviceApiId/apiInvokerPolicies/:apiInvokerId", acps.PutApiInvokerPolicy)
+
+	path := "/access-control-policy/v1/accessControlPolicyList/" + apiId + "/apiInvokerPolicies/invokerId"
+	result := testutil.NewRequest().Put(path+"?aef-id="+aefId+"&apf-id="+apfId).WithJsonBody(acpapi.ApiInvokerPolicy{ApiInvokerId: "invokerId"}).Go(t, e)
+
+	assert.Equal(t, http.StatusOK, result.Code())
+
+	result = testutil.NewRequest().Put(path+"?aef-id="+aefId+"&apf-id="+apfId).WithJsonBody(map[string]any{"apiInvokerId": "invokerId", "allowedTotalInvocations": "many"}).Go(t, e)
+
+	assert.Equal(t, http.StatusBadRequest, result.Code())
+	var problemDetails common29122.ProblemDetails
+	err = result.UnmarshalJsonToObject(&problemDetails)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, "/allowedTotalInvocations", (*problemDetails.InvalidParams)[0].Param)
+
+	result = testutil.NewRequest().Put(path+"?aef-id="+aefId).WithJsonBody(acpapi.ApiInvokerPolicy{ApiInvokerId: "invokerId"}).Go(t, e)
+
+	assert.Equal(t, http.StatusBadRequest, result.Code())
+	err = result.UnmarshalJsonToObject(&problemDetails)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, "apf-id", (*problemDetails.InvalidParams)[0].Param)
+}
+
+func TestPoliciesAreLoadedFromStore(t *testing.T) {
+	store := storagetest.NewStore()
+	eventChannel := make(chan eventsapi.EventNotification)
+	acpsUnderTest := NewAccessControlPolicyService(nil, eventChannel, store)
+	acpsUnderTest.AddInvokerPolicy(apiId, aefId, "invokerId")
+	waitForEvent(eventChannel, 1*time.Second)
+
+	restartedService := NewAccessControlPolicyService(nil, eventChannel, store)
+
+	assert.Equal(t, acpsUnderTest.policies, restartedService.policies)
+}
+
+func assertPolicyListEvent(t *testing.T, eventChannel chan eventsapi.EventNotification, eventType eventsapi.CAPIFEvent, invokerId string, wantPolicies []acpapi.ApiInvokerPolicy) {
+	if policyEvent, timedOut := waitForEvent(eventChannel, 1*time.Second); timedOut {
+		assert.Fail(t, "No event sent")
+	} else {
+		assert.Equal(t, eventType, policyEvent.Events)
+		assert.Equal(t, apiId, *policyEvent.EventDetail.AccCtrlPolList.ApiId)
+		assert.Equal(t, wantPolicies, *policyEvent.EventDetail.AccCtrlPolList.ApiInvokerPolicies)
+		assert.Equal(t, []string{apiId}, *policyEvent.EventDetail.ApiIds)
+		assert.Equal(t, []string{invokerId}, *policyEvent.EventDetail.ApiInvokerIds)
+	}
+}
+
+func getEcho() (*echo.Echo, *AccessControlPolicyService, chan eventsapi.EventNotification) {
+	swagger, err := acpapi.GetSwagger()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading swagger spec\n: %s", err)
+		os.Exit(1)
+	}
+
+	swagger.Servers = nil
+
+	publishRegisterMock := publishmocks.PublishRegister{}
+	publishRegisterMock.On("GetPublishingFunction", apiId).Return(apfId)
+	eventChannel := make(chan eventsapi.EventNotification)
+	acps := NewAccessControlPolicyService(&publishRegisterMock, eventChannel, storagetest.NewStore())
+
+	e := echo.New()
+	e.Use(echomiddleware.Logger())
+	group := e.Group("/accessControlPolicyList")
+	group.Use(middleware.OapiRequestValidator(swagger))
+
+	acpapi.RegisterHandlers(e, acps)
+	e.PUT("/accessControlPolicyList/:serviceApiId/apiInvokerPolicies/:apiInvokerId", acps.PutApiInvokerPolicy)
+	return e, acps, eventChannel
+}
+
+// waitForEvent waits for the channel to receive an event for the specified max timeout.
+// Returns true if waiting timed out.
+func waitForEvent(ch chan eventsapi.EventNotification, timeout time.Duration) (*eventsapi.EventNotification, bool) {
+	select {
+	case event := <-ch:
+		return &event, false // completed normally
+	case <-time.After(timeout):
+		return nil, true // timed out
+	}
+}
@@ -0,0 +1,512 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package accessrequestservice
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	echo "github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/utils/strings/slices"
+
+	"oransc.org/nonrtric/capifcore/internal/eventsapi"
+	"oransc.org/nonrtric/capifcore/internal/invokermanagement"
+	invokerapi "oransc.org/nonrtric/capifcore/internal/invokermanagementapi"
+	"oransc.org/nonrtric/capifcore/internal/providermanagement"
+	"oransc.org/nonrtric/capifcore/internal/publishservice"
+	publishapi "oransc.org/nonrtric/capifcore/internal/publishserviceapi"
+	"oransc.org/nonrtric/capifcore/internal/storage"
+	"oransc.org/nonrtric/capifcore/problemdetails"
+)
+
+const accessRequestsBucket = "accessRequests"
+
+type AccessRequestState string
+
+const (
+	AccessRequestStatePending  AccessRequestState = "PENDING"
+	AccessRequestStateApproved AccessRequestState = "APPROVED"
+	AccessRequestStateRejected AccessRequestState = "REJECTED"
+)
+
+// A request of an invoker for access to a published API, which the provider of the API approves or rejects.
+type AccessRequest struct {
+	Id           string `json:"id"`
+	ApiInvokerId string `json:"apiInvokerId"`
+	ApiId        string `json:"apiId"`
+	// The exposing functions of the API when the request was made. The request is handled by the publishing function
+	// of the provider of these functions.
+	AefIds []string           `json:"aefIds"`
+	State  AccessRequestState `json:"state"`
+	// The reason given by the provider when rejecting the request.
+	Reason *string   `json:"reason,omitempty"`
+	Time   time.Time `json:"time"`
+}
+
+// The body of a request for access to an API.
+type NewAccessRequest struct {
+	ApiId string `json:"apiId"`
+}
+
+// The body of a rejection of an access request.
+type Rejection struct {
+	Reason *string `json:"reason,omitempty"`
+}
+
+//go:generate mockery --name AccessGrantHandler
+type AccessGrantHandler interface {
+	// Gives the invoker access to the API of an approved access request.
+	GrantApiAccess(invokerId string, api publishapi.ServiceAPIDescription)
+}
+
+type AccessRequestService struct {
+	accessRequests  map[string]AccessRequest
+	invokerRegister invokermanagement.InvokerRegister
+	publishRegister publishservice.PublishRegister
+	serviceRegister providermanagement.ServiceRegister
+	grantHandlers   []AccessGrantHandler
+	eventChannel    chan<- eventsapi.EventNotification
+	store           storage.Store
+	lock            sync.Mutex
+}
+
+// Creates a service that lets invokers request access to published APIs, and the publishing functions of the
+// providers of the APIs approve or reject the requests. Access requests kept in the provided store are loaded at
+// creation.
+// This service is not part of the 3GPP API.
+func NewAccessRequestService(invokerRegister invokermanagement.InvokerRegister, publishRegister publishservice.PublishRegister, serviceRegister providermanagement.ServiceRegister, eventChannel chan<- eventsapi.EventNotification, store storage.Store) *AccessRequestService {
+	ars := &AccessRequestService{
+		accessRequests:  make(map[string]AccessRequest),
+		invokerRegister: invokerRegister,
+		publishRegister: publishRegister,
+		serviceRegister: serviceRegister,
+		eventChannel:    eventChannel,
+		store:           store,
+	}
+	if err := storage.Load(store, accessRequestsBucket, ars.accessRequests); err != nil {
+		log.Errorf("Unable to load access requests due to %s", err)
+	}
+	return ars
+}
+
+// Adds a handler that gives invokers access to the APIs of approved access requests.
+func (ars *AccessRequestService) AddAccessGrantHandler(handler AccessGrantHandler) {
+	ars.grantHandlers = append(ars.grantHandlers, handler)
+}
+
+// Removes the access requests of an offboarded invoker.
+func (ars *AccessRequestService) RemoveInvoker(invokerId string) {
+	ars.lock.Lock()
+	defer ars.lock.Unlock()
+
+	for id, accessRequest := range ars.accessRequests {
+		if accessRequest.ApiInvokerId == invokerId {
+			ars.deleteAccessRequest(id)
+		}
+	}
+}
+
+// Requests access to a published API for the invoker.
+func (ars *AccessRequestService) PostInvokerAccessRequests(ctx echo.Context) error {
+	errMsg := "Unable to request API access due to %s"
+	invokerId := ctx.Param("apiInvokerId")
+	if !ars.isInvoker(ctx, invokerId) {
+		return sendNotAuthenticated(ctx, errMsg, invokerId)
+	}
+
+	var newAccessRequest NewAccessRequest
+	if err := ctx.Bind(&newAccessRequest); err != nil {
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseInvalidMsgFormat, fmt.Sprintf(errMsg, "invalid format for access request"))
+	}
+	if newAccessRequest.ApiId == "" {
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseMandatoryIeMissing, fmt.Sprintf(errMsg, "missing apiId"))
+	}
+
+	apiList := ars.invokerRegister.GetInvokerApiList(invokerId)
+	if apiList == nil {
+		return problemdetails.Send(ctx, http.StatusNotFound, problemdetails.CauseContextNotFound, fmt.Sprintf(errMsg, "invoker not registered"))
+	}
+	api := ars.publishRegister.GetPublishedService(newAccessRequest.ApiId)
+	if api == nil {
+		return problemdetails.Send(ctx, http.StatusNotFound, problemdetails.CauseContextNotFound, fmt.Sprintf(errMsg, "API not published"))
+	}
+	if isGranted(*apiList, newAccessRequest.ApiId) {
+		return problemdetails.Send(ctx, http.StatusForbidden, problemdetails.CauseModificationNotAllowed, fmt.Sprintf(errMsg, "invoker already has access to the API"))
+	}
+
+	accessRequest, err := ars.requestAccess(invokerId, newAccessRequest.ApiId, *api)
+	if err != nil {
+		return problemdetails.Send(ctx, http.StatusForbidden, problemdetails.CauseModificationNotAllowed, fmt.Sprintf(errMsg, err))
+	}
+
+	uri := ctx.Request().Host + ctx.Request().URL.String()
+	ctx.Response().Header().Set(echo.HeaderLocation, ctx.Scheme()+`://`+path.Join(uri, accessRequest.Id))
+	err = ctx.JSON(http.StatusCreated, accessRequest)
+	if err != nil {
+		// Something really bad happened, tell Echo that our handler failed
+		return err
+	}
+
+	return nil
+}
+
+// Requests access for the invoker to the APIs of the API list it has asked for when it was onboarded or updated. APIs
+// that the invoker has already been granted or requested are skipped, and so are APIs that are not published.
+func (ars *AccessRequestService) RequestApiAccess(invokerId string, apiList invokerapi.APIList) {
+	grantedApis := ars.invokerRegister.GetInvokerApiList(invokerId)
+	if grantedApis == nil {
+		return
+	}
+	for _, requestedApi := range apiList {
+		if requestedApi.ApiId == nil || isGranted(*grantedApis, *requestedApi.ApiId) {
+			continue
+		}
+		api := ars.publishRegister.GetPublishedService(*requestedApi.ApiId)
+		if api == nil {
+			log.Warnf("Unable to request access to API %s for invoker %s due to API not published", *requestedApi.ApiId, invokerId)
+			continue
+		}
+		if _, err := ars.requestAccess(invokerId, *requestedApi.ApiId, *api); err != nil {
+			log.Debugf("Access to API %s not requested for invoker %s due to %s", *requestedApi.ApiId, invokerId, err)
+		}
+	}
+}
+
+func isGranted(apiList invokerapi.APIList, apiId string) bool {
+	for _, grantedApi := range apiList {
+		if grantedApi.ApiId != nil && *grantedApi.ApiId == apiId {
+			return true
+		}
+	}
+	return false
+}
+
+// Adds a pending access request of the invoker for the API, and sends the event that access has been requested.
+func (ars *AccessRequestService) requestAccess(invokerId, apiId string, api publishapi.ServiceAPIDescription) (AccessRequest, error) {
+	accessRequest := AccessRequest{
+		Id:           uuid.NewString(),
+		ApiInvokerId: invokerId,
+		ApiId:        apiId,
+		AefIds:       getAefIds(api),
+		State:        AccessRequestStatePending,
+		Time:         time.Now(),
+	}
+	if err := ars.addAccessRequest(accessRequest); err != nil {
+		return AccessRequest{}, err
+	}
+	go ars.sendEvent(accessRequest, &api, eventsapi.CAPIFEventAPIACCESSREQUESTED)
+	return accessRequest, nil
+}
+
+func getAefIds(api publishapi.ServiceAPIDescription) []string {
+	aefIds := []string{}
+	if api.AefProfiles != nil {
+		for _, profile := range *api.AefProfiles {
+			aefIds = append(aefIds, profile.AefId)
+		}
+	}
+	return aefIds
+}
+
+func (ars *AccessRequestService) addAccessRequest(accessRequest AccessRequest) error {
+	ars.lock.Lock()
+	defer ars.lock.Unlock()
+
+	for _, existingRequest := range ars.accessRequests {
+		if existingRequest.ApiInvokerId == accessRequest.ApiInvokerId && existingRequest.ApiId == accessRequest.ApiId && existingRequest.State == AccessRequestStatePending {
+			return fmt.Errorf("access to the API has already been requested by the invoker")
+		}
+	}
+	ars.putAccessRequest(accessRequest)
+	return nil
+}
+
+// Retrieves the access requests of the invoker, oldest first.
+func (ars *AccessRequestService) GetInvokerAccessRequests(ctx echo.Context) error {
+	invokerId := ctx.Param("apiInvokerId")
+	if !ars.isInvoker(ctx, invokerId) {
+		return sendNotAuthenticated(ctx, "Unable to get access requests due to %s", invokerId)
+	}
+
+	accessRequests := ars.getAccessRequests(func(accessRequest AccessRequest) bool {
+		return accessRequest.ApiInvokerId == invokerId
+	})
+
+	err := ctx.JSON(http.StatusOK, accessRequests)
+	if err != nil {
+		// Something really bad happened, tell Echo that our handler failed
+		return err
+	}
+
+	return nil
+}
+
+// Retrieves an access request of the invoker.
+func (ars *AccessRequestService) GetInvokerAccessRequest(ctx echo.Context) error {
+	invokerId := ctx.Param("apiInvokerId")
+	requestId := ctx.Param("requestId")
+	if !ars.isInvoker(ctx, invokerId) {
+		return sendNotAuthenticated(ctx, "Unable to get access request due to %s", invokerId)
+	}
+
+	accessRequest, ok := ars.getAccessRequest(requestId)
+	if !ok || accessRequest.ApiInvokerId != invokerId {
+		return problemdetails.Send(ctx, http.StatusNotFound, problemdetails.CauseContextNotFound, fmt.Sprintf("Unable to get access request due to no access request with id %s", requestId))
+	}
+
+	err := ctx.JSON(http.StatusOK, accessRequest)
+	if err != nil {
+		// Something really bad happened, tell Echo that our handler failed
+		return err
+	}
+
+	return nil
+}
+
+// Retrieves the access requests for the APIs of the provider of the publishing function, oldest first. The "state"
+// query parameter limits the result to the requests in that state.
+func (ars *AccessRequestService) GetProviderAccessRequests(ctx echo.Context) error {
+	apfId := ctx.Param("apfId")
+	state := AccessRequestState(ctx.QueryParam("state"))
+	if !ars.isPublishingFunction(ctx, apfId) {
+		return sendNotAuthenticated(ctx, "Unable to get access requests due to %s", apfId)
+	}
+
+	aefIds := ars.serviceRegister.GetAefsForPublisher(apfId)
+	accessRequests := ars.getAccessRequests(func(accessRequest AccessRequest) bool {
+		return isHandledBy(accessRequest, aefIds) && (state == "" || accessRequest.State == state)
+	})
+
+	err := ctx.JSON(http.StatusOK, accessRequests)
+	if err != nil {
+		// Something really bad happened, tell Echo that our handler failed
+		return err
+	}
+
+	return nil
+}
+
+// Approves a pending access request for an API of the provider of the publishing function. The invoker is given
+// access to the API.
+func (ars *AccessRequestService) ApproveAccessRequest(ctx echo.Context) error {
+	errMsg := "Unable to approve access request due to %s"
+	apfId := ctx.Param("apfId")
+	requestId := ctx.Param("requestId")
+	if !ars.isPublishingFunction(ctx, apfId) {
+		return sendNotAuthenticated(ctx, errMsg, apfId)
+	}
+
+	var api *publishapi.ServiceAPIDescription
+	accessRequest, err := ars.decideAccessRequest(apfId, requestId, func(accessRequest *AccessRequest) error {
+		if api = ars.publishRegister.GetPublishedService(accessRequest.ApiId); api == nil {
+			return fmt.Errorf("API is no longer published")
+		}
+		accessRequest.State = AccessRequestStateApproved
+		return nil
+	})
+	if err != nil {
+		return sendDecisionError(ctx, errMsg, err)
+	}
+
+	for _, handler := range ars.grantHandlers {
+		handler.GrantApiAccess(accessRequest.ApiInvokerId, *api)
+	}
+	go ars.sendEvent(accessRequest, api, eventsapi.CAPIFEventAPIACCESSAPPROVED)
+
+	err = ctx.JSON(http.StatusOK, accessRequest)
+	if err != nil {
+		// Something really bad happened, tell Echo that our handler failed
+		return err
+	}
+
+	return nil
+}
+
+// Rejects a pending access request for an API of the provider of the publishing function, optionally with a reason.
+func (ars *AccessRequestService) RejectAccessRequest(ctx echo.Context) error {
+	errMsg := "Unable to reject access request due to %s"
+	apfId := ctx.Param("apfId")
+	requestId := ctx.Param("requestId")
+	if !ars.isPublishingFunction(ctx, apfId) {
+		return sendNotAuthenticated(ctx, errMsg, apfId)
+	}
+
+	var rejection Rejection
+	if err := ctx.Bind(&rejection); err != nil {
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseInvalidMsgFormat, fmt.Sprintf(errMsg, "invalid format for rejection"))
+	}
+
+	accessRequest, err := ars.decideAccessRequest(apfId, requestId, func(accessRequest *AccessRequest) error {
+		accessRequest.State = AccessRequestStateRejected
+		accessRequest.Reason = rejection.Reason
+		return nil
+	})
+	if err != nil {
+		return sendDecisionError(ctx, errMsg, err)
+	}
+	go ars.sendEvent(accessRequest, ars.publishRegister.GetPublishedService(accessRequest.ApiId), eventsapi.CAPIFEventAPIACCESSREJECTED)
+
+	err = ctx.JSON(http.StatusOK, accessRequest)
+	if err != nil {
+		// Something really bad happened, tell Echo that our handler failed
+		return err
+	}
+
+	return nil
+}
+
+// Checks that the caller of the request is the invoker, authenticated by its onboarding secret if the request has no
+// client certificate.
+func (ars *AccessRequestService) isInvoker(ctx echo.Context, invokerId string) bool {
+	return isCaller(ctx, invokerId, ars.invokerRegister.VerifyInvokerSecret)
+}
+
+// Checks that the caller of the request is the API publishing function, authenticated by the registration secret,
+// regSec, of its provider if the request has no client certificate.
+func (ars *AccessRequestService) isPublishingFunction(ctx echo.Context, apfId string) bool {
+	return ars.serviceRegister.IsPublishingFunctionRegistered(apfId) && isCaller(ctx, apfId, ars.serviceRegister.VerifyFunctionSecret)
+}
+
+// Checks that the caller of the request is the client with the provided id. A request with a verified client
+// certificate is made by the client named by the common name of the certificate, as in the clientauth package. Without
+// a client certificate, the client authenticates with HTTP basic authentication, using its id and its secret.
+func isCaller(ctx echo.Context, clientId string, verifySecret func(clientId, secret string) bool) bool {
+	if tlsState := ctx.Request().TLS; tlsState != nil && len(tlsState.VerifiedChains) > 0 && len(tlsState.VerifiedChains[0]) > 0 {
+		return tlsState.VerifiedChains[0][0].Subject.CommonName == clientId
+	}
+	id, secret, ok := ctx.Request().BasicAuth()
+	return ok && id == clientId && verifySecret(id, secret)
+}
+
+func sendNotAuthenticated(ctx echo.Context, errMsg, clientId string) error {
+	ctx.Response().Header().Set(echo.HeaderWWWAuthenticate, `Basic realm="CAPIF Core"`)
+	return problemdetails.Send(ctx, http.StatusUnauthorized, "", fmt.Sprintf(errMsg, "caller not authenticated as "+clientId))
+}
+
+type notFoundError struct {
+	requestId string
+}
+
+func (e notFoundError) Error() string {
+	return fmt.Sprintf("no access request with id %s for the provider", e.requestId)
+}
+
+// Applies the decision to the pending access request, if the request is for an API of the provider of the publishing
+// function.
+func (ars *AccessRequestService) decideAccessRequest(apfId, requestId string, decide func(accessRequest *AccessRequest) error) (AccessRequest, error) {
+	aefIds := ars.serviceRegister.GetAefsForPublisher(apfId)
+
+	ars.lock.Lock()
+	defer ars.lock.Unlock()
+
+	accessRequest, ok := ars.accessRequests[requestId]
+	if !ok || !isHandledBy(accessRequest, aefIds) {
+		return AccessRequest{}, notFoundError{requestId: requestId}
+	}
+	if accessRequest.State != AccessRequestStatePending {
+		return AccessRequest{}, fmt.Errorf("access request is %s, not %s", accessRequest.State, AccessRequestStatePending)
+	}
+	if err := decide(&accessRequest); err != nil {
+		return AccessRequest{}, err
+	}
+	ars.putAccessRequest(accessRequest)
+	return accessRequest, nil
+}
+
+func sendDecisionError(ctx echo.Context, errMsg string, err error) error {
+	if _, ok := err.(notFoundError); ok {
+		return problemdetails.Send(ctx, http.StatusNotFound, problemdetails.CauseContextNotFound, fmt.Sprintf(errMsg, err))
+	}
+	return problemdetails.Send(ctx, http.StatusConflict, problemdetails.CauseModificationNotAllowed, fmt.Sprintf(errMsg, err))
+}
+
+// Checks if the access request is for an API exposed by one of the provided functions.
+func isHandledBy(accessRequest AccessRequest, aefIds []string) bool {
+	for _, aefId := range accessRequest.AefIds {
+		if slices.Contains(aefIds, aefId) {
+			return true
+		}
+	}
+	return false
+}
+
+func (ars *AccessRequestService) getAccessRequest(requestId string) (AccessRequest, bool) {
+	ars.lock.Lock()
+	defer ars.lock.Unlock()
+
+	accessRequest, ok := ars.accessRequests[requestId]
+	return accessRequest, ok
+}
+
+func (ars *AccessRequestService) getAccessRequests(matches func(accessRequest AccessRequest) bool) []AccessRequest {
+	ars.lock.Lock()
+	accessRequests := []AccessRequest{}
+	for _, accessRequest := range ars.accessRequests {
+		if matches(accessRequest) {
+			accessRequests = append(accessRequests, accessRequest)
+		}
+	}
+	ars.lock.Unlock()
+	sort.Slice(accessRequests, func(i, j int) bool {
+		return accessRequests[i].Time.Before(accessRequests[j].Time)
+	})
+	return accessRequests
+}
+
+// Must be called with the lock held.
+func (ars *AccessRequestService) putAccessRequest(accessRequest AccessRequest) {
+	ars.accessRequests[accessRequest.Id] = accessRequest
+	if err := ars.store.Put(accessRequestsBucket, accessRequest.Id, accessRequest); err != nil {
+		log.Errorf("Unable to store access request %s due to %s", accessRequest.Id, err)
+	}
+}
+
+// Must be called with the lock held.
+func (ars *AccessRequestService) deleteAccessRequest(requestId string) {
+	delete(ars.accessRequests, requestId)
+	if err := ars.store.Delete(accessRequestsBucket, requestId); err != nil {
+		log.Errorf("Unable to remove stored access request %s due to %s", requestId, err)
+	}
+}
+
+func (ars *AccessRequestService) sendEvent(accessRequest AccessRequest, api *publishapi.ServiceAPIDescription, eventType eventsapi.CAPIFEvent) {
+	apiIds := []string{accessRequest.ApiId}
+	invokerIds := []string{accessRequest.ApiInvokerId}
+	eventDetail := eventsapi.CAPIFEventDetail{
+		ApiIds:        &apiIds,
+		ApiInvokerIds: &invokerIds,
+	}
+	if api != nil {
+		// The API description lets subscribers filter the events on the exposing functions of the API
+		eventDetail.ServiceAPIDescriptions = &[]publishapi.ServiceAPIDescription{*api}
+	}
+	event := eventsapi.EventNotification{
+		EventDetail: &eventDetail,
+		Events:      eventType,
+	}
+	ars.eventChannel <- event
+}
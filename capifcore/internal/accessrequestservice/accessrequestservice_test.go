@@ -0,0 +1,401 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package accessrequestservice
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/deepmap/oapi-codegen/pkg/testutil"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	grantmocks "oransc.org/nonrtric/capifcore/internal/accessrequestservice/mocks"
+	"oransc.org/nonrtric/capifcore/internal/common29122"
+	"oransc.org/nonrtric/capifcore/internal/eventsapi"
+	invokermocks "oransc.org/nonrtric/capifcore/internal/invokermanagement/mocks"
+	"oransc.org/nonrtric/capifcore/internal/invokermanagementapi"
+	providermocks "oransc.org/nonrtric/capifcore/internal/providermanagement/mocks"
+	publishmocks "oransc.org/nonrtric/capifcore/internal/publishservice/mocks"
+	publishapi "oransc.org/nonrtric/capifcore/internal/publishserviceapi"
+	"oransc.org/nonrtric/capifcore/internal/storage/storagetest"
+	"oransc.org/nonrtric/capifcore/problemdetails"
+	"oransc.org/nonrtric/capifcore/validation"
+)
+
+const (
+	invokerId     = "invokerId"
+	invokerSecret = "invokerSecret"
+	apfId         = "APF_id"
+	regSec        = "regSec"
+	aefId         = "AEF_id"
+	apiId         = "apiId"
+)
+
+func TestApprovedAccessRequestGrantsAccess(t *testing.T) {
+	api := getApi(apiId, aefId)
+	grantHandlerMock := grantmocks.AccessGrantHandler{}
+	grantHandlerMock.On("GrantApiAccess", invokerId, api).Return()
+	serviceUnderTest, eventChannel, requestHandler := getEcho(map[string]*publishapi.ServiceAPIDescription{apiId: &api})
+	serviceUnderTest.AddAccessGrantHandler(&grantHandlerMock)
+
+	// The invoker requests access to the API
+	result := testutil.NewRequest().Post("/access-requests/v1/invokers/"+invokerId+"/requests").WithHeader(echo.HeaderAuthorization, invokerAuthorization).WithJsonBody(NewAccessRequest{ApiId: apiId}).Go(t, requestHandler)
+	assert.Equal(t, http.StatusCreated, result.Code())
+	var accessRequest AccessRequest
+	err := result.UnmarshalJsonToObject(&accessRequest)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, invokerId, accessRequest.ApiInvokerId)
+	assert.Equal(t, apiId, accessRequest.ApiId)
+	assert.Equal(t, []string{aefId}, accessRequest.AefIds)
+	assert.Equal(t, AccessRequestStatePending, accessRequest.State)
+	assert.Equal(t, "http://example.com/access-requests/v1/invokers/"+invokerId+"/requests/"+accessRequest.Id, result.Recorder.Header().Get(echo.HeaderLocation))
+	assertEvent(t, eventChannel, eventsapi.CAPIFEventAPIACCESSREQUESTED, &api)
+
+	// The same access cannot be requested again while the request is pending
+	result = testutil.NewRequest().Post("/access-requests/v1/invokers/"+invokerId+"/requests").WithHeader(echo.HeaderAuthorization, invokerAuthorization).WithJsonBody(NewAccessRequest{ApiId: apiId}).Go(t, requestHandler)
+	assert.Equal(t, http.StatusForbidden, result.Code())
+
+	// The publishing function of the provider sees the pending request
+	result = testutil.NewRequest().Get("/access-requests/v1/providers/"+apfId+"/requests?state=PENDING").WithHeader(echo.HeaderAuthorization, apfAuthorization).Go(t, requestHandler)
+	assert.Equal(t, http.StatusOK, result.Code())
+	var accessRequests []AccessRequest
+	err = result.UnmarshalJsonToObject(&accessRequests)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, []string{accessRequest.Id}, getIds(accessRequests))
+
+	// Another publishing function does not
+	result = testutil.NewRequest().Post("/access-requests/v1/providers/otherApfId/requests/"+accessRequest.Id+"/approve").WithHeader(echo.HeaderAuthorization, getBasicAuthorization("otherApfId", regSec)).Go(t, requestHandler)
+	assert.Equal(t, http.StatusNotFound, result.Code())
+
+	result = testutil.NewRequest().Post("/access-requests/v1/providers/"+apfId+"/requests/"+accessRequest.Id+"/approve").WithHeader(echo.HeaderAuthorization, apfAuthorization).Go(t, requestHandler)
+	assert.Equal(t, http.StatusOK, result.Code())
+	err = result.UnmarshalJsonToObject(&accessRequest)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, AccessRequestStateApproved, accessRequest.State)
+	grantHandlerMock.AssertCalled(t, "GrantApiAccess", invokerId, api)
+	assertEvent(t, eventChannel, eventsapi.CAPIFEventAPIACCESSAPPROVED, &api)
+
+	// The invoker sees the decision
+	result = testutil.NewRequest().Get("/access-requests/v1/invokers/"+invokerId+"/requests/"+accessRequest.Id).WithHeader(echo.HeaderAuthorization, invokerAuthorization).Go(t, requestHandler)
+	assert.Equal(t, http.StatusOK, result.Code())
+	var invokerAccessRequest AccessRequest
+	err = result.UnmarshalJsonToObject(&invokerAccessRequest)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, AccessRequestStateApproved, invokerAccessRequest.State)
+
+	// A decided request cannot be decided again
+	result = testutil.NewRequest().Post("/access-requests/v1/providers/"+apfId+"/requests/"+accessRequest.Id+"/reject").WithHeader(echo.HeaderAuthorization, apfAuthorization).Go(t, requestHandler)
+	assert.Equal(t, http.StatusConflict, result.Code())
+	var problemDetails common29122.ProblemDetails
+	err = result.UnmarshalJsonToObject(&problemDetails)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, problemdetails.CauseModificationNotAllowed, *problemDetails.Cause)
+	assert.Contains(t, *problemDetails.Detail, "APPROVED")
+}
+
+func TestRejectedAccessRequest(t *testing.T) {
+	api := getApi(apiId, aefId)
+	grantHandlerMock := grantmocks.AccessGrantHandler{}
+	serviceUnderTest, eventChannel, requestHandler := getEcho(map[string]*publishapi.ServiceAPIDescription{apiId: &api})
+	serviceUnderTest.AddAccessGrantHandler(&grantHandlerMock)
+
+	result := testutil.NewRequest().Post("/access-requests/v1/invokers/"+invokerId+"/requests").WithHeader(echo.HeaderAuthorization, invokerAuthorization).WithJsonBody(NewAccessRequest{ApiId: apiId}).Go(t, requestHandler)
+	assert.Equal(t, http.StatusCreated, result.Code())
+	var accessRequest AccessRequest
+	err := result.UnmarshalJsonToObject(&accessRequest)
+	assert.NoError(t, err, "error unmarshaling response")
+	assertEvent(t, eventChannel, eventsapi.CAPIFEventAPIACCESSREQUESTED, &api)
+
+	reason := "Not for this invoker"
+	result = testutil.NewRequest().Post("/access-requests/v1/providers/"+apfId+"/requests/"+accessRequest.Id+"/reject").WithHeader(echo.HeaderAuthorization, apfAuthorization).WithJsonBody(Rejection{Reason: &reason}).Go(t, requestHandler)
+	assert.Equal(t, http.StatusOK, result.Code())
+	err = result.UnmarshalJsonToObject(&accessRequest)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, AccessRequestStateRejected, accessRequest.State)
+	assert.Equal(t, &reason, accessRequest.Reason)
+	grantHandlerMock.AssertNotCalled(t, "GrantApiAccess", mock.Anything, mock.Anything)
+	assertEvent(t, eventChannel, eventsapi.CAPIFEventAPIACCESSREJECTED, &api)
+
+	// The access can be requested again after the rejection
+	result = testutil.NewRequest().Post("/access-requests/v1/invokers/"+invokerId+"/requests").WithHeader(echo.HeaderAuthorization, invokerAuthorization).WithJsonBody(NewAccessRequest{ApiId: apiId}).Go(t, requestHandler)
+	assert.Equal(t, http.StatusCreated, result.Code())
+	assertEvent(t, eventChannel, eventsapi.CAPIFEventAPIACCESSREQUESTED, &api)
+
+	result = testutil.NewRequest().Get("/access-requests/v1/invokers/"+invokerId+"/requests").WithHeader(echo.HeaderAuthorization, invokerAuthorization).Go(t, requestHandler)
+	assert.Equal(t, http.StatusOK, result.Code())
+	var accessRequests []AccessRequest
+	err = result.UnmarshalJsonToObject(&accessRequests)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Len(t, accessRequests, 2)
+	assert.Equal(t, AccessRequestStateRejected, accessRequests[0].State)
+	assert.Equal(t, AccessRequestStatePending, accessRequests[1].State)
+}
+
+func TestFailedAccessRequests(t *testing.T) {
+	api := getApi(apiId, aefId)
+	grantedApiId := "grantedApiId"
+	grantedApi := getApi(grantedApiId, aefId)
+	_, _, requestHandler := getEcho(map[string]*publishapi.ServiceAPIDescription{apiId: &api, grantedApiId: &grantedApi}, grantedApi)
+
+	tests := []struct {
+		name          string
+		authorization string
+		body          interface{}
+		status        int
+		cause         string
+		detail        string
+	}{
+		{
+			name:          "invalid format",
+			authorization: invokerAuthorization,
+			body:          "invalid",
+			status:        http.StatusBadRequest,
+			cause:         problemdetails.CauseMandatoryIeIncorrect,
+			detail:        "does not match the API specification",
+		},
+		{
+			name:          "missing API id",
+			authorization: invokerAuthorization,
+			body:          NewAccessRequest{},
+			status:        http.StatusBadRequest,
+			cause:         problemdetails.CauseMandatoryIeIncorrect,
+			detail:        "does not match the API specification",
+		},
+		{
+			name:          "invoker not authenticated",
+			authorization: getBasicAuthorization(invokerId, "wrongSecret"),
+			body:          NewAccessRequest{ApiId: apiId},
+			status:        http.StatusUnauthorized,
+			detail:        "caller not authenticated as " + invokerId,
+		},
+		{
+			name:          "API not published",
+			authorization: invokerAuthorization,
+			body:          NewAccessRequest{ApiId: "unpublished"},
+			status:        http.StatusNotFound,
+			cause:         problemdetails.CauseContextNotFound,
+			detail:        "API not published",
+		},
+		{
+			name:          "access already granted",
+			authorization: invokerAuthorization,
+			body:          NewAccessRequest{ApiId: grantedApiId},
+			status:        http.StatusForbidden,
+			cause:         problemdetails.CauseModificationNotAllowed,
+			detail:        "already has access",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := testutil.NewRequest().Post("/access-requests/v1/invokers/"+invokerId+"/requests").WithHeader(echo.HeaderAuthorization, tt.authorization).WithJsonBody(tt.body).Go(t, requestHandler)
+			assert.Equal(t, tt.status, result.Code())
+			var problemDetails common29122.ProblemDetails
+			err := result.UnmarshalJsonToObject(&problemDetails)
+			assert.NoError(t, err, "error unmarshaling response")
+			if tt.cause == "" {
+				assert.Nil(t, problemDetails.Cause)
+			} else {
+				assert.Equal(t, tt.cause, *problemDetails.Cause)
+			}
+			assert.Contains(t, *problemDetails.Detail, tt.detail)
+		})
+	}
+}
+
+func TestAccessRequestsAreOnlyHandledByTheirOwners(t *testing.T) {
+	api := getApi(apiId, aefId)
+	serviceUnderTest, eventChannel, requestHandler := getEcho(map[string]*publishapi.ServiceAPIDescription{apiId: &api})
+
+	result := testutil.NewRequest().Post("/access-requests/v1/invokers/"+invokerId+"/requests").WithHeader(echo.HeaderAuthorization, invokerAuthorization).WithJsonBody(NewAccessRequest{ApiId: apiId}).Go(t, requestHandler)
+	assert.Equal(t, http.StatusCreated, result.Code())
+	var accessRequest AccessRequest
+	err := result.UnmarshalJsonToObject(&accessRequest)
+	assert.NoError(t, err, "error unmarshaling response")
+	assertEvent(t, eventChannel, eventsapi.CAPIFEventAPIACCESSREQUESTED, &api)
+
+	invokerPaths := []string{
+		"/access-requests/v1/invokers/" + invokerId + "/requests",
+		"/access-requests/v1/invokers/" + invokerId + "/requests/" + accessRequest.Id,
+	}
+	providerPaths := []string{
+		"/access-requests/v1/providers/" + apfId + "/requests/" + accessRequest.Id + "/approve",
+		"/access-requests/v1/providers/" + apfId + "/requests/" + accessRequest.Id + "/reject",
+	}
+	for _, authorization := range []string{"", getBasicAuthorization(invokerId, regSec), apfAuthorization} {
+		for _, path := range invokerPaths {
+			result = testutil.NewRequest().Get(path).WithHeader(echo.HeaderAuthorization, authorization).Go(t, requestHandler)
+			assert.Equal(t, http.StatusUnauthorized, result.Code(), path)
+		}
+	}
+	for _, authorization := range []string{"", invokerAuthorization, getBasicAuthorization("otherApfId", regSec), getBasicAuthorization(aefId, regSec)} {
+		result = testutil.NewRequest().Get("/access-requests/v1/providers/"+apfId+"/requests").WithHeader(echo.HeaderAuthorization, authorization).Go(t, requestHandler)
+		assert.Equal(t, http.StatusUnauthorized, result.Code())
+		for _, path := range providerPaths {
+			result = testutil.NewRequest().Post(path).WithHeader(echo.HeaderAuthorization, authorization).Go(t, requestHandler)
+			assert.Equal(t, http.StatusUnauthorized, result.Code(), path)
+			assert.NotEmpty(t, result.Recorder.Header().Get(echo.HeaderWWWAuthenticate))
+		}
+	}
+	// An API exposing function cannot handle the requests, even with the secret of its provider
+	result = testutil.NewRequest().Get("/access-requests/v1/providers/"+aefId+"/requests").WithHeader(echo.HeaderAuthorization, getBasicAuthorization(aefId, regSec)).Go(t, requestHandler)
+	assert.Equal(t, http.StatusUnauthorized, result.Code())
+	assert.Equal(t, AccessRequestStatePending, serviceUnderTest.accessRequests[accessRequest.Id].State)
+}
+
+func TestRequestApiAccessOfOnboardedInvoker(t *testing.T) {
+	api := getApi(apiId, aefId)
+	grantedApiId := "grantedApiId"
+	grantedApi := getApi(grantedApiId, aefId)
+	serviceUnderTest, eventChannel, requestHandler := getEcho(map[string]*publishapi.ServiceAPIDescription{apiId: &api, grantedApiId: &grantedApi}, grantedApi)
+
+	// Only the API that is published and not granted is requested
+	unpublishedApi := getApi("unpublishedApiId", aefId)
+	serviceUnderTest.RequestApiAccess(invokerId, invokermanagementapi.APIList{api, grantedApi, unpublishedApi})
+	assertEvent(t, eventChannel, eventsapi.CAPIFEventAPIACCESSREQUESTED, &api)
+
+	result := testutil.NewRequest().Get("/access-requests/v1/invokers/"+invokerId+"/requests").WithHeader(echo.HeaderAuthorization, invokerAuthorization).Go(t, requestHandler)
+	assert.Equal(t, http.StatusOK, result.Code())
+	var accessRequests []AccessRequest
+	err := result.UnmarshalJsonToObject(&accessRequests)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Len(t, accessRequests, 1)
+	assert.Equal(t, apiId, accessRequests[0].ApiId)
+	assert.Equal(t, AccessRequestStatePending, accessRequests[0].State)
+
+	// The pending request is not requested again
+	serviceUnderTest.RequestApiAccess(invokerId, invokermanagementapi.APIList{api})
+	assert.Len(t, serviceUnderTest.accessRequests, 1)
+}
+
+func TestAccessRequestsAreRemovedWithInvokerAndLoadedFromStore(t *testing.T) {
+	api := getApi(apiId, aefId)
+	serviceUnderTest, eventChannel, requestHandler := getEcho(map[string]*publishapi.ServiceAPIDescription{apiId: &api})
+
+	result := testutil.NewRequest().Post("/access-requests/v1/invokers/"+invokerId+"/requests").WithHeader(echo.HeaderAuthorization, invokerAuthorization).WithJsonBody(NewAccessRequest{ApiId: apiId}).Go(t, requestHandler)
+	assert.Equal(t, http.StatusCreated, result.Code())
+	assertEvent(t, eventChannel, eventsapi.CAPIFEventAPIACCESSREQUESTED, &api)
+
+	restartedService := NewAccessRequestService(nil, nil, nil, nil, serviceUnderTest.store)
+	assert.Len(t, restartedService.accessRequests, 1)
+
+	serviceUnderTest.RemoveInvoker(invokerId)
+	assert.Empty(t, serviceUnderTest.accessRequests)
+	restartedService = NewAccessRequestService(nil, nil, nil, nil, serviceUnderTest.store)
+	assert.Empty(t, restartedService.accessRequests)
+}
+
+func getEcho(publishedApis map[string]*publishapi.ServiceAPIDescription, grantedApis ...publishapi.ServiceAPIDescription) (*AccessRequestService, chan eventsapi.EventNotification, *echo.Echo) {
+	apiList := invokermanagementapi.APIList(grantedApis)
+	invokerRegisterMock := invokermocks.InvokerRegister{}
+	invokerRegisterMock.On("GetInvokerApiList", invokerId).Return(&apiList)
+	invokerRegisterMock.On("GetInvokerApiList", mock.Anything).Return(nil)
+	invokerRegisterMock.On("VerifyInvokerSecret", invokerId, invokerSecret).Return(true)
+	invokerRegisterMock.On("VerifyInvokerSecret", mock.Anything, mock.Anything).Return(false)
+	publishRegisterMock := publishmocks.PublishRegister{}
+	publishRegisterMock.On("GetPublishedService", mock.Anything).Return(func(apiId string) *publishapi.ServiceAPIDescription {
+		return publishedApis[apiId]
+	})
+	serviceRegisterMock := providermocks.ServiceRegister{}
+	serviceRegisterMock.On("GetAefsForPublisher", apfId).Return([]string{aefId})
+	serviceRegisterMock.On("GetAefsForPublisher", mock.Anything).Return(nil)
+	serviceRegisterMock.On("IsPublishingFunctionRegistered", apfId).Return(true)
+	serviceRegisterMock.On("IsPublishingFunctionRegistered", "otherApfId").Return(true)
+	serviceRegisterMock.On("IsPublishingFunctionRegistered", mock.Anything).Return(false)
+	// The functions of both providers have the same secret
+	serviceRegisterMock.On("VerifyFunctionSecret", mock.Anything, regSec).Return(true)
+	serviceRegisterMock.On("VerifyFunctionSecret", mock.Anything, mock.Anything).Return(false)
+
+	eventChannel := make(chan eventsapi.EventNotification)
+	ars := NewAccessRequestService(&invokerRegisterMock, &publishRegisterMock, &serviceRegisterMock, eventChannel, storagetest.NewStore())
+
+	swagger, err := GetSwagger()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading swagger spec\n: %s", err)
+		os.Exit(1)
+	}
+
+	e := echo.New()
+	group := validation.NewGroup(e, swagger, "/access-requests/v1", validation.ResponseValidationReject)
+	group.POST("/invokers/:apiInvokerId/requests", ars.PostInvokerAccessRequests)
+	group.GET("/invokers/:apiInvokerId/requests", ars.GetInvokerAccessRequests)
+	group.GET("/invokers/:apiInvokerId/requests/:requestId", ars.GetInvokerAccessRequest)
+	group.GET("/providers/:apfId/requests", ars.GetProviderAccessRequests)
+	group.POST("/providers/:apfId/requests/:requestId/approve", ars.ApproveAccessRequest)
+	group.POST("/providers/:apfId/requests/:requestId/reject", ars.RejectAccessRequest)
+	return ars, eventChannel, e
+}
+
+var (
+	invokerAuthorization = getBasicAuthorization(invokerId, invokerSecret)
+	apfAuthorization     = getBasicAuthorization(apfId, regSec)
+)
+
+func getBasicAuthorization(clientId, secret string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(clientId+":"+secret))
+}
+
+func getApi(apiId, aefId string) publishapi.ServiceAPIDescription {
+	return publishapi.ServiceAPIDescription{
+		ApiId:   &apiId,
+		ApiName: "api",
+		AefProfiles: &[]publishapi.AefProfile{
+			{
+				AefId: aefId,
+			},
+		},
+	}
+}
+
+func getIds(accessRequests []AccessRequest) []string {
+	ids := []string{}
+	for _, accessRequest := range accessRequests {
+		ids = append(ids, accessRequest.Id)
+	}
+	return ids
+}
+
+func assertEvent(t *testing.T, eventChannel chan eventsapi.EventNotification, eventType eventsapi.CAPIFEvent, api *publishapi.ServiceAPIDescription) {
+	if event, timeout := waitForEvent(eventChannel, 1*time.Second); timeout {
+		assert.Fail(t, "No event sent")
+	} else {
+		assert.Equal(t, eventType, event.Events)
+		assert.Equal(t, []string{*api.ApiId}, *event.EventDetail.ApiIds)
+		assert.Equal(t, []string{invokerId}, *event.EventDetail.ApiInvokerIds)
+		assert.Equal(t, []publishapi.ServiceAPIDescription{*api}, *event.EventDetail.ServiceAPIDescriptions)
+	}
+}
+
+// waitForEvent waits for the channel to receive an event for the specified max timeout.
+// Returns true if waiting timed out.
+func waitForEvent(ch chan eventsapi.EventNotification, timeout time.Duration) (*eventsapi.EventNotification, bool) {
+	select {
+	case event := <-ch:
+		return &event, false // completed normally
+	case <-time.After(timeout):
+		return nil, true // timed out
+	}
+}
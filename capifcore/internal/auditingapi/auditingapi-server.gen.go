@@ -19,7 +19,8 @@ import (
 	"github.com/labstack/echo/v4"
 	externalRef0 "oransc.org/nonrtric/capifcore/internal/common29122"
 	externalRef1 "oransc.org/nonrtric/capifcore/internal/common29571"
-	externalRef2 "oransc.org/nonrtric/capifcore/internal/publishserviceapi"
+	externalRef2 "oransc.org/nonrtric/capifcore/internal/loggingapi"
+	externalRef3 "oransc.org/nonrtric/capifcore/internal/publishserviceapi"
 )
 
 // ServerInterface represents all server handlers.
@@ -121,7 +122,7 @@ func (w *ServerInterfaceWrapper) GetApiInvocationLogs(ctx echo.Context) error {
 
 	if paramValue := ctx.QueryParam("src-interface"); paramValue != "" {
 
-		var value externalRef2.InterfaceDescription
+		var value externalRef3.InterfaceDescription
 		err = json.Unmarshal([]byte(paramValue), &value)
 		if err != nil {
 			return echo.NewHTTPError(http.StatusBadRequest, "Error unmarshaling parameter 'src-interface' as JSON")
@@ -134,7 +135,7 @@ func (w *ServerInterfaceWrapper) GetApiInvocationLogs(ctx echo.Context) error {
 
 	if paramValue := ctx.QueryParam("dest-interface"); paramValue != "" {
 
-		var value externalRef2.InterfaceDescription
+		var value externalRef3.InterfaceDescription
 		err = json.Unmarshal([]byte(paramValue), &value)
 		if err != nil {
 			return echo.NewHTTPError(http.StatusBadRequest, "Error unmarshaling parameter 'dest-interface' as JSON")
@@ -256,7 +257,13 @@ func PathToRawSpec(pathToFile string) map[string]func() ([]byte, error) {
 		}
 		res[rawPath] = rawFunc
 	}
-	for rawPath, rawFunc := range externalRef2.PathToRawSpec(path.Join(pathPrefix, "TS29222_CAPIF_Publish_Service_API.yaml")) {
+	for rawPath, rawFunc := range externalRef2.PathToRawSpec(path.Join(pathPrefix, "TS29222_CAPIF_Logging_API_Invocation_API.yaml")) {
+		if _, ok := res[rawPath]; ok {
+			// it is not possible to compare functions in golang, so always overwrite the old value
+		}
+		res[rawPath] = rawFunc
+	}
+	for rawPath, rawFunc := range externalRef3.PathToRawSpec(path.Join(pathPrefix, "TS29222_CAPIF_Publish_Service_API.yaml")) {
 		if _, ok := res[rawPath]; ok {
 			// it is not possible to compare functions in golang, so always overwrite the old value
 		}
@@ -6,7 +6,7 @@ package auditingapi
 import (
 	externalRef0 "oransc.org/nonrtric/capifcore/internal/common29122"
 	externalRef1 "oransc.org/nonrtric/capifcore/internal/common29571"
-	externalRef2 "oransc.org/nonrtric/capifcore/internal/publishserviceapi"
+	externalRef3 "oransc.org/nonrtric/capifcore/internal/publishserviceapi"
 )
 
 // GetApiInvocationLogsParams defines parameters for GetApiInvocationLogs.
@@ -33,10 +33,10 @@ type GetApiInvocationLogsParams struct {
 	ApiVersion *string `json:"api-version,omitempty"`
 
 	// Protocol invoked.
-	Protocol *externalRef2.Protocol `json:"protocol,omitempty"`
+	Protocol *externalRef3.Protocol `json:"protocol,omitempty"`
 
 	// Operation that was invoked on the API.
-	Operation *externalRef2.Operation `json:"operation,omitempty"`
+	Operation *externalRef3.Operation `json:"operation,omitempty"`
 
 	// Result or output of the invocation.
 	Result *string `json:"result,omitempty"`
@@ -45,10 +45,10 @@ type GetApiInvocationLogsParams struct {
 	ResourceName *string `json:"resource-name,omitempty"`
 
 	// Interface description of the API invoker.
-	SrcInterface *externalRef2.InterfaceDescription `json:"src-interface,omitempty"`
+	SrcInterface *externalRef3.InterfaceDescription `json:"src-interface,omitempty"`
 
 	// Interface description of the API invoked.
-	DestInterface *externalRef2.InterfaceDescription `json:"dest-interface,omitempty"`
+	DestInterface *externalRef3.InterfaceDescription `json:"dest-interface,omitempty"`
 
 	// To filter irrelevant responses related to unsupported features
 	SupportedFeatures *externalRef1.SupportedFeatures `json:"supported-features,omitempty"`
@@ -0,0 +1,179 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package auditingservice
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	echo "github.com/labstack/echo/v4"
+
+	"oransc.org/nonrtric/capifcore/internal/auditingapi"
+	"oransc.org/nonrtric/capifcore/internal/common29122"
+	"oransc.org/nonrtric/capifcore/internal/loggingapi"
+	"oransc.org/nonrtric/capifcore/internal/loggingservice"
+	publishapi "oransc.org/nonrtric/capifcore/internal/publishserviceapi"
+	"oransc.org/nonrtric/capifcore/problemdetails"
+)
+
+type AuditingService struct {
+	loggingRegister loggingservice.LoggingRegister
+}
+
+// Creates a service that implements the auditingapi.ServerInterface interface.
+func NewAuditingService(loggingRegister loggingservice.LoggingRegister) *AuditingService {
+	return &AuditingService{
+		loggingRegister: loggingRegister,
+	}
+}
+
+// Query and retrieve service API invocation logs stored on the CAPIF core function.
+// All provided filters must match for a log to be returned. As defined by the specification, the matching logs are
+// returned as one invocation log, which is of one AEF and one invoker. The aef-id and api-invoker-id parameters are
+// therefore mandatory, even if they are optional in the specification.
+func (as *AuditingService) GetApiInvocationLogs(ctx echo.Context, params auditingapi.GetApiInvocationLogsParams) error {
+	errMsg := "Unable to get invocation logs due to %s"
+	if params.AefId == nil || params.ApiInvokerId == nil {
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseMandatoryQueryParamMissing, fmt.Sprintf(errMsg, "aef-id and api-invoker-id must be provided"))
+	}
+	if params.TimeRangeStart != nil && params.TimeRangeEnd != nil && time.Time(*params.TimeRangeEnd).Before(time.Time(*params.TimeRangeStart)) {
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseInvalidQueryParam, fmt.Sprintf(errMsg, "time-range-end is before time-range-start"))
+	}
+
+	invocationLog := as.getMatchingInvocationLog(params)
+	if len(invocationLog.Logs) == 0 {
+		return problemdetails.Send(ctx, http.StatusNotFound, problemdetails.CauseContextNotFound, fmt.Sprintf(errMsg, "no matching invocation logs"))
+	}
+
+	err := ctx.JSON(http.StatusOK, invocationLog)
+	if err != nil {
+		// Something really bad happened, tell Echo that our handler failed
+		return err
+	}
+
+	return nil
+}
+
+// The AEF and invoker of the parameters must be provided.
+func (as *AuditingService) getMatchingInvocationLog(params auditingapi.GetApiInvocationLogsParams) loggingapi.InvocationLog {
+	matchingLog := loggingapi.InvocationLog{
+		AefId:        *params.AefId,
+		ApiInvokerId: *params.ApiInvokerId,
+		Logs:         []loggingapi.Log{},
+	}
+	for _, invocationLog := range as.loggingRegister.GetAllInvocationLogs() {
+		if invocationLog.AefId != matchingLog.AefId || invocationLog.ApiInvokerId != matchingLog.ApiInvokerId {
+			continue
+		}
+		for _, log := range invocationLog.Logs {
+			if matchesLog(params, log) {
+				matchingLog.Logs = append(matchingLog.Logs, log)
+			}
+		}
+	}
+
+	sort.SliceStable(matchingLog.Logs, func(i, j int) bool {
+		return invocationTime(matchingLog.Logs[i]).Before(invocationTime(matchingLog.Logs[j]))
+	})
+	return matchingLog
+}
+
+func matchesLog(params auditingapi.GetApiInvocationLogsParams, log loggingapi.Log) bool {
+	return matchesTimeRange(params.TimeRangeStart, params.TimeRangeEnd, log.InvocationTime) &&
+		matchesString(params.ApiId, log.ApiId) &&
+		matchesString(params.ApiName, log.ApiName) &&
+		matchesString(params.ApiVersion, log.ApiVersion) &&
+		(params.Protocol == nil || *params.Protocol == log.Protocol) &&
+		(params.Operation == nil || (log.Operation != nil && *params.Operation == *log.Operation)) &&
+		matchesString(params.Result, log.Result) &&
+		matchesString(params.ResourceName, log.ResourceName) &&
+		matchesInterface(params.SrcInterface, log.SrcInterface) &&
+		matchesInterface(params.DestInterface, log.DestInterface)
+}
+
+func matchesString(filter *string, value string) bool {
+	return filter == nil || *filter == value
+}
+
+func matchesTimeRange(start, end, value *common29122.DateTime) bool {
+	if start == nil && end == nil {
+		return true
+	}
+	if value == nil {
+		return false
+	}
+	t := time.Time(*value)
+	if start != nil && t.Before(time.Time(*start)) {
+		return false
+	}
+	if end != nil && t.After(time.Time(*end)) {
+		return false
+	}
+	return true
+}
+
+// Only the attributes given in the filter are compared.
+func matchesInterface(filter, value *publishapi.InterfaceDescription) bool {
+	if filter == nil {
+		return true
+	}
+	if value == nil {
+		return false
+	}
+	if filter.Ipv4Addr != nil && (value.Ipv4Addr == nil || *filter.Ipv4Addr != *value.Ipv4Addr) {
+		return false
+	}
+	if filter.Ipv6Addr != nil && (value.Ipv6Addr == nil || *filter.Ipv6Addr != *value.Ipv6Addr) {
+		return false
+	}
+	if filter.Port != nil && (value.Port == nil || *filter.Port != *value.Port) {
+		return false
+	}
+	if filter.SecurityMethods != nil {
+		if value.SecurityMethods == nil {
+			return false
+		}
+		for _, method := range *filter.SecurityMethods {
+			if !containsSecurityMethod(*value.SecurityMethods, method) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func containsSecurityMethod(methods []publishapi.SecurityMethod, method publishapi.SecurityMethod) bool {
+	for _, m := range methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+func invocationTime(log loggingapi.Log) time.Time {
+	if log.InvocationTime == nil {
+		return time.Time{}
+	}
+	return time.Time(*log.InvocationTime)
+}
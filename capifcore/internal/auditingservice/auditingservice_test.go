@@ -0,0 +1,266 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package auditingservice
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/deepmap/oapi-codegen/pkg/middleware"
+	"github.com/deepmap/oapi-codegen/pkg/testutil"
+	"github.com/labstack/echo/v4"
+	echomiddleware "github.com/labstack/echo/v4/middleware"
+	"github.com/stretchr/testify/assert"
+
+	"oransc.org/nonrtric/capifcore/internal/auditingapi"
+	"oransc.org/nonrtric/capifcore/internal/common29122"
+	"oransc.org/nonrtric/capifcore/internal/loggingapi"
+	"oransc.org/nonrtric/capifcore/internal/loggingservice"
+	loggingmocks "oransc.org/nonrtric/capifcore/internal/loggingservice/mocks"
+	publishapi "oransc.org/nonrtric/capifcore/internal/publishserviceapi"
+	"oransc.org/nonrtric/capifcore/problemdetails"
+	"oransc.org/nonrtric/capifcore/validation"
+)
+
+var (
+	log1 = getLog("apiId1", "api1", "200", publishapi.OperationGET, time.Date(2026, time.January, 1, 10, 0, 0, 0, time.UTC))
+	log2 = getLog("apiId1", "api1", "500", publishapi.OperationPOST, time.Date(2026, time.January, 1, 11, 0, 0, 0, time.UTC))
+	log3 = getLog("apiId2", "api2", "200", publishapi.OperationGET, time.Date(2026, time.January, 1, 12, 0, 0, 0, time.UTC))
+	log4 = getLog("apiId3", "api3", "201", publishapi.OperationPUT, time.Date(2026, time.January, 1, 13, 0, 0, 0, time.UTC))
+)
+
+func TestGetAllInvocationLogsOfAefAndInvoker(t *testing.T) {
+	requestHandler := getEcho(getLoggingRegisterMock())
+
+	result := testutil.NewRequest().Get("/apiInvocationLogs?aef-id=aefId1&api-invoker-id=invokerId1").Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusOK, result.Code())
+	var resultLog loggingapi.InvocationLog
+	err := result.UnmarshalJsonToObject(&resultLog)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, getInvocationLog("aefId1", "invokerId1", log1, log2, log3), resultLog)
+}
+
+func TestGetInvocationLogsOfTwoInvokers(t *testing.T) {
+	requestHandler := getEcho(getLoggingRegisterMock())
+
+	// The logs of the invokers of the same AEF are not merged
+	for invokerId, want := range map[string]loggingapi.InvocationLog{
+		"invokerId1": getInvocationLog("aefId1", "invokerId1", log2),
+		"invokerId2": getInvocationLog("aefId1", "invokerId2", log2),
+	} {
+		query := url.Values{"aef-id": {"aefId1"}, "api-invoker-id": {invokerId}, "api-id": {"apiId1"}, "result": {"500"}}
+		result := testutil.NewRequest().Get("/apiInvocationLogs?"+query.Encode()).Go(t, requestHandler)
+
+		assert.Equal(t, http.StatusOK, result.Code())
+		var resultLog loggingapi.InvocationLog
+		err := result.UnmarshalJsonToObject(&resultLog)
+		assert.NoError(t, err, "error unmarshaling response")
+		assert.Equal(t, want, resultLog)
+	}
+}
+
+func TestGetInvocationLogsWithoutAefOrInvoker(t *testing.T) {
+	requestHandler := getEcho(getLoggingRegisterMock())
+
+	for _, query := range []url.Values{
+		{},
+		{"aef-id": {"aefId1"}},
+		{"api-invoker-id": {"invokerId1"}},
+	} {
+		result := testutil.NewRequest().Get("/apiInvocationLogs?"+query.Encode()).Go(t, requestHandler)
+
+		assert.Equal(t, http.StatusBadRequest, result.Code())
+		var problemDetails common29122.ProblemDetails
+		err := result.UnmarshalJsonToObject(&problemDetails)
+		assert.NoError(t, err, "error unmarshaling response")
+		assert.Equal(t, problemdetails.CauseMandatoryQueryParamMissing, *problemDetails.Cause)
+		assert.Contains(t, *problemDetails.Detail, "aef-id and api-invoker-id must be provided")
+	}
+}
+
+func TestGetInvocationLogsWithFilters(t *testing.T) {
+	requestHandler := getEcho(getLoggingRegisterMock())
+
+	type args struct {
+		query url.Values
+	}
+	tests := []struct {
+		name string
+		args args
+		want loggingapi.InvocationLog
+	}{
+		{
+			name: "No other filters",
+			args: args{query: url.Values{}},
+			want: getInvocationLog("aefId1", "invokerId1", log1, log2, log3),
+		},
+		{
+			name: "Filter on time range",
+			args: args{query: url.Values{"time-range-start": {"2026-01-01T10:30:00Z"}, "time-range-end": {"2026-01-01T12:00:00Z"}}},
+			want: getInvocationLog("aefId1", "invokerId1", log2, log3),
+		},
+		{
+			name: "Filter on api-id and result",
+			args: args{query: url.Values{"api-id": {"apiId1"}, "result": {"200"}}},
+			want: getInvocationLog("aefId1", "invokerId1", log1),
+		},
+		{
+			name: "Filter on api-name, api-version, protocol and resource-name",
+			args: args{query: url.Values{"api-name": {"api2"}, "api-version": {"v1"}, "protocol": {"HTTP_1_1"}, "resource-name": {"resource"}}},
+			want: getInvocationLog("aefId1", "invokerId1", log3),
+		},
+		{
+			name: "Filter on operation",
+			args: args{query: url.Values{"operation": {"POST"}}},
+			want: getInvocationLog("aefId1", "invokerId1", log2),
+		},
+		{
+			name: "Filter on src-interface and dest-interface",
+			args: args{query: url.Values{"src-interface": {`{"ipv4Addr":"10.0.0.1"}`}, "dest-interface": {`{"port":8080}`}}},
+			want: getInvocationLog("aefId1", "invokerId1", log1, log2, log3),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := tt.args.query
+			query.Set("aef-id", "aefId1")
+			query.Set("api-invoker-id", "invokerId1")
+			result := testutil.NewRequest().Get("/apiInvocationLogs?"+query.Encode()).Go(t, requestHandler)
+
+			assert.Equal(t, http.StatusOK, result.Code())
+			var resultLog loggingapi.InvocationLog
+			err := result.UnmarshalJsonToObject(&resultLog)
+			assert.NoError(t, err, "error unmarshaling response")
+			assert.Equal(t, tt.want, resultLog)
+		})
+	}
+}
+
+func TestGetInvocationLogsWithoutMatch(t *testing.T) {
+	requestHandler := getEcho(getLoggingRegisterMock())
+
+	for _, query := range []url.Values{
+		{"aef-id": {"aefId1"}, "api-invoker-id": {"invokerId1"}, "dest-interface": {`{"port":8081}`}},
+		{"aef-id": {"aefId2"}, "api-invoker-id": {"invokerId1"}, "api-id": {"apiId1"}},
+		{"aef-id": {"aefId2"}, "api-invoker-id": {"invokerId2"}},
+	} {
+		result := testutil.NewRequest().Get("/apiInvocationLogs?"+query.Encode()).Go(t, requestHandler)
+
+		assert.Equal(t, http.StatusNotFound, result.Code())
+		var problemDetails common29122.ProblemDetails
+		err := result.UnmarshalJsonToObject(&problemDetails)
+		assert.NoError(t, err, "error unmarshaling response")
+		assert.Equal(t, problemdetails.CauseContextNotFound, *problemDetails.Cause)
+		assert.Contains(t, *problemDetails.Detail, "no matching invocation logs")
+	}
+}
+
+func TestInvocationLogsMatchSpecification(t *testing.T) {
+	swagger, err := auditingapi.GetSwagger()
+	assert.NoError(t, err)
+	e := echo.New()
+	group := validation.NewGroup(e, swagger, "/logs/v1", validation.ResponseValidationReject)
+	auditingapi.RegisterHandlers(group, NewAuditingService(getLoggingRegisterMock()))
+
+	result := testutil.NewRequest().Get("/logs/v1/apiInvocationLogs?aef-id=aefId1&api-invoker-id=invokerId2").Go(t, e)
+
+	assert.Equal(t, http.StatusOK, result.Code())
+	var resultLog loggingapi.InvocationLog
+	err = result.UnmarshalJsonToObject(&resultLog)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, "aefId1", resultLog.AefId)
+	assert.Equal(t, "invokerId2", resultLog.ApiInvokerId)
+}
+
+func TestGetInvocationLogsWithInvalidTimeRange(t *testing.T) {
+	requestHandler := getEcho(getLoggingRegisterMock())
+
+	query := url.Values{"aef-id": {"aefId1"}, "api-invoker-id": {"invokerId1"}, "time-range-start": {"2026-01-02T00:00:00Z"}, "time-range-end": {"2026-01-01T00:00:00Z"}}
+	result := testutil.NewRequest().Get("/apiInvocationLogs?"+query.Encode()).Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusBadRequest, result.Code())
+	var problemDetails common29122.ProblemDetails
+	err := result.UnmarshalJsonToObject(&problemDetails)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, http.StatusBadRequest, *problemDetails.Status)
+	assert.Contains(t, *problemDetails.Detail, "time-range-end is before time-range-start")
+}
+
+func getLoggingRegisterMock() *loggingmocks.LoggingRegister {
+	loggingRegisterMock := loggingmocks.LoggingRegister{}
+	loggingRegisterMock.On("GetAllInvocationLogs").Return([]loggingapi.InvocationLog{
+		getInvocationLog("aefId1", "invokerId1", log3, log1),
+		getInvocationLog("aefId2", "invokerId1", log4),
+		getInvocationLog("aefId1", "invokerId2", log2),
+		getInvocationLog("aefId1", "invokerId1", log2),
+	})
+	return &loggingRegisterMock
+}
+
+func getEcho(loggingRegister loggingservice.LoggingRegister) *echo.Echo {
+	swagger, err := auditingapi.GetSwagger()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading swagger spec\n: %s", err)
+		os.Exit(1)
+	}
+
+	swagger.Servers = nil
+
+	as := NewAuditingService(loggingRegister)
+
+	e := echo.New()
+	e.Use(echomiddleware.Logger())
+	e.Use(middleware.OapiRequestValidator(swagger))
+
+	auditingapi.RegisterHandlers(e, as)
+	return e
+}
+
+func getInvocationLog(aefId, invokerId string, logs ...loggingapi.Log) loggingapi.InvocationLog {
+	return loggingapi.InvocationLog{
+		AefId:        aefId,
+		ApiInvokerId: invokerId,
+		Logs:         logs,
+	}
+}
+
+func getLog(apiId, apiName, result string, operation publishapi.Operation, invocationTime time.Time) loggingapi.Log {
+	srcAddress := common29122.Ipv4Addr("10.0.0.1")
+	destPort := common29122.Port(8080)
+	dateTime := common29122.DateTime(invocationTime)
+	return loggingapi.Log{
+		ApiId:          apiId,
+		ApiName:        apiName,
+		ApiVersion:     "v1",
+		DestInterface:  &publishapi.InterfaceDescription{Port: &destPort},
+		InvocationTime: &dateTime,
+		Operation:      &operation,
+		Protocol:       publishapi.ProtocolHTTP11,
+		ResourceName:   "resource",
+		Result:         result,
+		SrcInterface:   &publishapi.InterfaceDescription{Ipv4Addr: &srcAddress},
+	}
+}
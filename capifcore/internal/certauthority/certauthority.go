@@ -0,0 +1,130 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package certauthority
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+//go:generate mockery --name CertificateIssuer
+type CertificateIssuer interface {
+	// Issues a certificate for the provided subject, certifying the provided public key.
+	// Returns the PEM encoded certificate.
+	IssueCertificate(publicKey crypto.PublicKey, subject string) (string, error)
+}
+
+// A certificate authority issuing the client certificates of the invokers and the provider functions. The subject of
+// a certificate is the id of the invoker or function, given as common name.
+type CertificateAuthority struct {
+	certificate *x509.Certificate
+	key         crypto.Signer
+	validity    time.Duration
+}
+
+// Creates a certificate authority signing with the PEM encoded certificate and private key in the provided files.
+// The issued certificates are valid for the provided duration, but not longer than the certificate of the authority.
+func NewCertificateAuthority(certPath, keyPath string, validity time.Duration) (*CertificateAuthority, error) {
+	keyPair, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		return nil, err
+	}
+	certificate, err := x509.ParseCertificate(keyPair.Certificate[0])
+	if err != nil {
+		return nil, err
+	}
+	if !certificate.IsCA {
+		return nil, errors.New("certificate is not a CA certificate")
+	}
+	key, ok := keyPair.PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("unsupported private key type")
+	}
+	return &CertificateAuthority{
+		certificate: certificate,
+		key:         key,
+		validity:    validity,
+	}, nil
+}
+
+func (ca *CertificateAuthority) IssueCertificate(publicKey crypto.PublicKey, subject string) (string, error) {
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return "", err
+	}
+	notBefore := time.Now()
+	notAfter := notBefore.Add(ca.validity)
+	if notAfter.After(ca.certificate.NotAfter) {
+		notAfter = ca.certificate.NotAfter
+	}
+	keyUsage := x509.KeyUsageDigitalSignature
+	if _, ok := publicKey.(*rsa.PublicKey); ok {
+		keyUsage |= x509.KeyUsageKeyEncipherment
+	}
+	template := x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject:      pkix.Name{CommonName: subject},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+		KeyUsage:     keyUsage,
+		// The API exposing functions also serve their APIs with the certificate
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+	}
+	certificate, err := x509.CreateCertificate(rand.Reader, &template, ca.certificate, publicKey, ca.key)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate})), nil
+}
+
+// Parses a PEM encoded public key, or the public key of a PEM encoded certificate signing request. The signature of
+// the request is verified.
+func ParsePublicKey(publicKey string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(publicKey))
+	if block == nil {
+		return nil, errors.New("no PEM encoded public key or certificate signing request found")
+	}
+	switch block.Type {
+	case "CERTIFICATE REQUEST", "NEW CERTIFICATE REQUEST":
+		csr, err := x509.ParseCertificateRequest(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		if err = csr.CheckSignature(); err != nil {
+			return nil, err
+		}
+		return csr.PublicKey, nil
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	}
+	return nil, fmt.Errorf("unsupported PEM type %s", block.Type)
+}
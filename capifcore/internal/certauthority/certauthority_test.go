@@ -0,0 +1,142 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package certauthority
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIssueCertificateForCertificateSigningRequest(t *testing.T) {
+	caUnderTest, caCertificate := getCertificateAuthority(t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{Subject: pkix.Name{CommonName: "ignored"}}, key)
+	assert.NoError(t, err)
+	publicKey, err := ParsePublicKey(string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csr})))
+	assert.NoError(t, err)
+
+	certificatePem, err := caUnderTest.IssueCertificate(publicKey, "invokerId")
+
+	assert.NoError(t, err)
+	certificate := verifyCertificate(t, certificatePem, caCertificate)
+	assert.Equal(t, "invokerId", certificate.Subject.CommonName)
+	assert.Equal(t, &key.PublicKey, certificate.PublicKey)
+	assert.Equal(t, x509.KeyUsageDigitalSignature|x509.KeyUsageKeyEncipherment, certificate.KeyUsage)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), certificate.NotAfter, time.Minute)
+}
+
+func TestIssueCertificateForPublicKey(t *testing.T) {
+	caUnderTest, caCertificate := getCertificateAuthority(t)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	assert.NoError(t, err)
+	publicKey, err := ParsePublicKey(string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})))
+	assert.NoError(t, err)
+
+	caUnderTest.validity = 48 * time.Hour
+	certificatePem, err := caUnderTest.IssueCertificate(publicKey, "AEF_id_rApp_as_AEF")
+
+	assert.NoError(t, err)
+	certificate := verifyCertificate(t, certificatePem, caCertificate)
+	assert.Equal(t, "AEF_id_rApp_as_AEF", certificate.Subject.CommonName)
+	assert.Equal(t, x509.KeyUsageDigitalSignature, certificate.KeyUsage)
+	// The certificate must not outlive the certificate of the authority
+	assert.Equal(t, caCertificate.NotAfter, certificate.NotAfter)
+}
+
+func TestParseInvalidPublicKey(t *testing.T) {
+	_, err := ParsePublicKey("key")
+	assert.ErrorContains(t, err, "no PEM encoded public key")
+
+	_, err = ParsePublicKey(string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("key")})))
+	assert.ErrorContains(t, err, "unsupported PEM type PRIVATE KEY")
+
+	_, err = ParsePublicKey(string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: []byte("key")})))
+	assert.Error(t, err)
+}
+
+func TestCertificateAuthorityRequiresCaCertificate(t *testing.T) {
+	dir := writeCertificateAuthority(t, false)
+
+	_, err := NewCertificateAuthority(filepath.Join(dir, "ca.pem"), filepath.Join(dir, "ca-key.pem"), time.Hour)
+
+	assert.ErrorContains(t, err, "not a CA certificate")
+}
+
+func getCertificateAuthority(t *testing.T) (*CertificateAuthority, *x509.Certificate) {
+	dir := writeCertificateAuthority(t, true)
+	ca, err := NewCertificateAuthority(filepath.Join(dir, "ca.pem"), filepath.Join(dir, "ca-key.pem"), time.Hour)
+	assert.NoError(t, err)
+	return ca, ca.certificate
+}
+
+func writeCertificateAuthority(t *testing.T, isCA bool) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "CAPIF Core CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour).Truncate(time.Second),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	certificate, err := x509.CreateCertificate(rand.Reader, &template, &template, key.Public(), key)
+	assert.NoError(t, err)
+	keyBytes, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+	err = os.WriteFile(filepath.Join(dir, "ca.pem"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate}), 0600)
+	assert.NoError(t, err)
+	err = os.WriteFile(filepath.Join(dir, "ca-key.pem"), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyBytes}), 0600)
+	assert.NoError(t, err)
+	return dir
+}
+
+func verifyCertificate(t *testing.T, certificatePem string, caCertificate *x509.Certificate) *x509.Certificate {
+	block, _ := pem.Decode([]byte(certificatePem))
+	assert.Equal(t, "CERTIFICATE", block.Type)
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	assert.NoError(t, err)
+	roots := x509.NewCertPool()
+	roots.AddCert(caCertificate)
+	_, err = certificate.Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}})
+	assert.NoError(t, err)
+	return certificate
+}
@@ -0,0 +1,167 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package clientauth
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+
+	echo "github.com/labstack/echo/v4"
+
+	"oransc.org/nonrtric/capifcore/internal/invokermanagement"
+	"oransc.org/nonrtric/capifcore/internal/providermanagement"
+	"oransc.org/nonrtric/capifcore/internal/publishservice"
+	security "oransc.org/nonrtric/capifcore/internal/securityservice"
+	"oransc.org/nonrtric/capifcore/problemdetails"
+)
+
+// Authorizes the requests to the provider management, publish service, access control policy, security, routing info
+// and access request APIs by the client certificate of the request. The common name of the certificate is the id of
+// the API provider function or invoker making the request, as in the certificates issued by CAPIF Core. If CAPIF Core
+// has issued a certificate for the client, only that certificate is accepted. Requests to other APIs are passed on as
+// they are.
+type ClientAuthenticator struct {
+	serviceRegister  providermanagement.ServiceRegister
+	publishRegister  publishservice.PublishRegister
+	invokerRegister  invokermanagement.InvokerRegister
+	securityRegister security.SecurityRegister
+}
+
+func NewClientAuthenticator(serviceRegister providermanagement.ServiceRegister, publishRegister publishservice.PublishRegister, invokerRegister invokermanagement.InvokerRegister, securityRegister security.SecurityRegister) *ClientAuthenticator {
+	return &ClientAuthenticator{
+		serviceRegister:  serviceRegister,
+		publishRegister:  publishRegister,
+		invokerRegister:  invokerRegister,
+		securityRegister: securityRegister,
+	}
+}
+
+// Echo middleware rejecting requests without a verified client certificate with 401, and requests from clients that
+// do not own the resource with 403. The middleware must be added with Use, so that the route of the request is known.
+func (ca *ClientAuthenticator) Authenticate(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		authorize := ca.getAuthorization(ctx)
+		if authorize == nil {
+			return next(ctx)
+		}
+
+		clientId, ok := getClientId(ctx)
+		if !ok {
+			return problemdetails.Send(ctx, http.StatusUnauthorized, "", "A verified client certificate is required")
+		}
+		if !ca.isCurrentCertificate(clientId, ctx.Request().TLS.VerifiedChains[0][0]) {
+			return problemdetails.Send(ctx, http.StatusUnauthorized, "", "The client certificate has been replaced by a new certificate")
+		}
+		if !authorize(clientId) {
+			return problemdetails.Send(ctx, http.StatusForbidden, problemdetails.CauseRequestNotAuthorized, fmt.Sprintf("Client %s is not authorized to access %s", clientId, ctx.Request().URL.Path))
+		}
+		return next(ctx)
+	}
+}
+
+// Gets the check of the client id for the route of the request, nil if the route does not require a client
+// certificate.
+func (ca *ClientAuthenticator) getAuthorization(ctx echo.Context) func(clientId string) bool {
+	switch ctx.Path() {
+	case "/api-provider-management/v1/registrations":
+		// A new provider has no registered functions yet, any client trusted by the CA is allowed to register
+		return func(string) bool { return true }
+	case "/api-provider-management/v1/registrations/:registrationId":
+		return func(clientId string) bool {
+			return ca.serviceRegister.IsFunctionRegisteredForProvider(ctx.Param("registrationId"), clientId)
+		}
+	case "/published-apis/v1/:apfId/service-apis", "/published-apis/v1/:apfId/service-apis/:serviceApiId",
+		"/published-apis/v1/:apfId/service-apis/:serviceApiId/visibility",
+		"/access-requests/v1/providers/:apfId/requests",
+		"/access-requests/v1/providers/:apfId/requests/:requestId/approve",
+		"/access-requests/v1/providers/:apfId/requests/:requestId/reject":
+		return func(clientId string) bool {
+			return clientId == ctx.Param("apfId") && ca.serviceRegister.IsPublishingFunctionRegistered(clientId)
+		}
+	case "/access-control-policy/v1/accessControlPolicyList/:serviceApiId/apiInvokerPolicies/:apiInvokerId":
+		// The policies of an API are adjusted by the API publishing function given in the request, which the service
+		// checks has published the API
+		return func(clientId string) bool {
+			return clientId == ctx.QueryParam("apf-id")
+		}
+	case "/capif-routing-info/v1/service-apis/:serviceApiId":
+		if ctx.Request().Method == http.MethodGet {
+			return nil
+		}
+		// The routing information of an API is stored and removed by the API publishing function that has published
+		// the API
+		return func(clientId string) bool {
+			return clientId == ca.publishRegister.GetPublishingFunction(ctx.Param("serviceApiId"))
+		}
+	case "/capif-security/v1/trustedInvokers/:apiInvokerId",
+		"/capif-security/v1/trustedInvokers/:apiInvokerId/delete",
+		"/capif-security/v1/trustedInvokers/:apiInvokerId/update":
+		// The security context is managed by the invoker itself and by the API exposing functions it has security
+		// information for
+		return func(clientId string) bool {
+			invokerId := ctx.Param("apiInvokerId")
+			return clientId == invokerId || ca.securityRegister.HasSecurityInfoForAef(invokerId, clientId)
+		}
+	case "/capif-security/v1/securities/:securityId/token":
+		return func(clientId string) bool {
+			return clientId == ctx.Param("securityId") && ca.invokerRegister.IsInvokerRegistered(clientId)
+		}
+	case "/capif-security/v1/introspect":
+		return ca.serviceRegister.IsFunctionRegistered
+	case "/api-invoker-management/v1/onboardedInvokers/:onboardingId":
+		return func(clientId string) bool {
+			return clientId == ctx.Param("onboardingId")
+		}
+	case "/access-requests/v1/invokers/:apiInvokerId/requests",
+		"/access-requests/v1/invokers/:apiInvokerId/requests/:requestId":
+		return func(clientId string) bool {
+			return clientId == ctx.Param("apiInvokerId")
+		}
+	}
+	return nil
+}
+
+// Checks that the certificate is the current certificate of the client, if CAPIF core has issued a certificate for the
+// client. A certificate issued for a previous public key of the client is no longer accepted.
+func (ca *ClientAuthenticator) isCurrentCertificate(clientId string, certificate *x509.Certificate) bool {
+	issuedCertificate := ca.invokerRegister.GetInvokerCertificate(clientId)
+	if issuedCertificate == "" {
+		issuedCertificate = ca.serviceRegister.GetFunctionCertificate(clientId)
+	}
+	if issuedCertificate == "" {
+		return true
+	}
+	block, _ := pem.Decode([]byte(issuedCertificate))
+	return block != nil && bytes.Equal(block.Bytes, certificate.Raw)
+}
+
+// Gets the common name of the client certificate, if the certificate has been verified.
+func getClientId(ctx echo.Context) (string, bool) {
+	tlsState := ctx.Request().TLS
+	if tlsState == nil || len(tlsState.VerifiedChains) == 0 || len(tlsState.VerifiedChains[0]) == 0 {
+		return "", false
+	}
+	clientId := tlsState.VerifiedChains[0][0].Subject.CommonName
+	return clientId, clientId != ""
+}
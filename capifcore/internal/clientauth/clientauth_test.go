@@ -0,0 +1,276 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package clientauth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	echo "github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	invokermocks "oransc.org/nonrtric/capifcore/internal/invokermanagement/mocks"
+	providermocks "oransc.org/nonrtric/capifcore/internal/providermanagement/mocks"
+	publishmocks "oransc.org/nonrtric/capifcore/internal/publishservice/mocks"
+	securitymocks "oransc.org/nonrtric/capifcore/internal/securityservice/mocks"
+)
+
+func TestRequestsWithoutVerifiedCertificateAreUnauthorized(t *testing.T) {
+	requestHandler := getEcho(&providermocks.ServiceRegister{}, nil, &invokermocks.InvokerRegister{}, nil)
+
+	result := sendRequest(requestHandler, http.MethodGet, "/published-apis/v1/apfId/service-apis", "")
+	assert.Equal(t, http.StatusUnauthorized, result.Code)
+	assert.Contains(t, result.Body.String(), "A verified client certificate is required")
+
+	result = sendRequest(requestHandler, http.MethodPost, "/api-provider-management/v1/registrations", "")
+	assert.Equal(t, http.StatusUnauthorized, result.Code)
+
+	// APIs that do not require client certificates
+	result = sendRequest(requestHandler, http.MethodPost, "/api-invoker-management/v1/onboardedInvokers", "")
+	assert.Equal(t, http.StatusOK, result.Code)
+	result = sendRequest(requestHandler, http.MethodGet, "/service-apis/v1/allServiceAPIs", "")
+	assert.Equal(t, http.StatusOK, result.Code)
+}
+
+func TestProviderCanOnlyManageItsOwnRegistration(t *testing.T) {
+	serviceRegisterMock := providermocks.ServiceRegister{}
+	serviceRegisterMock.On("IsFunctionRegisteredForProvider", "domainId", "AMF_id").Return(true)
+	serviceRegisterMock.On("IsFunctionRegisteredForProvider", "otherDomainId", "AMF_id").Return(false)
+	requestHandler := getEcho(&serviceRegisterMock, nil, nil, nil)
+
+	result := sendRequest(requestHandler, http.MethodPost, "/api-provider-management/v1/registrations", "rApp")
+	assert.Equal(t, http.StatusOK, result.Code)
+
+	result = sendRequest(requestHandler, http.MethodPut, "/api-provider-management/v1/registrations/domainId", "AMF_id")
+	assert.Equal(t, http.StatusOK, result.Code)
+
+	result = sendRequest(requestHandler, http.MethodDelete, "/api-provider-management/v1/registrations/otherDomainId", "AMF_id")
+	assert.Equal(t, http.StatusForbidden, result.Code)
+	assert.Contains(t, result.Body.String(), "Client AMF_id is not authorized")
+}
+
+func TestProviderCanOnlyPublishUnderItsOwnApfId(t *testing.T) {
+	serviceRegisterMock := providermocks.ServiceRegister{}
+	serviceRegisterMock.On("IsPublishingFunctionRegistered", "APF_id").Return(true)
+	serviceRegisterMock.On("IsPublishingFunctionRegistered", "AEF_id").Return(false)
+	requestHandler := getEcho(&serviceRegisterMock, nil, nil, nil)
+
+	result := sendRequest(requestHandler, http.MethodPost, "/published-apis/v1/APF_id/service-apis", "APF_id")
+	assert.Equal(t, http.StatusOK, result.Code)
+
+	result = sendRequest(requestHandler, http.MethodPut, "/published-apis/v1/otherApfId/service-apis/apiId", "APF_id")
+	assert.Equal(t, http.StatusForbidden, result.Code)
+
+	result = sendRequest(requestHandler, http.MethodGet, "/published-apis/v1/AEF_id/service-apis", "AEF_id")
+	assert.Equal(t, http.StatusForbidden, result.Code)
+}
+
+func TestOnlyPublishingFunctionOfRequestCanUpdateAccessControlPolicies(t *testing.T) {
+	requestHandler := getEcho(nil, nil, nil, nil)
+
+	path := "/access-control-policy/v1/accessControlPolicyList/apiId/apiInvokerPolicies/invokerId"
+	result := sendRequest(requestHandler, http.MethodPut, path+"?aef-id=AEF_id&apf-id=APF_id", "APF_id")
+	assert.Equal(t, http.StatusOK, result.Code)
+
+	// Other functions cannot update the policies as the publishing function of the request
+	result = sendRequest(requestHandler, http.MethodPut, path+"?aef-id=AEF_id&apf-id=APF_id", "AEF_id")
+	assert.Equal(t, http.StatusForbidden, result.Code)
+	result = sendRequest(requestHandler, http.MethodPut, path+"?aef-id=AEF_id&apf-id=APF_id", "invokerId")
+	assert.Equal(t, http.StatusForbidden, result.Code)
+	result = sendRequest(requestHandler, http.MethodPut, path+"?aef-id=AEF_id&apf-id=APF_id", "")
+	assert.Equal(t, http.StatusUnauthorized, result.Code)
+}
+
+func TestSecurityApiAuthorization(t *testing.T) {
+	serviceRegisterMock := providermocks.ServiceRegister{}
+	serviceRegisterMock.On("IsFunctionRegistered", "AEF_id").Return(true)
+	serviceRegisterMock.On("IsFunctionRegistered", "otherInvokerId").Return(false)
+	invokerRegisterMock := invokermocks.InvokerRegister{}
+	invokerRegisterMock.On("IsInvokerRegistered", "invokerId").Return(true)
+	securityRegisterMock := securitymocks.SecurityRegister{}
+	securityRegisterMock.On("HasSecurityInfoForAef", "invokerId", "AEF_id").Return(true)
+	securityRegisterMock.On("HasSecurityInfoForAef", "invokerId", mock.Anything).Return(false)
+	requestHandler := getEcho(&serviceRegisterMock, nil, &invokerRegisterMock, &securityRegisterMock)
+
+	// The security context can be managed by the invoker itself and by the API exposing functions it has security information for
+	result := sendRequest(requestHandler, http.MethodPut, "/capif-security/v1/trustedInvokers/invokerId", "invokerId")
+	assert.Equal(t, http.StatusOK, result.Code)
+	result = sendRequest(requestHandler, http.MethodPost, "/capif-security/v1/trustedInvokers/invokerId/delete", "AEF_id")
+	assert.Equal(t, http.StatusOK, result.Code)
+	result = sendRequest(requestHandler, http.MethodPost, "/capif-security/v1/trustedInvokers/invokerId/delete", "APF_id")
+	assert.Equal(t, http.StatusForbidden, result.Code)
+	result = sendRequest(requestHandler, http.MethodGet, "/capif-security/v1/trustedInvokers/invokerId", "otherInvokerId")
+	assert.Equal(t, http.StatusForbidden, result.Code)
+
+	// Only the invoker itself can get a token
+	result = sendRequest(requestHandler, http.MethodPost, "/capif-security/v1/securities/invokerId/token", "invokerId")
+	assert.Equal(t, http.StatusOK, result.Code)
+	result = sendRequest(requestHandler, http.MethodPost, "/capif-security/v1/securities/invokerId/token", "AEF_id")
+	assert.Equal(t, http.StatusForbidden, result.Code)
+
+	// Only API provider functions can introspect tokens
+	result = sendRequest(requestHandler, http.MethodPost, "/capif-security/v1/introspect", "AEF_id")
+	assert.Equal(t, http.StatusOK, result.Code)
+	result = sendRequest(requestHandler, http.MethodPost, "/capif-security/v1/introspect", "otherInvokerId")
+	assert.Equal(t, http.StatusForbidden, result.Code)
+}
+
+func TestInvokerCanOnlyAccessItsOwnOnboarding(t *testing.T) {
+	requestHandler := getEcho(nil, nil, nil, nil)
+
+	result := sendRequest(requestHandler, http.MethodPut, "/api-invoker-management/v1/onboardedInvokers/invokerId", "invokerId")
+	assert.Equal(t, http.StatusOK, result.Code)
+
+	result = sendRequest(requestHandler, http.MethodDelete, "/api-invoker-management/v1/onboardedInvokers/invokerId", "otherInvokerId")
+	assert.Equal(t, http.StatusForbidden, result.Code)
+
+	result = sendRequest(requestHandler, http.MethodPatch, "/api-invoker-management/v1/onboardedInvokers/invokerId", "")
+	assert.Equal(t, http.StatusUnauthorized, result.Code)
+}
+
+func TestAccessRequestAuthorization(t *testing.T) {
+	serviceRegisterMock := providermocks.ServiceRegister{}
+	serviceRegisterMock.On("IsPublishingFunctionRegistered", "APF_id").Return(true)
+	requestHandler := getEcho(&serviceRegisterMock, nil, nil, nil)
+
+	// Invokers can only request access and see the requests for themselves
+	result := sendRequest(requestHandler, http.MethodPost, "/access-requests/v1/invokers/invokerId/requests", "invokerId")
+	assert.Equal(t, http.StatusOK, result.Code)
+	result = sendRequest(requestHandler, http.MethodGet, "/access-requests/v1/invokers/invokerId/requests/requestId", "otherInvokerId")
+	assert.Equal(t, http.StatusForbidden, result.Code)
+
+	// Only the publishing function itself can handle the requests for the APIs of its provider
+	result = sendRequest(requestHandler, http.MethodPost, "/access-requests/v1/providers/APF_id/requests/requestId/approve", "APF_id")
+	assert.Equal(t, http.StatusOK, result.Code)
+	result = sendRequest(requestHandler, http.MethodPost, "/access-requests/v1/providers/APF_id/requests/requestId/reject", "invokerId")
+	assert.Equal(t, http.StatusForbidden, result.Code)
+	result = sendRequest(requestHandler, http.MethodGet, "/access-requests/v1/providers/APF_id/requests", "")
+	assert.Equal(t, http.StatusUnauthorized, result.Code)
+}
+
+func TestOnlyPublisherOfApiCanManageRoutingInfo(t *testing.T) {
+	publishRegisterMock := publishmocks.PublishRegister{}
+	publishRegisterMock.On("GetPublishingFunction", "apiId").Return("APF_id")
+	requestHandler := getEcho(nil, &publishRegisterMock, nil, nil)
+
+	path := "/capif-routing-info/v1/service-apis/apiId"
+	result := sendRequest(requestHandler, http.MethodPut, path, "APF_id")
+	assert.Equal(t, http.StatusOK, result.Code)
+	result = sendRequest(requestHandler, http.MethodDelete, path, "APF_id")
+	assert.Equal(t, http.StatusOK, result.Code)
+
+	result = sendRequest(requestHandler, http.MethodPut, path, "otherApfId")
+	assert.Equal(t, http.StatusForbidden, result.Code)
+	result = sendRequest(requestHandler, http.MethodDelete, path, "otherApfId")
+	assert.Equal(t, http.StatusForbidden, result.Code)
+	result = sendRequest(requestHandler, http.MethodPut, path, "")
+	assert.Equal(t, http.StatusUnauthorized, result.Code)
+
+	// The routing rules are read by the AEFs without client certificate
+	result = sendRequest(requestHandler, http.MethodGet, path+"?aef-id=AEF_id", "")
+	assert.Equal(t, http.StatusOK, result.Code)
+}
+
+func TestOnlyCurrentCertificateIssuedByCapifCoreIsAccepted(t *testing.T) {
+	currentCertificate := &x509.Certificate{Subject: pkix.Name{CommonName: "invokerId"}, Raw: []byte("current")}
+	replacedCertificate := &x509.Certificate{Subject: pkix.Name{CommonName: "invokerId"}, Raw: []byte("replaced")}
+	invokerRegisterMock := invokermocks.InvokerRegister{}
+	invokerRegisterMock.On("GetInvokerCertificate", "invokerId").Return(string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: currentCertificate.Raw})))
+	requestHandler := getEcho(nil, nil, &invokerRegisterMock, nil)
+	path := "/api-invoker-management/v1/onboardedInvokers/invokerId"
+
+	result := sendRequestWithCertificate(requestHandler, http.MethodPut, path, currentCertificate)
+	assert.Equal(t, http.StatusOK, result.Code)
+
+	// The certificate was issued for a previous public key of the invoker
+	result = sendRequestWithCertificate(requestHandler, http.MethodPut, path, replacedCertificate)
+	assert.Equal(t, http.StatusUnauthorized, result.Code)
+	assert.Contains(t, result.Body.String(), "replaced")
+}
+
+func getEcho(serviceRegister *providermocks.ServiceRegister, publishRegister *publishmocks.PublishRegister, invokerRegister *invokermocks.InvokerRegister, securityRegister *securitymocks.SecurityRegister) *echo.Echo {
+	// Unless a test says otherwise, CAPIF Core has not issued the certificates of the clients
+	if serviceRegister == nil {
+		serviceRegister = &providermocks.ServiceRegister{}
+	}
+	serviceRegister.On("GetFunctionCertificate", mock.Anything).Return("")
+	if invokerRegister == nil {
+		invokerRegister = &invokermocks.InvokerRegister{}
+	}
+	invokerRegister.On("GetInvokerCertificate", mock.Anything).Return("")
+	if securityRegister == nil {
+		securityRegister = &securitymocks.SecurityRegister{}
+	}
+	e := echo.New()
+	e.Use(NewClientAuthenticator(serviceRegister, publishRegister, invokerRegister, securityRegister).Authenticate)
+	handler := func(ctx echo.Context) error {
+		return ctx.NoContent(http.StatusOK)
+	}
+	for _, route := range []string{
+		"/api-provider-management/v1/registrations",
+		"/api-provider-management/v1/registrations/:registrationId",
+		"/published-apis/v1/:apfId/service-apis",
+		"/published-apis/v1/:apfId/service-apis/:serviceApiId",
+		"/access-control-policy/v1/accessControlPolicyList/:serviceApiId/apiInvokerPolicies/:apiInvokerId",
+		"/capif-routing-info/v1/service-apis/:serviceApiId",
+		"/capif-security/v1/trustedInvokers/:apiInvokerId",
+		"/capif-security/v1/trustedInvokers/:apiInvokerId/delete",
+		"/capif-security/v1/securities/:securityId/token",
+		"/capif-security/v1/introspect",
+		"/api-invoker-management/v1/onboardedInvokers",
+		"/api-invoker-management/v1/onboardedInvokers/:onboardingId",
+		"/service-apis/v1/allServiceAPIs",
+		"/access-requests/v1/invokers/:apiInvokerId/requests",
+		"/access-requests/v1/invokers/:apiInvokerId/requests/:requestId",
+		"/access-requests/v1/providers/:apfId/requests",
+		"/access-requests/v1/providers/:apfId/requests/:requestId/approve",
+		"/access-requests/v1/providers/:apfId/requests/:requestId/reject",
+	} {
+		e.Any(route, handler)
+	}
+	return e
+}
+
+// Sends a request with a verified client certificate with the provided common name, or without certificate if the
+// common name is empty.
+func sendRequest(requestHandler *echo.Echo, method, path, commonName string) *httptest.ResponseRecorder {
+	if commonName == "" {
+		return sendRequestWithCertificate(requestHandler, method, path, nil)
+	}
+	return sendRequestWithCertificate(requestHandler, method, path, &x509.Certificate{Subject: pkix.Name{CommonName: commonName}})
+}
+
+// Sends a request with the provided verified client certificate, or without certificate if it is nil.
+func sendRequestWithCertificate(requestHandler *echo.Echo, method, path string, certificate *x509.Certificate) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	if certificate != nil {
+		req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{certificate}}}
+	}
+	rec := httptest.NewRecorder()
+	requestHandler.ServeHTTP(rec, req)
+	return rec
+}
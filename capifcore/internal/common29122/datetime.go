@@ -0,0 +1,42 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package common29122
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// The generated DateTime type does not inherit the methods of time.Time, so without these it would be marshalled as an
+// empty object instead of a "date-time" string.
+
+func (dt DateTime) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Time(dt))
+}
+
+func (dt *DateTime) UnmarshalJSON(data []byte) error {
+	var t time.Time
+	if err := json.Unmarshal(data, &t); err != nil {
+		return err
+	}
+	*dt = DateTime(t)
+	return nil
+}
@@ -25,33 +25,42 @@ import (
 	"fmt"
 	"net/http"
 
-	"oransc.org/nonrtric/capifcore/internal/common29122"
 	discoverapi "oransc.org/nonrtric/capifcore/internal/discoverserviceapi"
 	"oransc.org/nonrtric/capifcore/internal/invokermanagement"
+	"oransc.org/nonrtric/capifcore/internal/providermanagement"
+	"oransc.org/nonrtric/capifcore/internal/publishservice"
 
 	"github.com/labstack/echo/v4"
 
 	publishapi "oransc.org/nonrtric/capifcore/internal/publishserviceapi"
+	"oransc.org/nonrtric/capifcore/problemdetails"
 )
 
 type DiscoverService struct {
 	invokerRegister invokermanagement.InvokerRegister
+	publishRegister publishservice.PublishRegister
+	serviceRegister providermanagement.ServiceRegister
 }
 
-func NewDiscoverService(invokerRegister invokermanagement.InvokerRegister) *DiscoverService {
+// Creates a service that lets invokers discover the APIs that are visible to them. An invoker sees the APIs it has been
+// granted, the shareable APIs shared with the provider domains of its granted APIs, and the APIs whose providers allow
+// it. An API is never visible to an invoker that its provider denies.
+func NewDiscoverService(invokerRegister invokermanagement.InvokerRegister, publishRegister publishservice.PublishRegister, serviceRegister providermanagement.ServiceRegister) *DiscoverService {
 	return &DiscoverService{
 		invokerRegister: invokerRegister,
+		publishRegister: publishRegister,
+		serviceRegister: serviceRegister,
 	}
 }
 
 func (ds *DiscoverService) GetAllServiceAPIs(ctx echo.Context, params discoverapi.GetAllServiceAPIsParams) error {
-	allApis := ds.invokerRegister.GetInvokerApiList(params.ApiInvokerId)
-	if allApis == nil {
-		return sendCoreError(ctx, http.StatusNotFound, fmt.Sprintf("Invoker %s not registered", params.ApiInvokerId))
+	grantedApis := ds.invokerRegister.GetInvokerApiList(params.ApiInvokerId)
+	if grantedApis == nil {
+		return problemdetails.Send(ctx, http.StatusNotFound, problemdetails.CauseContextNotFound, fmt.Sprintf("Invoker %s not registered", params.ApiInvokerId))
 	}
 
 	filteredApis := []publishapi.ServiceAPIDescription{}
-	for _, api := range *allApis {
+	for _, api := range ds.getVisibleApis(params.ApiInvokerId, *grantedApis) {
 		if matchesFilter(api, params) {
 			filteredApis = append(filteredApis, api)
 		}
@@ -68,6 +77,70 @@ func (ds *DiscoverService) GetAllServiceAPIs(ctx echo.Context, params discoverap
 	return nil
 }
 
+// Gets the APIs that are visible to the invoker. The granted APIs come first, followed by the other visible APIs in the
+// order they are published.
+func (ds *DiscoverService) getVisibleApis(invokerId string, grantedApis []publishapi.ServiceAPIDescription) []publishapi.ServiceAPIDescription {
+	visibleApis := []publishapi.ServiceAPIDescription{}
+	grantedApiIds := make(map[string]bool)
+	for _, api := range grantedApis {
+		if api.ApiId == nil {
+			continue
+		}
+		grantedApiIds[*api.ApiId] = true
+		if visibility := ds.publishRegister.GetServiceVisibility(*api.ApiId); visibility == nil || !visibility.IsDenied(invokerId) {
+			visibleApis = append(visibleApis, api)
+		}
+	}
+
+	invokerDomains := ds.getProviderDomains(grantedApis)
+	for _, api := range ds.publishRegister.GetAllPublishedServices() {
+		if api.ApiId == nil || grantedApiIds[*api.ApiId] {
+			continue
+		}
+		visibility := ds.publishRegister.GetServiceVisibility(*api.ApiId)
+		if visibility != nil && visibility.IsDenied(invokerId) {
+			continue
+		}
+		if (visibility != nil && visibility.IsAllowed(invokerId)) || isSharedWith(api, invokerDomains) {
+			visibleApis = append(visibleApis, api)
+		}
+	}
+	return visibleApis
+}
+
+// Gets the domains of the providers that expose the provided APIs.
+func (ds *DiscoverService) getProviderDomains(apis []publishapi.ServiceAPIDescription) map[string]bool {
+	domains := make(map[string]bool)
+	for _, api := range apis {
+		if api.AefProfiles == nil {
+			continue
+		}
+		for _, profile := range *api.AefProfiles {
+			if domain := ds.serviceRegister.GetProviderDomain(profile.AefId); domain != "" {
+				domains[domain] = true
+			}
+		}
+	}
+	return domains
+}
+
+// Checks if the API is shareable with any of the provided domains. A shareable API without provider domains is shared
+// with all domains.
+func isSharedWith(api publishapi.ServiceAPIDescription, domains map[string]bool) bool {
+	if api.ShareableInfo == nil || !api.ShareableInfo.IsShareable {
+		return false
+	}
+	if api.ShareableInfo.CapifProvDoms == nil || len(*api.ShareableInfo.CapifProvDoms) == 0 {
+		return true
+	}
+	for _, domain := range *api.ShareableInfo.CapifProvDoms {
+		if domains[domain] {
+			return true
+		}
+	}
+	return false
+}
+
 func matchesFilter(api publishapi.ServiceAPIDescription, filter discoverapi.GetAllServiceAPIsParams) bool {
 	if filter.ApiName != nil && *filter.ApiName != api.ApiName {
 		return false
@@ -144,14 +217,3 @@ func checkCommType(resources *[]publishapi.Resource, commType *publishapi.Commun
 	}
 	return false
 }
-
-// This function wraps sending of an error in the Error format, and
-// handling the failure to marshal that.
-func sendCoreError(ctx echo.Context, code int, message string) error {
-	pd := common29122.ProblemDetails{
-		Cause:  &message,
-		Status: &code,
-	}
-	err := ctx.JSON(code, pd)
-	return err
-}
@@ -36,11 +36,17 @@ import (
 	publishapi "oransc.org/nonrtric/capifcore/internal/publishserviceapi"
 
 	"oransc.org/nonrtric/capifcore/internal/invokermanagement/mocks"
+	"oransc.org/nonrtric/capifcore/internal/providermanagement"
+	providermocks "oransc.org/nonrtric/capifcore/internal/providermanagement/mocks"
+	"oransc.org/nonrtric/capifcore/internal/publishservice"
+	publishmocks "oransc.org/nonrtric/capifcore/internal/publishservice/mocks"
+	_ "oransc.org/nonrtric/capifcore/problemdetails/problemdetailstest"
 
 	"github.com/deepmap/oapi-codegen/pkg/middleware"
 	"github.com/deepmap/oapi-codegen/pkg/testutil"
 	echomiddleware "github.com/labstack/echo/v4/middleware"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 var protocolHTTP11 = publishapi.ProtocolHTTP11
@@ -57,7 +63,7 @@ func TestGetAllServiceAPIs(t *testing.T) {
 	}
 	invokerId := "api_invoker_id"
 	invokerRegisterrMock := getInvokerRegisterMock(invokerId, apiList)
-	requestHandler := getEcho(invokerRegisterrMock)
+	requestHandler := getEcho(invokerRegisterrMock, getPublishRegisterMock(apiList, nil), getServiceRegisterMock(nil))
 
 	// Get all APIs, without any filter
 	result := testutil.NewRequest().Get("/allServiceAPIs?api-invoker-id="+invokerId).Go(t, requestHandler)
@@ -76,7 +82,7 @@ func TestGetAllServiceAPIsWhenMissingProvider(t *testing.T) {
 	invokerId := "unregistered"
 	invokerRegisterrMock := getInvokerRegisterMock(invokerId, nil)
 
-	requestHandler := getEcho(invokerRegisterrMock)
+	requestHandler := getEcho(invokerRegisterrMock, getPublishRegisterMock(nil, nil), getServiceRegisterMock(nil))
 
 	// Get all APIs, without any filter
 	result := testutil.NewRequest().Get("/allServiceAPIs?api-invoker-id="+invokerId).Go(t, requestHandler)
@@ -87,8 +93,8 @@ func TestGetAllServiceAPIsWhenMissingProvider(t *testing.T) {
 	assert.NoError(t, err, "error unmarshaling response")
 	notFound := http.StatusNotFound
 	assert.Equal(t, &notFound, problemDetails.Status)
-	assert.Contains(t, *problemDetails.Cause, invokerId)
-	assert.Contains(t, *problemDetails.Cause, "not registered")
+	assert.Contains(t, *problemDetails.Detail, invokerId)
+	assert.Contains(t, *problemDetails.Detail, "not registered")
 }
 
 func TestFilterApiName(t *testing.T) {
@@ -101,7 +107,7 @@ func TestFilterApiName(t *testing.T) {
 	}
 	invokerId := "api_invoker_id"
 	invokerRegisterrMock := getInvokerRegisterMock(invokerId, apiList)
-	requestHandler := getEcho(invokerRegisterrMock)
+	requestHandler := getEcho(invokerRegisterrMock, getPublishRegisterMock(apiList, nil), getServiceRegisterMock(nil))
 
 	// Get APIs with filter
 	result := testutil.NewRequest().Get("/allServiceAPIs?api-invoker-id="+invokerId+"&api-name="+apiName).Go(t, requestHandler)
@@ -125,7 +131,7 @@ func TestFilterAefId(t *testing.T) {
 	}
 	invokerId := "api_invoker_id"
 	invokerRegisterrMock := getInvokerRegisterMock(invokerId, apiList)
-	requestHandler := getEcho(invokerRegisterrMock)
+	requestHandler := getEcho(invokerRegisterrMock, getPublishRegisterMock(apiList, nil), getServiceRegisterMock(nil))
 
 	// Get APIs with filter
 	result := testutil.NewRequest().Get("/allServiceAPIs?api-invoker-id="+invokerId+"&aef-id="+aefId).Go(t, requestHandler)
@@ -149,7 +155,7 @@ func TestFilterVersion(t *testing.T) {
 	}
 	invokerId := "api_invoker_id"
 	invokerRegisterrMock := getInvokerRegisterMock(invokerId, apiList)
-	requestHandler := getEcho(invokerRegisterrMock)
+	requestHandler := getEcho(invokerRegisterrMock, getPublishRegisterMock(apiList, nil), getServiceRegisterMock(nil))
 
 	// Get APIs with filter
 	result := testutil.NewRequest().Get("/allServiceAPIs?api-invoker-id="+invokerId+"&api-version="+version).Go(t, requestHandler)
@@ -173,7 +179,7 @@ func TestFilterCommType(t *testing.T) {
 	}
 	invokerId := "api_invoker_id"
 	invokerRegisterrMock := getInvokerRegisterMock(invokerId, apiList)
-	requestHandler := getEcho(invokerRegisterrMock)
+	requestHandler := getEcho(invokerRegisterrMock, getPublishRegisterMock(apiList, nil), getServiceRegisterMock(nil))
 
 	// Get APIs with filter
 	result := testutil.NewRequest().Get("/allServiceAPIs?api-invoker-id="+invokerId+"&comm-type="+string(commType)).Go(t, requestHandler)
@@ -199,7 +205,7 @@ func TestFilterVersionAndCommType(t *testing.T) {
 	}
 	invokerId := "api_invoker_id"
 	invokerRegisterrMock := getInvokerRegisterMock(invokerId, apiList)
-	requestHandler := getEcho(invokerRegisterrMock)
+	requestHandler := getEcho(invokerRegisterrMock, getPublishRegisterMock(apiList, nil), getServiceRegisterMock(nil))
 
 	// Get APIs with filter
 	result := testutil.NewRequest().Get("/allServiceAPIs?api-invoker-id="+invokerId+"&api-version="+version+"&comm-type="+string(commType)).Go(t, requestHandler)
@@ -223,7 +229,7 @@ func TestFilterAPICategory(t *testing.T) {
 	}
 	invokerId := "api_invoker_id"
 	invokerRegisterrMock := getInvokerRegisterMock(invokerId, apiList)
-	requestHandler := getEcho(invokerRegisterrMock)
+	requestHandler := getEcho(invokerRegisterrMock, getPublishRegisterMock(apiList, nil), getServiceRegisterMock(nil))
 
 	// Get APIs with filter
 	result := testutil.NewRequest().Get("/allServiceAPIs?api-invoker-id="+invokerId+"&api-cat="+apiCategory).Go(t, requestHandler)
@@ -246,7 +252,7 @@ func TestFilterProtocol(t *testing.T) {
 	}
 	invokerId := "api_invoker_id"
 	invokerRegisterrMock := getInvokerRegisterMock(invokerId, apiList)
-	requestHandler := getEcho(invokerRegisterrMock)
+	requestHandler := getEcho(invokerRegisterrMock, getPublishRegisterMock(apiList, nil), getServiceRegisterMock(nil))
 
 	// Get APIs with filter
 	result := testutil.NewRequest().Get("/allServiceAPIs?api-invoker-id="+invokerId+"&protocol="+string(protocolHTTP11)).Go(t, requestHandler)
@@ -271,7 +277,7 @@ func TestFilterDataFormat(t *testing.T) {
 	}
 	invokerId := "api_invoker_id"
 	invokerRegisterrMock := getInvokerRegisterMock(invokerId, apiList)
-	requestHandler := getEcho(invokerRegisterrMock)
+	requestHandler := getEcho(invokerRegisterrMock, getPublishRegisterMock(apiList, nil), getServiceRegisterMock(nil))
 
 	// Get APIs with filter
 	result := testutil.NewRequest().Get("/allServiceAPIs?api-invoker-id="+invokerId+"&data-format="+string(dataFormatJSON)).Go(t, requestHandler)
@@ -284,7 +290,89 @@ func TestFilterDataFormat(t *testing.T) {
 	assert.Equal(t, apiName, (*resultInvoker.ServiceAPIDescriptions)[0].ApiName)
 }
 
-func getEcho(invokerManager invokermanagement.InvokerRegister) *echo.Echo {
+func TestGetAllServiceAPIsOnlyVisibleApis(t *testing.T) {
+	grantedApi := getAPI("grantedApi", "aefId", "", "v1", nil, nil, "")
+	notGrantedApi := getAPI("notGrantedApi", "otherProvidersAefId", "", "v1", nil, nil, "")
+	invokerId := "api_invoker_id"
+	invokerRegisterrMock := getInvokerRegisterMock(invokerId, []publishapi.ServiceAPIDescription{grantedApi})
+	requestHandler := getEcho(invokerRegisterrMock, getPublishRegisterMock([]publishapi.ServiceAPIDescription{grantedApi, notGrantedApi}, nil), getServiceRegisterMock(nil))
+
+	result := testutil.NewRequest().Get("/allServiceAPIs?api-invoker-id="+invokerId).Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusOK, result.Code())
+	var resultInvoker discoverserviceapi.DiscoveredAPIs
+	err := result.UnmarshalBodyToObject(&resultInvoker)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Len(t, *resultInvoker.ServiceAPIDescriptions, 1)
+	assert.Equal(t, "grantedApi", (*resultInvoker.ServiceAPIDescriptions)[0].ApiName)
+}
+
+func TestGetAllServiceAPIsSharedApis(t *testing.T) {
+	grantedApi := getAPI("grantedApi", "aefId", "", "v1", nil, nil, "")
+	sharedWithDomainApi := getAPI("sharedWithDomainApi", "sharingAefId", "", "v1", nil, nil, "")
+	sharedWithDomainApi.ShareableInfo = &publishapi.ShareableInformation{
+		IsShareable:   true,
+		CapifProvDoms: &[]string{"otherDomain", "invokersDomain"},
+	}
+	sharedWithAllApi := getAPI("sharedWithAllApi", "sharingAefId", "", "v1", nil, nil, "")
+	sharedWithAllApi.ShareableInfo = &publishapi.ShareableInformation{
+		IsShareable: true,
+	}
+	sharedWithOtherDomainApi := getAPI("sharedWithOtherDomainApi", "sharingAefId", "", "v1", nil, nil, "")
+	sharedWithOtherDomainApi.ShareableInfo = &publishapi.ShareableInformation{
+		IsShareable:   true,
+		CapifProvDoms: &[]string{"otherDomain"},
+	}
+	notShareableApi := getAPI("notShareableApi", "sharingAefId", "", "v1", nil, nil, "")
+	notShareableApi.ShareableInfo = &publishapi.ShareableInformation{
+		IsShareable:   false,
+		CapifProvDoms: &[]string{"invokersDomain"},
+	}
+	publishedApis := []publishapi.ServiceAPIDescription{sharedWithDomainApi, sharedWithAllApi, sharedWithOtherDomainApi, notShareableApi, grantedApi}
+	invokerId := "api_invoker_id"
+	invokerRegisterrMock := getInvokerRegisterMock(invokerId, []publishapi.ServiceAPIDescription{grantedApi})
+	requestHandler := getEcho(invokerRegisterrMock, getPublishRegisterMock(publishedApis, nil), getServiceRegisterMock(map[string]string{"aefId": "invokersDomain"}))
+
+	result := testutil.NewRequest().Get("/allServiceAPIs?api-invoker-id="+invokerId).Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusOK, result.Code())
+	var resultInvoker discoverserviceapi.DiscoveredAPIs
+	err := result.UnmarshalBodyToObject(&resultInvoker)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Len(t, *resultInvoker.ServiceAPIDescriptions, 3)
+	assert.Equal(t, "grantedApi", (*resultInvoker.ServiceAPIDescriptions)[0].ApiName)
+	assert.Equal(t, "sharedWithDomainApi", (*resultInvoker.ServiceAPIDescriptions)[1].ApiName)
+	assert.Equal(t, "sharedWithAllApi", (*resultInvoker.ServiceAPIDescriptions)[2].ApiName)
+}
+
+func TestGetAllServiceAPIsWithProviderVisibility(t *testing.T) {
+	invokerId := "api_invoker_id"
+	deniedGrantedApi := getAPI("deniedGrantedApi", "aefId", "", "v1", nil, nil, "")
+	allowedApi := getAPI("allowedApi", "otherAefId", "", "v1", nil, nil, "")
+	deniedSharedApi := getAPI("deniedSharedApi", "otherAefId", "", "v1", nil, nil, "")
+	deniedSharedApi.ShareableInfo = &publishapi.ShareableInformation{
+		IsShareable: true,
+	}
+	visibility := map[string]publishservice.ServiceVisibility{
+		*deniedGrantedApi.ApiId: {DeniedInvokers: []string{invokerId}},
+		*allowedApi.ApiId:       {AllowedInvokers: []string{"otherInvoker", invokerId}},
+		*deniedSharedApi.ApiId:  {DeniedInvokers: []string{invokerId}},
+	}
+	publishedApis := []publishapi.ServiceAPIDescription{deniedGrantedApi, allowedApi, deniedSharedApi}
+	invokerRegisterrMock := getInvokerRegisterMock(invokerId, []publishapi.ServiceAPIDescription{deniedGrantedApi})
+	requestHandler := getEcho(invokerRegisterrMock, getPublishRegisterMock(publishedApis, visibility), getServiceRegisterMock(nil))
+
+	result := testutil.NewRequest().Get("/allServiceAPIs?api-invoker-id="+invokerId).Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusOK, result.Code())
+	var resultInvoker discoverserviceapi.DiscoveredAPIs
+	err := result.UnmarshalBodyToObject(&resultInvoker)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Len(t, *resultInvoker.ServiceAPIDescriptions, 1)
+	assert.Equal(t, "allowedApi", (*resultInvoker.ServiceAPIDescriptions)[0].ApiName)
+}
+
+func getEcho(invokerManager invokermanagement.InvokerRegister, publishRegister publishservice.PublishRegister, serviceRegister providermanagement.ServiceRegister) *echo.Echo {
 	swagger, err := discoverserviceapi.GetSwagger()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error loading swagger spec\n: %s", err)
@@ -293,7 +381,7 @@ func getEcho(invokerManager invokermanagement.InvokerRegister) *echo.Echo {
 
 	swagger.Servers = nil
 
-	ds := NewDiscoverService(invokerManager)
+	ds := NewDiscoverService(invokerManager, publishRegister, serviceRegister)
 
 	e := echo.New()
 	e.Use(echomiddleware.Logger())
@@ -314,6 +402,26 @@ func getInvokerRegisterMock(invokerId string, apisToReturn []publishapi.ServiceA
 	return &invokerRegisterrMock
 }
 
+func getPublishRegisterMock(publishedApis []publishapi.ServiceAPIDescription, visibility map[string]publishservice.ServiceVisibility) *publishmocks.PublishRegister {
+	publishRegisterMock := publishmocks.PublishRegister{}
+	publishRegisterMock.On("GetAllPublishedServices").Return(publishedApis)
+	publishRegisterMock.On("GetServiceVisibility", mock.Anything).Return(func(apiId string) *publishservice.ServiceVisibility {
+		if apiVisibility, ok := visibility[apiId]; ok {
+			return &apiVisibility
+		}
+		return nil
+	})
+	return &publishRegisterMock
+}
+
+func getServiceRegisterMock(providerDomains map[string]string) *providermocks.ServiceRegister {
+	serviceRegisterMock := providermocks.ServiceRegister{}
+	serviceRegisterMock.On("GetProviderDomain", mock.Anything).Return(func(functionId string) string {
+		return providerDomains[functionId]
+	})
+	return &serviceRegisterMock
+}
+
 func getAPI(apiName, aefId, apiCategory, apiVersion string, protocol *publishapi.Protocol, dataFormat *publishapi.DataFormat, commType publishapi.CommunicationType) publishapi.ServiceAPIDescription {
 	apiId := "apiId_" + apiName
 	description := "description"
@@ -21,8 +21,9 @@ import (
 	externalRef2 "oransc.org/nonrtric/capifcore/internal/common29122"
 	externalRef3 "oransc.org/nonrtric/capifcore/internal/common29571"
 	externalRef4 "oransc.org/nonrtric/capifcore/internal/loggingapi"
-	externalRef5 "oransc.org/nonrtric/capifcore/internal/publishserviceapi"
-	externalRef6 "oransc.org/nonrtric/capifcore/internal/routinginfoapi"
+	externalRef5 "oransc.org/nonrtric/capifcore/internal/providermanagementapi"
+	externalRef6 "oransc.org/nonrtric/capifcore/internal/publishserviceapi"
+	externalRef7 "oransc.org/nonrtric/capifcore/internal/routinginfoapi"
 )
 
 // ServerInterface represents all server handlers.
@@ -116,42 +117,83 @@ func RegisterHandlersWithBaseURL(router EchoRouter, si ServerInterface, baseURL
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/+xZ4Y7iOPJ/Fcv7/zAjZaFhpv+zzbcM0DPRoQYRek57sy1kkgp4J9hZ26GbayHdO9xT",
-	"3Gvco9yTnGyHEEICdHMrre5mtdptOa5fVbmqflU2zzjgy4QzYErizjOWwQKWxPzpBgFI2eVMCR6PeEyD",
-	"9YBK1X9S+iuJ42GEO1+f8f8JiHAHT/z2TbvdnnbdkXc7tcLTTHpqxafuyGusyTL+obnT2sxUNmv04Y3z",
-	"jBPBExCKgrGMJNQL9R9qnQDuYKkEZXO82TjbFT77FQKFNw8ODkEGgiaKcoY7eAyJAKn1IrUABE8KmKSc",
-	"oYgLRIwFKLAmoETbQEE2sIMF/JZSASHufM3UP2wcbHztr4CZI9nXNOJS0lkMaEXiFCQiAjq/sB+R3x9/",
-	"8bp9fRZT94vrDdyPg34HGRSJBMREQYgUN/aRFaExmdGYqjXiEZIgVjQA5I48iUikQJht+8sCUJLOYioX",
-	"EDbKKu/vTipN2WvVpuyI4lHPnVTqDBaEzQFRVgRElEVcLIk+TYOlMby7L8M/9cfT4d3HoTvu9XtVcFZ4",
-	"xb+BQJzNOBGh/WCidYh1e3suWBRt0SLBlwW8op8at+tOvOHd1L/vdvu+X3fOMjX5FqWxUREYX8vHfQz/",
-	"1vUG9+PaOEaExhCewnaNjdPu8G4yHg6mo+HA6/58JFomQ5KQKDA1Y7K0om7WB96eqfg1+XmBEYVMcO8n",
-	"n4dj7y/2cMd9vdqrM0JA8VSNAalacEH/mi8WckdqsczCk3bYsz+ZjongEY23wajI8MlwNBwMP/08/ez1",
-	"vLtP0+64X4erbQoEZKaLbYAzz7RWxRMe8/kaLWhI2bxYntttryOnKktffPb/YQv3eQw7GFi61NRfSd7Y",
-	"wTUMW/5iAosdXMll5fWcl0oohwRTvyFjCA19pMzrP++5crJWSnuyPM5Wa7Kx5usW8cEpN/li1+2BIjQ+",
-	"7L2FLk9sUSDQ+1FoBExHL40UQdBVQo8dZubo5GPNS0YVPRptHDsfyEOrvBCYohE1raSceNjBVMFSVow1",
-	"Dl5S5tmPrfw8iBBkvVVnKeGI1h1PFijkIp0TnvDPNIRTZzXJyvKzqUotvGtIAz6vsnjXsGI+N8HKzawc",
-	"Ngd8PqdsbpM/Fz4+bXpFI076mwXKHXm9nakVphe+VsQYEbkjPzRbZ/G4Pe3hyEpNfQt23DW/ytgTLh6O",
-	"zsU6u6WxAvGCOouMQEWZQXRmaejjgqeES03lUcoCs/OiIvmvrsmqAJrY3XHtn0314xFkiLKQrmiYkjiL",
-	"pkFArABxGFPYZ+JjXHDA3BvHisvzJU05prPcCXsTPBnVggQSIHkqAtDzxOOCBguzpegloruh419/+3t2",
-	"GrkczfHNdHB4DS1eF0vG5h4/1EXMLwi8MmJ+lbs1obPFXZGlGcgMwr2qlhVsdSJmGX+cqhqjZQy/FYC7",
-	"fLnkrEcUqee7MSRcKMrm3m7i28+s4369wqGTrhSTqQdSUZYXYIHgW5rgz/DwXlCcZRVINQF5oqp9UGZW",
-	"FinoNpM7LPRqhmJy3uZMwAXkHKu3SGAhIkjpbXtlQSQKIaLMXCv1v0FMUgnoQ+P/GyjTGpFYgr5E8CVV",
-	"uoC4WoB4pBIaxWqZcR4DYbacEx1BCG+BqFRAuQ9ef2iddUz+Ac7GwY8wkzz4Zg6sy1lE568Mwp8Pgcql",
-	"nqdTXfirar40IB0reHP54anOdSTSGGT5kkOK7aui/dY8nDk4Qx1rUL3h+DgytrunuuCOzyLjHe7pBnb4",
-	"yFYy7PD4tBg8KRCMxD0eVFT7u0+jEZr4qH3TaLfb6EvrQ+O6cYVsxE3nvRVkCY9cfDMPGmb/HRdqMeMp",
-	"C82lEDs4FTHu4IVSiew0m4+Pj4138yRpcDFvRipp+gkEsklEsKAraLZvphIEBdm0Wpt27I34oXnaAK3W",
-	"Muxem1oSRuawBKYaCP3C/vkP1L5qtx1r4VDMCcseG0iMRkQoBkKiN+7Y++ggd+L5Dup2fddB/YnvOWji",
-	"98z/Jq7+T/etwXTjGAk6X5g7tk6d7YVXURXrc7bxttfwqR2JViCktb3VaDeutGs8AUYSqk+7cdW40nlH",
-	"1MIEo/ksc/bxwk2z6KHZkHB73wpIHM9I8M0sHqHP54y/GjMern9o1uzcFKEziY88XBtNnKnsyZYkSZwJ",
-	"N3+VVoFN3VO94HC22uynsCZfsyATzqStqvbV+8MMuOOoay1CbwpPgkXH3upDfnf14YXMletualkD8dMF",
-	"ED9piPdXV6+G0LIGonUBRMtCvLsA4p2FeH8BxHsD0brAkZZ1pHWBIy3rSOv6AohrA9G+eT1E+0ZDXF+Q",
-	"F9c2L64vCOq1DWoIEUlj9WqYrfzG/FP+8agrgCjQd10Gj+eM3roD6/5LthcVPOJS+QVC9PfoUPOmbkZ2",
-	"Hv96+lqzg9IzrN6jiVcPIGRpGnxBFS6zk1OguvIV5sH5/Vhz735zFmu2fm8DquIcojf+jo53r+PRXpd+",
-	"ix28ABJmN6gBr5vJNccTyuwQdz/2tiFk8BivLTyE+V3NQSQIuDAP2dvfLpRIAz3WomeS0DHnatMMSEKj",
-	"H+3Y2Vy1jjbb/GN2E92YRn9+Smy+s/939v+fYX8Hv6yYbMHHoKDqVVivn/tec9g0rHxt2/DLD0x/lCbi",
-	"HNe9fxzZT3173HzMnJ27L+xqp+bxyQJqwrRvHVoSFSwMQZceGL0QUYlsNpj71Pfp/Y/I399pr2Lotb91",
-	"banDvnrUzhvYwSsiKJnF+fuS3mdrKjMqfzOBJ7JMYmgEfInLE1cmWHpfzB8XrzVd7D/kaGsfNv8OAAD/",
-	"//oDEyW/JgAA",
+	"H4sIAAAAAAAC/+xZfW7byBW/yoDbPxKAK1lK3Gz0nyLJCVvXUknZxTZrCCPyUZoNOcOdGdpRDQG9Q0/R",
+
+	"a/QoPUkxMxRF8Uuy1QUWbYIgscl5v/f9MY9Pls/ihFGgUliDJ0v4a4ix/nHo+yDEiFHJWTRjEfE310TI",
+
+	"yVep3uIomobW4POT9TsOoTWw5l7/fb/fX4yGM+dqYYgXGfXCkC+GM6ezwXH0XXfPtZux7Dbws7b2k5Vw",
+
+	"lgCXBLRkOCFOoH6QmwSsgSUkJ3Rlbbf27glb/gy+tLb3thWA8DlJJGHUGlguJByE4ovkGhB8lUAFYRSF",
+
+	"jCOsJUC+EQElSgYComPZFodfUsIhsAafM/b3W9vSuk4egGqTHHKaMSHIMgL0gKMUBMIcBj/R75E3ce+c",
+
+	"0UTZYjG8GzrXww/XkwHSKAJxiLCEAEmm5cMPmER4SSIiN4iFSAB/ID6g4cwRCIcSuD52+JgDStJlRMQa",
+
+	"gk6Z5e3NUaYpfSnblLYwno2H81qe/hrTFSBCi4CI0JDxGCtraiyF4dzcTf84cRfTmw/ToTuejOvgDPED",
+
+	"+wIcMbpkmAfmhfZWFevq6lSwMNyhhZzFBbyingp3NJw705uFdzsaTTyvyc4i1fEWppFm4Wtdy+Zuw78a",
+
+	"Ote3bqMfQ0wiCI5hD7WMi9H0Zu5Orxez6bUz+rHFWzpCkgBL0Dmjfq3Lm01F2xMZvyQ+zxCiEAnD2/mn",
+
+	"qev81RjXnain4yYhOBStqgVI5Zpx8rf8YSF2hCLLJDwqh7H90XBMOAtJtHNGTYTPp7Pp9fTjj4tPzti5",
+
+	"+bgYuZMmXCWTzyETne8cnGmmuEqWsIitNmhNAkJXxfTcHXtZcaqT9Nm2/y9LmNKqjDN3eueMJ+7CnXx0",
+
+	"vPnEbZNvRYTkOWtMtYAJZw8kAI4CFmNCqw7LWbREQCH9mpGNAMAhaGEynhzXJIATdSkVRN3cTH67kz/f",
+
+	"TrwGbTBSjRWELEDv4rvQkfXJgpfKPIYzpVWzGjhR8uJIc8lZtjBAy00eVztVq4r9YTKat0WBmkF2ZjuD",
+
+	"q2VbQNNYjR61w4NlWw0dvvxGh5VlW7W9tPw874sllGqDaz6QdSgF3dJmml8fqHK0VpfOZFmUPW2ohg1v",
+
+	"DxFrcr/85pBZbZJl78p5cfh4F8rlwybWrHu7PPQWp9AxSEyi6ixamHqxyVIE6jwKNIGecEsjtu+PJFdj",
+
+	"uJ7BB/mY/5zRXV0VtraZl0VVKicAKklI9GhVLsSWbREJsagZ820rJtQxL3u5PTDneLNjZ0pIC9f93FAo",
+
+	"OWfxnGUJO2axQ0NmDZpuRio8srOLP2GKVxADlUfuRjNnRzOhnEVx7myRsZ+zhH0iARxz1Tzrkp90k1TE",
+
+	"+/nwmq3qDJa/RxFbiU7RSrUaXrPVitCVqQU5cbuCTlGIo+bO4mQ4c8Z7UWtEL7ytCTGExX4W2dfeq+Ma",
+
+	"zgzVwjNg7ap5dcIeUbF6ky2m+RWJJPBnpHmoCWqyHMITM1OZC74mTKjJKkypbmzn5ej/dEmoc6D23Q1T",
+
+	"+plQb/cgRYQG5IEEKY4yb2oERAsQVZ/CYSNoqwWVxrG1Dbk4nVLRiHSZK+EEVa1qvFqgQBwES7kPah56",
+
+	"XBN/rY8UtURkP139++//yKyR05EcXw9L1a1QcXtTEjbX+L7JY16B4IUe8+rUbXCdSe6aKM1AlhAcZLWo",
+
+	"qVZHfGZYHM0azcWFXwrAIxbHjI6xxM31zoWEcUnoytlfwA4jq12vFyh0VJViMI1BSELzBCwU+J4q8Cdo",
+
+	"eMuJlUUVCDkHcSSrPZD6UsBTUG0mV5irpxmKjnkTMz7jkNdYdUQADRBGUh07SAssUAAhoXrLo/76EU4F",
+
+	"oHed33dQxjXEkQB1p2cxkSqBmFwDfyQCOsVsWTIWAaYmnRPlQQiuAMuUQ7kPXr7rnWQmr4Kzta1HWArm",
+
+	"f9EGGzEaktULnfCXKlA51fNwanJ/Xc6XBqS2hNe3PJaqWEc8jUCUdw6Hl9Zq+23YY9tWhuoqUHWgfRxx",
+
+	"zemFSrj2WcTd4x5vYNWdd0mwqvkUmdqpc4qjMfNrsv3Nx9kMzT3Uf9/p9/vorveuc9m5QMbjuvNecRzD",
+
+	"I+Nf9FVZn79hXK6XLKWBOqE8mvLIGlhrKRMx6HYfHx87b1ZJ0mF81Q1l0vUS8EUXc39NHqDbf78QwAmI",
+
+	"ruHaNWNvyKriKQEUW1NhD9pUnM/qHYR+ov/6J+pf9Pu2kXDKV5hmuz8coRnmkgIX6NXQdT7YaDh3PBuN",
+
+	"Rt7QRpO559ho7o31f/Oh+mf0WmMOowhxslrrZYIKHQiyJCUyUnY2/jb7hoUZiR6ACyN7r9PvXCjVWAIU",
+
+	"J0RZu3PRuVBxh+VaO6P7JPLq4wTbblFDfSBh5rrn4yhaYv+LfthSPp+y+tVZsmDzXbfh5LYInVF8YMFG",
+
+	"c2JUZl9QcJJEGXH3Z2EYmNA91guqs9X2MIRV8dUPRMKoMFnVv3hbjYAbhkZGIvSqsKEvKvZaGfnNxbtn",
+
+	"Vq6cd1fRaogfzoD4QUG8vbh4MYSi1RC9MyB6BuLNGRBvDMTbMyDeaojeGYr0jCK9MxTpGUV6l2dAXGqI",
+
+	"/vuXQ/TfK4jLM+Li0sTF5RlOvTRODSDEaSRfDLOj3+o/5W+5Iw5YgrrrUng8ZfRWHVj1X7y7qFgzJqRX",
+
+	"KIjeQTlUdVM1IzOPfz5+rdlDqRlWnVGFVw0gONYNvsDKKlcnu1DqyleYe/vXq5pFnU+rmr1fW4A6Pwfo",
+
+	"lbcvx/uPVeFBl35t2dYacJDdoK5Z00yuajwm1Axxt66zcyGFx2hj4CHI72q2Wtkzrr8r7T4lSp76aqxF",
+
+	"TzghLmNy2/VxQsLvzdjZfei1NtvuU/FXJ9jqRn96SGy/Vf9v1f//pvrb1vOSySR8BBLqtsLq+an7mmrT",
+
+	"MPSNbcMrL5h+K03Ebud9aI7sm+ZBbW4TZ6/uM7vasXl8voYGNx1Kh2Is/bUu0KUFoxOo/aGJBn2f+ja9",
+
+	"/xbr97eyVzP0mm9du9Jhth6N84baBmBO8DLK90vqnMmpTKh8ZwJfcZxE0PFZbJUnroywtF/Ml4uXqlwc",
+
+	"LnKUtPfb/wwAH0FAT04qAAA=",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
@@ -205,6 +247,12 @@ func PathToRawSpec(pathToFile string) map[string]func() ([]byte, error) {
 		}
 		res[rawPath] = rawFunc
 	}
+	for rawPath, rawFunc := range externalRef5.PathToRawSpec(path.Join(pathPrefix, "TS29222_CAPIF_API_Provider_Management_API.yaml")) {
+		if _, ok := res[rawPath]; ok {
+			// it is not possible to compare functions in golang, so always overwrite the old value
+		}
+		res[rawPath] = rawFunc
+	}
 	for rawPath, rawFunc := range externalRef0.PathToRawSpec(path.Join(pathPrefix, "TS29222_CAPIF_Access_Control_Policy_API.yaml")) {
 		if _, ok := res[rawPath]; ok {
 			// it is not possible to compare functions in golang, so always overwrite the old value
@@ -217,13 +265,13 @@ func PathToRawSpec(pathToFile string) map[string]func() ([]byte, error) {
 		}
 		res[rawPath] = rawFunc
 	}
-	for rawPath, rawFunc := range externalRef5.PathToRawSpec(path.Join(pathPrefix, "TS29222_CAPIF_Publish_Service_API.yaml")) {
+	for rawPath, rawFunc := range externalRef6.PathToRawSpec(path.Join(pathPrefix, "TS29222_CAPIF_Publish_Service_API.yaml")) {
 		if _, ok := res[rawPath]; ok {
 			// it is not possible to compare functions in golang, so always overwrite the old value
 		}
 		res[rawPath] = rawFunc
 	}
-	for rawPath, rawFunc := range externalRef6.PathToRawSpec(path.Join(pathPrefix, "TS29222_CAPIF_Routing_Info_API.yaml")) {
+	for rawPath, rawFunc := range externalRef7.PathToRawSpec(path.Join(pathPrefix, "TS29222_CAPIF_Routing_Info_API.yaml")) {
 		if _, ok := res[rawPath]; ok {
 			// it is not possible to compare functions in golang, so always overwrite the old value
 		}
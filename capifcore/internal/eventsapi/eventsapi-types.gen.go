@@ -9,8 +9,9 @@ import (
 	externalRef2 "oransc.org/nonrtric/capifcore/internal/common29122"
 	externalRef3 "oransc.org/nonrtric/capifcore/internal/common29571"
 	externalRef4 "oransc.org/nonrtric/capifcore/internal/loggingapi"
-	externalRef5 "oransc.org/nonrtric/capifcore/internal/publishserviceapi"
-	externalRef6 "oransc.org/nonrtric/capifcore/internal/routinginfoapi"
+	externalRef5 "oransc.org/nonrtric/capifcore/internal/providermanagementapi"
+	externalRef6 "oransc.org/nonrtric/capifcore/internal/publishserviceapi"
+	externalRef7 "oransc.org/nonrtric/capifcore/internal/routinginfoapi"
 )
 
 // Defines values for CAPIFEvent.
@@ -19,6 +20,12 @@ const (
 
 	CAPIFEventACCESSCONTROLPOLICYUPDATE CAPIFEvent = "ACCESS_CONTROL_POLICY_UPDATE"
 
+	CAPIFEventAPIACCESSAPPROVED CAPIFEvent = "API_ACCESS_APPROVED"
+
+	CAPIFEventAPIACCESSREJECTED CAPIFEvent = "API_ACCESS_REJECTED"
+
+	CAPIFEventAPIACCESSREQUESTED CAPIFEvent = "API_ACCESS_REQUESTED"
+
 	CAPIFEventAPIINVOKERAUTHORIZATIONREVOKED CAPIFEvent = "API_INVOKER_AUTHORIZATION_REVOKED"
 
 	CAPIFEventAPIINVOKEROFFBOARDED CAPIFEvent = "API_INVOKER_OFFBOARDED"
@@ -27,6 +34,12 @@ const (
 
 	CAPIFEventAPIINVOKERUPDATED CAPIFEvent = "API_INVOKER_UPDATED"
 
+	CAPIFEventAPIPROVIDERDEREGISTERED CAPIFEvent = "API_PROVIDER_DEREGISTERED"
+
+	CAPIFEventAPIPROVIDERREGISTERED CAPIFEvent = "API_PROVIDER_REGISTERED"
+
+	CAPIFEventAPIPROVIDERUPDATED CAPIFEvent = "API_PROVIDER_UPDATED"
+
 	CAPIFEventAPITOPOLOGYHIDINGCREATED CAPIFEvent = "API_TOPOLOGY_HIDING_CREATED"
 
 	CAPIFEventAPITOPOLOGYHIDINGREVOKED CAPIFEvent = "API_TOPOLOGY_HIDING_REVOKED"
@@ -64,6 +77,12 @@ type AccessControlPolicyListExt struct {
 // - API_INVOKER_UPDATED: Events related to API invoker profile updated to CAPIF.
 // - API_TOPOLOGY_HIDING_CREATED: Events related to the creation or update of the API topology hiding information of the service APIs after the service APIs are published.
 // - API_TOPOLOGY_HIDING_REVOKED: Events related to the revocation of the API topology hiding information of the service APIs after the service APIs are unpublished.
+// - API_PROVIDER_REGISTERED: Events related to the registration of an API provider domain to CAPIF.
+// - API_PROVIDER_UPDATED: Events related to the update of an API provider domain registered to CAPIF.
+// - API_PROVIDER_DEREGISTERED: Events related to the deregistration of an API provider domain from CAPIF.
+// - API_ACCESS_REQUESTED: Events related to a request of an API invoker for access to a service API.
+// - API_ACCESS_APPROVED: Events related to the approval of a request for access to a service API by the API provider.
+// - API_ACCESS_REJECTED: Events related to the rejection of a request for access to a service API by the API provider.
 type CAPIFEvent string
 
 // Represents a CAPIF event details.
@@ -77,6 +96,9 @@ type CAPIFEventDetail struct {
 	// Identity of the API invoker
 	ApiInvokerIds *[]string `json:"apiInvokerIds,omitempty"`
 
+	// Represents an API provider domain's enrolment details.
+	ApiProviderDomInfo *externalRef5.APIProviderEnrolmentDetails `json:"apiProviderDomInfo,omitempty"`
+
 	// Represents the routing rules information of a service API.
 	ApiTopoHide *TopologyHiding `json:"apiTopoHide,omitempty"`
 
@@ -84,7 +106,7 @@ type CAPIFEventDetail struct {
 	InvocationLogs *[]externalRef4.InvocationLog `json:"invocationLogs,omitempty"`
 
 	// Description of the service API as published by the APF.
-	ServiceAPIDescriptions *[]externalRef5.ServiceAPIDescription `json:"serviceAPIDescriptions,omitempty"`
+	ServiceAPIDescriptions *[]externalRef6.ServiceAPIDescription `json:"serviceAPIDescriptions,omitempty"`
 }
 
 // Represents a CAPIF event filter.
@@ -151,7 +173,7 @@ type EventSubscription struct {
 // Represents the routing rules information of a service API.
 type TopologyHiding struct {
 	ApiId        string                     `json:"apiId"`
-	RoutingRules []externalRef6.RoutingRule `json:"routingRules"`
+	RoutingRules []externalRef7.RoutingRule `json:"routingRules"`
 }
 
 // PostSubscriberIdSubscriptionsJSONBody defines parameters for PostSubscriberIdSubscriptions.
@@ -0,0 +1,56 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package eventsapi
+
+import (
+	"oransc.org/nonrtric/capifcore/internal/common"
+	"oransc.org/nonrtric/capifcore/internal/common29122"
+)
+
+// Represents the modifications of an event subscription. Attributes that are not present are left unchanged.
+type EventSubscriptionPatch struct {
+	EventFilters            *[]CAPIFEventFilter             `json:"eventFilters,omitempty"`
+	EventReq                *common.ReportingInformation    `json:"eventReq,omitempty"`
+	Events                  *[]CAPIFEvent                   `json:"events,omitempty"`
+	NotificationDestination *common29122.Uri                `json:"notificationDestination,omitempty"`
+	WebsockNotifConfig      *common29122.WebsockNotifConfig `json:"websockNotifConfig,omitempty"`
+}
+
+// Returns a copy of the subscription where the attributes present in the patch replace the current ones.
+func (es EventSubscription) ApplyPatch(patch EventSubscriptionPatch) EventSubscription {
+	patchedSubscription := es
+	if patch.EventFilters != nil {
+		patchedSubscription.EventFilters = patch.EventFilters
+	}
+	if patch.EventReq != nil {
+		patchedSubscription.EventReq = patch.EventReq
+	}
+	if patch.Events != nil {
+		patchedSubscription.Events = *patch.Events
+	}
+	if patch.NotificationDestination != nil {
+		patchedSubscription.NotificationDestination = *patch.NotificationDestination
+	}
+	if patch.WebsockNotifConfig != nil {
+		patchedSubscription.WebsockNotifConfig = patch.WebsockNotifConfig
+	}
+	return patchedSubscription
+}
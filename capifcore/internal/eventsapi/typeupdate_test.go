@@ -0,0 +1,61 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package eventsapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"oransc.org/nonrtric/capifcore/internal/common29122"
+)
+
+func TestApplyPatch(t *testing.T) {
+	apiIds := []string{"apiId"}
+	subscriptionUnderTest := EventSubscription{
+		EventFilters: &[]CAPIFEventFilter{
+			{
+				ApiIds: &apiIds,
+			},
+		},
+		Events:                  []CAPIFEvent{CAPIFEventSERVICEAPIAVAILABLE},
+		NotificationDestination: "http://golang.cafe/",
+	}
+
+	events := []CAPIFEvent{CAPIFEventAPIINVOKERONBOARDED}
+	patchedSubscription := subscriptionUnderTest.ApplyPatch(EventSubscriptionPatch{
+		Events: &events,
+	})
+
+	assert.Equal(t, events, patchedSubscription.Events)
+	assert.Equal(t, subscriptionUnderTest.EventFilters, patchedSubscription.EventFilters)
+	assert.Equal(t, subscriptionUnderTest.NotificationDestination, patchedSubscription.NotificationDestination)
+	assert.Equal(t, []CAPIFEvent{CAPIFEventSERVICEAPIAVAILABLE}, subscriptionUnderTest.Events)
+
+	destination := common29122.Uri("http://other.host/")
+	patchedSubscription = subscriptionUnderTest.ApplyPatch(EventSubscriptionPatch{
+		EventFilters:            &[]CAPIFEventFilter{},
+		NotificationDestination: &destination,
+	})
+
+	assert.Empty(t, *patchedSubscription.EventFilters)
+	assert.Equal(t, destination, patchedSubscription.NotificationDestination)
+	assert.Equal(t, subscriptionUnderTest.Events, patchedSubscription.Events)
+}
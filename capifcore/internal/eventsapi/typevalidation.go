@@ -25,6 +25,10 @@ import (
 	"fmt"
 	"net/url"
 	"strings"
+	"time"
+
+	"oransc.org/nonrtric/capifcore/internal/common"
+	"oransc.org/nonrtric/capifcore/internal/common29571"
 )
 
 func (es EventSubscription) Validate() error {
@@ -45,6 +49,43 @@ func (es EventSubscription) Validate() error {
 		return fmt.Errorf("APIInvokerEnrolmentDetails has invalid notificationDestination, err=%s", err)
 	}
 
+	if es.EventReq != nil {
+		if err := validateEventReq(*es.EventReq); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateEventReq(eventReq common.ReportingInformation) error {
+	if eventReq.NotifMethod != nil {
+		switch *eventReq.NotifMethod {
+		case common.NotificationMethodPERIODIC:
+			if eventReq.RepPeriod == nil || *eventReq.RepPeriod <= 0 {
+				return errors.New("EventSubscription eventReq missing required repPeriod for periodic reporting")
+			}
+		case common.NotificationMethodONETIME:
+		case common.NotificationMethodONEVENTDETECTION:
+		default:
+			return errors.New("EventSubscription eventReq has invalid notifMethod")
+		}
+	}
+
+	if eventReq.NotifFlag != nil {
+		switch *eventReq.NotifFlag {
+		case common29571.NotificationFlagACTIVATE:
+		case common29571.NotificationFlagDEACTIVATE:
+		case common29571.NotificationFlagRETRIEVAL:
+		default:
+			return errors.New("EventSubscription eventReq has invalid notifFlag")
+		}
+	}
+
+	if eventReq.MonDur != nil && !time.Time(*eventReq.MonDur).After(time.Now()) {
+		return errors.New("EventSubscription eventReq has monDur that has already passed")
+	}
+
 	return nil
 }
 
@@ -52,10 +93,16 @@ func validateEvent(event CAPIFEvent) error {
 	switch event {
 	case CAPIFEventACCESSCONTROLPOLICYUNAVAILABLE:
 	case CAPIFEventACCESSCONTROLPOLICYUPDATE:
+	case CAPIFEventAPIACCESSAPPROVED:
+	case CAPIFEventAPIACCESSREJECTED:
+	case CAPIFEventAPIACCESSREQUESTED:
 	case CAPIFEventAPIINVOKERAUTHORIZATIONREVOKED:
 	case CAPIFEventAPIINVOKEROFFBOARDED:
 	case CAPIFEventAPIINVOKERONBOARDED:
 	case CAPIFEventAPIINVOKERUPDATED:
+	case CAPIFEventAPIPROVIDERDEREGISTERED:
+	case CAPIFEventAPIPROVIDERREGISTERED:
+	case CAPIFEventAPIPROVIDERUPDATED:
 	case CAPIFEventAPITOPOLOGYHIDINGCREATED:
 	case CAPIFEventAPITOPOLOGYHIDINGREVOKED:
 	case CAPIFEventSERVICEAPIAVAILABLE:
@@ -22,8 +22,11 @@ package eventsapi
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"oransc.org/nonrtric/capifcore/internal/common"
+	"oransc.org/nonrtric/capifcore/internal/common29571"
 )
 
 func TestValidateEventSubscription(t *testing.T) {
@@ -59,3 +62,53 @@ func TestValidateEventSubscription(t *testing.T) {
 	err = subUnderTest.Validate()
 	assert.Nil(t, err)
 }
+
+func TestValidateEventReq(t *testing.T) {
+	subUnderTest := EventSubscription{
+		Events:                  []CAPIFEvent{CAPIFEventAPIINVOKERONBOARDED},
+		NotificationDestination: "http://golang.cafe/",
+		EventReq:                &common.ReportingInformation{},
+	}
+	assert.Nil(t, subUnderTest.Validate())
+
+	periodic := common.NotificationMethodPERIODIC
+	subUnderTest.EventReq.NotifMethod = &periodic
+	err := subUnderTest.Validate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "missing")
+		assert.Contains(t, err.Error(), "repPeriod")
+	}
+
+	repPeriod := common29571.DurationSec(10)
+	subUnderTest.EventReq.RepPeriod = &repPeriod
+	assert.Nil(t, subUnderTest.Validate())
+
+	var invalidMethod common.NotificationMethod = "invalid"
+	subUnderTest.EventReq.NotifMethod = &invalidMethod
+	err = subUnderTest.Validate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "invalid")
+		assert.Contains(t, err.Error(), "notifMethod")
+	}
+	subUnderTest.EventReq.NotifMethod = &periodic
+
+	var invalidFlag common29571.NotificationFlag = "invalid"
+	subUnderTest.EventReq.NotifFlag = &invalidFlag
+	err = subUnderTest.Validate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "invalid")
+		assert.Contains(t, err.Error(), "notifFlag")
+	}
+	subUnderTest.EventReq.NotifFlag = nil
+
+	passed := common29571.DateTime(time.Now().Add(-1 * time.Minute))
+	subUnderTest.EventReq.MonDur = &passed
+	err = subUnderTest.Validate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "monDur")
+	}
+
+	future := common29571.DateTime(time.Now().Add(1 * time.Minute))
+	subUnderTest.EventReq.MonDur = &future
+	assert.Nil(t, subUnderTest.Validate())
+}
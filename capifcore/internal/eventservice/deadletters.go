@@ -0,0 +1,178 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package eventservice
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+	"oransc.org/nonrtric/capifcore/internal/eventsapi"
+	"oransc.org/nonrtric/capifcore/problemdetails"
+)
+
+const deadLettersBucket = "deadLetters"
+
+// A report that could not be delivered to a subscription.
+type DeadLetter struct {
+	Id             string                        `json:"id"`
+	SubscriptionId string                        `json:"subscriptionId"`
+	Events         []eventsapi.EventNotification `json:"events"`
+	Periodic       bool                          `json:"periodic"`
+	Attempts       int                           `json:"attempts"`
+	LastError      string                        `json:"lastError"`
+	Time           time.Time                     `json:"time"`
+}
+
+func (es *EventService) addDeadLetter(r report, subscriptionId string, attempts int, err error) {
+	deadLetter := DeadLetter{
+		Id:             uuid.NewString(),
+		SubscriptionId: subscriptionId,
+		Events:         r.events,
+		Periodic:       r.periodic,
+		Attempts:       attempts,
+		LastError:      fmt.Sprint(err),
+		Time:           time.Now(),
+	}
+	log.Errorf("Unable to deliver report to subscription %s, it is kept as dead letter %s", subscriptionId, deadLetter.Id)
+
+	es.deliveryLock.Lock()
+	es.deadLetters[deadLetter.Id] = deadLetter
+	if err := es.store.Put(deadLettersBucket, deadLetter.Id, deadLetter); err != nil {
+		log.Errorf("Unable to store dead letter %s due to %s", deadLetter.Id, err)
+	}
+	es.deliveryLock.Unlock()
+
+	// The subscription may have been removed while the report was delivered, after its dead letters were dropped
+	if es.getSubscription(subscriptionId) == nil {
+		es.deleteDeadLetter(deadLetter.Id)
+	}
+}
+
+// Retrieves the reports that could not be delivered, oldest first. The "subscription-id" query parameter limits the
+// result to the reports of one subscription. This operation is not part of the 3GPP API.
+func (es *EventService) GetDeadLetters(ctx echo.Context) error {
+	subscriptionId := ctx.QueryParam("subscription-id")
+
+	es.deliveryLock.Lock()
+	deadLetters := []DeadLetter{}
+	for _, deadLetter := range es.deadLetters {
+		if subscriptionId == "" || deadLetter.SubscriptionId == subscriptionId {
+			deadLetters = append(deadLetters, deadLetter)
+		}
+	}
+	es.deliveryLock.Unlock()
+	sort.Slice(deadLetters, func(i, j int) bool {
+		return deadLetters[i].Time.Before(deadLetters[j].Time)
+	})
+
+	err := ctx.JSON(http.StatusOK, deadLetters)
+	if err != nil {
+		// Something really bad happened, tell Echo that our handler failed
+		return err
+	}
+
+	return nil
+}
+
+// Retrieves a report that could not be delivered. This operation is not part of the 3GPP API.
+func (es *EventService) GetDeadLetter(ctx echo.Context) error {
+	deadLetterId := ctx.Param("deadLetterId")
+
+	deadLetter, ok := es.getDeadLetter(deadLetterId)
+	if !ok {
+		return problemdetails.Send(ctx, http.StatusNotFound, problemdetails.CauseContextNotFound, fmt.Sprintf("Unable to get dead letter due to no dead letter with id %s", deadLetterId))
+	}
+
+	err := ctx.JSON(http.StatusOK, deadLetter)
+	if err != nil {
+		// Something really bad happened, tell Echo that our handler failed
+		return err
+	}
+
+	return nil
+}
+
+// Queues a report that could not be delivered for a new delivery to its subscription, and removes it from the
+// dead letters. This operation is not part of the 3GPP API.
+func (es *EventService) ReplayDeadLetter(ctx echo.Context) error {
+	errMsg := "Unable to replay dead letter due to %s"
+	deadLetterId := ctx.Param("deadLetterId")
+
+	deadLetter, ok := es.getDeadLetter(deadLetterId)
+	if !ok {
+		return problemdetails.Send(ctx, http.StatusNotFound, problemdetails.CauseContextNotFound, fmt.Sprintf(errMsg, fmt.Sprintf("no dead letter with id %s", deadLetterId)))
+	}
+	if es.getSubscription(deadLetter.SubscriptionId) == nil {
+		return problemdetails.Send(ctx, http.StatusConflict, problemdetails.CauseSubscriptionNotFound, fmt.Sprintf(errMsg, fmt.Sprintf("subscription %s has been removed", deadLetter.SubscriptionId)))
+	}
+
+	es.deleteDeadLetter(deadLetterId)
+	es.queueReport(report{events: deadLetter.Events, periodic: deadLetter.Periodic}, deadLetter.SubscriptionId)
+
+	return ctx.NoContent(http.StatusAccepted)
+}
+
+// Discards a report that could not be delivered. This operation is not part of the 3GPP API.
+func (es *EventService) DeleteDeadLetter(ctx echo.Context) error {
+	es.deleteDeadLetter(ctx.Param("deadLetterId"))
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+func (es *EventService) getDeadLetter(deadLetterId string) (DeadLetter, bool) {
+	es.deliveryLock.Lock()
+	defer es.deliveryLock.Unlock()
+	deadLetter, ok := es.deadLetters[deadLetterId]
+	return deadLetter, ok
+}
+
+func (es *EventService) deleteDeadLetter(deadLetterId string) {
+	es.deliveryLock.Lock()
+	defer es.deliveryLock.Unlock()
+	es.removeDeadLetter(deadLetterId)
+}
+
+// Drops the dead letters of a removed subscription.
+func (es *EventService) deleteDeadLetters(subscriptionId string) {
+	es.deliveryLock.Lock()
+	defer es.deliveryLock.Unlock()
+	for deadLetterId, deadLetter := range es.deadLetters {
+		if deadLetter.SubscriptionId == subscriptionId {
+			es.removeDeadLetter(deadLetterId)
+		}
+	}
+}
+
+// Must be called with the delivery lock held.
+func (es *EventService) removeDeadLetter(deadLetterId string) {
+	if _, ok := es.deadLetters[deadLetterId]; !ok {
+		return
+	}
+	delete(es.deadLetters, deadLetterId)
+	if err := es.store.Delete(deadLettersBucket, deadLetterId); err != nil {
+		log.Errorf("Unable to remove stored dead letter %s due to %s", deadLetterId, err)
+	}
+}
@@ -0,0 +1,194 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package eventservice
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/deepmap/oapi-codegen/pkg/testutil"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"oransc.org/nonrtric/capifcore/internal/common29122"
+	"oransc.org/nonrtric/capifcore/internal/eventsapi"
+)
+
+func TestUndeliverableEventIsDeadLetteredAndReplayed(t *testing.T) {
+	notificationUrl := "url"
+	subId := "sub1"
+	available := false
+	lock := sync.Mutex{}
+	wg := sync.WaitGroup{}
+	clientMock := NewTestClient(func(req *http.Request) *http.Response {
+		lock.Lock()
+		defer lock.Unlock()
+		if !available {
+			return &http.Response{
+				StatusCode: http.StatusServiceUnavailable,
+				Body:       io.NopCloser(bytes.NewBufferString(`Unavailable`)),
+				Header:     make(http.Header), // Must be set to non-nil value or it panics
+			}
+		}
+		wg.Done()
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(bytes.NewBufferString(`OK`)),
+			Header:     make(http.Header), // Must be set to non-nil value or it panics
+		}
+	})
+	serviceUnderTest, _ := getEcho(clientMock)
+	serviceUnderTest.retryPolicy = retryPolicy{
+		maxAttempts:    2,
+		initialBackoff: 1 * time.Millisecond,
+		maxBackoff:     1 * time.Millisecond,
+	}
+	requestHandler := getDeadLettersEcho(serviceUnderTest)
+	serviceUnderTest.addSubscription(subId, eventsapi.EventSubscription{
+		Events: []eventsapi.CAPIFEvent{
+			eventsapi.CAPIFEventSERVICEAPIAVAILABLE,
+		},
+		NotificationDestination: common29122.Uri(notificationUrl),
+	})
+	event := eventsapi.EventNotification{Events: eventsapi.CAPIFEventSERVICEAPIAVAILABLE}
+
+	serviceUnderTest.deliverReport(report{events: []eventsapi.EventNotification{event}}, subId)
+
+	// The undelivered event can be inspected
+	result := testutil.NewRequest().Get("/capif-events/v1/dead-letters?subscription-id="+subId).Go(t, requestHandler)
+	assert.Equal(t, http.StatusOK, result.Code())
+	var deadLetters []DeadLetter
+	err := result.UnmarshalBodyToObject(&deadLetters)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Len(t, deadLetters, 1)
+	deadLetter := deadLetters[0]
+	assert.Equal(t, subId, deadLetter.SubscriptionId)
+	assert.Equal(t, []eventsapi.EventNotification{event}, deadLetter.Events)
+	assert.False(t, deadLetter.Periodic)
+	assert.Equal(t, 2, deadLetter.Attempts)
+	assert.Contains(t, deadLetter.LastError, "503")
+
+	result = testutil.NewRequest().Get("/capif-events/v1/dead-letters?subscription-id=other").Go(t, requestHandler)
+	err = result.UnmarshalBodyToObject(&deadLetters)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Empty(t, deadLetters)
+
+	result = testutil.NewRequest().Get("/capif-events/v1/dead-letters/"+deadLetter.Id).Go(t, requestHandler)
+	assert.Equal(t, http.StatusOK, result.Code())
+	var resultDeadLetter DeadLetter
+	err = result.UnmarshalBodyToObject(&resultDeadLetter)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, deadLetter.Id, resultDeadLetter.Id)
+
+	// Dead letters are kept in the store
+	restartedService := NewEventService(nil, serviceUnderTest.notifier, serviceUnderTest.store)
+	assert.Contains(t, restartedService.deadLetters, deadLetter.Id)
+
+	// The event is delivered when replayed
+	lock.Lock()
+	available = true
+	lock.Unlock()
+	wg.Add(1)
+	result = testutil.NewRequest().Post("/capif-events/v1/dead-letters/"+deadLetter.Id+"/replay").Go(t, requestHandler)
+	assert.Equal(t, http.StatusAccepted, result.Code())
+	if waitTimeout(&wg, 1*time.Second) {
+		t.Error("The replayed event was not delivered")
+		t.Fail()
+	}
+	result = testutil.NewRequest().Get("/capif-events/v1/dead-letters/"+deadLetter.Id).Go(t, requestHandler)
+	assert.Equal(t, http.StatusNotFound, result.Code())
+
+	result = testutil.NewRequest().Post("/capif-events/v1/dead-letters/"+deadLetter.Id+"/replay").Go(t, requestHandler)
+	assert.Equal(t, http.StatusNotFound, result.Code())
+	var problemDetails common29122.ProblemDetails
+	err = result.UnmarshalBodyToObject(&problemDetails)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Contains(t, *problemDetails.Detail, "no dead letter")
+}
+
+func TestDeadLettersAreDroppedWithTheirSubscription(t *testing.T) {
+	serviceUnderTest, _ := getEcho(nil)
+	subId := "sub1"
+	serviceUnderTest.addSubscription(subId, eventsapi.EventSubscription{
+		Events: []eventsapi.CAPIFEvent{
+			eventsapi.CAPIFEventSERVICEAPIAVAILABLE,
+		},
+		NotificationDestination: common29122.Uri("url"),
+	})
+	r := report{events: []eventsapi.EventNotification{{Events: eventsapi.CAPIFEventSERVICEAPIAVAILABLE}}}
+	serviceUnderTest.addDeadLetter(r, subId, 5, nil)
+	assert.Len(t, getDeadLetterIds(serviceUnderTest), 1)
+
+	serviceUnderTest.deleteSubscription(subId)
+
+	assert.Empty(t, getDeadLetterIds(serviceUnderTest))
+
+	// A report that fails after its subscription has been removed is not kept
+	serviceUnderTest.addDeadLetter(r, subId, 5, nil)
+
+	assert.Empty(t, getDeadLetterIds(serviceUnderTest))
+}
+
+func TestReplayDeadLetterForRemovedSubscription(t *testing.T) {
+	serviceUnderTest, _ := getEcho(nil)
+	requestHandler := getDeadLettersEcho(serviceUnderTest)
+	// A dead letter of a removed subscription may remain in a store written by an earlier version
+	deadLetterId := "deadLetterId"
+	serviceUnderTest.deadLetters[deadLetterId] = DeadLetter{
+		Id:             deadLetterId,
+		SubscriptionId: "removed",
+		Events:         []eventsapi.EventNotification{{Events: eventsapi.CAPIFEventSERVICEAPIAVAILABLE}},
+	}
+
+	result := testutil.NewRequest().Post("/capif-events/v1/dead-letters/"+deadLetterId+"/replay").Go(t, requestHandler)
+	assert.Equal(t, http.StatusConflict, result.Code())
+	var problemDetails common29122.ProblemDetails
+	err := result.UnmarshalBodyToObject(&problemDetails)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Contains(t, *problemDetails.Detail, "removed")
+
+	// The dead letter can be discarded instead
+	result = testutil.NewRequest().Delete("/capif-events/v1/dead-letters/"+deadLetterId).Go(t, requestHandler)
+	assert.Equal(t, http.StatusNoContent, result.Code())
+	assert.Empty(t, getDeadLetterIds(serviceUnderTest))
+}
+
+func getDeadLettersEcho(es *EventService) *echo.Echo {
+	e := echo.New()
+	e.GET("/capif-events/v1/dead-letters", es.GetDeadLetters)
+	e.GET("/capif-events/v1/dead-letters/:deadLetterId", es.GetDeadLetter)
+	e.POST("/capif-events/v1/dead-letters/:deadLetterId/replay", es.ReplayDeadLetter)
+	e.DELETE("/capif-events/v1/dead-letters/:deadLetterId", es.DeleteDeadLetter)
+	return e
+}
+
+func getDeadLetterIds(es *EventService) []string {
+	es.deliveryLock.Lock()
+	defer es.deliveryLock.Unlock()
+	ids := []string{}
+	for id := range es.deadLetters {
+		ids = append(ids, id)
+	}
+	return ids
+}
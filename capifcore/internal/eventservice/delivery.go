@@ -0,0 +1,117 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package eventservice
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"oransc.org/nonrtric/capifcore/internal/eventsapi"
+)
+
+// Delivery of a notification is attempted at most maxAttempts times. The wait between the attempts starts at
+// initialBackoff and is doubled after each failed attempt, up to maxBackoff.
+type retryPolicy struct {
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+}
+
+var defaultRetryPolicy = retryPolicy{
+	maxAttempts:    5,
+	initialBackoff: 1 * time.Second,
+	maxBackoff:     1 * time.Minute,
+}
+
+// The events delivered to a subscription in one notification. A periodic report holds the events of a reporting
+// period, that are sent as an array. Other reports hold a single event.
+type report struct {
+	events   []eventsapi.EventNotification
+	periodic bool
+	// The subscription is terminated once its last report has been handled.
+	last bool
+}
+
+// Queues the report for delivery to the subscription. The reports of a subscription are delivered one at a time, in
+// the order they were queued.
+func (es *EventService) queueReport(r report, subscriptionId string) {
+	es.deliveryLock.Lock()
+	defer es.deliveryLock.Unlock()
+
+	queue := es.deliveryQueues[subscriptionId]
+	es.deliveryQueues[subscriptionId] = append(queue, r)
+	// The report at the head of a queue is kept until it has been handled, so there is a delivery running for every
+	// queue that is not empty.
+	if len(queue) == 0 {
+		go es.deliverQueuedReports(subscriptionId)
+	}
+}
+
+func (es *EventService) deliverQueuedReports(subscriptionId string) {
+	for {
+		es.deliveryLock.Lock()
+		queue := es.deliveryQueues[subscriptionId]
+		if len(queue) == 0 {
+			delete(es.deliveryQueues, subscriptionId)
+			es.deliveryLock.Unlock()
+			return
+		}
+		r := queue[0]
+		es.deliveryLock.Unlock()
+
+		es.deliverReport(r, subscriptionId)
+		if r.last {
+			es.deleteSubscription(subscriptionId)
+		}
+
+		es.deliveryLock.Lock()
+		es.deliveryQueues[subscriptionId] = es.deliveryQueues[subscriptionId][1:]
+		es.deliveryLock.Unlock()
+	}
+}
+
+// Delivers the report to the subscription, retrying with exponential backoff when the delivery fails. A report that
+// cannot be delivered is put in the dead-letter store. Reports for a subscription that has been removed are dropped.
+func (es *EventService) deliverReport(r report, subscriptionId string) {
+	backoff := es.retryPolicy.initialBackoff
+	var err error
+	for attempt := 1; attempt <= es.retryPolicy.maxAttempts; attempt++ {
+		subscription := es.getSubscription(subscriptionId)
+		if subscription == nil {
+			log.Debugf("Dropping report for removed subscription %s", subscriptionId)
+			return
+		}
+
+		if err = es.sendReport(r, subscriptionId, *subscription); err == nil {
+			return
+		}
+		log.Warnf("Unable to send report to subscription %s, attempt %d of %d, due to %s", subscriptionId, attempt, es.retryPolicy.maxAttempts, err)
+
+		if attempt < es.retryPolicy.maxAttempts {
+			time.Sleep(backoff)
+			backoff = backoff * 2
+			if backoff > es.retryPolicy.maxBackoff {
+				backoff = es.retryPolicy.maxBackoff
+			}
+		}
+	}
+	es.addDeadLetter(r, subscriptionId, es.retryPolicy.maxAttempts, err)
+}
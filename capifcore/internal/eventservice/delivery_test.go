@@ -0,0 +1,104 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package eventservice
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"oransc.org/nonrtric/capifcore/internal/common29122"
+	"oransc.org/nonrtric/capifcore/internal/eventsapi"
+)
+
+func TestEventsAreRetriedAndDeliveredInOrder(t *testing.T) {
+	notificationUrl := "url"
+	subId := "sub1"
+	failures := 2
+	deliveredEvents := []eventsapi.CAPIFEvent{}
+	attempts := 0
+	lock := sync.Mutex{}
+	wg := sync.WaitGroup{}
+	clientMock := NewTestClient(func(req *http.Request) *http.Response {
+		lock.Lock()
+		defer lock.Unlock()
+		attempts++
+		if failures > 0 {
+			failures--
+			return &http.Response{
+				StatusCode: http.StatusServiceUnavailable,
+				Body:       io.NopCloser(bytes.NewBufferString(`Unavailable`)),
+				Header:     make(http.Header), // Must be set to non-nil value or it panics
+			}
+		}
+		deliveredEvents = append(deliveredEvents, getBodyAsEvent(req, t).Events)
+		wg.Done()
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(bytes.NewBufferString(`OK`)),
+			Header:     make(http.Header), // Must be set to non-nil value or it panics
+		}
+	})
+	serviceUnderTest, _ := getEcho(clientMock)
+	serviceUnderTest.retryPolicy = retryPolicy{
+		maxAttempts:    3,
+		initialBackoff: 10 * time.Millisecond,
+		maxBackoff:     20 * time.Millisecond,
+	}
+	serviceUnderTest.addSubscription(subId, eventsapi.EventSubscription{
+		Events: []eventsapi.CAPIFEvent{
+			eventsapi.CAPIFEventSERVICEAPIAVAILABLE,
+			eventsapi.CAPIFEventSERVICEAPIUNAVAILABLE,
+		},
+		NotificationDestination: common29122.Uri(notificationUrl),
+	})
+
+	wg.Add(2)
+	serviceUnderTest.handleEvent(eventsapi.EventNotification{Events: eventsapi.CAPIFEventSERVICEAPIAVAILABLE})
+	serviceUnderTest.handleEvent(eventsapi.EventNotification{Events: eventsapi.CAPIFEventSERVICEAPIUNAVAILABLE})
+
+	if waitTimeout(&wg, 1*time.Second) {
+		t.Error("Not all events were delivered")
+		t.Fail()
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	assert.Equal(t, 4, attempts)
+	assert.Equal(t, []eventsapi.CAPIFEvent{eventsapi.CAPIFEventSERVICEAPIAVAILABLE, eventsapi.CAPIFEventSERVICEAPIUNAVAILABLE}, deliveredEvents)
+	assert.Empty(t, serviceUnderTest.deadLetters)
+}
+
+func TestEventsForRemovedSubscriptionAreDropped(t *testing.T) {
+	clientMock := NewTestClient(func(req *http.Request) *http.Response {
+		t.Error("Event sent for removed subscription: ", req)
+		t.Fail()
+		return nil
+	})
+	serviceUnderTest, _ := getEcho(clientMock)
+
+	serviceUnderTest.deliverReport(report{events: []eventsapi.EventNotification{{Events: eventsapi.CAPIFEventSERVICEAPIAVAILABLE}}}, "removed")
+
+	assert.Empty(t, serviceUnderTest.deadLetters)
+}
@@ -25,31 +25,77 @@ import (
 	"fmt"
 	"net/http"
 	"path"
-	"strconv"
 	"sync"
 
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	log "github.com/sirupsen/logrus"
 	"k8s.io/utils/strings/slices"
 	"oransc.org/nonrtric/capifcore/internal/common29122"
 	"oransc.org/nonrtric/capifcore/internal/eventsapi"
+	"oransc.org/nonrtric/capifcore/internal/providermanagementapi"
 	"oransc.org/nonrtric/capifcore/internal/publishserviceapi"
 	"oransc.org/nonrtric/capifcore/internal/restclient"
+	"oransc.org/nonrtric/capifcore/internal/storage"
+	"oransc.org/nonrtric/capifcore/internal/websocketnotifier"
+	"oransc.org/nonrtric/capifcore/problemdetails"
+	"oransc.org/nonrtric/capifcore/validation"
 )
 
+const subscriptionsBucket = "subscriptions"
+const subscribersBucket = "subscribers"
+
 type EventService struct {
 	notificationChannel chan eventsapi.EventNotification
 	client              restclient.HTTPClient
+	notifier            *websocketnotifier.WebSocketNotifier
 	subscriptions       map[string]eventsapi.EventSubscription
-	idCounter           uint
+	subscribers         map[string]string // The subscriber of each subscription
+	store               storage.Store
 	lock                sync.Mutex
+	reporters           map[string]*reporter
+	// The reports waiting to be delivered per subscription, and the reports that could not be delivered.
+	deliveryQueues map[string][]report
+	deadLetters    map[string]DeadLetter
+	retryPolicy    retryPolicy
+	deliveryLock   sync.Mutex
 }
 
-func NewEventService(c restclient.HTTPClient) *EventService {
+// Creates a service that implements the eventsapi.ServerInterface interface.
+// Subscriptions and dead letters kept in the provided store are loaded at creation.
+// Notifications are sent through the provided client, or over a websocket kept by the provided notifier if the
+// subscription requests one.
+func NewEventService(c restclient.HTTPClient, notifier *websocketnotifier.WebSocketNotifier, store storage.Store) *EventService {
 	es := EventService{
 		notificationChannel: make(chan eventsapi.EventNotification),
 		client:              c,
+		notifier:            notifier,
 		subscriptions:       make(map[string]eventsapi.EventSubscription),
+		subscribers:         make(map[string]string),
+		store:               store,
+		reporters:           make(map[string]*reporter),
+		deliveryQueues:      make(map[string][]report),
+		deadLetters:         make(map[string]DeadLetter),
+		retryPolicy:         defaultRetryPolicy,
+	}
+	if err := storage.Load(store, subscriptionsBucket, es.subscriptions); err != nil {
+		log.Errorf("Unable to load subscriptions due to %s", err)
+	}
+	if err := storage.Load(store, subscribersBucket, es.subscribers); err != nil {
+		log.Errorf("Unable to load subscribers due to %s", err)
+	}
+	if err := storage.Load(store, deadLettersBucket, es.deadLetters); err != nil {
+		log.Errorf("Unable to load dead letters due to %s", err)
+	}
+	for subId, subscription := range es.subscriptions {
+		if _, ok := es.subscribers[subId]; !ok {
+			log.Warnf("Subscription %s has no recorded subscriber, it cannot be managed by any subscriber", subId)
+		}
+		notifier.RestoreSocket(subscription.WebsockNotifConfig)
+		es.lock.Lock()
+		es.startReporting(subId, subscription)
+		es.lock.Unlock()
 	}
 	es.start()
 	return &es
@@ -72,17 +118,24 @@ func (es *EventService) PostSubscriberIdSubscriptions(ctx echo.Context, subscrib
 	newSubscription, err := getEventSubscriptionFromRequest(ctx)
 	errMsg := "Unable to register subscription due to %s."
 	if err != nil {
-		return sendCoreError(ctx, http.StatusBadRequest, fmt.Sprintf(errMsg, err))
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseInvalidMsgFormat, fmt.Sprintf(errMsg, err))
 	}
 
 	if err := newSubscription.Validate(); err != nil {
-		return sendCoreError(ctx, http.StatusBadRequest, fmt.Sprintf(errMsg, err))
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseMandatoryIeIncorrect, fmt.Sprintf(errMsg, err))
 	}
 
+	es.notifier.SetUpSocket(ctx, newSubscription.WebsockNotifConfig)
+
 	uri := ctx.Request().Host + ctx.Request().URL.String()
-	subId := es.getSubscriptionId(subscriberId)
-	es.addSubscription(subId, newSubscription)
-	ctx.Response().Header().Set(echo.HeaderLocation, ctx.Scheme()+`://`+path.Join(uri, subId))
+	subId := uuid.NewString()
+	es.addSubscriberSubscription(subscriberId, subId, newSubscription)
+	location := ctx.Scheme() + `://` + path.Join(uri, subId)
+	ctx.Response().Header().Set(echo.HeaderLocation, location)
+
+	if newSubscription.RequestTestNotification != nil && *newSubscription.RequestTestNotification {
+		go es.sendTestNotification(newSubscription, location)
+	}
 	err = ctx.JSON(http.StatusCreated, newSubscription)
 	if err != nil {
 		// Something really bad happened, tell Echo that our handler failed
@@ -92,21 +145,229 @@ func (es *EventService) PostSubscriberIdSubscriptions(ctx echo.Context, subscrib
 	return nil
 }
 
-func (es *EventService) DeleteSubscriberIdSubscriptionsSubscriptionId(ctx echo.Context, subscriberId string, subscriptionId string) error {
+// Retrieves all the subscriptions of the subscriber, keyed by subscription id. This operation is not part of the
+// 3GPP API.
+func (es *EventService) GetSubscriberIdSubscriptions(ctx echo.Context) error {
+	subscriberId := ctx.Param("subscriberId")
+
+	es.lock.Lock()
+	subscriptions := make(map[string]eventsapi.EventSubscription)
+	for subId, subscription := range es.subscriptions {
+		if es.isSubscriber(subscriberId, subId) {
+			subscriptions[subId] = subscription
+		}
+	}
+	es.lock.Unlock()
+
+	err := ctx.JSON(http.StatusOK, subscriptions)
+	if err != nil {
+		// Something really bad happened, tell Echo that our handler failed
+		return err
+	}
+
+	return nil
+}
+
+// Retrieves an individual subscription of the subscriber. This operation is not part of the 3GPP API.
+func (es *EventService) GetSubscriberIdSubscriptionsSubscriptionId(ctx echo.Context) error {
+	subscriberId := ctx.Param("subscriberId")
+	subscriptionId := ctx.Param("subscriptionId")
+
+	subscription := es.getSubscriberSubscription(subscriberId, subscriptionId)
+	if subscription == nil {
+		return problemdetails.Send(ctx, http.StatusNotFound, problemdetails.CauseSubscriptionNotFound, fmt.Sprintf("Unable to get subscription due to %s", getNotFoundMessage(subscriberId, subscriptionId)))
+	}
+
+	err := ctx.JSON(http.StatusOK, *subscription)
+	if err != nil {
+		// Something really bad happened, tell Echo that our handler failed
+		return err
+	}
+
+	return nil
+}
 
-	log.Debug(es.subscriptions)
-	if _, ok := es.subscriptions[subscriptionId]; ok {
-		es.deleteSubscription(subscriptionId)
+// Replaces an individual subscription of the subscriber. This operation is not part of the 3GPP API.
+func (es *EventService) PutSubscriberIdSubscriptionsSubscriptionId(ctx echo.Context) error {
+	errMsg := "Unable to update subscription due to %s."
+	subscriberId := ctx.Param("subscriberId")
+	subscriptionId := ctx.Param("subscriptionId")
+
+	updatedSubscription, err := getEventSubscriptionFromRequest(ctx)
+	if err != nil {
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseInvalidMsgFormat, fmt.Sprintf(errMsg, err))
+	}
+
+	return es.updateSubscription(ctx, subscriberId, subscriptionId, func(eventsapi.EventSubscription) eventsapi.EventSubscription {
+		return updatedSubscription
+	})
+}
+
+// Modifies an individual subscription of the subscriber. Only the attributes present in the request are changed.
+// This operation is not part of the 3GPP API.
+func (es *EventService) PatchSubscriberIdSubscriptionsSubscriptionId(ctx echo.Context) error {
+	errMsg := "Unable to update subscription due to %s."
+	subscriberId := ctx.Param("subscriberId")
+	subscriptionId := ctx.Param("subscriptionId")
+
+	var patch eventsapi.EventSubscriptionPatch
+	if err := validation.BindMergePatch(ctx, &patch); err != nil {
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseInvalidMsgFormat, fmt.Sprintf(errMsg, "invalid format for subscription patch"))
+	}
+
+	return es.updateSubscription(ctx, subscriberId, subscriptionId, func(subscription eventsapi.EventSubscription) eventsapi.EventSubscription {
+		return subscription.ApplyPatch(patch)
+	})
+}
+
+func (es *EventService) updateSubscription(ctx echo.Context, subscriberId, subscriptionId string, update func(eventsapi.EventSubscription) eventsapi.EventSubscription) error {
+	errMsg := "Unable to update subscription due to %s."
+
+	es.lock.Lock()
+	defer es.lock.Unlock()
+
+	subscription, ok := es.subscriptions[subscriptionId]
+	if !ok || !es.isSubscriber(subscriberId, subscriptionId) {
+		return problemdetails.Send(ctx, http.StatusNotFound, problemdetails.CauseSubscriptionNotFound, fmt.Sprintf(errMsg, getNotFoundMessage(subscriberId, subscriptionId)))
+	}
+
+	updatedSubscription := update(subscription)
+	if err := updatedSubscription.Validate(); err != nil {
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseMandatoryIeIncorrect, fmt.Sprintf(errMsg, err))
 	}
 
+	es.notifier.UpdateSocket(ctx, subscription.WebsockNotifConfig, updatedSubscription.WebsockNotifConfig)
+	es.putSubscription(subscriptionId, updatedSubscription)
+
+	err := ctx.JSON(http.StatusOK, updatedSubscription)
+	if err != nil {
+		// Something really bad happened, tell Echo that our handler failed
+		return err
+	}
+
+	return nil
+}
+
+func (es *EventService) DeleteSubscriberIdSubscriptionsSubscriptionId(ctx echo.Context, subscriberId string, subscriptionId string) error {
+	if es.getSubscriberSubscription(subscriberId, subscriptionId) == nil {
+		return problemdetails.Send(ctx, http.StatusNotFound, problemdetails.CauseSubscriptionNotFound, fmt.Sprintf("Unable to delete subscription due to %s", getNotFoundMessage(subscriberId, subscriptionId)))
+	}
+
+	es.deleteSubscription(subscriptionId)
+
 	return ctx.NoContent(http.StatusNoContent)
 }
 
+// Adds the operations that list, read, replace and modify the subscriptions of a subscriber to the specification of the
+// API, so that their requests are validated like the requests of the 3GPP operations.
+func AddSubscriberOperations(swagger *openapi3.T) {
+	subscriptionsItem := swagger.Paths["/{subscriberId}/subscriptions"]
+	subscriptionItem := swagger.Paths["/{subscriberId}/subscriptions/{subscriptionId}"]
+	if subscriptionsItem == nil || subscriptionsItem.Post == nil || subscriptionItem == nil || subscriptionItem.Delete == nil {
+		return
+	}
+	subscriptionSchema := swagger.Components.Schemas["EventSubscription"]
+	if subscriptionSchema == nil || subscriptionSchema.Value == nil {
+		return
+	}
+
+	subscriptionsSchema := openapi3.NewObjectSchema().WithAdditionalProperties(subscriptionSchema.Value)
+	subscriptionsItem.Get = &openapi3.Operation{
+		Description: "Retrieves the subscriptions of the subscriber, keyed by subscription id.",
+		OperationID: "GetSubscriberIdSubscriptions",
+		Parameters:  getPathParameters(subscriptionsItem.Post),
+		Responses:   getResponses(subscriptionsItem.Post, "201", openapi3.NewResponse().WithDescription("OK.").WithJSONSchema(subscriptionsSchema)),
+	}
+
+	// Only the attributes of a subscription that can be modified are allowed in a patch
+	patchSchema := openapi3.NewObjectSchema()
+	for _, property := range []string{"events", "eventFilters", "eventReq", "notificationDestination", "websockNotifConfig"} {
+		if propertySchema, ok := subscriptionSchema.Value.Properties[property]; ok {
+			patchSchema.WithPropertyRef(property, propertySchema)
+		}
+	}
+	parameters := getPathParameters(subscriptionItem.Delete)
+	okResponse := openapi3.NewResponse().WithDescription("OK.").WithJSONSchemaRef(subscriptionSchema)
+	subscriptionItem.Get = &openapi3.Operation{
+		Description: "Retrieves an individual subscription of the subscriber.",
+		OperationID: "GetSubscriberIdSubscriptionsSubscriptionId",
+		Parameters:  parameters,
+		Responses:   getResponses(subscriptionItem.Delete, "204", okResponse),
+	}
+	subscriptionItem.Put = &openapi3.Operation{
+		Description: "Replaces an individual subscription of the subscriber.",
+		OperationID: "PutSubscriberIdSubscriptionsSubscriptionId",
+		Parameters:  parameters,
+		RequestBody: &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().WithRequired(true).WithJSONSchemaRef(subscriptionSchema)},
+		Responses:   getResponses(subscriptionItem.Delete, "204", okResponse),
+	}
+	subscriptionItem.Patch = &openapi3.Operation{
+		Description: "Modifies an individual subscription of the subscriber.",
+		OperationID: "PatchSubscriberIdSubscriptionsSubscriptionId",
+		Parameters:  parameters,
+		RequestBody: &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().WithRequired(true).WithSchema(patchSchema, []string{"application/merge-patch+json"})},
+		Responses:   getResponses(subscriptionItem.Delete, "204", okResponse),
+	}
+}
+
+func getPathParameters(operation *openapi3.Operation) openapi3.Parameters {
+	parameters := openapi3.Parameters{}
+	for _, parameter := range operation.Parameters {
+		if parameter.Value != nil && parameter.Value.In == openapi3.ParameterInPath {
+			parameters = append(parameters, parameter)
+		}
+	}
+	return parameters
+}
+
+// Gets the responses of the operation with the provided success response replacing the response with the success
+// status of the operation.
+func getResponses(operation *openapi3.Operation, successStatus string, successResponse *openapi3.Response) openapi3.Responses {
+	responses := openapi3.Responses{}
+	for status, response := range operation.Responses {
+		if status != successStatus {
+			responses[status] = response
+		}
+	}
+	responses["200"] = &openapi3.ResponseRef{Value: successResponse}
+	return responses
+}
+
+// Removes the subscriptions of an offboarded invoker.
+func (es *EventService) RemoveInvoker(invokerId string) {
+	es.lock.Lock()
+	defer es.lock.Unlock()
+	for subId, subscriberId := range es.subscribers {
+		if subscriberId == invokerId {
+			es.removeSubscription(subId)
+		}
+	}
+}
+
+func getNotFoundMessage(subscriberId, subscriptionId string) string {
+	return fmt.Sprintf("subscriber %s has no subscription with id %s", subscriberId, subscriptionId)
+}
+
 func (es *EventService) deleteSubscription(subscriptionId string) {
 	log.Debug("Deleting subscription", subscriptionId)
 	es.lock.Lock()
 	defer es.lock.Unlock()
+	es.removeSubscription(subscriptionId)
+}
+
+// Must be called with the lock held.
+func (es *EventService) removeSubscription(subscriptionId string) {
+	es.stopReporting(subscriptionId)
+	es.notifier.RemoveSocket(es.subscriptions[subscriptionId].WebsockNotifConfig)
 	delete(es.subscriptions, subscriptionId)
+	if err := es.store.Delete(subscriptionsBucket, subscriptionId); err != nil {
+		log.Errorf("Unable to remove stored subscription %s due to %s", subscriptionId, err)
+	}
+	delete(es.subscribers, subscriptionId)
+	if err := es.store.Delete(subscribersBucket, subscriptionId); err != nil {
+		log.Errorf("Unable to remove stored subscriber of subscription %s due to %s", subscriptionId, err)
+	}
+	es.deleteDeadLetters(subscriptionId)
 }
 
 func getEventSubscriptionFromRequest(ctx echo.Context) (eventsapi.EventSubscription, error) {
@@ -121,15 +382,46 @@ func getEventSubscriptionFromRequest(ctx echo.Context) (eventsapi.EventSubscript
 func (es *EventService) handleEvent(event eventsapi.EventNotification) {
 	subsIds := es.getMatchingSubs(event)
 	for _, subId := range subsIds {
-		go es.sendEvent(event, subId)
+		es.reportEvent(event, subId)
+	}
+}
+
+// Sends the report to the subscription. The events of a periodic report are sent as an array.
+func (es *EventService) sendReport(r report, subscriptionId string, subscription eventsapi.EventSubscription) error {
+	events := make([]eventsapi.EventNotification, len(r.events))
+	for i, event := range r.events {
+		event.SubscriptionId = subscriptionId
+		events[i] = event
+	}
+	var notification interface{} = events[0]
+	if r.periodic {
+		notification = events
 	}
+
+	if socketId, ok := websocketnotifier.GetSocketId(subscription.WebsockNotifConfig); ok {
+		return es.notifier.Send(socketId, notification)
+	}
+	e, _ := json.Marshal(notification)
+	return restclient.Put(string(subscription.NotificationDestination), []byte(e), es.client)
 }
 
-func (es *EventService) sendEvent(event eventsapi.EventNotification, subscriptionId string) {
-	event.SubscriptionId = subscriptionId
-	e, _ := json.Marshal(event)
-	if error := restclient.Put(string(es.subscriptions[subscriptionId].NotificationDestination), []byte(e), es.client); error != nil {
-		log.Error("Unable to send event")
+// Sends a test notification to the subscriber, so that it can verify that notifications reach it. The notification
+// links to the created subscription.
+func (es *EventService) sendTestNotification(subscription eventsapi.EventSubscription, subscriptionLink string) {
+	notification := common29122.TestNotification{
+		Subscription: common29122.Link(subscriptionLink),
+	}
+	if socketId, ok := websocketnotifier.GetSocketId(subscription.WebsockNotifConfig); ok {
+		if err := es.notifier.Send(socketId, notification); err != nil {
+			log.Errorf("Unable to send test notification over websocket due to %s", err)
+		}
+		return
+	}
+	notificationDestination := string(subscription.NotificationDestination)
+	body, _ := json.Marshal(notification)
+	header := map[string]string{"Content-Type": restclient.ContentTypeJSON}
+	if err := restclient.Post(notificationDestination, body, header, es.client); err != nil {
+		log.Errorf("Unable to send test notification to %s due to %s", notificationDestination, err)
 	}
 }
 
@@ -144,7 +436,7 @@ func (es *EventService) getMatchingSubs(event eventsapi.EventNotification) []str
 			matchingSubs = append(matchingSubs, subId)
 		} else if matchesFilters(event.EventDetail.ApiIds, *subscription.EventFilters, getApiIdsFromFilter) &&
 			matchesFilters(event.EventDetail.ApiInvokerIds, *subscription.EventFilters, getInvokerIdsFromFilter) &&
-			matchesFilters(getAefIdsFromEvent(event.EventDetail.ServiceAPIDescriptions), *subscription.EventFilters, getAefIdsFromFilter) {
+			matchesFilters(getAefIdsFromEvent(*event.EventDetail), *subscription.EventFilters, getAefIdsFromFilter) {
 			matchingSubs = append(matchingSubs, subId)
 		}
 	}
@@ -186,20 +478,37 @@ func getInvokerIdsFromFilter(filter eventsapi.CAPIFEventFilter) *[]string {
 	return filter.ApiInvokerIds
 }
 
-func getAefIdsFromEvent(serviceAPIDescriptions *[]publishserviceapi.ServiceAPIDescription) *[]string {
+func getAefIdsFromEvent(eventDetail eventsapi.CAPIFEventDetail) *[]string {
+	aefIds := getAefIdsFromServiceDescriptions(eventDetail.ServiceAPIDescriptions)
+	if eventDetail.InvocationLogs != nil {
+		for _, invocationLog := range *eventDetail.InvocationLogs {
+			aefIds = append(aefIds, invocationLog.AefId)
+		}
+	}
+	if eventDetail.ApiProviderDomInfo != nil && eventDetail.ApiProviderDomInfo.ApiProvFuncs != nil {
+		for _, function := range *eventDetail.ApiProviderDomInfo.ApiProvFuncs {
+			if function.ApiProvFuncRole == providermanagementapi.ApiProviderFuncRoleAEF && function.ApiProvFuncId != nil {
+				aefIds = append(aefIds, *function.ApiProvFuncId)
+			}
+		}
+	}
+	return &aefIds
+}
+
+func getAefIdsFromServiceDescriptions(serviceAPIDescriptions *[]publishserviceapi.ServiceAPIDescription) []string {
 	aefIds := []string{}
 	if serviceAPIDescriptions == nil {
-		return &aefIds
+		return aefIds
 	}
 	for _, serviceDescription := range *serviceAPIDescriptions {
 		if serviceDescription.AefProfiles == nil {
-			return &aefIds
+			return aefIds
 		}
 		for _, profile := range *serviceDescription.AefProfiles {
 			aefIds = append(aefIds, profile.AefId)
 		}
 	}
-	return &aefIds
+	return aefIds
 }
 
 func getAefIdsFromFilter(filter eventsapi.CAPIFEventFilter) *[]string {
@@ -214,15 +523,46 @@ func asStrings(events []eventsapi.CAPIFEvent) []string {
 	return asStrings
 }
 
-func (es *EventService) getSubscriptionId(subscriberId string) string {
-	es.idCounter++
-	return subscriberId + strconv.FormatUint(uint64(es.idCounter), 10)
+func (es *EventService) addSubscription(subId string, subscription eventsapi.EventSubscription) {
+	es.lock.Lock()
+	defer es.lock.Unlock()
+	es.putSubscription(subId, subscription)
 }
 
-func (es *EventService) addSubscription(subId string, subscription eventsapi.EventSubscription) {
+func (es *EventService) addSubscriberSubscription(subscriberId, subId string, subscription eventsapi.EventSubscription) {
 	es.lock.Lock()
 	defer es.lock.Unlock()
+	es.subscribers[subId] = subscriberId
+	if err := es.store.Put(subscribersBucket, subId, subscriberId); err != nil {
+		log.Errorf("Unable to store subscriber of subscription %s due to %s", subId, err)
+	}
+	es.putSubscription(subId, subscription)
+}
+
+// Must be called with the lock held.
+func (es *EventService) putSubscription(subId string, subscription eventsapi.EventSubscription) {
 	es.subscriptions[subId] = subscription
+	es.startReporting(subId, subscription)
+	if err := es.store.Put(subscriptionsBucket, subId, subscription); err != nil {
+		log.Errorf("Unable to store subscription %s due to %s", subId, err)
+	}
+}
+
+// Checks if the subscription belongs to the subscriber. Subscriptions stored before their subscriber was recorded
+// belong to no subscriber.
+// Must be called with the lock held.
+func (es *EventService) isSubscriber(subscriberId, subId string) bool {
+	owner, ok := es.subscribers[subId]
+	return ok && owner == subscriberId
+}
+
+func (es *EventService) getSubscriberSubscription(subscriberId, subId string) *eventsapi.EventSubscription {
+	es.lock.Lock()
+	defer es.lock.Unlock()
+	if sub, ok := es.subscriptions[subId]; ok && es.isSubscriber(subscriberId, subId) {
+		return &sub
+	}
+	return nil
 }
 
 func (es *EventService) getSubscription(subId string) *eventsapi.EventSubscription {
@@ -234,14 +574,3 @@ func (es *EventService) getSubscription(subId string) *eventsapi.EventSubscripti
 		return nil
 	}
 }
-
-// This function wraps sending of an error in the Error format, and
-// handling the failure to marshal that.
-func sendCoreError(ctx echo.Context, code int, message string) error {
-	pd := common29122.ProblemDetails{
-		Cause:  &message,
-		Status: &code,
-	}
-	err := ctx.JSON(code, pd)
-	return err
-}
@@ -26,8 +26,10 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"path"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -37,10 +39,18 @@ import (
 	"github.com/labstack/echo/v4"
 	echomiddleware "github.com/labstack/echo/v4/middleware"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/websocket"
 	"oransc.org/nonrtric/capifcore/internal/common29122"
 	"oransc.org/nonrtric/capifcore/internal/eventsapi"
+	"oransc.org/nonrtric/capifcore/internal/loggingapi"
+	"oransc.org/nonrtric/capifcore/internal/providermanagementapi"
 	"oransc.org/nonrtric/capifcore/internal/publishserviceapi"
 	"oransc.org/nonrtric/capifcore/internal/restclient"
+	"oransc.org/nonrtric/capifcore/internal/storage/storagetest"
+	"oransc.org/nonrtric/capifcore/internal/websocketnotifier"
+	"oransc.org/nonrtric/capifcore/problemdetails"
+	_ "oransc.org/nonrtric/capifcore/problemdetails/problemdetailstest"
+	"oransc.org/nonrtric/capifcore/validation"
 )
 
 func TestRegisterSubscriptions(t *testing.T) {
@@ -59,7 +69,7 @@ func TestRegisterSubscriptions(t *testing.T) {
 	err := result.UnmarshalBodyToObject(&resultEvent)
 	assert.NoError(t, err, "error unmarshaling response")
 	assert.Equal(t, resultEvent, subscription1)
-	assert.Regexp(t, "http://example.com/"+subscriberId+"/subscriptions/"+subscriberId+"[0-9]+", result.Recorder.Header().Get(echo.HeaderLocation))
+	assert.Regexp(t, "http://example.com/"+subscriberId+"/subscriptions/[0-9a-f-]{36}", result.Recorder.Header().Get(echo.HeaderLocation))
 	subscriptionId1 := path.Base(result.Recorder.Header().Get(echo.HeaderLocation))
 
 	subscription2 := subscription1
@@ -67,7 +77,7 @@ func TestRegisterSubscriptions(t *testing.T) {
 		eventsapi.CAPIFEventAPIINVOKERUPDATED,
 	}
 	result = testutil.NewRequest().Post("/"+subscriberId+"/subscriptions").WithJsonBody(subscription2).Go(t, requestHandler)
-	assert.Regexp(t, "http://example.com/"+subscriberId+"/subscriptions/"+subscriberId+"[0-9]+", result.Recorder.Header().Get(echo.HeaderLocation))
+	assert.Regexp(t, "http://example.com/"+subscriberId+"/subscriptions/[0-9a-f-]{36}", result.Recorder.Header().Get(echo.HeaderLocation))
 	subscriptionId2 := path.Base(result.Recorder.Header().Get(echo.HeaderLocation))
 
 	assert.NotEqual(t, subscriptionId1, subscriptionId2)
@@ -77,6 +87,26 @@ func TestRegisterSubscriptions(t *testing.T) {
 	assert.Equal(t, subscription2, *registeredSub2)
 }
 
+func TestSubscriptionsAreLoadedFromStore(t *testing.T) {
+	subscription := eventsapi.EventSubscription{
+		Events: []eventsapi.CAPIFEvent{
+			eventsapi.CAPIFEventSERVICEAPIAVAILABLE,
+		},
+		NotificationDestination: common29122.Uri("http://golang.cafe/"),
+	}
+	serviceUnderTest, requestHandler := getEcho(nil)
+	subscriberId := "subscriberId"
+
+	result := testutil.NewRequest().Post("/"+subscriberId+"/subscriptions").WithJsonBody(subscription).Go(t, requestHandler)
+	assert.Equal(t, http.StatusCreated, result.Code())
+	subscriptionId := path.Base(result.Recorder.Header().Get(echo.HeaderLocation))
+
+	restartedService := NewEventService(nil, websocketnotifier.NewWebSocketNotifier(), serviceUnderTest.store)
+	assert.Equal(t, subscription, *restartedService.getSubscription(subscriptionId))
+	assert.NotNil(t, restartedService.getSubscriberSubscription(subscriberId, subscriptionId))
+	assert.Nil(t, restartedService.getSubscriberSubscription("otherSubscriberId", subscriptionId))
+}
+
 func TestRegisterInvalidSubscription(t *testing.T) {
 	subscription1 := eventsapi.EventSubscription{
 		Events: []eventsapi.CAPIFEvent{eventsapi.CAPIFEventACCESSCONTROLPOLICYUNAVAILABLE},
@@ -91,13 +121,55 @@ func TestRegisterInvalidSubscription(t *testing.T) {
 	assert.NoError(t, err, "error unmarshaling response")
 	badRequest := http.StatusBadRequest
 	assert.Equal(t, &badRequest, problemDetails.Status)
-	assert.Contains(t, *problemDetails.Cause, "missing")
-	assert.Contains(t, *problemDetails.Cause, "notificationDestination")
+	assert.Contains(t, *problemDetails.Detail, "missing")
+	assert.Contains(t, *problemDetails.Detail, "notificationDestination")
 	subscriptionId := path.Base(result.Recorder.Header().Get(echo.HeaderLocation))
 	registeredSub := serviceUnderTest.getSubscription(subscriptionId)
 	assert.Nil(t, registeredSub)
 }
 
+func TestRegisterSubscriptionWithTestNotification(t *testing.T) {
+	notificationUrl := "http://golang.cafe/"
+	requestTestNotification := true
+	subscription := eventsapi.EventSubscription{
+		Events: []eventsapi.CAPIFEvent{
+			eventsapi.CAPIFEventSERVICEAPIAVAILABLE,
+		},
+		NotificationDestination: common29122.Uri(notificationUrl),
+		RequestTestNotification: &requestTestNotification,
+	}
+	wg := sync.WaitGroup{}
+	var testNotification common29122.TestNotification
+	clientMock := NewTestClient(func(req *http.Request) *http.Response {
+		if req.URL.String() == notificationUrl {
+			assert.Equal(t, req.Method, "POST")
+			assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+			body, _ := io.ReadAll(req.Body)
+			assert.NoError(t, json.Unmarshal(body, &testNotification))
+			wg.Done()
+			return &http.Response{
+				StatusCode: 204,
+				Body:       io.NopCloser(bytes.NewBufferString(``)),
+				Header:     make(http.Header), // Must be set to non-nil value or it panics
+			}
+		}
+		t.Error("Wrong call to client: ", req)
+		t.Fail()
+		return nil
+	})
+	_, requestHandler := getEcho(clientMock)
+
+	wg.Add(1)
+	result := testutil.NewRequest().Post("/subscriberId/subscriptions").WithJsonBody(subscription).Go(t, requestHandler)
+	assert.Equal(t, http.StatusCreated, result.Code())
+
+	if waitTimeout(&wg, 1*time.Second) {
+		t.Error("No test notification was sent")
+		t.Fail()
+	}
+	assert.Equal(t, common29122.Link(result.Recorder.Header().Get(echo.HeaderLocation)), testNotification.Subscription)
+}
+
 func TestDeregisterSubscription(t *testing.T) {
 	subscription := eventsapi.EventSubscription{
 		Events: []eventsapi.CAPIFEvent{
@@ -107,11 +179,188 @@ func TestDeregisterSubscription(t *testing.T) {
 	}
 	serviceUnderTest, requestHandler := getEcho(nil)
 	subId := "sub1"
-	serviceUnderTest.addSubscription(subId, subscription)
+	serviceUnderTest.addSubscriberSubscription("subscriberId", subId, subscription)
 
 	result := testutil.NewRequest().Delete("/subscriberId/subscriptions/"+subId).Go(t, requestHandler)
 	assert.Equal(t, http.StatusNoContent, result.Code())
 	assert.Nil(t, serviceUnderTest.getSubscription(subId))
+
+	result = testutil.NewRequest().Delete("/subscriberId/subscriptions/"+subId).Go(t, requestHandler)
+	assert.Equal(t, http.StatusNotFound, result.Code())
+}
+
+func TestSubscriptionWithoutSubscriberBelongsToNoSubscriber(t *testing.T) {
+	serviceUnderTest, requestHandler := getEcho(nil)
+	subId := "sub1"
+	serviceUnderTest.addSubscription(subId, eventsapi.EventSubscription{
+		Events: []eventsapi.CAPIFEvent{
+			eventsapi.CAPIFEventSERVICEAPIAVAILABLE,
+		},
+	})
+
+	result := testutil.NewRequest().Delete("/subscriberId/subscriptions/"+subId).Go(t, requestHandler)
+	assert.Equal(t, http.StatusNotFound, result.Code())
+	assert.NotNil(t, serviceUnderTest.getSubscription(subId))
+}
+
+func TestRemoveInvoker(t *testing.T) {
+	subscription := eventsapi.EventSubscription{
+		Events: []eventsapi.CAPIFEvent{
+			eventsapi.CAPIFEventSERVICEAPIAVAILABLE,
+		},
+		NotificationDestination: common29122.Uri(""),
+	}
+	serviceUnderTest, _ := getEcho(nil)
+	invokerSubId := "sub1"
+	serviceUnderTest.addSubscriberSubscription("invokerId", invokerSubId, subscription)
+	otherSubId := "sub2"
+	serviceUnderTest.addSubscriberSubscription("otherInvokerId", otherSubId, subscription)
+
+	serviceUnderTest.RemoveInvoker("invokerId")
+
+	assert.Nil(t, serviceUnderTest.getSubscription(invokerSubId))
+	assert.NotNil(t, serviceUnderTest.getSubscription(otherSubId))
+}
+
+func TestDeregisterSubscriptionOfOtherSubscriber(t *testing.T) {
+	subscription := eventsapi.EventSubscription{
+		Events: []eventsapi.CAPIFEvent{
+			eventsapi.CAPIFEventSERVICEAPIAVAILABLE,
+		},
+		NotificationDestination: common29122.Uri("http://golang.cafe/"),
+	}
+	serviceUnderTest, requestHandler := getEcho(nil)
+
+	result := testutil.NewRequest().Post("/subscriberId/subscriptions").WithJsonBody(subscription).Go(t, requestHandler)
+	assert.Equal(t, http.StatusCreated, result.Code())
+	subId := path.Base(result.Recorder.Header().Get(echo.HeaderLocation))
+
+	result = testutil.NewRequest().Delete("/otherSubscriberId/subscriptions/"+subId).Go(t, requestHandler)
+	assert.Equal(t, http.StatusNotFound, result.Code())
+	var problemDetails common29122.ProblemDetails
+	err := result.UnmarshalBodyToObject(&problemDetails)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, problemdetails.CauseSubscriptionNotFound, *problemDetails.Cause)
+	assert.Contains(t, *problemDetails.Detail, "otherSubscriberId")
+	assert.NotNil(t, serviceUnderTest.getSubscription(subId))
+}
+
+func TestGetSubscriptions(t *testing.T) {
+	subscription := eventsapi.EventSubscription{
+		Events: []eventsapi.CAPIFEvent{
+			eventsapi.CAPIFEventSERVICEAPIAVAILABLE,
+		},
+		NotificationDestination: common29122.Uri("http://golang.cafe/"),
+	}
+	serviceUnderTest, requestHandler := getEcho(nil)
+	subscriptionsHandler := getSubscriptionsEcho(serviceUnderTest)
+
+	result := testutil.NewRequest().Post("/subscriberId/subscriptions").WithJsonBody(subscription).Go(t, requestHandler)
+	subId1 := path.Base(result.Recorder.Header().Get(echo.HeaderLocation))
+	result = testutil.NewRequest().Post("/subscriberId/subscriptions").WithJsonBody(subscription).Go(t, requestHandler)
+	subId2 := path.Base(result.Recorder.Header().Get(echo.HeaderLocation))
+	testutil.NewRequest().Post("/otherSubscriberId/subscriptions").WithJsonBody(subscription).Go(t, requestHandler)
+
+	result = testutil.NewRequest().Get("/capif-events/v1/subscriberId/subscriptions").Go(t, subscriptionsHandler)
+	assert.Equal(t, http.StatusOK, result.Code())
+	var subscriptions map[string]eventsapi.EventSubscription
+	err := result.UnmarshalBodyToObject(&subscriptions)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Len(t, subscriptions, 2)
+	assert.Equal(t, subscription, subscriptions[subId1])
+	assert.Equal(t, subscription, subscriptions[subId2])
+
+	result = testutil.NewRequest().Get("/capif-events/v1/subscriberId/subscriptions/"+subId1).Go(t, subscriptionsHandler)
+	assert.Equal(t, http.StatusOK, result.Code())
+	var resultSubscription eventsapi.EventSubscription
+	err = result.UnmarshalBodyToObject(&resultSubscription)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, subscription, resultSubscription)
+
+	result = testutil.NewRequest().Get("/capif-events/v1/otherSubscriberId/subscriptions/"+subId1).Go(t, subscriptionsHandler)
+	assert.Equal(t, http.StatusNotFound, result.Code())
+
+	result = testutil.NewRequest().Get("/capif-events/v1/subscriberId/subscriptions/unknown").Go(t, subscriptionsHandler)
+	assert.Equal(t, http.StatusNotFound, result.Code())
+}
+
+func TestUpdateSubscription(t *testing.T) {
+	subscription := eventsapi.EventSubscription{
+		Events: []eventsapi.CAPIFEvent{
+			eventsapi.CAPIFEventSERVICEAPIAVAILABLE,
+		},
+		NotificationDestination: common29122.Uri("http://golang.cafe/"),
+	}
+	serviceUnderTest, requestHandler := getEcho(nil)
+	subscriptionsHandler := getSubscriptionsEcho(serviceUnderTest)
+
+	result := testutil.NewRequest().Post("/subscriberId/subscriptions").WithJsonBody(subscription).Go(t, requestHandler)
+	subId := path.Base(result.Recorder.Header().Get(echo.HeaderLocation))
+
+	updatedSubscription := subscription
+	updatedSubscription.Events = []eventsapi.CAPIFEvent{
+		eventsapi.CAPIFEventAPIINVOKERONBOARDED,
+	}
+	result = testutil.NewRequest().Put("/capif-events/v1/subscriberId/subscriptions/"+subId).WithJsonBody(updatedSubscription).Go(t, subscriptionsHandler)
+	assert.Equal(t, http.StatusOK, result.Code())
+	var resultSubscription eventsapi.EventSubscription
+	err := result.UnmarshalBodyToObject(&resultSubscription)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, updatedSubscription, resultSubscription)
+	assert.Equal(t, updatedSubscription, *serviceUnderTest.getSubscription(subId))
+
+	result = testutil.NewRequest().Put("/capif-events/v1/otherSubscriberId/subscriptions/"+subId).WithJsonBody(subscription).Go(t, subscriptionsHandler)
+	assert.Equal(t, http.StatusNotFound, result.Code())
+	assert.Equal(t, updatedSubscription, *serviceUnderTest.getSubscription(subId))
+
+	updatedSubscription.Events = []eventsapi.CAPIFEvent{}
+	result = testutil.NewRequest().Put("/capif-events/v1/subscriberId/subscriptions/"+subId).WithJsonBody(updatedSubscription).Go(t, subscriptionsHandler)
+	assert.Equal(t, http.StatusBadRequest, result.Code())
+	var problemDetails common29122.ProblemDetails
+	err = result.UnmarshalBodyToObject(&problemDetails)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, "/events", (*problemDetails.InvalidParams)[0].Param)
+}
+
+func TestPatchSubscription(t *testing.T) {
+	subscription := eventsapi.EventSubscription{
+		Events: []eventsapi.CAPIFEvent{
+			eventsapi.CAPIFEventSERVICEAPIAVAILABLE,
+		},
+		NotificationDestination: common29122.Uri("http://golang.cafe/"),
+	}
+	serviceUnderTest, requestHandler := getEcho(nil)
+	subscriptionsHandler := getSubscriptionsEcho(serviceUnderTest)
+
+	result := testutil.NewRequest().Post("/subscriberId/subscriptions").WithJsonBody(subscription).Go(t, requestHandler)
+	subId := path.Base(result.Recorder.Header().Get(echo.HeaderLocation))
+
+	destination := common29122.Uri("http://other.host/")
+	patch := eventsapi.EventSubscriptionPatch{
+		NotificationDestination: &destination,
+	}
+	result = testutil.NewRequest().Patch("/capif-events/v1/subscriberId/subscriptions/"+subId).WithJsonBody(patch).WithContentType("application/merge-patch+json").Go(t, subscriptionsHandler)
+	assert.Equal(t, http.StatusOK, result.Code())
+	expectedSubscription := subscription
+	expectedSubscription.NotificationDestination = destination
+	assert.Equal(t, expectedSubscription, *serviceUnderTest.getSubscription(subId))
+
+	invalidDestination := common29122.Uri("invalid url")
+	patch.NotificationDestination = &invalidDestination
+	result = testutil.NewRequest().Patch("/capif-events/v1/subscriberId/subscriptions/"+subId).WithJsonBody(patch).WithContentType("application/merge-patch+json").Go(t, subscriptionsHandler)
+	assert.Equal(t, http.StatusBadRequest, result.Code())
+	assert.Equal(t, expectedSubscription, *serviceUnderTest.getSubscription(subId))
+
+	// The patch is validated against the specification
+	result = testutil.NewRequest().Patch("/capif-events/v1/subscriberId/subscriptions/"+subId).WithJsonBody(map[string]any{"events": "notAList"}).WithContentType("application/merge-patch+json").Go(t, subscriptionsHandler)
+	assert.Equal(t, http.StatusBadRequest, result.Code())
+	var problemDetails common29122.ProblemDetails
+	err := result.UnmarshalBodyToObject(&problemDetails)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, "/events", (*problemDetails.InvalidParams)[0].Param)
+
+	result = testutil.NewRequest().Patch("/capif-events/v1/subscriberId/subscriptions/unknown").WithJsonBody(patch).WithContentType("application/merge-patch+json").Go(t, subscriptionsHandler)
+	assert.Equal(t, http.StatusNotFound, result.Code())
 }
 
 func TestSendEvent(t *testing.T) {
@@ -169,10 +418,64 @@ func TestSendEvent(t *testing.T) {
 	}
 }
 
+func TestSendEventOverWebsocket(t *testing.T) {
+	clientMock := NewTestClient(func(req *http.Request) *http.Response {
+		t.Error("Notification sent over HTTP: ", req)
+		t.Fail()
+		return nil
+	})
+	serviceUnderTest, requestHandler := getEcho(clientMock)
+	socketHandler := echo.New()
+	serviceUnderTest.notifier.RegisterHandler(socketHandler)
+	socketServer := httptest.NewServer(socketHandler)
+	defer socketServer.Close()
+
+	requestWebsocketUri := true
+	subscription := eventsapi.EventSubscription{
+		Events: []eventsapi.CAPIFEvent{
+			eventsapi.CAPIFEventSERVICEAPIAVAILABLE,
+		},
+		NotificationDestination: common29122.Uri("http://golang.cafe/"),
+		WebsockNotifConfig: &common29122.WebsockNotifConfig{
+			RequestWebsocketUri: &requestWebsocketUri,
+		},
+	}
+	result := testutil.NewRequest().Post("/subscriberId/subscriptions").WithJsonBody(subscription).Go(t, requestHandler)
+	assert.Equal(t, http.StatusCreated, result.Code())
+	var resultSubscription eventsapi.EventSubscription
+	err := result.UnmarshalBodyToObject(&resultSubscription)
+	assert.NoError(t, err, "error unmarshaling response")
+	websocketUri := string(*resultSubscription.WebsockNotifConfig.WebsocketUri)
+	assert.Regexp(t, "^ws://example.com"+websocketnotifier.SocketsPath+"/", websocketUri)
+
+	conn, err := websocket.Dial(strings.Replace(websocketUri, "example.com", strings.TrimPrefix(socketServer.URL, "http://"), 1), "", socketServer.URL)
+	if err != nil {
+		t.Fatalf("Unable to connect to websocket due to %s", err)
+	}
+	defer conn.Close()
+
+	apiIds := []string{"apiId"}
+	newEvent := eventsapi.EventNotification{
+		EventDetail: &eventsapi.CAPIFEventDetail{
+			ApiIds: &apiIds,
+		},
+		Events: eventsapi.CAPIFEventSERVICEAPIAVAILABLE,
+	}
+	go func() {
+		serviceUnderTest.GetNotificationChannel() <- newEvent
+	}()
+
+	var receivedEvent eventsapi.EventNotification
+	assert.NoError(t, conn.SetReadDeadline(time.Now().Add(1*time.Second)))
+	assert.NoError(t, websocket.JSON.Receive(conn, &receivedEvent))
+	newEvent.SubscriptionId = path.Base(result.Recorder.Header().Get(echo.HeaderLocation))
+	assert.Equal(t, newEvent, receivedEvent)
+}
+
 func TestMatchEventType(t *testing.T) {
 	notificationUrl := "url"
 	subId := "sub1"
-	serviceUnderTest := NewEventService(nil)
+	serviceUnderTest := NewEventService(nil, websocketnotifier.NewWebSocketNotifier(), storagetest.NewStore())
 	serviceUnderTest.addSubscription(subId, eventsapi.EventSubscription{
 		Events: []eventsapi.CAPIFEvent{
 			eventsapi.CAPIFEventSERVICEAPIAVAILABLE,
@@ -203,7 +506,7 @@ func TestMatchEventTypeAndFilters(t *testing.T) {
 	invokerIds := []string{"invokerId"}
 	aefId := "aefId"
 	aefIds := []string{aefId}
-	serviceUnderTest := NewEventService(nil)
+	serviceUnderTest := NewEventService(nil, websocketnotifier.NewWebSocketNotifier(), storagetest.NewStore())
 	serviceUnderTest.addSubscription(subId, eventsapi.EventSubscription{
 		Events: []eventsapi.CAPIFEvent{
 			eventsapi.CAPIFEventSERVICEAPIAVAILABLE,
@@ -289,6 +592,87 @@ func TestMatchEventTypeAndFilters(t *testing.T) {
 	assert.Len(t, matchingSubs, 1)
 }
 
+func TestMatchInvocationEventOnAefIds(t *testing.T) {
+	subId := "sub1"
+	aefIds := []string{"aefId"}
+	serviceUnderTest := NewEventService(nil, websocketnotifier.NewWebSocketNotifier(), storagetest.NewStore())
+	serviceUnderTest.addSubscription(subId, eventsapi.EventSubscription{
+		Events: []eventsapi.CAPIFEvent{
+			eventsapi.CAPIFEventSERVICEAPIINVOCATIONFAILURE,
+		},
+		EventFilters: &[]eventsapi.CAPIFEventFilter{
+			{
+				AefIds: &aefIds,
+			},
+		},
+	})
+
+	invocationLogs := []loggingapi.InvocationLog{
+		{
+			AefId:        "aefId",
+			ApiInvokerId: "invokerId",
+		},
+	}
+	event := eventsapi.EventNotification{
+		EventDetail: &eventsapi.CAPIFEventDetail{
+			InvocationLogs: &invocationLogs,
+		},
+		Events: eventsapi.CAPIFEventSERVICEAPIINVOCATIONFAILURE,
+	}
+	matchingSubs := serviceUnderTest.getMatchingSubs(event)
+	assert.Len(t, matchingSubs, 1)
+	assert.Equal(t, subId, matchingSubs[0])
+
+	invocationLogs[0].AefId = "otherAefId"
+	matchingSubs = serviceUnderTest.getMatchingSubs(event)
+	assert.Len(t, matchingSubs, 0)
+}
+
+func TestMatchProviderEventOnAefIds(t *testing.T) {
+	subId := "sub1"
+	aefIds := []string{"aefId"}
+	serviceUnderTest := NewEventService(nil, websocketnotifier.NewWebSocketNotifier(), storagetest.NewStore())
+	serviceUnderTest.addSubscription(subId, eventsapi.EventSubscription{
+		Events: []eventsapi.CAPIFEvent{
+			eventsapi.CAPIFEventAPIPROVIDERUPDATED,
+		},
+		EventFilters: &[]eventsapi.CAPIFEventFilter{
+			{
+				AefIds: &aefIds,
+			},
+		},
+	})
+
+	aefId := "aefId"
+	apfId := "apfId"
+	functions := []providermanagementapi.APIProviderFunctionDetails{
+		{
+			ApiProvFuncId:   &apfId,
+			ApiProvFuncRole: providermanagementapi.ApiProviderFuncRoleAPF,
+		},
+		{
+			ApiProvFuncId:   &aefId,
+			ApiProvFuncRole: providermanagementapi.ApiProviderFuncRoleAEF,
+		},
+	}
+	event := eventsapi.EventNotification{
+		EventDetail: &eventsapi.CAPIFEventDetail{
+			ApiProviderDomInfo: &providermanagementapi.APIProviderEnrolmentDetails{
+				ApiProvFuncs: &functions,
+			},
+		},
+		Events: eventsapi.CAPIFEventAPIPROVIDERUPDATED,
+	}
+	matchingSubs := serviceUnderTest.getMatchingSubs(event)
+	assert.Len(t, matchingSubs, 1)
+	assert.Equal(t, subId, matchingSubs[0])
+
+	otherAefId := "otherAefId"
+	functions[1].ApiProvFuncId = &otherAefId
+	matchingSubs = serviceUnderTest.getMatchingSubs(event)
+	assert.Len(t, matchingSubs, 0)
+}
+
 func getEcho(client restclient.HTTPClient) (*EventService, *echo.Echo) {
 	swagger, err := eventsapi.GetSwagger()
 	if err != nil {
@@ -298,7 +682,7 @@ func getEcho(client restclient.HTTPClient) (*EventService, *echo.Echo) {
 
 	swagger.Servers = nil
 
-	es := NewEventService(client)
+	es := NewEventService(client, websocketnotifier.NewWebSocketNotifier(), storagetest.NewStore())
 
 	e := echo.New()
 	e.Use(echomiddleware.Logger())
@@ -308,6 +692,24 @@ func getEcho(client restclient.HTTPClient) (*EventService, *echo.Echo) {
 	return es, e
 }
 
+// The subscriber operations are validated against the specification they are added to.
+func getSubscriptionsEcho(es *EventService) *echo.Echo {
+	swagger, err := eventsapi.GetSwagger()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading swagger spec\n: %s", err)
+		os.Exit(1)
+	}
+	AddSubscriberOperations(swagger)
+
+	e := echo.New()
+	group := validation.NewGroup(e, swagger, "/capif-events/v1", validation.ResponseValidationReject)
+	group.GET("/:subscriberId/subscriptions", es.GetSubscriberIdSubscriptions)
+	group.GET("/:subscriberId/subscriptions/:subscriptionId", es.GetSubscriberIdSubscriptionsSubscriptionId)
+	group.PUT("/:subscriberId/subscriptions/:subscriptionId", es.PutSubscriberIdSubscriptionsSubscriptionId)
+	group.PATCH("/:subscriberId/subscriptions/:subscriptionId", es.PatchSubscriberIdSubscriptionsSubscriptionId)
+	return e
+}
+
 type RoundTripFunc func(req *http.Request) *http.Response
 
 func (f RoundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
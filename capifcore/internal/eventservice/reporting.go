@@ -0,0 +1,226 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package eventservice
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"oransc.org/nonrtric/capifcore/internal/common"
+	"oransc.org/nonrtric/capifcore/internal/common29571"
+	"oransc.org/nonrtric/capifcore/internal/eventsapi"
+)
+
+// Events buffered while the notifications of a subscription are muted. When there are more, the oldest ones are
+// dropped.
+const maxMutedEvents = 1000
+
+// Reports the events of a subscription as requested by the reporting information of the subscription.
+type reporter struct {
+	periodic bool
+	// The subscription is terminated when this many reports have been sent, zero means no limit.
+	maxReports  int
+	reports     int
+	muted       bool
+	batch       []eventsapi.EventNotification
+	mutedEvents []eventsapi.EventNotification
+	terminated  bool
+	ticker      *time.Ticker
+	expiryTimer *time.Timer
+	stop        chan struct{}
+}
+
+func newReporter(eventReq *common.ReportingInformation) *reporter {
+	r := reporter{
+		stop: make(chan struct{}),
+	}
+	if eventReq == nil {
+		return &r
+	}
+
+	if eventReq.NotifMethod != nil {
+		switch *eventReq.NotifMethod {
+		case common.NotificationMethodPERIODIC:
+			r.periodic = true
+		case common.NotificationMethodONETIME:
+			r.maxReports = 1
+		}
+	}
+	if eventReq.MaxReportNbr != nil && *eventReq.MaxReportNbr > 0 && r.maxReports == 0 {
+		r.maxReports = int(*eventReq.MaxReportNbr)
+	}
+	if eventReq.NotifFlag != nil {
+		r.muted = *eventReq.NotifFlag != common29571.NotificationFlagACTIVATE
+	}
+	return &r
+}
+
+// Starts reporting the events of the subscription. When the subscription is updated, the reports sent so far and the
+// events not yet reported are kept. Events buffered while muted are reported if the updated subscription activates or
+// retrieves the notifications.
+// Must be called with the lock held.
+func (es *EventService) startReporting(subId string, subscription eventsapi.EventSubscription) {
+	r := newReporter(subscription.EventReq)
+	if previous, ok := es.reporters[subId]; ok {
+		previous.terminate()
+		r.reports = previous.reports
+		r.batch = previous.batch
+		r.mutedEvents = previous.mutedEvents
+	}
+	es.reporters[subId] = r
+
+	eventReq := subscription.EventReq
+	if eventReq == nil {
+		return
+	}
+	if r.periodic {
+		r.ticker = time.NewTicker(time.Duration(*eventReq.RepPeriod) * time.Second)
+		go es.reportPeriodically(subId, r)
+	}
+	if eventReq.MonDur != nil {
+		r.expiryTimer = time.AfterFunc(time.Until(time.Time(*eventReq.MonDur)), func() {
+			es.expireSubscription(subId, r)
+		})
+	}
+	if eventReq.NotifFlag != nil && *eventReq.NotifFlag != common29571.NotificationFlagDEACTIVATE {
+		es.reportMutedEvents(subId, r)
+	}
+}
+
+// Must be called with the lock held.
+func (es *EventService) stopReporting(subId string) {
+	if r, ok := es.reporters[subId]; ok {
+		r.terminate()
+		delete(es.reporters, subId)
+	}
+}
+
+func (r *reporter) terminate() {
+	if r.terminated {
+		return
+	}
+	r.terminated = true
+	if r.ticker != nil {
+		r.ticker.Stop()
+	}
+	if r.expiryTimer != nil {
+		r.expiryTimer.Stop()
+	}
+	close(r.stop)
+}
+
+// Creates the next report of the subscription. The reporting is terminated when the maximum number of reports is
+// reached, and the subscription is then removed once the report has been delivered.
+func (r *reporter) nextReport(events []eventsapi.EventNotification) report {
+	r.reports++
+	last := r.maxReports > 0 && r.reports >= r.maxReports
+	if last {
+		r.terminate()
+	}
+	return report{
+		events:   events,
+		periodic: r.periodic,
+		last:     last,
+	}
+}
+
+func (es *EventService) reportEvent(event eventsapi.EventNotification, subId string) {
+	es.lock.Lock()
+	defer es.lock.Unlock()
+
+	r, ok := es.reporters[subId]
+	if !ok || r.terminated {
+		return
+	}
+	switch {
+	case r.muted:
+		r.mutedEvents = append(r.mutedEvents, event)
+		if len(r.mutedEvents) > maxMutedEvents {
+			log.Warnf("Dropping muted event for subscription %s as too many events are buffered", subId)
+			r.mutedEvents = r.mutedEvents[1:]
+		}
+	case r.periodic:
+		r.batch = append(r.batch, event)
+	default:
+		es.queueReport(r.nextReport([]eventsapi.EventNotification{event}), subId)
+	}
+}
+
+// Must be called with the lock held.
+func (es *EventService) reportMutedEvents(subId string, r *reporter) {
+	events := r.mutedEvents
+	r.mutedEvents = nil
+	if len(events) == 0 {
+		return
+	}
+	if r.periodic {
+		es.queueReport(r.nextReport(events), subId)
+		return
+	}
+	for _, event := range events {
+		if r.terminated {
+			return
+		}
+		es.queueReport(r.nextReport([]eventsapi.EventNotification{event}), subId)
+	}
+}
+
+func (es *EventService) reportPeriodically(subId string, r *reporter) {
+	for {
+		select {
+		case <-r.ticker.C:
+			es.reportBatch(subId, r)
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+func (es *EventService) reportBatch(subId string, r *reporter) {
+	es.lock.Lock()
+	defer es.lock.Unlock()
+
+	if r.terminated || r.muted || len(r.batch) == 0 {
+		return
+	}
+	es.queueReport(r.nextReport(r.batch), subId)
+	r.batch = nil
+}
+
+// Removes the subscription when its monitoring duration has passed. The events of the current reporting period are
+// reported before the subscription is removed.
+func (es *EventService) expireSubscription(subId string, r *reporter) {
+	es.lock.Lock()
+	defer es.lock.Unlock()
+
+	if r.terminated {
+		return
+	}
+	log.Debugf("Subscription %s has expired", subId)
+	if r.periodic && !r.muted && len(r.batch) > 0 {
+		finalReport := r.nextReport(r.batch)
+		finalReport.last = true
+		r.terminate()
+		es.queueReport(finalReport, subId)
+		return
+	}
+	es.removeSubscription(subId)
+}
@@ -0,0 +1,228 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package eventservice
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/deepmap/oapi-codegen/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"oransc.org/nonrtric/capifcore/internal/common"
+	"oransc.org/nonrtric/capifcore/internal/common29122"
+	"oransc.org/nonrtric/capifcore/internal/common29571"
+	"oransc.org/nonrtric/capifcore/internal/eventsapi"
+)
+
+func TestRegisterSubscriptionWithEventReq(t *testing.T) {
+	serviceUnderTest, requestHandler := getEcho(nil)
+	monDur := common29571.DateTime(time.Now().Add(1 * time.Hour).Truncate(time.Second).UTC())
+	periodic := common.NotificationMethodPERIODIC
+	repPeriod := common29571.DurationSec(60)
+	subscription := getSubscription(&common.ReportingInformation{
+		MonDur:      &monDur,
+		NotifMethod: &periodic,
+		RepPeriod:   &repPeriod,
+	})
+
+	result := testutil.NewRequest().Post("/subscriberId/subscriptions").WithJsonBody(subscription).Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusCreated, result.Code())
+	var resultSubscription eventsapi.EventSubscription
+	err := result.UnmarshalBodyToObject(&resultSubscription)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, subscription, resultSubscription)
+
+	// A periodic subscription must have a reporting period
+	subscription.EventReq.RepPeriod = nil
+	result = testutil.NewRequest().Post("/subscriberId/subscriptions").WithJsonBody(subscription).Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusBadRequest, result.Code())
+	var problemDetails common29122.ProblemDetails
+	err = result.UnmarshalBodyToObject(&problemDetails)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Contains(t, *problemDetails.Detail, "repPeriod")
+	assert.Len(t, serviceUnderTest.subscriptions, 1)
+}
+
+func TestPeriodicReporting(t *testing.T) {
+	clientMock, notifications := getNotificationClient(t)
+	serviceUnderTest, _ := getEcho(clientMock)
+	periodic := common.NotificationMethodPERIODIC
+	repPeriod := common29571.DurationSec(1)
+	serviceUnderTest.addSubscription("sub1", getSubscription(&common.ReportingInformation{
+		NotifMethod: &periodic,
+		RepPeriod:   &repPeriod,
+	}))
+
+	serviceUnderTest.handleEvent(eventsapi.EventNotification{Events: eventsapi.CAPIFEventSERVICEAPIAVAILABLE})
+	serviceUnderTest.handleEvent(eventsapi.EventNotification{Events: eventsapi.CAPIFEventSERVICEAPIUNAVAILABLE})
+
+	body := waitForNotification(t, notifications, 2*time.Second)
+	var events []eventsapi.EventNotification
+	assert.NoError(t, json.Unmarshal(body, &events))
+	assert.Equal(t, []eventsapi.EventNotification{
+		{Events: eventsapi.CAPIFEventSERVICEAPIAVAILABLE, SubscriptionId: "sub1"},
+		{Events: eventsapi.CAPIFEventSERVICEAPIUNAVAILABLE, SubscriptionId: "sub1"},
+	}, events)
+	assertNoNotification(t, notifications)
+}
+
+func TestSubscriptionIsRemovedAfterMaxReports(t *testing.T) {
+	clientMock, notifications := getNotificationClient(t)
+	serviceUnderTest, _ := getEcho(clientMock)
+	maxReportNbr := common29571.Uinteger(2)
+	serviceUnderTest.addSubscription("sub1", getSubscription(&common.ReportingInformation{
+		MaxReportNbr: &maxReportNbr,
+	}))
+	oneTime := common.NotificationMethodONETIME
+	serviceUnderTest.addSubscription("sub2", getSubscription(&common.ReportingInformation{
+		NotifMethod: &oneTime,
+	}))
+
+	for i := 0; i < 3; i++ {
+		serviceUnderTest.handleEvent(eventsapi.EventNotification{Events: eventsapi.CAPIFEventSERVICEAPIAVAILABLE})
+	}
+
+	subscriptionIds := []string{}
+	for i := 0; i < 3; i++ {
+		var event eventsapi.EventNotification
+		assert.NoError(t, json.Unmarshal(waitForNotification(t, notifications, 1*time.Second), &event))
+		subscriptionIds = append(subscriptionIds, event.SubscriptionId)
+	}
+	assert.ElementsMatch(t, []string{"sub1", "sub1", "sub2"}, subscriptionIds)
+	assertNoNotification(t, notifications)
+	assert.Eventually(t, func() bool {
+		return serviceUnderTest.getSubscription("sub1") == nil && serviceUnderTest.getSubscription("sub2") == nil
+	}, 1*time.Second, 10*time.Millisecond)
+}
+
+func TestSubscriptionExpires(t *testing.T) {
+	clientMock, notifications := getNotificationClient(t)
+	serviceUnderTest, _ := getEcho(clientMock)
+	monDur := common29571.DateTime(time.Now().Add(200 * time.Millisecond))
+	serviceUnderTest.addSubscription("sub1", getSubscription(&common.ReportingInformation{
+		MonDur: &monDur,
+	}))
+	periodic := common.NotificationMethodPERIODIC
+	repPeriod := common29571.DurationSec(60)
+	serviceUnderTest.addSubscription("sub2", getSubscription(&common.ReportingInformation{
+		MonDur:      &monDur,
+		NotifMethod: &periodic,
+		RepPeriod:   &repPeriod,
+	}))
+
+	serviceUnderTest.handleEvent(eventsapi.EventNotification{Events: eventsapi.CAPIFEventSERVICEAPIAVAILABLE})
+	waitForNotification(t, notifications, 1*time.Second)
+
+	// The events of the current period are reported when a periodic subscription expires
+	var events []eventsapi.EventNotification
+	assert.NoError(t, json.Unmarshal(waitForNotification(t, notifications, 1*time.Second), &events))
+	assert.Len(t, events, 1)
+	assert.Equal(t, "sub2", events[0].SubscriptionId)
+
+	assert.Eventually(t, func() bool {
+		return serviceUnderTest.getSubscription("sub1") == nil && serviceUnderTest.getSubscription("sub2") == nil
+	}, 1*time.Second, 10*time.Millisecond)
+	assert.Empty(t, serviceUnderTest.reporters)
+}
+
+func TestMutedEventsAreReportedWhenNotificationsAreActivated(t *testing.T) {
+	clientMock, notifications := getNotificationClient(t)
+	serviceUnderTest, _ := getEcho(clientMock)
+	deactivate := common29571.NotificationFlagDEACTIVATE
+	subscription := getSubscription(&common.ReportingInformation{
+		NotifFlag: &deactivate,
+	})
+	serviceUnderTest.addSubscription("sub1", subscription)
+
+	serviceUnderTest.handleEvent(eventsapi.EventNotification{Events: eventsapi.CAPIFEventSERVICEAPIAVAILABLE})
+	assertNoNotification(t, notifications)
+
+	// Retrieval reports the buffered events and then mutes the notifications again
+	retrieval := common29571.NotificationFlagRETRIEVAL
+	subscription.EventReq = &common.ReportingInformation{NotifFlag: &retrieval}
+	serviceUnderTest.addSubscription("sub1", subscription)
+	var event eventsapi.EventNotification
+	assert.NoError(t, json.Unmarshal(waitForNotification(t, notifications, 1*time.Second), &event))
+	assert.Equal(t, eventsapi.CAPIFEventSERVICEAPIAVAILABLE, event.Events)
+
+	serviceUnderTest.handleEvent(eventsapi.EventNotification{Events: eventsapi.CAPIFEventSERVICEAPIUNAVAILABLE})
+	assertNoNotification(t, notifications)
+
+	activate := common29571.NotificationFlagACTIVATE
+	subscription.EventReq = &common.ReportingInformation{NotifFlag: &activate}
+	serviceUnderTest.addSubscription("sub1", subscription)
+	assert.NoError(t, json.Unmarshal(waitForNotification(t, notifications, 1*time.Second), &event))
+	assert.Equal(t, eventsapi.CAPIFEventSERVICEAPIUNAVAILABLE, event.Events)
+
+	serviceUnderTest.handleEvent(eventsapi.EventNotification{Events: eventsapi.CAPIFEventSERVICEAPIUPDATE})
+	assert.NoError(t, json.Unmarshal(waitForNotification(t, notifications, 1*time.Second), &event))
+	assert.Equal(t, eventsapi.CAPIFEventSERVICEAPIUPDATE, event.Events)
+}
+
+func getSubscription(eventReq *common.ReportingInformation) eventsapi.EventSubscription {
+	return eventsapi.EventSubscription{
+		Events: []eventsapi.CAPIFEvent{
+			eventsapi.CAPIFEventSERVICEAPIAVAILABLE,
+			eventsapi.CAPIFEventSERVICEAPIUNAVAILABLE,
+			eventsapi.CAPIFEventSERVICEAPIUPDATE,
+		},
+		NotificationDestination: common29122.Uri("http://golang.cafe/"),
+		EventReq:                eventReq,
+	}
+}
+
+func getNotificationClient(t *testing.T) (*http.Client, chan []byte) {
+	notifications := make(chan []byte, 10)
+	clientMock := NewTestClient(func(req *http.Request) *http.Response {
+		body, _ := io.ReadAll(req.Body)
+		notifications <- body
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(bytes.NewBufferString(`OK`)),
+			Header:     make(http.Header), // Must be set to non-nil value or it panics
+		}
+	})
+	return clientMock, notifications
+}
+
+func waitForNotification(t *testing.T, notifications chan []byte, timeout time.Duration) []byte {
+	select {
+	case body := <-notifications:
+		return body
+	case <-time.After(timeout):
+		assert.Fail(t, "No notification sent")
+		return []byte("null")
+	}
+}
+
+func assertNoNotification(t *testing.T, notifications chan []byte) {
+	select {
+	case body := <-notifications:
+		assert.Fail(t, "Unexpected notification sent", string(body))
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -22,21 +22,38 @@
 package invokermanagement
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"path"
+	"strings"
 	"sync"
 
+	"oransc.org/nonrtric/capifcore/internal/accesscontrolpolicyservice"
+	"oransc.org/nonrtric/capifcore/internal/certauthority"
 	"oransc.org/nonrtric/capifcore/internal/eventsapi"
 	"oransc.org/nonrtric/capifcore/internal/keycloak"
 
 	"oransc.org/nonrtric/capifcore/internal/common29122"
 	invokerapi "oransc.org/nonrtric/capifcore/internal/invokermanagementapi"
 	"oransc.org/nonrtric/capifcore/internal/publishservice"
-
+	publishapi "oransc.org/nonrtric/capifcore/internal/publishserviceapi"
+	"oransc.org/nonrtric/capifcore/internal/restclient"
+	"oransc.org/nonrtric/capifcore/internal/storage"
+	"oransc.org/nonrtric/capifcore/internal/websocketnotifier"
+	"oransc.org/nonrtric/capifcore/problemdetails"
+	"oransc.org/nonrtric/capifcore/validation"
+
+	"github.com/google/uuid"
 	echo "github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
 )
 
+const onboardedInvokersBucket = "onboardedInvokers"
+
+var errCertificateNotIssued = errors.New("unable to issue certificate")
+
 //go:generate mockery --name InvokerRegister
 type InvokerRegister interface {
 	// Checks if the invoker is registered.
@@ -45,29 +62,81 @@ type InvokerRegister interface {
 	// Verifies that the provided secret is the invoker's registered secret.
 	// Returns true if the provided secret is the registered invoker's secret, false otherwise.
 	VerifyInvokerSecret(invokerId, secret string) bool
-	// Gets the provided invoker's registered APIs.
-	// Returns a list of all the invoker's registered APIs.
+	// Gets the APIs the provided invoker has been granted, as they are currently published.
+	// Returns a list of the invoker's granted APIs that are still published, or nil if the invoker is not registered.
 	GetInvokerApiList(invokerId string) *invokerapi.APIList
+	// Gets the client certificate CAPIF core has issued for the provided invoker.
+	// Returns the PEM encoded certificate, or an empty string if no certificate has been issued for the invoker.
+	GetInvokerCertificate(invokerId string) string
+}
+
+//go:generate mockery --name OffboardingHandler
+type OffboardingHandler interface {
+	// Removes what is kept for the provided invoker when it is offboarded.
+	RemoveInvoker(invokerId string)
+}
+
+//go:generate mockery --name AccessRequester
+type AccessRequester interface {
+	// Requests access for the invoker to the APIs of the provided list that it has not been granted.
+	RequestApiAccess(invokerId string, apiList invokerapi.APIList)
 }
 
 type InvokerManager struct {
-	onboardedInvokers map[string]invokerapi.APIInvokerEnrolmentDetails
-	publishRegister   publishservice.PublishRegister
-	nextId            int64
-	keycloak          keycloak.AccessManagement
-	eventChannel      chan<- eventsapi.EventNotification
-	lock              sync.Mutex
+	onboardedInvokers           map[string]invokerapi.APIInvokerEnrolmentDetails
+	publishRegister             publishservice.PublishRegister
+	accessControlPolicyRegister accesscontrolpolicyservice.AccessControlPolicyRegister
+	nextId                      int64
+	keycloak                    keycloak.AccessManagement
+	invokerRealm                string
+	certificateIssuer           certauthority.CertificateIssuer
+	client                      restclient.HTTPClient
+	notifier                    *websocketnotifier.WebSocketNotifier
+	eventChannel                chan<- eventsapi.EventNotification
+	offboardingHandlers         []OffboardingHandler
+	accessRequesters            []AccessRequester
+	store                       storage.Store
+	lock                        sync.Mutex
 }
 
 // Creates a manager that implements both the InvokerRegister and the invokermanagementapi.ServerInterface interfaces.
-func NewInvokerManager(publishRegister publishservice.PublishRegister, km keycloak.AccessManagement, eventChannel chan<- eventsapi.EventNotification) *InvokerManager {
-	return &InvokerManager{
-		onboardedInvokers: make(map[string]invokerapi.APIInvokerEnrolmentDetails),
-		publishRegister:   publishRegister,
-		nextId:            1000,
-		keycloak:          km,
-		eventChannel:      eventChannel,
+// Invokers onboarded in the provided store are loaded at creation.
+// Notifications are sent to the invokers through the provided client, or over a websocket kept by the provided notifier
+// if the invoker requests one.
+// If a certificate issuer is provided, the invokers get client certificates for their public keys.
+// The invokers are added as clients in the provided realm of the authorization server.
+func NewInvokerManager(publishRegister publishservice.PublishRegister, accessControlPolicyRegister accesscontrolpolicyservice.AccessControlPolicyRegister, km keycloak.AccessManagement, invokerRealm string, certificateIssuer certauthority.CertificateIssuer, client restclient.HTTPClient, notifier *websocketnotifier.WebSocketNotifier, eventChannel chan<- eventsapi.EventNotification, store storage.Store) *InvokerManager {
+	im := &InvokerManager{
+		onboardedInvokers:           make(map[string]invokerapi.APIInvokerEnrolmentDetails),
+		publishRegister:             publishRegister,
+		accessControlPolicyRegister: accessControlPolicyRegister,
+		nextId:                      1000,
+		keycloak:                    km,
+		invokerRealm:                invokerRealm,
+		certificateIssuer:           certificateIssuer,
+		client:                      client,
+		notifier:                    notifier,
+		eventChannel:                eventChannel,
+		store:                       store,
 	}
+	if err := storage.Load(store, onboardedInvokersBucket, im.onboardedInvokers); err != nil {
+		log.Errorf("Unable to load onboarded invokers due to %s", err)
+	}
+	for _, invoker := range im.onboardedInvokers {
+		notifier.RestoreSocket(invoker.WebsockNotifConfig)
+	}
+	return im
+}
+
+// Adds a handler that is called when an invoker is offboarded.
+func (im *InvokerManager) AddOffboardingHandler(handler OffboardingHandler) {
+	im.offboardingHandlers = append(im.offboardingHandlers, handler)
+}
+
+// Adds a requester that requests access to the APIs that an invoker asks for in its API list when it is onboarded or
+// updated.
+func (im *InvokerManager) AddAccessRequester(requester AccessRequester) {
+	im.accessRequesters = append(im.accessRequesters, requester)
 }
 
 func (im *InvokerManager) IsInvokerRegistered(invokerId string) bool {
@@ -83,47 +152,128 @@ func (im *InvokerManager) VerifyInvokerSecret(invokerId, secret string) bool {
 	defer im.lock.Unlock()
 
 	verified := false
-	if invoker, registered := im.onboardedInvokers[invokerId]; registered {
+	if invoker, registered := im.onboardedInvokers[invokerId]; registered && invoker.OnboardingInformation.OnboardingSecret != nil {
 		verified = *invoker.OnboardingInformation.OnboardingSecret == secret
 	}
 	return verified
 }
 
-func (im *InvokerManager) GetInvokerApiList(invokerId string) *invokerapi.APIList {
-	var apiList invokerapi.APIList = im.publishRegister.GetAllPublishedServices()
+func (im *InvokerManager) GetInvokerCertificate(invokerId string) string {
 	im.lock.Lock()
 	defer im.lock.Unlock()
+
+	if invoker, registered := im.onboardedInvokers[invokerId]; registered && invoker.OnboardingInformation.ApiInvokerCertificate != nil {
+		return *invoker.OnboardingInformation.ApiInvokerCertificate
+	}
+	return ""
+}
+
+func (im *InvokerManager) GetInvokerApiList(invokerId string) *invokerapi.APIList {
+	im.lock.Lock()
 	invoker, ok := im.onboardedInvokers[invokerId]
-	if ok {
-		invoker.ApiList = &apiList
-		return &apiList
+	var grantedApis invokerapi.APIList
+	if ok && invoker.ApiList != nil {
+		grantedApis = append(grantedApis, *invoker.ApiList...)
 	}
-	return nil
+	im.lock.Unlock()
+	if !ok {
+		return nil
+	}
+
+	// The granted APIs are looked up again so that the invoker gets the APIs as they are currently published.
+	apiList := invokerapi.APIList{}
+	for _, grantedApi := range grantedApis {
+		if grantedApi.ApiId == nil {
+			continue
+		}
+		if publishedApi := im.publishRegister.GetPublishedService(*grantedApi.ApiId); publishedApi != nil {
+			apiList = append(apiList, *publishedApi)
+		}
+	}
+	return &apiList
+}
+
+// Adds the API to the invoker's API list, unless the invoker has already been granted the API.
+func (im *InvokerManager) GrantApiAccess(invokerId string, api publishapi.ServiceAPIDescription) {
+	im.lock.Lock()
+	invoker, ok := im.onboardedInvokers[invokerId]
+	if !ok || api.ApiId == nil || isApiGranted(invoker, *api.ApiId) {
+		im.lock.Unlock()
+		return
+	}
+	apiList := invokerapi.APIList{}
+	if invoker.ApiList != nil {
+		apiList = append(apiList, *invoker.ApiList...)
+	}
+	apiList = append(apiList, api)
+	invoker.ApiList = &apiList
+	im.onboardedInvokers[invokerId] = invoker
+	im.storeInvoker(invoker)
+	im.lock.Unlock()
+
+	go im.sendEvent(invokerId, eventsapi.CAPIFEventAPIINVOKERUPDATED)
+}
+
+func isApiGranted(invoker invokerapi.APIInvokerEnrolmentDetails, apiId string) bool {
+	if invoker.ApiList == nil {
+		return false
+	}
+	for _, api := range *invoker.ApiList {
+		if api.ApiId != nil && *api.ApiId == apiId {
+			return true
+		}
+	}
+	return false
 }
 
 // Creates a new individual API Invoker profile.
+// If the request prefers an asynchronous response, the request is accepted and the invoker is onboarded in the
+// background. The result is then sent as an onboarding notification to the invoker's notification destination.
 func (im *InvokerManager) PostOnboardedInvokers(ctx echo.Context) error {
 	errMsg := "Unable to onboard invoker due to %s"
 
 	newInvoker, err := getInvokerFromRequest(ctx)
 	if err != nil {
-		return sendCoreError(ctx, http.StatusBadRequest, fmt.Sprintf(errMsg, err))
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseInvalidMsgFormat, fmt.Sprintf(errMsg, err))
 	}
 
-	if err = im.isInvokerOnboarded(newInvoker); err != nil {
-		return sendCoreError(ctx, http.StatusForbidden, fmt.Sprintf(errMsg, err))
+	im.lock.Lock()
+	err = im.isInvokerOnboarded(newInvoker)
+	im.lock.Unlock()
+	if err != nil {
+		return problemdetails.Send(ctx, http.StatusForbidden, problemdetails.CauseModificationNotAllowed, fmt.Sprintf(errMsg, err))
 	}
 
 	if err = im.validateInvoker(newInvoker); err != nil {
-		return sendCoreError(ctx, http.StatusBadRequest, fmt.Sprintf(errMsg, err))
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseMandatoryIeIncorrect, fmt.Sprintf(errMsg, err))
 	}
 
-	im.prepareNewInvoker(&newInvoker)
+	im.notifier.SetUpSocket(ctx, newInvoker.WebsockNotifConfig)
+
+	scheme := ctx.Scheme()
+	uri := ctx.Request().Host + ctx.Request().URL.String()
+	if isAsyncResponsePreferred(ctx) {
+		go im.onboardInvokerAsync(newInvoker, scheme, uri)
+		return ctx.NoContent(http.StatusAccepted)
+	}
+
+	requestedApiList := newInvoker.ApiList
+	if err = im.prepareNewInvoker(&newInvoker); err != nil {
+		if errors.Is(err, errCertificateNotIssued) {
+			return problemdetails.Send(ctx, http.StatusInternalServerError, problemdetails.CauseSystemFailure, fmt.Sprintf(errMsg, err))
+		}
+		return problemdetails.Send(ctx, http.StatusForbidden, problemdetails.CauseModificationNotAllowed, fmt.Sprintf(errMsg, err))
+	}
+	im.requestApiAccess(*newInvoker.ApiInvokerId, requestedApiList)
 
 	go im.sendEvent(*newInvoker.ApiInvokerId, eventsapi.CAPIFEventAPIINVOKERONBOARDED)
 
-	uri := ctx.Request().Host + ctx.Request().URL.String()
-	ctx.Response().Header().Set(echo.HeaderLocation, ctx.Scheme()+`://`+path.Join(uri, *newInvoker.ApiInvokerId))
+	location := getInvokerLocation(scheme, uri, *newInvoker.ApiInvokerId)
+	ctx.Response().Header().Set(echo.HeaderLocation, location)
+
+	if isTestNotificationRequested(newInvoker) {
+		go im.sendTestNotification(newInvoker, location)
+	}
 
 	err = ctx.JSON(http.StatusCreated, newInvoker)
 	if err != nil {
@@ -134,6 +284,97 @@ func (im *InvokerManager) PostOnboardedInvokers(ctx echo.Context) error {
 	return nil
 }
 
+// Checks if the request has the "Prefer: respond-async" header, see RFC 7240.
+func isAsyncResponsePreferred(ctx echo.Context) bool {
+	for _, preference := range ctx.Request().Header.Values("Prefer") {
+		if strings.Contains(preference, "respond-async") {
+			return true
+		}
+	}
+	return false
+}
+
+func (im *InvokerManager) onboardInvokerAsync(newInvoker invokerapi.APIInvokerEnrolmentDetails, scheme, uri string) {
+	requestedApiList := newInvoker.ApiList
+	if err := im.prepareNewInvoker(&newInvoker); err != nil {
+		log.Errorf("Unable to onboard invoker due to %s", err)
+		notification := invokerapi.OnboardingNotification{
+			ApiInvokerEnrolmentDetails: &newInvoker,
+			Result:                     false,
+		}
+		if err = im.postNotification(newInvoker.NotificationDestination, notification); err != nil {
+			log.Errorf("Unable to send failed onboarding notification due to %s", err)
+		}
+		return
+	}
+	im.requestApiAccess(*newInvoker.ApiInvokerId, requestedApiList)
+
+	go im.sendEvent(*newInvoker.ApiInvokerId, eventsapi.CAPIFEventAPIINVOKERONBOARDED)
+
+	location := getInvokerLocation(scheme, uri, *newInvoker.ApiInvokerId)
+	resourceLocation := common29122.Uri(location)
+	// The API list of the notification holds the granted APIs, which are none yet. The requested APIs are granted
+	// when their access requests are approved.
+	notification := invokerapi.OnboardingNotification{
+		ApiInvokerEnrolmentDetails: &newInvoker,
+		ApiList:                    newInvoker.ApiList,
+		ResourceLocation:           &resourceLocation,
+		Result:                     true,
+	}
+	// The invoker gets to know the URI of its websocket from this notification, so it is always sent to the
+	// notification destination.
+	if err := im.postNotification(newInvoker.NotificationDestination, notification); err != nil {
+		log.Errorf("Unable to send onboarding notification for invoker %s due to %s", *newInvoker.ApiInvokerId, err)
+	}
+
+	if isTestNotificationRequested(newInvoker) {
+		im.sendTestNotification(newInvoker, location)
+	}
+}
+
+// Requests access to the APIs of the API list that the invoker asks for, as they are only granted when the access
+// requests are approved, see GrantApiAccess.
+func (im *InvokerManager) requestApiAccess(invokerId string, apiList *invokerapi.APIList) {
+	if apiList == nil || len(*apiList) == 0 {
+		return
+	}
+	for _, requester := range im.accessRequesters {
+		requester.RequestApiAccess(invokerId, *apiList)
+	}
+}
+
+func getInvokerLocation(scheme, uri, invokerId string) string {
+	return scheme + `://` + path.Join(uri, invokerId)
+}
+
+func isTestNotificationRequested(invoker invokerapi.APIInvokerEnrolmentDetails) bool {
+	return invoker.RequestTestNotification != nil && *invoker.RequestTestNotification
+}
+
+// Sends a test notification to the invoker, so that it can verify that notifications reach it. The notification
+// links to the onboarded invoker resource.
+func (im *InvokerManager) sendTestNotification(invoker invokerapi.APIInvokerEnrolmentDetails, location string) {
+	notification := common29122.TestNotification{
+		Subscription: common29122.Link(location),
+	}
+	if socketId, ok := websocketnotifier.GetSocketId(invoker.WebsockNotifConfig); ok {
+		if err := im.notifier.Send(socketId, notification); err != nil {
+			log.Errorf("Unable to send test notification for invoker %s over websocket due to %s", *invoker.ApiInvokerId, err)
+		}
+		return
+	}
+	if err := im.postNotification(invoker.NotificationDestination, notification); err != nil {
+		log.Errorf("Unable to send test notification for invoker %s due to %s", *invoker.ApiInvokerId, err)
+	}
+}
+
+func (im *InvokerManager) postNotification(notificationDestination common29122.Uri, notification interface{}) error {
+	body, _ := json.Marshal(notification)
+	header := map[string]string{"Content-Type": restclient.ContentTypeJSON}
+	return restclient.Post(string(notificationDestination), body, header, im.client)
+}
+
+// Must be called with the lock held.
 func (im *InvokerManager) isInvokerOnboarded(newInvoker invokerapi.APIInvokerEnrolmentDetails) error {
 	for _, invoker := range im.onboardedInvokers {
 		if err := invoker.ValidateAlreadyOnboarded(newInvoker); err != nil {
@@ -143,47 +384,95 @@ func (im *InvokerManager) isInvokerOnboarded(newInvoker invokerapi.APIInvokerEnr
 	return nil
 }
 
-func (im *InvokerManager) prepareNewInvoker(newInvoker *invokerapi.APIInvokerEnrolmentDetails) {
-	var apiListRequestedServices invokerapi.APIList = nil
-	if newInvoker.ApiList != nil {
-		apiListRequestedServices = *newInvoker.ApiList
-	}
-	var allowedPublishedServices invokerapi.APIList = im.publishRegister.GetAllowedPublishedServices(apiListRequestedServices)
-	newInvoker.ApiList = &allowedPublishedServices
+// Onboards the new invoker. The invoker is checked again under the lock, as another invoker with the same public key
+// may have been onboarded since the request was checked.
+func (im *InvokerManager) prepareNewInvoker(newInvoker *invokerapi.APIInvokerEnrolmentDetails) error {
+	// A new invoker has not been granted any APIs, whatever it asks for. APIs are only granted through access requests,
+	// see requestApiAccess and GrantApiAccess.
+	newInvoker.ApiList = &invokerapi.APIList{}
 
 	im.lock.Lock()
 	defer im.lock.Unlock()
 
+	if err := im.isInvokerOnboarded(*newInvoker); err != nil {
+		return err
+	}
+
 	newInvoker.PrepareNewInvoker()
+	if err := im.issueCertificate(newInvoker, nil); err != nil {
+		return err
+	}
 
+	// The onboarding secret is given by CAPIF core, never by the invoker. It is replaced by the secret of the client
+	// in the authorization server, if there is one.
+	onboardingSecret := uuid.NewString()
+	newInvoker.OnboardingInformation.OnboardingSecret = &onboardingSecret
 	if im.keycloak != nil {
-		// The type assertion fails when unit testing from ServiceManager where we use Capif as a library, and we are not using Keycloak.
-		if _, ok := im.keycloak.(*keycloak.KeycloakManager); !ok {
-			// im.keycloak is not nil and its dynamic value is not nil.
-			im.addClientInKeycloak(newInvoker)
-        }
+		if err := im.addClientInKeycloak(newInvoker); err != nil {
+			log.Errorf("Unable to add client for invoker %s to Keycloak due to %s", *newInvoker.ApiInvokerId, err)
+		}
 	}
 
 	im.onboardedInvokers[*newInvoker.ApiInvokerId] = *newInvoker
+	im.storeInvoker(*newInvoker)
+	return nil
+}
+
+// Issues a client certificate for the public key of the invoker, if CAPIF core acts as a certificate authority. If the
+// registered invoker has the same public key, the invoker keeps the certificate of the registered invoker.
+// When the public key changes, the certificate of the registered invoker is replaced and is no longer accepted as
+// client certificate, see the clientauth package.
+func (im *InvokerManager) issueCertificate(invoker *invokerapi.APIInvokerEnrolmentDetails, registeredInvoker *invokerapi.APIInvokerEnrolmentDetails) error {
+	if im.certificateIssuer == nil {
+		return nil
+	}
+	publicKey := invoker.OnboardingInformation.ApiInvokerPublicKey
+	if registeredInvoker != nil && registeredInvoker.OnboardingInformation.ApiInvokerPublicKey == publicKey && registeredInvoker.OnboardingInformation.ApiInvokerCertificate != nil {
+		invoker.OnboardingInformation.ApiInvokerCertificate = registeredInvoker.OnboardingInformation.ApiInvokerCertificate
+		return nil
+	}
+
+	invoker.OnboardingInformation.ApiInvokerCertificate = nil
+	// The public key has already been validated
+	parsedKey, _ := certauthority.ParsePublicKey(publicKey)
+	certificate, err := im.certificateIssuer.IssueCertificate(parsedKey, *invoker.ApiInvokerId)
+	if err != nil {
+		return fmt.Errorf("%w, %s", errCertificateNotIssued, err)
+	}
+	invoker.OnboardingInformation.ApiInvokerCertificate = &certificate
+	return nil
 }
 
 func (im *InvokerManager) addClientInKeycloak(newInvoker *invokerapi.APIInvokerEnrolmentDetails) error {
-	if err := im.keycloak.AddClient(*newInvoker.ApiInvokerId, "invokerrealm"); err != nil {
+	if err := im.keycloak.AddClient(*newInvoker.ApiInvokerId, im.invokerRealm); err != nil {
 		return err
 	}
 
-	if body, err := im.keycloak.GetClientRepresentation(*newInvoker.ApiInvokerId, "invokerrealm"); err != nil {
+	if body, err := im.keycloak.GetClientRepresentation(*newInvoker.ApiInvokerId, im.invokerRealm); err != nil {
 		return err
-	} else {
+	} else if body != nil {
 		newInvoker.OnboardingInformation.OnboardingSecret = body.Secret
 	}
 	return nil
 }
 
 // Deletes an individual API Invoker.
+// The offboarded invoker is also removed from the access control policy lists of the APIs it had access to, its
+// client is removed from Keycloak and the registered offboarding handlers are told to remove what they keep for it.
 func (im *InvokerManager) DeleteOnboardedInvokersOnboardingId(ctx echo.Context, onboardingId string) error {
 	if _, ok := im.onboardedInvokers[onboardingId]; ok {
 		im.deleteInvoker(onboardingId)
+		if im.accessControlPolicyRegister != nil {
+			im.accessControlPolicyRegister.RemoveInvoker(onboardingId)
+		}
+		if im.keycloak != nil {
+			if err := im.keycloak.RemoveClient(onboardingId, im.invokerRealm); err != nil {
+				log.Errorf("Unable to remove client for invoker %s from Keycloak due to %s", onboardingId, err)
+			}
+		}
+		for _, handler := range im.offboardingHandlers {
+			handler.RemoveInvoker(onboardingId)
+		}
 	}
 
 	go im.sendEvent(onboardingId, eventsapi.CAPIFEventAPIINVOKEROFFBOARDED)
@@ -194,7 +483,11 @@ func (im *InvokerManager) DeleteOnboardedInvokersOnboardingId(ctx echo.Context,
 func (im *InvokerManager) deleteInvoker(onboardingId string) {
 	im.lock.Lock()
 	defer im.lock.Unlock()
+	im.notifier.RemoveSocket(im.onboardedInvokers[onboardingId].WebsockNotifConfig)
 	delete(im.onboardedInvokers, onboardingId)
+	if err := im.store.Delete(onboardedInvokersBucket, onboardingId); err != nil {
+		log.Errorf("Unable to remove stored invoker %s due to %s", onboardingId, err)
+	}
 }
 
 func getInvokerFromRequest(ctx echo.Context) (invokerapi.APIInvokerEnrolmentDetails, error) {
@@ -211,23 +504,30 @@ func (im *InvokerManager) PutOnboardedInvokersOnboardingId(ctx echo.Context, onb
 
 	newInvoker, err := getInvokerFromRequest(ctx)
 	if err != nil {
-		return sendCoreError(ctx, http.StatusBadRequest, fmt.Sprintf(errMsg, err))
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseInvalidMsgFormat, fmt.Sprintf(errMsg, err))
 	}
 
 	// Additional validation for PUT
 	if (newInvoker.ApiInvokerId == nil) || (*newInvoker.ApiInvokerId != onboardingId) {
 		errMismatch := "APIInvokerEnrolmentDetails ApiInvokerId doesn't match path parameter"
-		return sendCoreError(ctx, http.StatusBadRequest, fmt.Sprintf(errMsg, errMismatch))
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseMandatoryIeIncorrect, fmt.Sprintf(errMsg, errMismatch))
 	}
 
 	if err := im.validateInvoker(newInvoker); err != nil {
-		return sendCoreError(ctx, http.StatusBadRequest, fmt.Sprintf(errMsg, err))
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseMandatoryIeIncorrect, fmt.Sprintf(errMsg, err))
 	}
 
-	if _, ok := im.onboardedInvokers[onboardingId]; ok {
-		im.updateInvoker(newInvoker)
+	if registeredInvoker, ok := im.onboardedInvokers[onboardingId]; ok {
+		if err = im.issueCertificate(&newInvoker, &registeredInvoker); err != nil {
+			return problemdetails.Send(ctx, http.StatusInternalServerError, problemdetails.CauseSystemFailure, fmt.Sprintf(errMsg, err))
+		}
+		im.notifier.UpdateSocket(ctx, registeredInvoker.WebsockNotifConfig, newInvoker.WebsockNotifConfig)
+		requestedApiList := newInvoker.ApiList
+		im.updateInvoker(&newInvoker)
+		im.requestApiAccess(onboardingId, requestedApiList)
+		go im.sendEvent(onboardingId, eventsapi.CAPIFEventAPIINVOKERUPDATED)
 	} else {
-		return sendCoreError(ctx, http.StatusNotFound, "The invoker to update has not been onboarded")
+		return problemdetails.Send(ctx, http.StatusNotFound, problemdetails.CauseContextNotFound, "The invoker to update has not been onboarded")
 	}
 
 	err = ctx.JSON(http.StatusOK, newInvoker)
@@ -239,14 +539,58 @@ func (im *InvokerManager) PutOnboardedInvokersOnboardingId(ctx echo.Context, onb
 	return nil
 }
 
-func (im *InvokerManager) updateInvoker(invoker invokerapi.APIInvokerEnrolmentDetails) {
+// The invoker keeps the APIs it has been granted, as APIs are only granted through access requests, see
+// requestApiAccess and GrantApiAccess.
+func (im *InvokerManager) updateInvoker(invoker *invokerapi.APIInvokerEnrolmentDetails) {
 	im.lock.Lock()
 	defer im.lock.Unlock()
-	im.onboardedInvokers[*invoker.ApiInvokerId] = invoker
+	invoker.ApiList = im.onboardedInvokers[*invoker.ApiInvokerId].ApiList
+	im.onboardedInvokers[*invoker.ApiInvokerId] = *invoker
+	im.storeInvoker(*invoker)
+}
+
+func (im *InvokerManager) storeInvoker(invoker invokerapi.APIInvokerEnrolmentDetails) {
+	if err := im.store.Put(onboardedInvokersBucket, *invoker.ApiInvokerId, invoker); err != nil {
+		log.Errorf("Unable to store invoker %s due to %s", *invoker.ApiInvokerId, err)
+	}
 }
 
+// Modifies an individual API invoker details. Only the attributes present in the request are changed.
 func (im *InvokerManager) ModifyIndApiInvokeEnrolment(ctx echo.Context, onboardingId string) error {
-	return ctx.NoContent(http.StatusNotImplemented)
+	errMsg := "Unable to update invoker due to %s"
+
+	im.lock.Lock()
+	registeredInvoker, ok := im.onboardedInvokers[onboardingId]
+	im.lock.Unlock()
+	if !ok {
+		return problemdetails.Send(ctx, http.StatusNotFound, problemdetails.CauseContextNotFound, "The invoker to update has not been onboarded")
+	}
+
+	var patch invokerapi.APIInvokerEnrolmentDetailsPatch
+	if err := validation.BindMergePatch(ctx, &patch); err != nil {
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseInvalidMsgFormat, fmt.Sprintf(errMsg, "invalid format for invoker patch"))
+	}
+
+	patchedInvoker := registeredInvoker.ApplyPatch(patch)
+	if err := im.validateInvoker(patchedInvoker); err != nil {
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseMandatoryIeIncorrect, fmt.Sprintf(errMsg, err))
+	}
+
+	if err := im.issueCertificate(&patchedInvoker, &registeredInvoker); err != nil {
+		return problemdetails.Send(ctx, http.StatusInternalServerError, problemdetails.CauseSystemFailure, fmt.Sprintf(errMsg, err))
+	}
+	im.updateInvoker(&patchedInvoker)
+	im.requestApiAccess(onboardingId, patch.ApiList)
+
+	go im.sendEvent(onboardingId, eventsapi.CAPIFEventAPIINVOKERUPDATED)
+
+	err := ctx.JSON(http.StatusOK, patchedInvoker)
+	if err != nil {
+		// Something really bad happened, tell Echo that our handler failed
+		return err
+	}
+
+	return nil
 }
 
 func (im *InvokerManager) validateInvoker(invoker invokerapi.APIInvokerEnrolmentDetails) error {
@@ -254,6 +598,12 @@ func (im *InvokerManager) validateInvoker(invoker invokerapi.APIInvokerEnrolment
 		return err
 	}
 
+	if im.certificateIssuer != nil {
+		if _, err := certauthority.ParsePublicKey(invoker.OnboardingInformation.ApiInvokerPublicKey); err != nil {
+			return fmt.Errorf("APIInvokerEnrolmentDetails has invalid OnboardingInformation.ApiInvokerPublicKey, %s", err)
+		}
+	}
+
 	return nil
 }
 
@@ -267,14 +617,3 @@ func (im *InvokerManager) sendEvent(invokerId string, eventType eventsapi.CAPIFE
 	}
 	im.eventChannel <- event
 }
-
-// This function wraps sending of an error in the Error format, and
-// handling the failure to marshal that.
-func sendCoreError(ctx echo.Context, code int, message string) error {
-	pd := common29122.ProblemDetails{
-		Cause:  &message,
-		Status: &code,
-	}
-	err := ctx.JSON(code, pd)
-	return err
-}
@@ -21,13 +21,26 @@
 package invokermanagement
 
 import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"strings"
 	"testing"
 	"time"
 
+	"oransc.org/nonrtric/capifcore/internal/accesscontrolpolicyservice"
+	"oransc.org/nonrtric/capifcore/internal/config"
 	"oransc.org/nonrtric/capifcore/internal/eventsapi"
 	"oransc.org/nonrtric/capifcore/internal/invokermanagementapi"
 	"oransc.org/nonrtric/capifcore/internal/keycloak"
@@ -37,12 +50,21 @@ import (
 	"oransc.org/nonrtric/capifcore/internal/common29122"
 	"oransc.org/nonrtric/capifcore/internal/publishserviceapi"
 
+	accesscontrolmocks "oransc.org/nonrtric/capifcore/internal/accesscontrolpolicyservice/mocks"
+	certmocks "oransc.org/nonrtric/capifcore/internal/certauthority/mocks"
+	"oransc.org/nonrtric/capifcore/internal/invokermanagement/mocks"
 	keycloackmocks "oransc.org/nonrtric/capifcore/internal/keycloak/mocks"
 	"oransc.org/nonrtric/capifcore/internal/publishservice"
 	publishmocks "oransc.org/nonrtric/capifcore/internal/publishservice/mocks"
+	"oransc.org/nonrtric/capifcore/internal/restclient"
+	"oransc.org/nonrtric/capifcore/internal/storage/storagetest"
+	"oransc.org/nonrtric/capifcore/internal/websocketnotifier"
+	"oransc.org/nonrtric/capifcore/problemdetails"
+	_ "oransc.org/nonrtric/capifcore/problemdetails/problemdetailstest"
 
 	"github.com/deepmap/oapi-codegen/pkg/middleware"
 	"github.com/deepmap/oapi-codegen/pkg/testutil"
+	"github.com/getkin/kin-openapi/openapi3filter"
 	echomiddleware "github.com/labstack/echo/v4/middleware"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -65,15 +87,19 @@ func TestOnboardInvoker(t *testing.T) {
 	var client keycloak.Client
 	client.Secret = &wantedInvokerSecret
 	publishRegisterMock := publishmocks.PublishRegister{}
-	publishRegisterMock.On("GetAllowedPublishedServices", mock.AnythingOfType("[]publishserviceapi.ServiceAPIDescription")).Return(publishedServices)
 
 	accessMgmMock := keycloackmocks.AccessManagement{}
 	accessMgmMock.On("AddClient", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(nil)
 	accessMgmMock.On("GetClientRepresentation", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(&client, nil)
 
-	invokerUnderTest, eventChannel, requestHandler := getEcho(&publishRegisterMock, &accessMgmMock)
+	invokerUnderTest, eventChannel, requestHandler := getEcho(&publishRegisterMock, nil, &accessMgmMock, nil)
+	accessRequesterMock := mocks.AccessRequester{}
+	accessRequesterMock.On("RequestApiAccess", mock.Anything, mock.Anything).Return()
+	invokerUnderTest.AddAccessRequester(&accessRequesterMock)
 
 	newInvoker := getInvoker(invokerInfo)
+	requestedApiList := invokermanagementapi.APIList(publishedServices)
+	newInvoker.ApiList = &requestedApiList
 
 	// Onboard a valid invoker
 	result := testutil.NewRequest().Post("/onboardedInvokers").WithJsonBody(newInvoker).Go(t, requestHandler)
@@ -92,9 +118,10 @@ func TestOnboardInvoker(t *testing.T) {
 	assert.True(t, invokerUnderTest.IsInvokerRegistered(wantedInvokerId))
 	assert.True(t, invokerUnderTest.VerifyInvokerSecret(wantedInvokerId, wantedInvokerSecret))
 
-	publishRegisterMock.AssertCalled(t, "GetAllowedPublishedServices", mock.AnythingOfType("[]publishserviceapi.ServiceAPIDescription"))
-
-	assert.Equal(t, invokermanagementapi.APIList(publishedServices), *resultInvoker.ApiList)
+	// The requested APIs are not granted, access to them is requested instead
+	assert.Empty(t, *resultInvoker.ApiList)
+	assert.Empty(t, *invokerUnderTest.GetInvokerApiList(wantedInvokerId))
+	accessRequesterMock.AssertCalled(t, "RequestApiAccess", wantedInvokerId, requestedApiList)
 	if invokerEvent, timeout := waitForEvent(eventChannel, 1*time.Second); timeout {
 		assert.Fail(t, "No event sent")
 	} else {
@@ -110,7 +137,8 @@ func TestOnboardInvoker(t *testing.T) {
 	err = result.UnmarshalBodyToObject(&problemDetails)
 	assert.NoError(t, err, "error unmarshaling response")
 	assert.Equal(t, http.StatusForbidden, *problemDetails.Status)
-	assert.Contains(t, *problemDetails.Cause, "already onboarded")
+	assert.Equal(t, problemdetails.CauseModificationNotAllowed, *problemDetails.Cause)
+	assert.Contains(t, *problemDetails.Detail, "already onboarded")
 
 	// Onboard an invoker missing required NotificationDestination, should get 400 with problem details
 	invalidInvoker := invokermanagementapi.APIInvokerEnrolmentDetails{
@@ -124,8 +152,9 @@ func TestOnboardInvoker(t *testing.T) {
 	err = result.UnmarshalBodyToObject(&problemDetails)
 	assert.NoError(t, err, "error unmarshaling response")
 	assert.Equal(t, http.StatusBadRequest, *problemDetails.Status)
-	assert.Contains(t, *problemDetails.Cause, "missing")
-	assert.Contains(t, *problemDetails.Cause, "NotificationDestination")
+	assert.Equal(t, problemdetails.CauseMandatoryIeIncorrect, *problemDetails.Cause)
+	assert.Contains(t, *problemDetails.Detail, "missing")
+	assert.Contains(t, *problemDetails.Detail, "NotificationDestination")
 
 	// Onboard an invoker missing required OnboardingInformation.ApiInvokerPublicKey, should get 400 with problem details
 	invalidInvoker = invokermanagementapi.APIInvokerEnrolmentDetails{
@@ -138,14 +167,215 @@ func TestOnboardInvoker(t *testing.T) {
 	err = result.UnmarshalBodyToObject(&problemDetails)
 	assert.NoError(t, err, "error unmarshaling response")
 	assert.Equal(t, http.StatusBadRequest, *problemDetails.Status)
-	assert.Contains(t, *problemDetails.Cause, "missing")
-	assert.Contains(t, *problemDetails.Cause, "OnboardingInformation.ApiInvokerPublicKey")
+	assert.Contains(t, *problemDetails.Detail, "missing")
+	assert.Contains(t, *problemDetails.Detail, "OnboardingInformation.ApiInvokerPublicKey")
 }
 
-func TestDeleteInvoker(t *testing.T) {
-	invokerUnderTest, eventChannel, requestHandler := getEcho(nil, nil)
+func TestOnboardInvokerWithCertificate(t *testing.T) {
+	publishRegisterMock := publishmocks.PublishRegister{}
+	publishRegisterMock.On("GetAllPublishedServices").Return([]publishserviceapi.ServiceAPIDescription{})
+	issuerMock := certmocks.CertificateIssuer{}
+	issuerMock.On("IssueCertificate", mock.Anything, mock.AnythingOfType("string")).Return("certificate", nil).Once()
+	invokerUnderTest, _, requestHandler := getEcho(&publishRegisterMock, nil, nil, nil)
+	invokerUnderTest.certificateIssuer = &issuerMock
+
+	newInvoker := getInvoker("invoker a")
+
+	// Onboard an invoker with a public key that cannot be parsed, should get 400 with problem details
+	result := testutil.NewRequest().Post("/onboardedInvokers").WithJsonBody(newInvoker).Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusBadRequest, result.Code())
+	var problemDetails common29122.ProblemDetails
+	err := result.UnmarshalBodyToObject(&problemDetails)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Contains(t, *problemDetails.Detail, "invalid OnboardingInformation.ApiInvokerPublicKey")
+	issuerMock.AssertNotCalled(t, "IssueCertificate", mock.Anything, mock.Anything)
+
+	// Onboard an invoker with a valid public key, should get a certificate
+	newInvoker.OnboardingInformation.ApiInvokerPublicKey = getPublicKey(t)
+	result = testutil.NewRequest().Post("/onboardedInvokers").WithJsonBody(newInvoker).Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusCreated, result.Code())
+	var resultInvoker invokermanagementapi.APIInvokerEnrolmentDetails
+	err = result.UnmarshalBodyToObject(&resultInvoker)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, "certificate", *resultInvoker.OnboardingInformation.ApiInvokerCertificate)
+	issuerMock.AssertCalled(t, "IssueCertificate", mock.Anything, *resultInvoker.ApiInvokerId)
+
+	// Update the invoker with the same public key, should keep the certificate
+	resultInvoker.NotificationDestination = "http://golang.org/"
+	resultInvoker.OnboardingInformation.ApiInvokerCertificate = nil
+	resultInvoker.ApiList = nil
+	result = testutil.NewRequest().Put("/onboardedInvokers/"+*resultInvoker.ApiInvokerId).WithJsonBody(resultInvoker).Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusOK, result.Code())
+	err = result.UnmarshalBodyToObject(&resultInvoker)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, "certificate", *resultInvoker.OnboardingInformation.ApiInvokerCertificate)
+	issuerMock.AssertNumberOfCalls(t, "IssueCertificate", 1)
+
+	// Re-key the invoker, should get a new certificate
+	issuerMock.On("IssueCertificate", mock.Anything, mock.AnythingOfType("string")).Return("newCertificate", nil)
+	resultInvoker.OnboardingInformation.ApiInvokerPublicKey = getPublicKey(t)
+	resultInvoker.ApiList = nil
+	result = testutil.NewRequest().Put("/onboardedInvokers/"+*resultInvoker.ApiInvokerId).WithJsonBody(resultInvoker).Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusOK, result.Code())
+	err = result.UnmarshalBodyToObject(&resultInvoker)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, "newCertificate", *resultInvoker.OnboardingInformation.ApiInvokerCertificate)
+	assert.Equal(t, "newCertificate", *invokerUnderTest.onboardedInvokers[*resultInvoker.ApiInvokerId].OnboardingInformation.ApiInvokerCertificate)
+	issuerMock.AssertNumberOfCalls(t, "IssueCertificate", 2)
+}
 
+func TestOnboardInvokerWhenCertificateCannotBeIssued(t *testing.T) {
+	publishRegisterMock := publishmocks.PublishRegister{}
+	issuerMock := certmocks.CertificateIssuer{}
+	issuerMock.On("IssueCertificate", mock.Anything, mock.AnythingOfType("string")).Return("", errors.New("CA unavailable"))
+	invokerUnderTest, _, requestHandler := getEcho(&publishRegisterMock, nil, nil, nil)
+	invokerUnderTest.certificateIssuer = &issuerMock
+
+	// Onboard an invoker, should get 500 with problem details and the invoker is not onboarded
+	newInvoker := getInvoker("invoker a")
+	newInvoker.OnboardingInformation.ApiInvokerPublicKey = getPublicKey(t)
+	result := testutil.NewRequest().Post("/onboardedInvokers").WithJsonBody(newInvoker).Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusInternalServerError, result.Code())
+	var problemDetails common29122.ProblemDetails
+	err := result.UnmarshalBodyToObject(&problemDetails)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, problemdetails.CauseSystemFailure, *problemDetails.Cause)
+	assert.Contains(t, *problemDetails.Detail, "CA unavailable")
+	assert.False(t, invokerUnderTest.IsInvokerRegistered("api_invoker_id_invoker_a"))
+
+	// Re-key an onboarded invoker, should get 500 with problem details and the invoker keeps its certificate
 	invokerId := "invokerId"
+	certificate := "certificate"
+	registeredInvoker := getInvoker("invoker b")
+	registeredInvoker.ApiInvokerId = &invokerId
+	registeredInvoker.OnboardingInformation.ApiInvokerPublicKey = getPublicKey(t)
+	registeredInvoker.OnboardingInformation.ApiInvokerCertificate = &certificate
+	invokerUnderTest.onboardedInvokers[invokerId] = registeredInvoker
+	patch := invokermanagementapi.APIInvokerEnrolmentDetailsPatch{
+		OnboardingInformation: &invokermanagementapi.OnboardingInformation{
+			ApiInvokerPublicKey: getPublicKey(t),
+		},
+	}
+	result = testutil.NewRequest().Patch("/onboardedInvokers/"+invokerId).WithJsonBody(patch).WithContentType("application/merge-patch+json").Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusInternalServerError, result.Code())
+	assert.Equal(t, registeredInvoker, invokerUnderTest.onboardedInvokers[invokerId])
+}
+
+func TestOnboardInvokerAsynchronously(t *testing.T) {
+	publishRegisterMock := publishmocks.PublishRegister{}
+
+	notificationUrl := "http://golang.cafe/"
+	notifications := make(chan []byte, 2)
+	clientMock := NewTestClient(func(req *http.Request) *http.Response {
+		if req.URL.String() == notificationUrl {
+			assert.Equal(t, req.Method, "POST")
+			assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+			body, _ := io.ReadAll(req.Body)
+			notifications <- body
+			return &http.Response{
+				StatusCode: 204,
+				Body:       io.NopCloser(bytes.NewBufferString(``)),
+				Header:     make(http.Header), // Must be set to non-nil value or it panics
+			}
+		}
+		t.Error("Wrong call to client: ", req)
+		t.Fail()
+		return nil
+	})
+	invokerUnderTest, eventChannel, requestHandler := getEcho(&publishRegisterMock, nil, nil, clientMock)
+
+	invokerInfo := "invoker a"
+	newInvoker := getInvoker(invokerInfo)
+	requestTestNotification := true
+	newInvoker.RequestTestNotification = &requestTestNotification
+
+	result := testutil.NewRequest().Post("/onboardedInvokers").WithHeader("Prefer", "respond-async").WithJsonBody(newInvoker).Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusAccepted, result.Code())
+	assert.Empty(t, result.Recorder.Header().Get(echo.HeaderLocation))
+
+	wantedInvokerId := "api_invoker_id_" + strings.Replace(invokerInfo, " ", "_", 1)
+	if invokerEvent, timeout := waitForEvent(eventChannel, 1*time.Second); timeout {
+		assert.Fail(t, "No event sent")
+	} else {
+		assert.Equal(t, wantedInvokerId, (*invokerEvent.EventDetail.ApiInvokerIds)[0])
+		assert.Equal(t, eventsapi.CAPIFEventAPIINVOKERONBOARDED, invokerEvent.Events)
+	}
+	assert.True(t, invokerUnderTest.IsInvokerRegistered(wantedInvokerId))
+
+	wantedLocation := "http://example.com/onboardedInvokers/" + wantedInvokerId
+	if body, timeout := waitForNotification(notifications, 1*time.Second); timeout {
+		assert.Fail(t, "No onboarding notification sent")
+	} else {
+		var onboardingNotification invokermanagementapi.OnboardingNotification
+		assert.NoError(t, json.Unmarshal(body, &onboardingNotification))
+		assert.True(t, onboardingNotification.Result)
+		assert.Equal(t, common29122.Uri(wantedLocation), *onboardingNotification.ResourceLocation)
+		assert.Empty(t, *onboardingNotification.ApiList)
+		assert.Equal(t, wantedInvokerId, *onboardingNotification.ApiInvokerEnrolmentDetails.ApiInvokerId)
+	}
+	if body, timeout := waitForNotification(notifications, 1*time.Second); timeout {
+		assert.Fail(t, "No test notification sent")
+	} else {
+		var testNotification common29122.TestNotification
+		assert.NoError(t, json.Unmarshal(body, &testNotification))
+		assert.Equal(t, common29122.Link(wantedLocation), testNotification.Subscription)
+	}
+}
+
+func TestAsyncOnboardingOfAlreadyOnboardedInvokerFails(t *testing.T) {
+	publishRegisterMock := publishmocks.PublishRegister{}
+
+	notifications := make(chan []byte, 1)
+	clientMock := NewTestClient(func(req *http.Request) *http.Response {
+		body, _ := io.ReadAll(req.Body)
+		notifications <- body
+		return &http.Response{
+			StatusCode: 204,
+			Body:       io.NopCloser(bytes.NewBufferString(``)),
+			Header:     make(http.Header), // Must be set to non-nil value or it panics
+		}
+	})
+	invokerUnderTest, eventChannel, _ := getEcho(&publishRegisterMock, nil, nil, clientMock)
+
+	onboardedInvoker := getInvoker("invoker a")
+	assert.NoError(t, invokerUnderTest.prepareNewInvoker(&onboardedInvoker))
+
+	// An invoker with the same public key has been onboarded after the request was accepted
+	invokerUnderTest.onboardInvokerAsync(getInvoker("invoker b"), "http", "example.com/onboardedInvokers")
+
+	assert.Len(t, invokerUnderTest.onboardedInvokers, 1)
+	if body, timeout := waitForNotification(notifications, 1*time.Second); timeout {
+		assert.Fail(t, "No onboarding notification sent")
+	} else {
+		var onboardingNotification invokermanagementapi.OnboardingNotification
+		assert.NoError(t, json.Unmarshal(body, &onboardingNotification))
+		assert.False(t, onboardingNotification.Result)
+		assert.Nil(t, onboardingNotification.ResourceLocation)
+		assert.Nil(t, onboardingNotification.ApiInvokerEnrolmentDetails.ApiInvokerId)
+	}
+	if _, timeout := waitForEvent(eventChannel, 100*time.Millisecond); !timeout {
+		assert.Fail(t, "Unexpected event sent")
+	}
+}
+
+func TestDeleteInvoker(t *testing.T) {
+	invokerId := "invokerId"
+	accessControlPolicyRegisterMock := accesscontrolmocks.AccessControlPolicyRegister{}
+	accessControlPolicyRegisterMock.On("RemoveInvoker", invokerId).Return()
+	accessMgmMock := keycloackmocks.AccessManagement{}
+	accessMgmMock.On("RemoveClient", invokerId, "invokerrealm").Return(nil)
+	offboardingHandlerMock := mocks.OffboardingHandler{}
+	offboardingHandlerMock.On("RemoveInvoker", invokerId).Return()
+	invokerUnderTest, eventChannel, requestHandler := getEcho(nil, &accessControlPolicyRegisterMock, &accessMgmMock, nil)
+	invokerUnderTest.AddOffboardingHandler(&offboardingHandlerMock)
+
 	newInvoker := invokermanagementapi.APIInvokerEnrolmentDetails{
 		ApiInvokerId:            &invokerId,
 		NotificationDestination: "url",
@@ -161,6 +391,9 @@ func TestDeleteInvoker(t *testing.T) {
 
 	assert.Equal(t, http.StatusNoContent, result.Code())
 	assert.False(t, invokerUnderTest.IsInvokerRegistered(invokerId))
+	accessControlPolicyRegisterMock.AssertCalled(t, "RemoveInvoker", invokerId)
+	accessMgmMock.AssertCalled(t, "RemoveClient", invokerId, "invokerrealm")
+	offboardingHandlerMock.AssertCalled(t, "RemoveInvoker", invokerId)
 	if invokerEvent, timeout := waitForEvent(eventChannel, 1*time.Second); timeout {
 		assert.Fail(t, "No event sent")
 	} else {
@@ -169,10 +402,51 @@ func TestDeleteInvoker(t *testing.T) {
 	}
 }
 
+func TestInvokerClientIsAddedAndRemovedInKeycloak(t *testing.T) {
+	var keycloakRequests []string
+	keycloakServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keycloakRequests = append(keycloakRequests, r.Method+" "+r.URL.Path)
+		switch {
+		case r.URL.Path == "/realms/master/protocol/openid-connect/token":
+			w.Write([]byte(`{"access_token":"adminToken"}`))
+		case r.Method == http.MethodGet:
+			w.Write([]byte(`[{"id":"clientUuid","clientId":"` + r.URL.Query().Get("clientId") + `","secret":"clientSecret"}]`))
+		case r.Method == http.MethodPost:
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer keycloakServer.Close()
+	keycloakUrl, _ := url.Parse(keycloakServer.URL)
+	km := keycloak.NewKeycloakManager(&config.Config{
+		AuthorizationServer: config.AuthorizationServer{
+			Host:   keycloakUrl.Hostname(),
+			Port:   keycloakUrl.Port(),
+			Realms: map[string]string{"master": "master", keycloak.DefaultInvokerRealm: "invokers"},
+		},
+	}, &http.Client{})
+	publishRegisterMock := publishmocks.PublishRegister{}
+	_, _, requestHandler := getEcho(&publishRegisterMock, nil, km, nil)
+
+	result := testutil.NewRequest().Post("/onboardedInvokers").WithJsonBody(getInvoker("invoker a")).Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusCreated, result.Code())
+	var resultInvoker invokermanagementapi.APIInvokerEnrolmentDetails
+	result.UnmarshalBodyToObject(&resultInvoker)
+	assert.Equal(t, "clientSecret", *resultInvoker.OnboardingInformation.OnboardingSecret)
+	assert.Contains(t, keycloakRequests, "POST /admin/realms/invokers/clients")
+
+	result = testutil.NewRequest().Delete("/onboardedInvokers/"+*resultInvoker.ApiInvokerId).Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusNoContent, result.Code())
+	assert.Contains(t, keycloakRequests, "DELETE /admin/realms/invokers/clients/clientUuid")
+}
+
 func TestFailedUpdateInvoker(t *testing.T) {
 	publishRegisterMock := publishmocks.PublishRegister{}
 	publishRegisterMock.On("GetAllPublishedServices").Return([]publishserviceapi.ServiceAPIDescription{})
-	serviceUnderTest, _, requestHandler := getEcho(&publishRegisterMock, nil)
+	serviceUnderTest, _, requestHandler := getEcho(&publishRegisterMock, nil, nil, nil)
 
 	invokerInfo := "invoker a"
 	invokerId := "api_invoker_id_" + strings.Replace(invokerInfo, " ", "_", 1)
@@ -189,29 +463,47 @@ func TestFailedUpdateInvoker(t *testing.T) {
 	assert.NoError(t, err, "error unmarshaling response")
 	assert.Equal(t, http.StatusBadRequest, *problemDetails.Status)
 
-	assert.Contains(t, *problemDetails.Cause, "APIInvokerEnrolmentDetails ApiInvokerId doesn't match path parameter")
+	assert.Contains(t, *problemDetails.Detail, "APIInvokerEnrolmentDetails ApiInvokerId doesn't match path parameter")
 }
 
 func TestUpdateInvoker(t *testing.T) {
 	publishRegisterMock := publishmocks.PublishRegister{}
 	publishRegisterMock.On("GetAllPublishedServices").Return([]publishserviceapi.ServiceAPIDescription{})
-	serviceUnderTest, _, requestHandler := getEcho(&publishRegisterMock, nil)
+	serviceUnderTest, eventChannel, requestHandler := getEcho(&publishRegisterMock, nil, nil, nil)
 
 	invokerId := "invokerId"
+	apiId := "apiId"
+	grantedApiList := invokermanagementapi.APIList{
+		{
+			ApiId:   &apiId,
+			ApiName: "api",
+		},
+	}
 	invoker := invokermanagementapi.APIInvokerEnrolmentDetails{
 		ApiInvokerId:            &invokerId,
 		NotificationDestination: "http://golang.cafe/",
 		OnboardingInformation: invokermanagementapi.OnboardingInformation{
 			ApiInvokerPublicKey: "key",
 		},
+		ApiList: &grantedApiList,
 	}
 	serviceUnderTest.onboardedInvokers[invokerId] = invoker
+	accessRequesterMock := mocks.AccessRequester{}
+	accessRequesterMock.On("RequestApiAccess", mock.Anything, mock.Anything).Return()
+	serviceUnderTest.AddAccessRequester(&accessRequesterMock)
 
-	// Update the invoker with valid invoker, should return 200 with updated invoker details
+	// Update the invoker with valid invoker, should return 200 with updated invoker details that keep the granted APIs
 	newNotifURL := "http://golang.org/"
 	invoker.NotificationDestination = common29122.Uri(newNotifURL)
 	newPublicKey := "newPublicKey"
 	invoker.OnboardingInformation.ApiInvokerPublicKey = newPublicKey
+	otherApiId := "otherApiId"
+	invoker.ApiList = &invokermanagementapi.APIList{
+		{
+			ApiId:   &otherApiId,
+			ApiName: "otherApi",
+		},
+	}
 	result := testutil.NewRequest().Put("/onboardedInvokers/"+invokerId).WithJsonBody(invoker).Go(t, requestHandler)
 
 	var resultInvoker invokermanagementapi.APIInvokerEnrolmentDetails
@@ -221,6 +513,16 @@ func TestUpdateInvoker(t *testing.T) {
 	assert.Equal(t, invokerId, *resultInvoker.ApiInvokerId)
 	assert.Equal(t, newNotifURL, string(resultInvoker.NotificationDestination))
 	assert.Equal(t, newPublicKey, resultInvoker.OnboardingInformation.ApiInvokerPublicKey)
+	assert.Equal(t, grantedApiList, *resultInvoker.ApiList)
+	assert.Equal(t, grantedApiList, *serviceUnderTest.onboardedInvokers[invokerId].ApiList)
+	accessRequesterMock.AssertCalled(t, "RequestApiAccess", invokerId, *invoker.ApiList)
+
+	if invokerEvent, timeout := waitForEvent(eventChannel, 1*time.Second); timeout {
+		assert.Fail(t, "No event sent")
+	} else {
+		assert.Equal(t, invokerId, (*invokerEvent.EventDetail.ApiInvokerIds)[0])
+		assert.Equal(t, eventsapi.CAPIFEventAPIINVOKERUPDATED, invokerEvent.Events)
+	}
 
 	// Update with an invoker missing required NotificationDestination, should get 400 with problem details
 	validOnboardingInfo := invokermanagementapi.OnboardingInformation{
@@ -237,8 +539,8 @@ func TestUpdateInvoker(t *testing.T) {
 	err = result.UnmarshalBodyToObject(&problemDetails)
 	assert.NoError(t, err, "error unmarshaling response")
 	assert.Equal(t, http.StatusBadRequest, *problemDetails.Status)
-	assert.Contains(t, *problemDetails.Cause, "missing")
-	assert.Contains(t, *problemDetails.Cause, "NotificationDestination")
+	assert.Contains(t, *problemDetails.Detail, "missing")
+	assert.Contains(t, *problemDetails.Detail, "NotificationDestination")
 
 	// Update with an invoker missing required OnboardingInformation.ApiInvokerPublicKey, should get 400 with problem details
 	invalidInvoker.NotificationDestination = "http://golang.org/"
@@ -249,8 +551,8 @@ func TestUpdateInvoker(t *testing.T) {
 	err = result.UnmarshalBodyToObject(&problemDetails)
 	assert.NoError(t, err, "error unmarshaling response")
 	assert.Equal(t, http.StatusBadRequest, *problemDetails.Status)
-	assert.Contains(t, *problemDetails.Cause, "missing")
-	assert.Contains(t, *problemDetails.Cause, "OnboardingInformation.ApiInvokerPublicKey")
+	assert.Contains(t, *problemDetails.Detail, "missing")
+	assert.Contains(t, *problemDetails.Detail, "OnboardingInformation.ApiInvokerPublicKey")
 
 	// Update with an invoker with other ApiInvokerId than the one provided in the URL, should get 400 with problem details
 	invalidId := "1"
@@ -262,7 +564,7 @@ func TestUpdateInvoker(t *testing.T) {
 	err = result.UnmarshalBodyToObject(&problemDetails)
 	assert.NoError(t, err, "error unmarshaling response")
 	assert.Equal(t, http.StatusBadRequest, *problemDetails.Status)
-	assert.Contains(t, *problemDetails.Cause, "APIInvokerEnrolmentDetails ApiInvokerId doesn't match path parameter")
+	assert.Contains(t, *problemDetails.Detail, "APIInvokerEnrolmentDetails ApiInvokerId doesn't match path parameter")
 
 	// Update an invoker that has not been onboarded, should get 404 with problem details
 	missingId := "1"
@@ -273,37 +575,141 @@ func TestUpdateInvoker(t *testing.T) {
 	err = result.UnmarshalBodyToObject(&problemDetails)
 	assert.NoError(t, err, "error unmarshaling response")
 	assert.Equal(t, http.StatusNotFound, *problemDetails.Status)
-	assert.Contains(t, *problemDetails.Cause, "not been onboarded")
-	assert.Contains(t, *problemDetails.Cause, "invoker")
+	assert.Contains(t, *problemDetails.Detail, "not been onboarded")
+	assert.Contains(t, *problemDetails.Detail, "invoker")
 }
 
-func TestGetInvokerApiList(t *testing.T) {
-	aefProfiles1 := []publishserviceapi.AefProfile{
-		getAefProfile("aefId"),
+func TestModifyInvoker(t *testing.T) {
+	apiId := "apiId"
+	publishedServices := []publishserviceapi.ServiceAPIDescription{
+		{
+			ApiId:   &apiId,
+			ApiName: "api",
+		},
+	}
+	publishRegisterMock := publishmocks.PublishRegister{}
+	serviceUnderTest, eventChannel, requestHandler := getEcho(&publishRegisterMock, nil, nil, nil)
+
+	invokerId := "invokerId"
+	secret := "secret"
+	grantedApiList := invokermanagementapi.APIList(publishedServices)
+	invoker := invokermanagementapi.APIInvokerEnrolmentDetails{
+		ApiInvokerId:            &invokerId,
+		NotificationDestination: "http://golang.cafe/",
+		OnboardingInformation: invokermanagementapi.OnboardingInformation{
+			ApiInvokerPublicKey: "key",
+			OnboardingSecret:    &secret,
+		},
+		ApiList: &grantedApiList,
+	}
+	serviceUnderTest.onboardedInvokers[invokerId] = invoker
+	accessRequesterMock := mocks.AccessRequester{}
+	accessRequesterMock.On("RequestApiAccess", mock.Anything, mock.Anything).Return()
+	serviceUnderTest.AddAccessRequester(&accessRequesterMock)
+
+	// Modify the notification destination, the public key and the API list, should return 200 with the patched invoker
+	// that keeps its granted APIs
+	newNotifURL := common29122.Uri("http://golang.org/")
+	otherApiId := "otherApiId"
+	requestedApiList := invokermanagementapi.APIList{
+		{
+			ApiId:   &otherApiId,
+			ApiName: "otherApi",
+		},
+	}
+	patch := invokermanagementapi.APIInvokerEnrolmentDetailsPatch{
+		NotificationDestination: &newNotifURL,
+		OnboardingInformation: &invokermanagementapi.OnboardingInformation{
+			ApiInvokerPublicKey: "newPublicKey",
+		},
+		ApiList: &requestedApiList,
+	}
+	result := testutil.NewRequest().Patch("/onboardedInvokers/"+invokerId).WithJsonBody(patch).WithContentType("application/merge-patch+json").Go(t, requestHandler)
+
+	var resultInvoker invokermanagementapi.APIInvokerEnrolmentDetails
+	assert.Equal(t, http.StatusOK, result.Code())
+	err := result.UnmarshalBodyToObject(&resultInvoker)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, invokerId, *resultInvoker.ApiInvokerId)
+	assert.Equal(t, newNotifURL, resultInvoker.NotificationDestination)
+	assert.Equal(t, "newPublicKey", resultInvoker.OnboardingInformation.ApiInvokerPublicKey)
+	assert.Equal(t, secret, *resultInvoker.OnboardingInformation.OnboardingSecret)
+	assert.Len(t, *resultInvoker.ApiList, 1)
+	assert.Equal(t, apiId, *(*resultInvoker.ApiList)[0].ApiId)
+	assert.Equal(t, newNotifURL, serviceUnderTest.onboardedInvokers[invokerId].NotificationDestination)
+	assert.Equal(t, grantedApiList, *serviceUnderTest.onboardedInvokers[invokerId].ApiList)
+	accessRequesterMock.AssertCalled(t, "RequestApiAccess", invokerId, requestedApiList)
+
+	if invokerEvent, timeout := waitForEvent(eventChannel, 1*time.Second); timeout {
+		assert.Fail(t, "No event sent")
+	} else {
+		assert.Equal(t, invokerId, (*invokerEvent.EventDetail.ApiInvokerIds)[0])
+		assert.Equal(t, eventsapi.CAPIFEventAPIINVOKERUPDATED, invokerEvent.Events)
+	}
+
+	// Modify an invoker that has not been onboarded, should get 404 with problem details
+	result = testutil.NewRequest().Patch("/onboardedInvokers/missingId").WithJsonBody(patch).WithContentType("application/merge-patch+json").Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusNotFound, result.Code())
+	var problemDetails common29122.ProblemDetails
+	err = result.UnmarshalBodyToObject(&problemDetails)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, http.StatusNotFound, *problemDetails.Status)
+	assert.Contains(t, *problemDetails.Detail, "not been onboarded")
+}
+
+func TestFailedModifyInvoker(t *testing.T) {
+	publishRegisterMock := publishmocks.PublishRegister{}
+	serviceUnderTest, _, requestHandler := getEcho(&publishRegisterMock, nil, nil, nil)
+
+	invokerId := "invokerId"
+	invoker := invokermanagementapi.APIInvokerEnrolmentDetails{
+		ApiInvokerId:            &invokerId,
+		NotificationDestination: "http://golang.cafe/",
+		OnboardingInformation: invokermanagementapi.OnboardingInformation{
+			ApiInvokerPublicKey: "key",
+		},
 	}
+	serviceUnderTest.onboardedInvokers[invokerId] = invoker
+
+	// Modify with an invalid body, should get 400 with problem details and leave the invoker unchanged
+	result := testutil.NewRequest().Patch("/onboardedInvokers/"+invokerId).WithBody([]byte("{")).WithContentType("application/merge-patch+json").Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusBadRequest, result.Code())
+	assert.Equal(t, invoker, serviceUnderTest.onboardedInvokers[invokerId])
+}
+
+func TestGetInvokerApiList(t *testing.T) {
 	apiId := "apiId"
-	apiList := []publishserviceapi.ServiceAPIDescription{
+	unpublishedApiId := "unpublishedApiId"
+	grantedApis := invokermanagementapi.APIList{
 		{
-			ApiId:       &apiId,
-			AefProfiles: &aefProfiles1,
+			ApiId:   &apiId,
+			ApiName: "api",
+		},
+		{
+			ApiId:   &unpublishedApiId,
+			ApiName: "unpublishedApi",
 		},
 	}
-	aefProfiles2 := []publishserviceapi.AefProfile{
-		getAefProfile("aefId2"),
+	aefProfiles := []publishserviceapi.AefProfile{
+		getAefProfile("aefId"),
+	}
+	publishedApi := publishserviceapi.ServiceAPIDescription{
+		ApiId:       &apiId,
+		ApiName:     "api",
+		AefProfiles: &aefProfiles,
 	}
-	apiId2 := "apiId2"
-	apiList = append(apiList, publishserviceapi.ServiceAPIDescription{
-		ApiId:       &apiId2,
-		AefProfiles: &aefProfiles2,
-	})
 	publishRegisterMock := publishmocks.PublishRegister{}
-	publishRegisterMock.On("GetAllPublishedServices").Return(apiList)
-	invokerUnderTest, _, _ := getEcho(&publishRegisterMock, nil)
+	publishRegisterMock.On("GetPublishedService", apiId).Return(&publishedApi)
+	publishRegisterMock.On("GetPublishedService", unpublishedApiId).Return(nil)
+	invokerUnderTest, _, _ := getEcho(&publishRegisterMock, nil, nil, nil)
 
 	invokerInfo := "invoker a"
 	newInvoker := getInvoker(invokerInfo)
 	invokerAId := "api_invoker_id_" + strings.ReplaceAll(invokerInfo, " ", "_")
 	newInvoker.ApiInvokerId = &invokerAId
+	newInvoker.ApiList = &grantedApis
 	invokerUnderTest.onboardedInvokers[invokerAId] = newInvoker
 	invokerInfo = "invoker b"
 	newInvoker = getInvoker(invokerInfo)
@@ -311,13 +717,64 @@ func TestGetInvokerApiList(t *testing.T) {
 	newInvoker.ApiInvokerId = &invokerId
 	invokerUnderTest.onboardedInvokers[invokerId] = newInvoker
 
+	// Only the granted APIs that are still published are returned, as they are currently published
 	wantedApiList := invokerUnderTest.GetInvokerApiList(invokerAId)
 	assert.NotNil(t, wantedApiList)
-	assert.Len(t, *wantedApiList, 2)
-	assert.Equal(t, apiId, *(*wantedApiList)[0].ApiId)
+	assert.Len(t, *wantedApiList, 1)
+	assert.Equal(t, publishedApi, (*wantedApiList)[0])
+
+	// An invoker without granted APIs gets an empty list
+	wantedApiList = invokerUnderTest.GetInvokerApiList(invokerId)
+	assert.NotNil(t, wantedApiList)
+	assert.Empty(t, *wantedApiList)
+
+	assert.Nil(t, invokerUnderTest.GetInvokerApiList("notOnboarded"))
+}
+
+func TestGrantApiAccess(t *testing.T) {
+	apiId := "apiId"
+	aefProfiles := []publishserviceapi.AefProfile{
+		getAefProfile("aefId"),
+	}
+	api := publishserviceapi.ServiceAPIDescription{
+		ApiId:       &apiId,
+		ApiName:     "api",
+		AefProfiles: &aefProfiles,
+	}
+	invokerUnderTest, eventChannel, _ := getEcho(nil, nil, nil, nil)
+
+	invokerInfo := "invoker a"
+	newInvoker := getInvoker(invokerInfo)
+	invokerId := "api_invoker_id_" + strings.ReplaceAll(invokerInfo, " ", "_")
+	newInvoker.ApiInvokerId = &invokerId
+	invokerUnderTest.onboardedInvokers[invokerId] = newInvoker
+
+	invokerUnderTest.GrantApiAccess(invokerId, api)
+
+	grantedApiList := invokerUnderTest.onboardedInvokers[invokerId].ApiList
+	assert.NotNil(t, grantedApiList)
+	assert.Equal(t, invokermanagementapi.APIList{api}, *grantedApiList)
+	if invokerEvent, timeout := waitForEvent(eventChannel, 1*time.Second); timeout {
+		assert.Fail(t, "No event sent")
+	} else {
+		assert.Equal(t, eventsapi.CAPIFEventAPIINVOKERUPDATED, invokerEvent.Events)
+		assert.Equal(t, invokerId, (*invokerEvent.EventDetail.ApiInvokerIds)[0])
+	}
+
+	// Granting access to an already granted API is ignored
+	invokerUnderTest.GrantApiAccess(invokerId, api)
+	assert.Len(t, *invokerUnderTest.onboardedInvokers[invokerId].ApiList, 1)
+	if _, timeout := waitForEvent(eventChannel, 100*time.Millisecond); !timeout {
+		assert.Fail(t, "Event sent for already granted API")
+	}
+
+	// Granting access to an invoker that is not onboarded is ignored
+	invokerUnderTest.GrantApiAccess("notOnboarded", api)
+	_, ok := invokerUnderTest.onboardedInvokers["notOnboarded"]
+	assert.False(t, ok)
 }
 
-func getEcho(publishRegister publishservice.PublishRegister, keycloakMgm keycloak.AccessManagement) (*InvokerManager, chan eventsapi.EventNotification, *echo.Echo) {
+func getEcho(publishRegister publishservice.PublishRegister, accessControlPolicyRegister accesscontrolpolicyservice.AccessControlPolicyRegister, keycloakMgm keycloak.AccessManagement, client restclient.HTTPClient) (*InvokerManager, chan eventsapi.EventNotification, *echo.Echo) {
 	swagger, err := invokermanagementapi.GetSwagger()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error loading swagger spec\n: %s", err)
@@ -327,10 +784,12 @@ func getEcho(publishRegister publishservice.PublishRegister, keycloakMgm keycloa
 	swagger.Servers = nil
 
 	eventChannel := make(chan eventsapi.EventNotification)
-	im := NewInvokerManager(publishRegister, keycloakMgm, eventChannel)
+	im := NewInvokerManager(publishRegister, accessControlPolicyRegister, keycloakMgm, keycloak.DefaultInvokerRealm, nil, client, websocketnotifier.NewWebSocketNotifier(), eventChannel, storagetest.NewStore())
 
 	e := echo.New()
 	e.Use(echomiddleware.Logger())
+	// PATCH requests use the merge patch content type, which the request validator must decode as JSON
+	openapi3filter.RegisterBodyDecoder("application/merge-patch+json", openapi3filter.RegisteredBodyDecoder("application/json"))
 	e.Use(middleware.OapiRequestValidator(swagger))
 
 	invokermanagementapi.RegisterHandlers(e, im)
@@ -364,6 +823,38 @@ func getInvoker(invokerInfo string) invokermanagementapi.APIInvokerEnrolmentDeta
 	return newInvoker
 }
 
+func getPublicKey(t *testing.T) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	assert.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+type RoundTripFunc func(req *http.Request) *http.Response
+
+func (f RoundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req), nil
+}
+
+// NewTestClient returns *http.Client with Transport replaced to avoid making real calls
+func NewTestClient(fn RoundTripFunc) *http.Client {
+	return &http.Client{
+		Transport: RoundTripFunc(fn),
+	}
+}
+
+// waitForNotification waits for the channel to receive a notification body for the specified max timeout.
+// Returns true if waiting timed out.
+func waitForNotification(ch chan []byte, timeout time.Duration) ([]byte, bool) {
+	select {
+	case body := <-ch:
+		return body, false // completed normally
+	case <-time.After(timeout):
+		return nil, true // timed out
+	}
+}
+
 // waitForEvent waits for the channel to receive an event for the specified max timeout.
 // Returns true if waiting timed out.
 func waitForEvent(ch chan eventsapi.EventNotification, timeout time.Duration) (*eventsapi.EventNotification, bool) {
@@ -28,6 +28,20 @@ func (_m *InvokerRegister) GetInvokerApiList(invokerId string) *invokermanagemen
 	return r0
 }
 
+// GetInvokerCertificate provides a mock function with given fields: invokerId
+func (_m *InvokerRegister) GetInvokerCertificate(invokerId string) string {
+	ret := _m.Called(invokerId)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(string) string); ok {
+		r0 = rf(invokerId)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
 // IsInvokerRegistered provides a mock function with given fields: invokerId
 func (_m *InvokerRegister) IsInvokerRegistered(invokerId string) bool {
 	ret := _m.Called(invokerId)
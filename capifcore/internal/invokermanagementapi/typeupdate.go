@@ -32,6 +32,33 @@ func (ied *APIInvokerEnrolmentDetails) PrepareNewInvoker() {
 	ied.createId()
 }
 
+// Returns a copy of the invoker where the attributes present in the patch replace the current ones. Attributes not
+// present in the patch are left unchanged. The onboarding secret is provided by the CAPIF core function, so it is kept
+// when the onboarding information is patched.
+func (ied *APIInvokerEnrolmentDetails) ApplyPatch(patch APIInvokerEnrolmentDetailsPatch) APIInvokerEnrolmentDetails {
+	patchedInvoker := *ied
+	if patch.ApiInvokerInformation != nil {
+		patchedInvoker.ApiInvokerInformation = patch.ApiInvokerInformation
+	}
+	if patch.ApiList != nil {
+		patchedInvoker.ApiList = patch.ApiList
+	}
+	if patch.NotificationDestination != nil {
+		patchedInvoker.NotificationDestination = *patch.NotificationDestination
+	}
+	if patch.OnboardingInformation != nil {
+		onboardingInformation := ied.OnboardingInformation
+		if patch.OnboardingInformation.ApiInvokerPublicKey != "" {
+			onboardingInformation.ApiInvokerPublicKey = patch.OnboardingInformation.ApiInvokerPublicKey
+		}
+		if patch.OnboardingInformation.ApiInvokerCertificate != nil {
+			onboardingInformation.ApiInvokerCertificate = patch.OnboardingInformation.ApiInvokerCertificate
+		}
+		patchedInvoker.OnboardingInformation = onboardingInformation
+	}
+	return patchedInvoker
+}
+
 func (ied *APIInvokerEnrolmentDetails) createId() {
 	idAsString := "api_invoker_id_"
 	if ied.ApiInvokerInformation != nil {
@@ -40,3 +40,40 @@ func TestPrepareNewInvoker(t *testing.T) {
 	invokerUnderTest.PrepareNewInvoker()
 	assert.Equal(t, "api_invoker_id_invoker_info", *invokerUnderTest.ApiInvokerId)
 }
+
+func TestApplyPatch(t *testing.T) {
+	invokerId := "invokerId"
+	secret := "secret"
+	invokerUnderTest := APIInvokerEnrolmentDetails{
+		ApiInvokerId:            &invokerId,
+		NotificationDestination: "http://golang.cafe/",
+		OnboardingInformation: OnboardingInformation{
+			ApiInvokerPublicKey: "key",
+			OnboardingSecret:    &secret,
+		},
+	}
+
+	invokerInfo := "invoker info"
+	patchedInvoker := invokerUnderTest.ApplyPatch(APIInvokerEnrolmentDetailsPatch{
+		ApiInvokerInformation: &invokerInfo,
+	})
+
+	assert.Equal(t, invokerInfo, *patchedInvoker.ApiInvokerInformation)
+	assert.Equal(t, invokerUnderTest.NotificationDestination, patchedInvoker.NotificationDestination)
+	assert.Equal(t, invokerUnderTest.OnboardingInformation, patchedInvoker.OnboardingInformation)
+	assert.Nil(t, invokerUnderTest.ApiInvokerInformation)
+
+	certificate := "certificate"
+	otherSecret := "otherSecret"
+	patchedInvoker = invokerUnderTest.ApplyPatch(APIInvokerEnrolmentDetailsPatch{
+		OnboardingInformation: &OnboardingInformation{
+			ApiInvokerCertificate: &certificate,
+			OnboardingSecret:      &otherSecret,
+		},
+	})
+
+	assert.Equal(t, "key", patchedInvoker.OnboardingInformation.ApiInvokerPublicKey)
+	assert.Equal(t, certificate, *patchedInvoker.OnboardingInformation.ApiInvokerCertificate)
+	assert.Equal(t, secret, *patchedInvoker.OnboardingInformation.OnboardingSecret)
+	assert.Nil(t, invokerUnderTest.OnboardingInformation.ApiInvokerCertificate)
+}
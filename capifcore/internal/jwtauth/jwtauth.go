@@ -0,0 +1,263 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package jwtauth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+
+	"oransc.org/nonrtric/capifcore/internal/keycloak"
+	"oransc.org/nonrtric/capifcore/internal/storage"
+)
+
+// The issuer of the tokens signed by CAPIF core.
+const Issuer = "capifcore"
+
+const clientsBucket = "authorizationClients"
+
+// An authorization server built into CAPIF core, implementing the keycloak.AccessManagement interface without
+// Keycloak. The access tokens are JWTs signed with a configured RSA or EC key. The server has a single realm, so the
+// realm given to its operations is not used.
+type JwtManager struct {
+	signingKey    crypto.Signer
+	signingMethod jwt.SigningMethod
+	jwk           keycloak.Jwk
+	tokenLifetime time.Duration
+	clients       map[string]keycloak.Client
+	store         storage.Store
+	lock          sync.Mutex
+}
+
+// Creates a manager signing the access tokens with the PEM encoded RSA or EC private key in the provided file.
+// The tokens expire after the provided lifetime. The clients are kept in the provided store, and the clients added
+// earlier are loaded at creation.
+func NewJwtManager(keyPath string, tokenLifetime time.Duration, store storage.Store) (*JwtManager, error) {
+	keyPem, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, err
+	}
+	signingKey, err := parsePrivateKey(keyPem)
+	if err != nil {
+		return nil, err
+	}
+	signingMethod, jwk, err := getSigningMethodAndJwk(signingKey)
+	if err != nil {
+		return nil, err
+	}
+	jm := &JwtManager{
+		signingKey:    signingKey,
+		signingMethod: signingMethod,
+		jwk:           jwk,
+		tokenLifetime: tokenLifetime,
+		clients:       make(map[string]keycloak.Client),
+		store:         store,
+	}
+	if err := storage.Load(store, clientsBucket, jm.clients); err != nil {
+		return nil, err
+	}
+	return jm, nil
+}
+
+func parsePrivateKey(keyPem []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(keyPem)
+	if block == nil {
+		return nil, errors.New("no PEM encoded key found")
+	}
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		if signer, ok := key.(crypto.Signer); ok {
+			return signer, nil
+		}
+		return nil, errors.New("unsupported private key type")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return nil, errors.New("private key is neither an RSA nor an EC key")
+}
+
+func getSigningMethodAndJwk(signingKey crypto.Signer) (jwt.SigningMethod, keycloak.Jwk, error) {
+	var signingMethod jwt.SigningMethod
+	var jwk keycloak.Jwk
+	switch publicKey := signingKey.Public().(type) {
+	case *rsa.PublicKey:
+		signingMethod = jwt.SigningMethodRS256
+		jwk = keycloak.Jwk{
+			Kty: "RSA",
+			N:   encodeBase64Url(publicKey.N.Bytes()),
+			E:   encodeBase64Url(big.NewInt(int64(publicKey.E)).Bytes()),
+		}
+	case *ecdsa.PublicKey:
+		switch publicKey.Curve {
+		case elliptic.P256():
+			signingMethod = jwt.SigningMethodES256
+		case elliptic.P384():
+			signingMethod = jwt.SigningMethodES384
+		case elliptic.P521():
+			signingMethod = jwt.SigningMethodES512
+		default:
+			return nil, jwk, fmt.Errorf("unsupported curve %s", publicKey.Curve.Params().Name)
+		}
+		size := (publicKey.Curve.Params().BitSize + 7) / 8
+		jwk = keycloak.Jwk{
+			Kty: "EC",
+			Crv: publicKey.Curve.Params().Name,
+			X:   encodeBase64Url(publicKey.X.FillBytes(make([]byte, size))),
+			Y:   encodeBase64Url(publicKey.Y.FillBytes(make([]byte, size))),
+		}
+	default:
+		return nil, jwk, errors.New("unsupported public key type")
+	}
+	jwk.Use = "sig"
+	jwk.Alg = signingMethod.Alg()
+	jwk.Kid = getThumbprint(jwk)
+	return signingMethod, jwk, nil
+}
+
+// Gets the RFC 7638 thumbprint of the key, which is used as its key id.
+func getThumbprint(jwk keycloak.Jwk) string {
+	var members string
+	if jwk.Kty == "RSA" {
+		members = fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`, jwk.E, jwk.N)
+	} else {
+		members = fmt.Sprintf(`{"crv":"%s","kty":"EC","x":"%s","y":"%s"}`, jwk.Crv, jwk.X, jwk.Y)
+	}
+	thumbprint := sha256.Sum256([]byte(members))
+	return encodeBase64Url(thumbprint[:])
+}
+
+func encodeBase64Url(value []byte) string {
+	return base64.RawURLEncoding.EncodeToString(value)
+}
+
+// Signs an access token for the client given by "client_id" in the provided data, granting the scope given by "scope".
+// The client's credentials must have been verified by the caller.
+func (jm *JwtManager) GetToken(realm string, data map[string][]string) (keycloak.Jwttoken, error) {
+	var token keycloak.Jwttoken
+	clientId := getFirstValue(data, "client_id")
+	if clientId == "" {
+		return token, errors.New("client_id missing")
+	}
+
+	now := time.Now()
+	claims := keycloak.TokenClaims{
+		Id:        uuid.NewString(),
+		Issuer:    Issuer,
+		Subject:   clientId,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(jm.tokenLifetime).Unix(),
+		ClientId:  clientId,
+		Scope:     getFirstValue(data, "scope"),
+	}
+	jwtToken := jwt.NewWithClaims(jm.signingMethod, claims)
+	jwtToken.Header["kid"] = jm.jwk.Kid
+	signedToken, err := jwtToken.SignedString(jm.signingKey)
+	if err != nil {
+		return token, err
+	}
+
+	token.AccessToken = signedToken
+	token.ExpiresIn = int(jm.tokenLifetime.Seconds())
+	token.TokenType = "Bearer"
+	token.Scope = claims.Scope
+	return token, nil
+}
+
+func getFirstValue(data map[string][]string, key string) string {
+	if values := data[key]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// Adds a client with a generated secret.
+func (jm *JwtManager) AddClient(clientId string, realm string) error {
+	jm.lock.Lock()
+	defer jm.lock.Unlock()
+	id := uuid.NewString()
+	secret := uuid.NewString()
+	client := keycloak.Client{
+		ClientID:               clientId,
+		Enabled:                true,
+		ID:                     &id,
+		Secret:                 &secret,
+		ServiceAccountsEnabled: true,
+	}
+	if err := jm.store.Put(clientsBucket, clientId, client); err != nil {
+		return err
+	}
+	jm.clients[clientId] = client
+	log.Debugf("Added client %s", clientId)
+	return nil
+}
+
+// Gets the client, including its secret. Returns nil if the client has not been added.
+func (jm *JwtManager) GetClientRepresentation(clientId string, realm string) (*keycloak.Client, error) {
+	jm.lock.Lock()
+	defer jm.lock.Unlock()
+	if client, ok := jm.clients[clientId]; ok {
+		return &client, nil
+	}
+	return nil, nil
+}
+
+func (jm *JwtManager) RemoveClient(clientId string, realm string) error {
+	jm.lock.Lock()
+	defer jm.lock.Unlock()
+	if err := jm.store.Delete(clientsBucket, clientId); err != nil {
+		return err
+	}
+	delete(jm.clients, clientId)
+	return nil
+}
+
+// Gets the public key of the key signing the access tokens, as a JSON Web Key Set.
+func (jm *JwtManager) GetJwks(realm string) (keycloak.Jwks, error) {
+	return keycloak.Jwks{Keys: []keycloak.Jwk{jm.jwk}}, nil
+}
+
+// Verifies the signature and expiry of a token signed by the manager.
+func (jm *JwtManager) VerifyToken(tokenString string, realm string) (*keycloak.TokenClaims, error) {
+	return keycloak.ParseToken(tokenString, func(kid string) (crypto.PublicKey, error) {
+		if kid != jm.jwk.Kid {
+			return nil, fmt.Errorf("no key with id %s", kid)
+		}
+		return jm.signingKey.Public(), nil
+	})
+}
@@ -0,0 +1,218 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package jwtauth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+
+	"oransc.org/nonrtric/capifcore/internal/keycloak"
+	"oransc.org/nonrtric/capifcore/internal/storage"
+	"oransc.org/nonrtric/capifcore/internal/storage/storagetest"
+)
+
+func TestGetTokenSignedWithRsaKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	managerUnderTest := getManager(t, &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	data := url.Values{"grant_type": {"client_credentials"}, "client_id": {"invokerId"}, "client_secret": {"secret"}, "scope": {"3gpp#aefId:apiName"}}
+	token, err := managerUnderTest.GetToken("invokerrealm", data)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer", token.TokenType)
+	assert.Equal(t, 300, token.ExpiresIn)
+	assert.Equal(t, "3gpp#aefId:apiName", token.Scope)
+	claims := &keycloak.TokenClaims{}
+	parsedToken, err := jwt.ParseWithClaims(token.AccessToken, claims, func(*jwt.Token) (interface{}, error) {
+		return &key.PublicKey, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "RS256", parsedToken.Method.Alg())
+	assert.Equal(t, managerUnderTest.jwk.Kid, parsedToken.Header["kid"])
+	assert.Equal(t, "invokerId", claims.Subject)
+	assert.Equal(t, "invokerId", claims.ClientId)
+	assert.Equal(t, "3gpp#aefId:apiName", claims.Scope)
+	assert.Equal(t, Issuer, claims.Issuer)
+	assert.Equal(t, claims.IssuedAt+300, claims.ExpiresAt)
+
+	verifiedClaims, err := managerUnderTest.VerifyToken(token.AccessToken, "invokerrealm")
+	assert.NoError(t, err)
+	assert.Equal(t, "invokerId", verifiedClaims.GetClientId())
+}
+
+func TestGetTokenSignedWithEcKey(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	keyBytes, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.NoError(t, err)
+	managerUnderTest := getManager(t, &pem.Block{Type: "PRIVATE KEY", Bytes: keyBytes})
+
+	token, err := managerUnderTest.GetToken("invokerrealm", url.Values{"client_id": {"invokerId"}})
+
+	assert.NoError(t, err)
+	claims := &keycloak.TokenClaims{}
+	parsedToken, err := jwt.ParseWithClaims(token.AccessToken, claims, func(*jwt.Token) (interface{}, error) {
+		return &key.PublicKey, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "ES256", parsedToken.Method.Alg())
+	assert.Equal(t, "invokerId", claims.Subject)
+	assert.Empty(t, claims.Scope)
+
+	// A token without client is not issued
+	_, err = managerUnderTest.GetToken("invokerrealm", url.Values{})
+	assert.Error(t, err)
+}
+
+func TestVerifyTokenFails(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	keyBytes, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+	managerUnderTest := getManager(t, &pem.Block{Type: "EC PRIVATE KEY", Bytes: keyBytes})
+
+	// Expired token
+	managerUnderTest.tokenLifetime = -time.Minute
+	token, err := managerUnderTest.GetToken("invokerrealm", url.Values{"client_id": {"invokerId"}})
+	assert.NoError(t, err)
+	_, err = managerUnderTest.VerifyToken(token.AccessToken, "invokerrealm")
+	assert.Error(t, err)
+
+	// Token signed with another key
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	otherToken := jwt.NewWithClaims(jwt.SigningMethodES256, keycloak.TokenClaims{ClientId: "invokerId", ExpiresAt: time.Now().Add(time.Minute).Unix()})
+	otherToken.Header["kid"] = managerUnderTest.jwk.Kid
+	signedOtherToken, _ := otherToken.SignedString(otherKey)
+	_, err = managerUnderTest.VerifyToken(signedOtherToken, "invokerrealm")
+	assert.Error(t, err)
+
+	// Unsigned token
+	unsignedToken := jwt.NewWithClaims(jwt.SigningMethodNone, keycloak.TokenClaims{ClientId: "invokerId", ExpiresAt: time.Now().Add(time.Minute).Unix()})
+	unsignedToken.Header["kid"] = managerUnderTest.jwk.Kid
+	signedUnsignedToken, _ := unsignedToken.SignedString(jwt.UnsafeAllowNoneSignatureType)
+	_, err = managerUnderTest.VerifyToken(signedUnsignedToken, "invokerrealm")
+	assert.Error(t, err)
+}
+
+func TestGetJwks(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assert.NoError(t, err)
+	keyBytes, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+	managerUnderTest := getManager(t, &pem.Block{Type: "EC PRIVATE KEY", Bytes: keyBytes})
+	jwks, err := managerUnderTest.GetJwks("invokerrealm")
+
+	assert.NoError(t, err)
+	assert.Len(t, jwks.Keys, 1)
+	jwk := jwks.Keys[0]
+	assert.Equal(t, "EC", jwk.Kty)
+	assert.Equal(t, "P-384", jwk.Crv)
+	assert.Equal(t, "ES384", jwk.Alg)
+	assert.Equal(t, "sig", jwk.Use)
+	assert.Equal(t, encodeBase64Url(key.X.FillBytes(make([]byte, 48))), jwk.X)
+	assert.Equal(t, encodeBase64Url(key.Y.FillBytes(make([]byte, 48))), jwk.Y)
+	assert.NotEmpty(t, jwk.Kid)
+}
+
+func TestThumbprint(t *testing.T) {
+	// The example of RFC 7638
+	jwk := keycloak.Jwk{
+		Kty: "RSA",
+		N:   "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+		E:   "AQAB",
+	}
+	assert.Equal(t, "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs", getThumbprint(jwk))
+}
+
+func TestClients(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	keyBytes, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+	managerUnderTest := getManager(t, &pem.Block{Type: "EC PRIVATE KEY", Bytes: keyBytes})
+
+	err = managerUnderTest.AddClient("invokerId", "invokerrealm")
+	assert.NoError(t, err)
+	client, err := managerUnderTest.GetClientRepresentation("invokerId", "invokerrealm")
+	assert.NoError(t, err)
+	assert.Equal(t, "invokerId", client.ClientID)
+	assert.NotEmpty(t, *client.Secret)
+
+	err = managerUnderTest.RemoveClient("invokerId", "invokerrealm")
+	assert.NoError(t, err)
+	client, err = managerUnderTest.GetClientRepresentation("invokerId", "invokerrealm")
+	assert.NoError(t, err)
+	assert.Nil(t, client)
+}
+
+func TestClientsAreLoadedFromStore(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	keyBytes, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+	keyBlock := &pem.Block{Type: "EC PRIVATE KEY", Bytes: keyBytes}
+	store := storagetest.NewStore()
+	managerUnderTest := getManagerWithStore(t, keyBlock, store)
+	err = managerUnderTest.AddClient("invokerId", "invokerrealm")
+	assert.NoError(t, err)
+
+	restartedManager := getManagerWithStore(t, keyBlock, store)
+
+	assert.Equal(t, managerUnderTest.clients, restartedManager.clients)
+}
+
+func TestNewJwtManagerWithInvalidKey(t *testing.T) {
+	keyPath := filepath.Join(t.TempDir(), "key.pem")
+	err := os.WriteFile(keyPath, []byte("not a key"), 0600)
+	assert.NoError(t, err)
+
+	_, err = NewJwtManager(keyPath, time.Minute, storagetest.NewStore())
+	assert.Error(t, err)
+
+	_, err = NewJwtManager(filepath.Join(t.TempDir(), "missing.pem"), time.Minute, storagetest.NewStore())
+	assert.Error(t, err)
+}
+
+func getManager(t *testing.T, keyBlock *pem.Block) *JwtManager {
+	return getManagerWithStore(t, keyBlock, storagetest.NewStore())
+}
+
+func getManagerWithStore(t *testing.T, keyBlock *pem.Block, store storage.Store) *JwtManager {
+	keyPath := filepath.Join(t.TempDir(), "key.pem")
+	err := os.WriteFile(keyPath, pem.EncodeToMemory(keyBlock), 0600)
+	assert.NoError(t, err)
+	jm, err := NewJwtManager(keyPath, 300*time.Second, store)
+	assert.NoError(t, err)
+	return jm
+}
@@ -21,17 +21,23 @@
 package keycloak
 
 import (
+	"crypto"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"sync"
 
 	log "github.com/sirupsen/logrus"
 	"oransc.org/nonrtric/capifcore/internal/config"
 	"oransc.org/nonrtric/capifcore/internal/restclient"
 )
 
+// The default key of the realm of the invokers in the realms of the configuration.
+const DefaultInvokerRealm = "invokerrealm"
+
 //go:generate mockery --name AccessManagement
 type AccessManagement interface {
 	// Get JWT token for a client.
@@ -41,6 +47,13 @@ type AccessManagement interface {
 	AddClient(clientId string, realm string) error
 	// Returns information about client including secret
 	GetClientRepresentation(clientId string, realm string) (*Client, error)
+	// Remove client from keycloak
+	RemoveClient(clientId string, realm string) error
+	// Get the keys verifying the JWT tokens of a realm
+	GetJwks(realm string) (Jwks, error)
+	// Verify the signature and expiry of a JWT token of a realm.
+	// Returns the claims of the token if it is valid otherwise returns error.
+	VerifyToken(tokenString string, realm string) (*TokenClaims, error)
 }
 
 type AdminUser struct {
@@ -53,6 +66,8 @@ type KeycloakManager struct {
 	admin             AdminUser
 	realms            map[string]string
 	client            restclient.HTTPClient
+	jwks              map[string]Jwks
+	lock              sync.Mutex
 }
 
 func NewKeycloakManager(cfg *config.Config, c restclient.HTTPClient) *KeycloakManager {
@@ -67,6 +82,7 @@ func NewKeycloakManager(cfg *config.Config, c restclient.HTTPClient) *KeycloakMa
 			Password: cfg.AuthorizationServer.AdminUser.Password,
 		},
 		realms: cfg.AuthorizationServer.Realms,
+		jwks:   make(map[string]Jwks),
 	}
 }
 
@@ -90,7 +106,14 @@ func (km *KeycloakManager) GetToken(realm string, data map[string][]string) (Jwt
 		return jwt, errors.New("realm does not exist")
 	}
 	getTokenUrl := km.keycloakServerUrl + "/realms/" + realmVal + "/protocol/openid-connect/token"
-	resp, err := http.PostForm(getTokenUrl, data)
+	// The CAPIF scope is checked by CAPIF core, it is not a Keycloak client scope
+	form := url.Values{}
+	for key, values := range data {
+		if key != "scope" {
+			form[key] = values
+		}
+	}
+	resp, err := http.PostForm(getTokenUrl, form)
 
 	if err != nil {
 		return jwt, err
@@ -199,3 +222,75 @@ func (km *KeycloakManager) GetClientRepresentation(clientId string, realm string
 	}
 
 }
+
+func (km *KeycloakManager) RemoveClient(clientId string, realm string) error {
+	client, err := km.GetClientRepresentation(clientId, realm)
+	if err != nil {
+		return err
+	}
+	if client == nil || client.ID == nil {
+		log.Debugf("Client %s does not exist, nothing to remove", clientId)
+		return nil
+	}
+
+	data := url.Values{"grant_type": {"password"}, "username": {km.admin.User}, "password": {km.admin.Password}, "client_id": {"admin-cli"}}
+	token, err := km.GetToken("master", data)
+	if err != nil {
+		log.Errorf("error wrong credentials or url %v\n", err)
+		return err
+	}
+
+	deleteClientUrl := km.keycloakServerUrl + "/admin/realms/" + km.realms[realm] + "/clients/" + url.PathEscape(*client.ID)
+	var headers = map[string]string{"Authorization": "Bearer " + token.AccessToken}
+	if err := restclient.Delete(deleteClientUrl, headers, km.client); err != nil {
+		log.Errorf("removeClient - error with http request: %+v\n", err)
+		return err
+	}
+
+	log.Debug("Removed client")
+	return nil
+}
+
+func (km *KeycloakManager) GetJwks(realm string) (Jwks, error) {
+	var jwks Jwks
+	realmVal, ok := km.realms[realm]
+	if !ok {
+		log.Errorf("error realm does not exist\n")
+		return jwks, errors.New("realm does not exist")
+	}
+
+	certsUrl := km.keycloakServerUrl + "/realms/" + realmVal + "/protocol/openid-connect/certs"
+	resp, err := restclient.Get(certsUrl, nil, km.client)
+	if err != nil {
+		log.Errorf("getJwks - error with http request: %+v\n", err)
+		return jwks, err
+	}
+	if err = json.Unmarshal(resp, &jwks); err != nil {
+		log.Errorf("error unmarshal keycloak certs: %+v\n", err)
+		return jwks, err
+	}
+
+	km.lock.Lock()
+	defer km.lock.Unlock()
+	km.jwks[realm] = jwks
+	return jwks, nil
+}
+
+func (km *KeycloakManager) VerifyToken(tokenString string, realm string) (*TokenClaims, error) {
+	return ParseToken(tokenString, func(kid string) (crypto.PublicKey, error) {
+		km.lock.Lock()
+		jwk := km.jwks[realm].GetKey(kid)
+		km.lock.Unlock()
+		if jwk == nil {
+			// The keys are fetched again as Keycloak may have rotated them
+			jwks, err := km.GetJwks(realm)
+			if err != nil {
+				return nil, err
+			}
+			if jwk = jwks.GetKey(kid); jwk == nil {
+				return nil, fmt.Errorf("no key with id %s", kid)
+			}
+		}
+		return jwk.PublicKey()
+	})
+}
@@ -52,6 +52,30 @@ func (_m *AccessManagement) GetClientRepresentation(clientId string, realm strin
 	return r0, r1
 }
 
+// GetJwks provides a mock function with given fields: realm
+func (_m *AccessManagement) GetJwks(realm string) (keycloak.Jwks, error) {
+	ret := _m.Called(realm)
+
+	var r0 keycloak.Jwks
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (keycloak.Jwks, error)); ok {
+		return rf(realm)
+	}
+	if rf, ok := ret.Get(0).(func(string) keycloak.Jwks); ok {
+		r0 = rf(realm)
+	} else {
+		r0 = ret.Get(0).(keycloak.Jwks)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(realm)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetToken provides a mock function with given fields: realm, data
 func (_m *AccessManagement) GetToken(realm string, data map[string][]string) (keycloak.Jwttoken, error) {
 	ret := _m.Called(realm, data)
@@ -76,6 +100,46 @@ func (_m *AccessManagement) GetToken(realm string, data map[string][]string) (ke
 	return r0, r1
 }
 
+// RemoveClient provides a mock function with given fields: clientId, realm
+func (_m *AccessManagement) RemoveClient(clientId string, realm string) error {
+	ret := _m.Called(clientId, realm)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(clientId, realm)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// VerifyToken provides a mock function with given fields: tokenString, realm
+func (_m *AccessManagement) VerifyToken(tokenString string, realm string) (*keycloak.TokenClaims, error) {
+	ret := _m.Called(tokenString, realm)
+
+	var r0 *keycloak.TokenClaims
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (*keycloak.TokenClaims, error)); ok {
+		return rf(tokenString, realm)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) *keycloak.TokenClaims); ok {
+		r0 = rf(tokenString, realm)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*keycloak.TokenClaims)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(tokenString, realm)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewAccessManagement creates a new instance of AccessManagement. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewAccessManagement(t interface {
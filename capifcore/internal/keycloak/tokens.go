@@ -0,0 +1,162 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package keycloak
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// A JSON Web Key, see RFC 7517.
+type Jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	// RSA public key parameters
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// EC public key parameters
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+	// Certificate chain of the key
+	X5c []string `json:"x5c,omitempty"`
+}
+
+// A JSON Web Key Set, see RFC 7517.
+type Jwks struct {
+	Keys []Jwk `json:"keys"`
+}
+
+// Gets the key with the provided key id, or nil if the set has no such key.
+func (jwks Jwks) GetKey(kid string) *Jwk {
+	for _, jwk := range jwks.Keys {
+		if jwk.Kid == kid {
+			return &jwk
+		}
+	}
+	return nil
+}
+
+// Gets the public key of the JSON Web Key. Only RSA and EC keys are supported.
+func (jwk Jwk) PublicKey() (crypto.PublicKey, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeBase64Url(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBase64Url(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %s", jwk.Crv)
+		}
+		x, err := decodeBase64Url(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBase64Url(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %s", jwk.Kty)
+}
+
+func decodeBase64Url(value string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// The claims of an access token.
+type TokenClaims struct {
+	Id        string `json:"jti,omitempty"`
+	Issuer    string `json:"iss,omitempty"`
+	Subject   string `json:"sub,omitempty"`
+	IssuedAt  int64  `json:"iat,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+	// The client the token is issued to.
+	ClientId string `json:"client_id,omitempty"`
+	// The client the token is issued to, as given by Keycloak.
+	AuthorizedParty string `json:"azp,omitempty"`
+	// The granted scope.
+	Scope string `json:"scope,omitempty"`
+}
+
+// Checks that the token has not expired. Only tokens with an expiry are valid.
+func (c TokenClaims) Valid() error {
+	if c.ExpiresAt == 0 {
+		return errors.New("token has no expiry")
+	}
+	if time.Now().Unix() > c.ExpiresAt {
+		return errors.New("token is expired")
+	}
+	return nil
+}
+
+// Gets the client the token is issued to.
+func (c TokenClaims) GetClientId() string {
+	if c.ClientId != "" {
+		return c.ClientId
+	}
+	return c.AuthorizedParty
+}
+
+// Parses a token and verifies its signature and expiry. The key verifying the signature is given by the key id in the
+// header of the token.
+func ParseToken(tokenString string, getKey func(kid string) (crypto.PublicKey, error)) (*TokenClaims, error) {
+	claims := &TokenClaims{}
+	parser := jwt.Parser{ValidMethods: []string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}}
+	_, err := parser.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return getKey(kid)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
@@ -0,0 +1,128 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package keycloak
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"oransc.org/nonrtric/capifcore/internal/config"
+	"oransc.org/nonrtric/capifcore/internal/restclient/mocks"
+)
+
+func TestVerifyTokenWithKeycloakKeys(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	jwks := Jwks{
+		Keys: []Jwk{
+			{
+				Kty: "RSA",
+				Kid: "kid",
+				Use: "sig",
+				Alg: "RS256",
+				N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			},
+		},
+	}
+	jwksBody, _ := json.Marshal(jwks)
+	clientMock := mocks.HTTPClient{}
+	clientMock.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+		return req.URL.String() == "http://keycloak:8080/realms/invokers/protocol/openid-connect/certs"
+	})).Return(func(*http.Request) *http.Response {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(bytes.NewReader(jwksBody)),
+		}
+	}, nil)
+	managerUnderTest := NewKeycloakManager(&config.Config{
+		AuthorizationServer: config.AuthorizationServer{
+			Host:   "keycloak",
+			Port:   "8080",
+			Realms: map[string]string{DefaultInvokerRealm: "invokers"},
+		},
+	}, &clientMock)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, TokenClaims{
+		AuthorizedParty: "invokerId",
+		ExpiresAt:       time.Now().Add(time.Minute).Unix(),
+	})
+	token.Header["kid"] = "kid"
+	signedToken, _ := token.SignedString(key)
+
+	claims, err := managerUnderTest.VerifyToken(signedToken, DefaultInvokerRealm)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "invokerId", claims.GetClientId())
+
+	// The keys are only fetched again for an unknown key
+	_, err = managerUnderTest.VerifyToken(signedToken, DefaultInvokerRealm)
+	assert.NoError(t, err)
+	clientMock.AssertNumberOfCalls(t, "Do", 1)
+
+	token.Header["kid"] = "otherKid"
+	signedToken, _ = token.SignedString(key)
+	_, err = managerUnderTest.VerifyToken(signedToken, DefaultInvokerRealm)
+	assert.ErrorContains(t, err, "no key with id otherKid")
+	clientMock.AssertNumberOfCalls(t, "Do", 2)
+
+	// Expired token
+	token = jwt.NewWithClaims(jwt.SigningMethodRS256, TokenClaims{
+		AuthorizedParty: "invokerId",
+		ExpiresAt:       time.Now().Add(-time.Minute).Unix(),
+	})
+	token.Header["kid"] = "kid"
+	signedToken, _ = token.SignedString(key)
+	_, err = managerUnderTest.VerifyToken(signedToken, DefaultInvokerRealm)
+	assert.ErrorContains(t, err, "expired")
+}
+
+func TestJwkPublicKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	jwk := Jwk{
+		Kty: "RSA",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+
+	publicKey, err := jwk.PublicKey()
+
+	assert.NoError(t, err)
+	assert.True(t, key.PublicKey.Equal(publicKey))
+
+	_, err = Jwk{Kty: "oct"}.PublicKey()
+	assert.ErrorContains(t, err, "unsupported key type oct")
+	_, err = Jwk{Kty: "EC", Crv: "P-192"}.PublicKey()
+	assert.ErrorContains(t, err, "unsupported curve P-192")
+}
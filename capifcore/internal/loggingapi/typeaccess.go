@@ -0,0 +1,37 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package loggingapi
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Checks if the logged invocation was successful. For HTTP the result is the status code of the invocation, where
+// informational, successful and redirection codes count as success. Other results count as success only if they
+// read "success".
+func (l Log) IsSuccess() bool {
+	result := strings.TrimSpace(l.Result)
+	if code, err := strconv.Atoi(result); err == nil {
+		return code >= 100 && code < 400
+	}
+	return strings.EqualFold(result, "success")
+}
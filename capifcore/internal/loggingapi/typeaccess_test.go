@@ -0,0 +1,48 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package loggingapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsSuccess(t *testing.T) {
+	tests := []struct {
+		result string
+		want   bool
+	}{
+		{result: "200", want: true},
+		{result: "204", want: true},
+		{result: "302", want: true},
+		{result: "400", want: false},
+		{result: "503", want: false},
+		{result: "SUCCESS", want: true},
+		{result: "failure", want: false},
+		{result: "", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.result, func(t *testing.T) {
+			assert.Equal(t, tt.want, Log{Result: tt.result}.IsSuccess())
+		})
+	}
+}
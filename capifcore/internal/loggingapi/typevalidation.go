@@ -0,0 +1,77 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package loggingapi
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"oransc.org/nonrtric/capifcore/internal/publishserviceapi"
+)
+
+func (il InvocationLog) Validate() error {
+	if len(strings.TrimSpace(il.AefId)) == 0 {
+		return errors.New("InvocationLog missing required aefId")
+	}
+
+	if len(strings.TrimSpace(il.ApiInvokerId)) == 0 {
+		return errors.New("InvocationLog missing required apiInvokerId")
+	}
+
+	if len(il.Logs) == 0 {
+		return errors.New("InvocationLog missing required logs")
+	}
+
+	for i, log := range il.Logs {
+		if err := log.Validate(); err != nil {
+			return fmt.Errorf("InvocationLog has invalid log at index %d, err=%s", i, err)
+		}
+	}
+	return nil
+}
+
+func (l Log) Validate() error {
+	if len(strings.TrimSpace(l.ApiId)) == 0 {
+		return errors.New("Log missing required apiId")
+	}
+
+	if len(strings.TrimSpace(l.ApiName)) == 0 {
+		return errors.New("Log missing required apiName")
+	}
+
+	if len(strings.TrimSpace(l.ApiVersion)) == 0 {
+		return errors.New("Log missing required apiVersion")
+	}
+
+	if len(strings.TrimSpace(l.ResourceName)) == 0 {
+		return errors.New("Log missing required resourceName")
+	}
+
+	if len(strings.TrimSpace(l.Result)) == 0 {
+		return errors.New("Log missing required result")
+	}
+
+	if l.Protocol != publishserviceapi.ProtocolHTTP11 && l.Protocol != publishserviceapi.ProtocolHTTP2 {
+		return errors.New("Log has invalid protocol")
+	}
+	return nil
+}
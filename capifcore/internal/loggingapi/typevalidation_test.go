@@ -0,0 +1,121 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package loggingapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"oransc.org/nonrtric/capifcore/internal/publishserviceapi"
+)
+
+func TestValidateInvocationLog(t *testing.T) {
+	logUnderTest := InvocationLog{}
+
+	err := logUnderTest.Validate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "missing")
+		assert.Contains(t, err.Error(), "aefId")
+	}
+
+	logUnderTest.AefId = "aefId"
+	err = logUnderTest.Validate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "missing")
+		assert.Contains(t, err.Error(), "apiInvokerId")
+	}
+
+	logUnderTest.ApiInvokerId = "invokerId"
+	err = logUnderTest.Validate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "missing")
+		assert.Contains(t, err.Error(), "logs")
+	}
+
+	logUnderTest.Logs = []Log{{}}
+	err = logUnderTest.Validate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "invalid log at index 0")
+		assert.Contains(t, err.Error(), "apiId")
+	}
+
+	logUnderTest.Logs = []Log{getLog()}
+	assert.Nil(t, logUnderTest.Validate())
+}
+
+func TestValidateLog(t *testing.T) {
+	logUnderTest := Log{}
+
+	err := logUnderTest.Validate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "missing")
+		assert.Contains(t, err.Error(), "apiId")
+	}
+
+	logUnderTest.ApiId = "apiId"
+	err = logUnderTest.Validate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "missing")
+		assert.Contains(t, err.Error(), "apiName")
+	}
+
+	logUnderTest.ApiName = "apiName"
+	err = logUnderTest.Validate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "missing")
+		assert.Contains(t, err.Error(), "apiVersion")
+	}
+
+	logUnderTest.ApiVersion = "v1"
+	err = logUnderTest.Validate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "missing")
+		assert.Contains(t, err.Error(), "resourceName")
+	}
+
+	logUnderTest.ResourceName = "resource"
+	err = logUnderTest.Validate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "missing")
+		assert.Contains(t, err.Error(), "result")
+	}
+
+	logUnderTest.Result = "200"
+	err = logUnderTest.Validate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "invalid")
+		assert.Contains(t, err.Error(), "protocol")
+	}
+
+	logUnderTest.Protocol = publishserviceapi.ProtocolHTTP2
+	assert.Nil(t, logUnderTest.Validate())
+}
+
+func getLog() Log {
+	return Log{
+		ApiId:        "apiId",
+		ApiName:      "apiName",
+		ApiVersion:   "v1",
+		Protocol:     publishserviceapi.ProtocolHTTP11,
+		ResourceName: "resource",
+		Result:       "200",
+	}
+}
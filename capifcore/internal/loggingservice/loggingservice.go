@@ -0,0 +1,192 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package loggingservice
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"sync"
+
+	"github.com/google/uuid"
+	echo "github.com/labstack/echo/v4"
+	"k8s.io/utils/strings/slices"
+
+	"oransc.org/nonrtric/capifcore/internal/eventsapi"
+	"oransc.org/nonrtric/capifcore/internal/invokermanagement"
+	"oransc.org/nonrtric/capifcore/internal/loggingapi"
+	"oransc.org/nonrtric/capifcore/internal/providermanagement"
+	"oransc.org/nonrtric/capifcore/internal/publishservice"
+	"oransc.org/nonrtric/capifcore/internal/storage"
+	"oransc.org/nonrtric/capifcore/problemdetails"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const invocationLogsBucket = "invocationLogs"
+
+//go:generate mockery --name LoggingRegister
+type LoggingRegister interface {
+	// Gets all stored invocation logs.
+	// Returns a list of all invocation logs that have been stored.
+	GetAllInvocationLogs() []loggingapi.InvocationLog
+}
+
+type LoggingService struct {
+	invocationLogs  map[string]loggingapi.InvocationLog
+	serviceRegister providermanagement.ServiceRegister
+	publishRegister publishservice.PublishRegister
+	invokerRegister invokermanagement.InvokerRegister
+	eventChannel    chan<- eventsapi.EventNotification
+	store           storage.Store
+	lock            sync.Mutex
+}
+
+// Creates a service that implements both the LoggingRegister and the loggingapi.ServerInterface interfaces.
+// Invocation logs stored in the provided store are loaded at creation.
+func NewLoggingService(serviceRegister providermanagement.ServiceRegister, publishRegister publishservice.PublishRegister, invokerRegister invokermanagement.InvokerRegister, eventChannel chan<- eventsapi.EventNotification, store storage.Store) *LoggingService {
+	ls := &LoggingService{
+		invocationLogs:  make(map[string]loggingapi.InvocationLog),
+		serviceRegister: serviceRegister,
+		publishRegister: publishRegister,
+		invokerRegister: invokerRegister,
+		eventChannel:    eventChannel,
+		store:           store,
+	}
+	if err := storage.Load(store, invocationLogsBucket, ls.invocationLogs); err != nil {
+		log.Errorf("Unable to load invocation logs due to %s", err)
+	}
+	return ls
+}
+
+func (ls *LoggingService) GetAllInvocationLogs() []loggingapi.InvocationLog {
+	ls.lock.Lock()
+	defer ls.lock.Unlock()
+
+	invocationLogs := []loggingapi.InvocationLog{}
+	for _, invocationLog := range ls.invocationLogs {
+		invocationLogs = append(invocationLogs, invocationLog)
+	}
+	return invocationLogs
+}
+
+// Creates a new log entry for service API invocations.
+func (ls *LoggingService) PostAefIdLogs(ctx echo.Context, aefId string) error {
+	errMsg := "Unable to store invocation logs due to %s"
+
+	invocationLog, err := getInvocationLogFromRequest(ctx)
+	if err != nil {
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseInvalidMsgFormat, fmt.Sprintf(errMsg, err))
+	}
+
+	if err = invocationLog.Validate(); err != nil {
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseMandatoryIeIncorrect, fmt.Sprintf(errMsg, err))
+	}
+
+	if invocationLog.AefId != aefId {
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseMandatoryIeIncorrect, fmt.Sprintf(errMsg, "InvocationLog aefId doesn't match path parameter"))
+	}
+
+	if !ls.serviceRegister.IsFunctionRegistered(aefId) {
+		return problemdetails.Send(ctx, http.StatusForbidden, problemdetails.CauseRequestNotAuthorized, fmt.Sprintf(errMsg, "api is only available for registered exposing functions "+aefId))
+	}
+
+	if !ls.invokerRegister.IsInvokerRegistered(invocationLog.ApiInvokerId) {
+		return problemdetails.Send(ctx, http.StatusNotFound, problemdetails.CauseContextNotFound, fmt.Sprintf(errMsg, fmt.Sprintf("invoker %s not registered", invocationLog.ApiInvokerId)))
+	}
+
+	if err = ls.checkApisPublished(aefId, invocationLog.Logs); err != nil {
+		return problemdetails.Send(ctx, http.StatusNotFound, problemdetails.CauseContextNotFound, fmt.Sprintf(errMsg, err))
+	}
+
+	logId := ls.addInvocationLog(invocationLog)
+
+	go ls.sendEvents(invocationLog)
+
+	uri := ctx.Request().Host + ctx.Request().URL.String()
+	ctx.Response().Header().Set(echo.HeaderLocation, ctx.Scheme()+`://`+path.Join(uri, logId))
+	err = ctx.JSON(http.StatusCreated, invocationLog)
+	if err != nil {
+		// Something really bad happened, tell Echo that our handler failed
+		return err
+	}
+
+	return nil
+}
+
+func getInvocationLogFromRequest(ctx echo.Context) (loggingapi.InvocationLog, error) {
+	var invocationLog loggingapi.InvocationLog
+	if err := ctx.Bind(&invocationLog); err != nil {
+		return loggingapi.InvocationLog{}, fmt.Errorf("invalid format for invocation log")
+	}
+	return invocationLog, nil
+}
+
+func (ls *LoggingService) checkApisPublished(aefId string, logs []loggingapi.Log) error {
+	for _, invocation := range logs {
+		publishedService := ls.publishRegister.GetPublishedService(invocation.ApiId)
+		if publishedService == nil || !slices.Contains(publishedService.GetAefIds(), aefId) {
+			return fmt.Errorf("api %s not published by %s", invocation.ApiId, aefId)
+		}
+	}
+	return nil
+}
+
+func (ls *LoggingService) addInvocationLog(invocationLog loggingapi.InvocationLog) string {
+	ls.lock.Lock()
+	defer ls.lock.Unlock()
+
+	logId := uuid.NewString()
+	ls.invocationLogs[logId] = invocationLog
+	if err := ls.store.Put(invocationLogsBucket, logId, invocationLog); err != nil {
+		log.Errorf("Unable to store invocation log %s due to %s", logId, err)
+	}
+	return logId
+}
+
+// Sends one event per log, so that subscriptions filtering on API ids only get the invocations of their APIs.
+func (ls *LoggingService) sendEvents(invocationLog loggingapi.InvocationLog) {
+	for _, invocation := range invocationLog.Logs {
+		eventType := eventsapi.CAPIFEventSERVICEAPIINVOCATIONFAILURE
+		if invocation.IsSuccess() {
+			eventType = eventsapi.CAPIFEventSERVICEAPIINVOCATIONSUCCESS
+		}
+		apiIds := []string{invocation.ApiId}
+		invokerIds := []string{invocationLog.ApiInvokerId}
+		invocationLogs := []loggingapi.InvocationLog{
+			{
+				AefId:             invocationLog.AefId,
+				ApiInvokerId:      invocationLog.ApiInvokerId,
+				Logs:              []loggingapi.Log{invocation},
+				SupportedFeatures: invocationLog.SupportedFeatures,
+			},
+		}
+		event := eventsapi.EventNotification{
+			EventDetail: &eventsapi.CAPIFEventDetail{
+				ApiIds:         &apiIds,
+				ApiInvokerIds:  &invokerIds,
+				InvocationLogs: &invocationLogs,
+			},
+			Events: eventType,
+		}
+		ls.eventChannel <- event
+	}
+}
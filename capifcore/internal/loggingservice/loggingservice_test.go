@@ -0,0 +1,299 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package loggingservice
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/deepmap/oapi-codegen/pkg/middleware"
+	"github.com/deepmap/oapi-codegen/pkg/testutil"
+	"github.com/labstack/echo/v4"
+	echomiddleware "github.com/labstack/echo/v4/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"oransc.org/nonrtric/capifcore/internal/common29122"
+	"oransc.org/nonrtric/capifcore/internal/eventsapi"
+	"oransc.org/nonrtric/capifcore/internal/invokermanagement"
+	invokermocks "oransc.org/nonrtric/capifcore/internal/invokermanagement/mocks"
+	"oransc.org/nonrtric/capifcore/internal/loggingapi"
+	"oransc.org/nonrtric/capifcore/internal/providermanagement"
+	servicemocks "oransc.org/nonrtric/capifcore/internal/providermanagement/mocks"
+	"oransc.org/nonrtric/capifcore/internal/publishservice"
+	publishmocks "oransc.org/nonrtric/capifcore/internal/publishservice/mocks"
+	"oransc.org/nonrtric/capifcore/internal/publishserviceapi"
+	"oransc.org/nonrtric/capifcore/internal/storage/storagetest"
+)
+
+func TestPostLogs(t *testing.T) {
+	aefId := "aefId"
+	invokerId := "invokerId"
+	apiId := "apiId"
+	serviceRegisterMock := servicemocks.ServiceRegister{}
+	serviceRegisterMock.On("IsFunctionRegistered", aefId).Return(true)
+	invokerRegisterMock := invokermocks.InvokerRegister{}
+	invokerRegisterMock.On("IsInvokerRegistered", invokerId).Return(true)
+	publishRegisterMock := publishmocks.PublishRegister{}
+	publishRegisterMock.On("GetPublishedService", apiId).Return(getServiceAPIDescription(aefId))
+	requestHandler, loggingUnderTest, eventChannel := getEcho(&serviceRegisterMock, &publishRegisterMock, &invokerRegisterMock)
+
+	invocationLog := getInvocationLog(aefId, invokerId, apiId)
+	result := testutil.NewRequest().Post("/"+aefId+"/logs").WithJsonBody(invocationLog).Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusCreated, result.Code())
+	var resultLog loggingapi.InvocationLog
+	err := result.UnmarshalJsonToObject(&resultLog)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, invocationLog, resultLog)
+	location := result.Recorder.Header().Get(echo.HeaderLocation)
+	assert.True(t, strings.HasPrefix(location, "http://example.com/"+aefId+"/logs/"))
+	logId := getLogId(location)
+	assert.Equal(t, invocationLog, loggingUnderTest.invocationLogs[logId])
+	serviceRegisterMock.AssertCalled(t, "IsFunctionRegistered", aefId)
+	invokerRegisterMock.AssertCalled(t, "IsInvokerRegistered", invokerId)
+	publishRegisterMock.AssertCalled(t, "GetPublishedService", apiId)
+
+	if invocationEvent, ok := waitForEvent(eventChannel, 1*time.Second); ok {
+		assert.Fail(t, "No event sent")
+	} else {
+		assert.Equal(t, eventsapi.CAPIFEventSERVICEAPIINVOCATIONSUCCESS, invocationEvent.Events)
+		assert.Equal(t, []string{apiId}, *invocationEvent.EventDetail.ApiIds)
+		assert.Equal(t, []string{invokerId}, *invocationEvent.EventDetail.ApiInvokerIds)
+		assert.Equal(t, []loggingapi.InvocationLog{invocationLog}, *invocationEvent.EventDetail.InvocationLogs)
+	}
+}
+
+func TestPostLogsSendsOneEventPerLog(t *testing.T) {
+	aefId := "aefId"
+	invokerId := "invokerId"
+	serviceRegisterMock := servicemocks.ServiceRegister{}
+	serviceRegisterMock.On("IsFunctionRegistered", aefId).Return(true)
+	invokerRegisterMock := invokermocks.InvokerRegister{}
+	invokerRegisterMock.On("IsInvokerRegistered", invokerId).Return(true)
+	publishRegisterMock := publishmocks.PublishRegister{}
+	publishRegisterMock.On("GetPublishedService", mock.AnythingOfType("string")).Return(getServiceAPIDescription(aefId))
+	requestHandler, _, eventChannel := getEcho(&serviceRegisterMock, &publishRegisterMock, &invokerRegisterMock)
+
+	invocationLog := getInvocationLog(aefId, invokerId, "apiId1")
+	failedLog := getInvocationLog(aefId, invokerId, "apiId2").Logs[0]
+	failedLog.Result = "500"
+	invocationLog.Logs = append(invocationLog.Logs, failedLog)
+	result := testutil.NewRequest().Post("/"+aefId+"/logs").WithJsonBody(invocationLog).Go(t, requestHandler)
+	assert.Equal(t, http.StatusCreated, result.Code())
+
+	if successEvent, ok := waitForEvent(eventChannel, 1*time.Second); ok {
+		assert.Fail(t, "No event sent")
+	} else {
+		assert.Equal(t, eventsapi.CAPIFEventSERVICEAPIINVOCATIONSUCCESS, successEvent.Events)
+		assert.Equal(t, []string{"apiId1"}, *successEvent.EventDetail.ApiIds)
+		assert.Equal(t, []loggingapi.Log{invocationLog.Logs[0]}, (*successEvent.EventDetail.InvocationLogs)[0].Logs)
+	}
+	if failureEvent, ok := waitForEvent(eventChannel, 1*time.Second); ok {
+		assert.Fail(t, "No event sent")
+	} else {
+		assert.Equal(t, eventsapi.CAPIFEventSERVICEAPIINVOCATIONFAILURE, failureEvent.Events)
+		assert.Equal(t, []string{"apiId2"}, *failureEvent.EventDetail.ApiIds)
+		assert.Equal(t, aefId, (*failureEvent.EventDetail.InvocationLogs)[0].AefId)
+		assert.Equal(t, []loggingapi.Log{failedLog}, (*failureEvent.EventDetail.InvocationLogs)[0].Logs)
+	}
+}
+
+func TestPostLogsInvalidLog(t *testing.T) {
+	aefId := "aefId"
+	requestHandler, _, _ := getEcho(nil, nil, nil)
+
+	invocationLog := getInvocationLog(aefId, "invokerId", "apiId")
+	invocationLog.Logs[0].ApiName = ""
+	result := testutil.NewRequest().Post("/"+aefId+"/logs").WithJsonBody(invocationLog).Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusBadRequest, result.Code())
+	var problemDetails common29122.ProblemDetails
+	err := result.UnmarshalJsonToObject(&problemDetails)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, http.StatusBadRequest, *problemDetails.Status)
+	assert.Contains(t, *problemDetails.Detail, "missing")
+	assert.Contains(t, *problemDetails.Detail, "apiName")
+}
+
+func TestPostLogsAefIdMismatch(t *testing.T) {
+	requestHandler, _, _ := getEcho(nil, nil, nil)
+
+	invocationLog := getInvocationLog("otherAefId", "invokerId", "apiId")
+	result := testutil.NewRequest().Post("/aefId/logs").WithJsonBody(invocationLog).Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusBadRequest, result.Code())
+	var problemDetails common29122.ProblemDetails
+	err := result.UnmarshalJsonToObject(&problemDetails)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Contains(t, *problemDetails.Detail, "aefId doesn't match path parameter")
+}
+
+func TestPostLogsUnregisteredFunction(t *testing.T) {
+	aefId := "aefId"
+	serviceRegisterMock := servicemocks.ServiceRegister{}
+	serviceRegisterMock.On("IsFunctionRegistered", aefId).Return(false)
+	requestHandler, _, _ := getEcho(&serviceRegisterMock, nil, nil)
+
+	invocationLog := getInvocationLog(aefId, "invokerId", "apiId")
+	result := testutil.NewRequest().Post("/"+aefId+"/logs").WithJsonBody(invocationLog).Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusForbidden, result.Code())
+	var problemDetails common29122.ProblemDetails
+	err := result.UnmarshalJsonToObject(&problemDetails)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, http.StatusForbidden, *problemDetails.Status)
+	assert.Contains(t, *problemDetails.Detail, "registered exposing functions")
+}
+
+func TestPostLogsUnregisteredInvoker(t *testing.T) {
+	aefId := "aefId"
+	invokerId := "invokerId"
+	serviceRegisterMock := servicemocks.ServiceRegister{}
+	serviceRegisterMock.On("IsFunctionRegistered", aefId).Return(true)
+	invokerRegisterMock := invokermocks.InvokerRegister{}
+	invokerRegisterMock.On("IsInvokerRegistered", invokerId).Return(false)
+	requestHandler, _, _ := getEcho(&serviceRegisterMock, nil, &invokerRegisterMock)
+
+	invocationLog := getInvocationLog(aefId, invokerId, "apiId")
+	result := testutil.NewRequest().Post("/"+aefId+"/logs").WithJsonBody(invocationLog).Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusNotFound, result.Code())
+	var problemDetails common29122.ProblemDetails
+	err := result.UnmarshalJsonToObject(&problemDetails)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Contains(t, *problemDetails.Detail, "invoker invokerId not registered")
+}
+
+func TestPostLogsUnpublishedApi(t *testing.T) {
+	aefId := "aefId"
+	invokerId := "invokerId"
+	serviceRegisterMock := servicemocks.ServiceRegister{}
+	serviceRegisterMock.On("IsFunctionRegistered", aefId).Return(true)
+	invokerRegisterMock := invokermocks.InvokerRegister{}
+	invokerRegisterMock.On("IsInvokerRegistered", invokerId).Return(true)
+	publishRegisterMock := publishmocks.PublishRegister{}
+	publishRegisterMock.On("GetPublishedService", "unknownApi").Return(nil)
+	publishRegisterMock.On("GetPublishedService", "otherAefsApi").Return(getServiceAPIDescription("otherAefId"))
+	requestHandler, loggingUnderTest, _ := getEcho(&serviceRegisterMock, &publishRegisterMock, &invokerRegisterMock)
+
+	invocationLog := getInvocationLog(aefId, invokerId, "unknownApi")
+	result := testutil.NewRequest().Post("/"+aefId+"/logs").WithJsonBody(invocationLog).Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusNotFound, result.Code())
+	var problemDetails common29122.ProblemDetails
+	err := result.UnmarshalJsonToObject(&problemDetails)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Contains(t, *problemDetails.Detail, "api unknownApi not published by aefId")
+
+	invocationLog = getInvocationLog(aefId, invokerId, "otherAefsApi")
+	result = testutil.NewRequest().Post("/"+aefId+"/logs").WithJsonBody(invocationLog).Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusNotFound, result.Code())
+	err = result.UnmarshalJsonToObject(&problemDetails)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Contains(t, *problemDetails.Detail, "api otherAefsApi not published by aefId")
+	assert.Empty(t, loggingUnderTest.invocationLogs)
+}
+
+func TestGetAllInvocationLogs(t *testing.T) {
+	loggingUnderTest := NewLoggingService(nil, nil, nil, nil, storagetest.NewStore())
+	assert.Empty(t, loggingUnderTest.GetAllInvocationLogs())
+
+	invocationLog1 := getInvocationLog("aefId1", "invokerId", "apiId1")
+	invocationLog2 := getInvocationLog("aefId2", "invokerId", "apiId2")
+	loggingUnderTest.addInvocationLog(invocationLog1)
+	loggingUnderTest.addInvocationLog(invocationLog2)
+
+	result := loggingUnderTest.GetAllInvocationLogs()
+	assert.Len(t, result, 2)
+	assert.Contains(t, result, invocationLog1)
+	assert.Contains(t, result, invocationLog2)
+}
+
+func getEcho(serviceRegister providermanagement.ServiceRegister, publishRegister publishservice.PublishRegister, invokerRegister invokermanagement.InvokerRegister) (*echo.Echo, *LoggingService, chan eventsapi.EventNotification) {
+	swagger, err := loggingapi.GetSwagger()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading swagger spec\n: %s", err)
+		os.Exit(1)
+	}
+
+	swagger.Servers = nil
+
+	eventChannel := make(chan eventsapi.EventNotification)
+	ls := NewLoggingService(serviceRegister, publishRegister, invokerRegister, eventChannel, storagetest.NewStore())
+
+	e := echo.New()
+	e.Use(echomiddleware.Logger())
+	e.Use(middleware.OapiRequestValidator(swagger))
+
+	loggingapi.RegisterHandlers(e, ls)
+	return e, ls, eventChannel
+}
+
+func getInvocationLog(aefId, invokerId, apiId string) loggingapi.InvocationLog {
+	invocationTime := common29122.DateTime(time.Date(2026, time.January, 2, 3, 4, 5, 0, time.UTC))
+	return loggingapi.InvocationLog{
+		AefId:        aefId,
+		ApiInvokerId: invokerId,
+		Logs: []loggingapi.Log{
+			{
+				ApiId:          apiId,
+				ApiName:        "apiName",
+				ApiVersion:     "v1",
+				InvocationTime: &invocationTime,
+				Protocol:       publishserviceapi.ProtocolHTTP11,
+				ResourceName:   "resource",
+				Result:         "201",
+			},
+		},
+	}
+}
+
+func getServiceAPIDescription(aefId string) *publishserviceapi.ServiceAPIDescription {
+	return &publishserviceapi.ServiceAPIDescription{
+		AefProfiles: &[]publishserviceapi.AefProfile{
+			{
+				AefId: aefId,
+			},
+		},
+		ApiName: "apiName",
+	}
+}
+
+func getLogId(location string) string {
+	return location[strings.LastIndex(location, "/")+1:]
+}
+
+// waitForEvent waits for the channel to receive an event for the specified max timeout.
+// Returns true if waiting timed out.
+func waitForEvent(ch chan eventsapi.EventNotification, timeout time.Duration) (*eventsapi.EventNotification, bool) {
+	select {
+	case event := <-ch:
+		return &event, false // completed normally
+	case <-time.After(timeout):
+		return nil, true // timed out
+	}
+}
@@ -25,6 +25,34 @@ func (_m *ServiceRegister) GetAefsForPublisher(apfId string) []string {
 	return r0
 }
 
+// GetFunctionCertificate provides a mock function with given fields: functionId
+func (_m *ServiceRegister) GetFunctionCertificate(functionId string) string {
+	ret := _m.Called(functionId)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(string) string); ok {
+		r0 = rf(functionId)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// GetProviderDomain provides a mock function with given fields: functionId
+func (_m *ServiceRegister) GetProviderDomain(functionId string) string {
+	ret := _m.Called(functionId)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(string) string); ok {
+		r0 = rf(functionId)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
 // IsFunctionRegistered provides a mock function with given fields: functionId
 func (_m *ServiceRegister) IsFunctionRegistered(functionId string) bool {
 	ret := _m.Called(functionId)
@@ -39,6 +67,20 @@ func (_m *ServiceRegister) IsFunctionRegistered(functionId string) bool {
 	return r0
 }
 
+// IsFunctionRegisteredForProvider provides a mock function with given fields: registrationId, functionId
+func (_m *ServiceRegister) IsFunctionRegisteredForProvider(registrationId string, functionId string) bool {
+	ret := _m.Called(registrationId, functionId)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string, string) bool); ok {
+		r0 = rf(registrationId, functionId)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
 // IsPublishingFunctionRegistered provides a mock function with given fields: apiProvFuncId
 func (_m *ServiceRegister) IsPublishingFunctionRegistered(apiProvFuncId string) bool {
 	ret := _m.Called(apiProvFuncId)
@@ -53,6 +95,20 @@ func (_m *ServiceRegister) IsPublishingFunctionRegistered(apiProvFuncId string)
 	return r0
 }
 
+// VerifyFunctionSecret provides a mock function with given fields: functionId, secret
+func (_m *ServiceRegister) VerifyFunctionSecret(functionId string, secret string) bool {
+	ret := _m.Called(functionId, secret)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string, string) bool); ok {
+		r0 = rf(functionId, secret)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
 // NewServiceRegister creates a new instance of ServiceRegister. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewServiceRegister(t interface {
@@ -30,29 +30,76 @@ import (
 	echo "github.com/labstack/echo/v4"
 	log "github.com/sirupsen/logrus"
 
-	"oransc.org/nonrtric/capifcore/internal/common29122"
+	"oransc.org/nonrtric/capifcore/internal/certauthority"
+	"oransc.org/nonrtric/capifcore/internal/eventsapi"
 	provapi "oransc.org/nonrtric/capifcore/internal/providermanagementapi"
+	publishapi "oransc.org/nonrtric/capifcore/internal/publishserviceapi"
+	"oransc.org/nonrtric/capifcore/internal/storage"
+	"oransc.org/nonrtric/capifcore/problemdetails"
+	"oransc.org/nonrtric/capifcore/validation"
 )
 
+const providersBucket = "providers"
+
 //go:generate mockery --name ServiceRegister
 type ServiceRegister interface {
 	IsFunctionRegistered(functionId string) bool
 	GetAefsForPublisher(apfId string) []string
 	IsPublishingFunctionRegistered(apiProvFuncId string) bool
+	IsFunctionRegisteredForProvider(registrationId string, functionId string) bool
+	// Gets the domain, i.e. the registration id, of the provider that has registered the provided function.
+	// Returns the domain, or an empty string if the function is not registered.
+	GetProviderDomain(functionId string) string
+	// Checks that the provided secret is the registration secret, regSec, of the provider that has registered the
+	// provided function.
+	VerifyFunctionSecret(functionId, secret string) bool
+	// Gets the client certificate CAPIF core has issued for the provided function.
+	// Returns the PEM encoded certificate, or an empty string if no certificate has been issued for the function.
+	GetFunctionCertificate(functionId string) string
+}
+
+//go:generate mockery --name ServicePublisher
+type ServicePublisher interface {
+	// Removes all APIs published by the provided API publishing function, together with their Helm charts.
+	// Returns a list of the removed APIs, empty if the function has not published any APIs.
+	UnpublishServices(apfId string) []publishapi.ServiceAPIDescription
 }
 
 type ProviderManager struct {
 	registeredProviders map[string]provapi.APIProviderEnrolmentDetails
+	servicePublisher    ServicePublisher
+	certificateIssuer   certauthority.CertificateIssuer
+	eventChannel        chan<- eventsapi.EventNotification
+	store               storage.Store
 	lock                sync.Mutex
 }
 
-func NewProviderManager() *ProviderManager {
-	return &ProviderManager{
+// Creates a manager that implements both the ServiceRegister and the providermanagementapi.ServerInterface interfaces.
+// Providers registered in the provided store are loaded at creation.
+// If a certificate issuer is provided, the API provider functions get certificates for their public keys.
+func NewProviderManager(certificateIssuer certauthority.CertificateIssuer, eventChannel chan<- eventsapi.EventNotification, store storage.Store) *ProviderManager {
+	pm := &ProviderManager{
 		registeredProviders: make(map[string]provapi.APIProviderEnrolmentDetails),
+		certificateIssuer:   certificateIssuer,
+		eventChannel:        eventChannel,
+		store:               store,
+	}
+	if err := storage.Load(store, providersBucket, pm.registeredProviders); err != nil {
+		log.Errorf("Unable to load registered providers due to %s", err)
 	}
+	return pm
+}
+
+// Sets the publisher of the APIs exposed by the registered providers. The publisher needs the provider manager to be
+// created, so it cannot be given at creation of the manager.
+func (pm *ProviderManager) SetServicePublisher(servicePublisher ServicePublisher) {
+	pm.servicePublisher = servicePublisher
 }
 
 func (pm *ProviderManager) IsFunctionRegistered(functionId string) bool {
+	pm.lock.Lock()
+	defer pm.lock.Unlock()
+
 	for _, provider := range pm.registeredProviders {
 		if provider.IsFunctionRegistered(functionId) {
 			return true
@@ -62,6 +109,9 @@ func (pm *ProviderManager) IsFunctionRegistered(functionId string) bool {
 }
 
 func (pm *ProviderManager) GetAefsForPublisher(apfId string) []string {
+	pm.lock.Lock()
+	defer pm.lock.Unlock()
+
 	for _, provider := range pm.registeredProviders {
 		if aefs := provider.GetExposingFunctionIdsForPublisher(apfId); aefs != nil {
 			return aefs
@@ -71,6 +121,9 @@ func (pm *ProviderManager) GetAefsForPublisher(apfId string) []string {
 }
 
 func (pm *ProviderManager) IsPublishingFunctionRegistered(apiProvFuncId string) bool {
+	pm.lock.Lock()
+	defer pm.lock.Unlock()
+
 	for _, provider := range pm.registeredProviders {
 		if provider.IsPublishingFunctionRegistered(apiProvFuncId) {
 			return true
@@ -79,22 +132,72 @@ func (pm *ProviderManager) IsPublishingFunctionRegistered(apiProvFuncId string)
 	return false
 }
 
+func (pm *ProviderManager) IsFunctionRegisteredForProvider(registrationId string, functionId string) bool {
+	pm.lock.Lock()
+	defer pm.lock.Unlock()
+
+	if provider, ok := pm.registeredProviders[registrationId]; ok {
+		return provider.IsFunctionRegistered(functionId)
+	}
+	return false
+}
+
+func (pm *ProviderManager) GetProviderDomain(functionId string) string {
+	pm.lock.Lock()
+	defer pm.lock.Unlock()
+
+	for registrationId, provider := range pm.registeredProviders {
+		if provider.IsFunctionRegistered(functionId) {
+			return registrationId
+		}
+	}
+	return ""
+}
+
+func (pm *ProviderManager) VerifyFunctionSecret(functionId, secret string) bool {
+	pm.lock.Lock()
+	defer pm.lock.Unlock()
+
+	for _, provider := range pm.registeredProviders {
+		if provider.IsFunctionRegistered(functionId) {
+			return provider.RegSec == secret
+		}
+	}
+	return false
+}
+
+func (pm *ProviderManager) GetFunctionCertificate(functionId string) string {
+	pm.lock.Lock()
+	defer pm.lock.Unlock()
+
+	for _, provider := range pm.registeredProviders {
+		if function := getFunction(&provider, functionId); function != nil && function.RegInfo.ApiProvCert != nil {
+			return *function.RegInfo.ApiProvCert
+		}
+	}
+	return ""
+}
+
 func (pm *ProviderManager) PostRegistrations(ctx echo.Context) error {
 	var newProvider provapi.APIProviderEnrolmentDetails
 	errMsg := "Unable to register provider due to %s"
 	if err := ctx.Bind(&newProvider); err != nil {
-		return sendCoreError(ctx, http.StatusBadRequest, fmt.Sprintf(errMsg, "invalid format for provider"))
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseInvalidMsgFormat, fmt.Sprintf(errMsg, "invalid format for provider"))
 	}
 
 	if err := pm.isProviderRegistered(newProvider); err != nil {
-		return sendCoreError(ctx, http.StatusForbidden, fmt.Sprintf(errMsg, err))
+		return problemdetails.Send(ctx, http.StatusForbidden, problemdetails.CauseModificationNotAllowed, fmt.Sprintf(errMsg, err))
+	}
+
+	if err := pm.validateProvider(newProvider); err != nil {
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseMandatoryIeIncorrect, fmt.Sprintf(errMsg, err))
 	}
 
-	if err := newProvider.Validate(); err != nil {
-		return sendCoreError(ctx, http.StatusBadRequest, fmt.Sprintf(errMsg, err))
+	if err := pm.prepareNewProvider(&newProvider); err != nil {
+		return problemdetails.Send(ctx, http.StatusInternalServerError, problemdetails.CauseSystemFailure, fmt.Sprintf(errMsg, err))
 	}
 
-	pm.prepareNewProvider(&newProvider)
+	go pm.sendEvent(newProvider, eventsapi.CAPIFEventAPIPROVIDERREGISTERED)
 
 	uri := ctx.Request().Host + ctx.Request().URL.String()
 	ctx.Response().Header().Set(echo.HeaderLocation, ctx.Scheme()+`://`+path.Join(uri, *newProvider.ApiProvDomId))
@@ -106,6 +209,9 @@ func (pm *ProviderManager) PostRegistrations(ctx echo.Context) error {
 }
 
 func (pm *ProviderManager) isProviderRegistered(newProvider provapi.APIProviderEnrolmentDetails) error {
+	pm.lock.Lock()
+	defer pm.lock.Unlock()
+
 	for _, prov := range pm.registeredProviders {
 		if err := prov.ValidateAlreadyRegistered(newProvider); err != nil {
 			return err
@@ -114,55 +220,88 @@ func (pm *ProviderManager) isProviderRegistered(newProvider provapi.APIProviderE
 	return nil
 }
 
-func (pm *ProviderManager) prepareNewProvider(newProvider *provapi.APIProviderEnrolmentDetails) {
+func (pm *ProviderManager) prepareNewProvider(newProvider *provapi.APIProviderEnrolmentDetails) error {
 	pm.lock.Lock()
 	defer pm.lock.Unlock()
 
 	newProvider.PrepareNewProvider()
+	if err := pm.issueCertificates(newProvider, nil); err != nil {
+		return err
+	}
 	pm.registeredProviders[*newProvider.ApiProvDomId] = *newProvider
+	pm.storeProvider(*newProvider)
+	return nil
 }
 
 func (pm *ProviderManager) DeleteRegistrationsRegistrationId(ctx echo.Context, registrationId string) error {
-	log.Debug(pm.registeredProviders)
-	if _, ok := pm.registeredProviders[registrationId]; ok {
-		pm.deleteProvider(registrationId)
+	if provider, ok := pm.deleteProvider(registrationId); ok {
+		go pm.sendDeregistrationEvents(provider, pm.unpublishServices(provider))
 	}
 	return ctx.NoContent(http.StatusNoContent)
 }
 
-func (pm *ProviderManager) deleteProvider(registrationId string) {
-	log.Debug("Deleting provider", registrationId)
+// The APIs published by a provider's functions are removed together with the provider.
+func (pm *ProviderManager) unpublishServices(provider provapi.APIProviderEnrolmentDetails) []publishapi.ServiceAPIDescription {
+	services := []publishapi.ServiceAPIDescription{}
+	if pm.servicePublisher == nil || provider.ApiProvFuncs == nil {
+		return services
+	}
+	for _, function := range *provider.ApiProvFuncs {
+		if function.ApiProvFuncRole == provapi.ApiProviderFuncRoleAPF && function.ApiProvFuncId != nil {
+			services = append(services, pm.servicePublisher.UnpublishServices(*function.ApiProvFuncId)...)
+		}
+	}
+	return services
+}
+
+func (pm *ProviderManager) deleteProvider(registrationId string) (provapi.APIProviderEnrolmentDetails, bool) {
 	pm.lock.Lock()
 	defer pm.lock.Unlock()
+
+	provider, ok := pm.registeredProviders[registrationId]
+	if !ok {
+		return provider, false
+	}
+	log.Debug("Deleting provider", registrationId)
 	delete(pm.registeredProviders, registrationId)
+	if err := pm.store.Delete(providersBucket, registrationId); err != nil {
+		log.Errorf("Unable to remove stored provider %s due to %s", registrationId, err)
+	}
+	return provider, true
 }
 
 func (pm *ProviderManager) PutRegistrationsRegistrationId(ctx echo.Context, registrationId string) error {
 	errMsg := "Unable to update provider due to %s."
 	registeredProvider, err := pm.checkIfProviderIsRegistered(registrationId)
 	if err != nil {
-		return sendCoreError(ctx, http.StatusBadRequest, fmt.Sprintf(errMsg, err))
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseContextNotFound, fmt.Sprintf(errMsg, err))
 	}
 
 	updatedProvider, err := getProviderFromRequest(ctx)
 	if err != nil {
-		return sendCoreError(ctx, http.StatusBadRequest, fmt.Sprintf(errMsg, err))
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseInvalidMsgFormat, fmt.Sprintf(errMsg, err))
 	}
 
-	if updatedProvider.Validate() != nil {
-		return sendCoreError(ctx, http.StatusBadRequest, fmt.Sprintf(errMsg, err))
+	if err = pm.validateProvider(updatedProvider); err != nil {
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseMandatoryIeIncorrect, fmt.Sprintf(errMsg, err))
 	}
 
 	// Additional validation for PUT
 	if (updatedProvider.ApiProvDomId == nil) || (*updatedProvider.ApiProvDomId != registrationId) {
 		errDetail := "APIProviderEnrolmentDetails ApiProvDomId doesn't match path parameter"
-		return sendCoreError(ctx, http.StatusBadRequest, fmt.Sprintf(errMsg, errDetail))
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseMandatoryIeIncorrect, fmt.Sprintf(errMsg, errDetail))
+	}
+
+	if err = updatedProvider.UpdateFuncs(*registeredProvider); err != nil {
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseMandatoryIeIncorrect, fmt.Sprintf(errMsg, err))
 	}
 
-	if err = pm.updateProvider(updatedProvider, registeredProvider); err != nil {
-		return sendCoreError(ctx, http.StatusBadRequest, fmt.Sprintf(errMsg, err))
+	if err = pm.updateProvider(&updatedProvider, registeredProvider); err != nil {
+		return problemdetails.Send(ctx, http.StatusInternalServerError, problemdetails.CauseSystemFailure, fmt.Sprintf(errMsg, err))
 	}
 
+	go pm.sendEvent(updatedProvider, eventsapi.CAPIFEventAPIPROVIDERUPDATED)
+
 	if err = ctx.JSON(http.StatusOK, updatedProvider); err != nil {
 		// Something really bad happened, tell Echo that our handler failed
 		return err
@@ -170,11 +309,45 @@ func (pm *ProviderManager) PutRegistrationsRegistrationId(ctx echo.Context, regi
 	return nil
 }
 
+// Modifies an existing API provider domain. Only the attributes present in the request are changed.
 func (pm *ProviderManager) ModifyIndApiProviderEnrolment(ctx echo.Context, registrationId string) error {
-	return ctx.NoContent(http.StatusNotImplemented)
+	errMsg := "Unable to update provider due to %s."
+	registeredProvider, err := pm.checkIfProviderIsRegistered(registrationId)
+	if err != nil {
+		return problemdetails.Send(ctx, http.StatusNotFound, problemdetails.CauseContextNotFound, fmt.Sprintf(errMsg, err))
+	}
+
+	var patch provapi.APIProviderEnrolmentDetailsPatch
+	if err = validation.BindMergePatch(ctx, &patch); err != nil {
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseInvalidMsgFormat, fmt.Sprintf(errMsg, "invalid format for provider patch"))
+	}
+
+	patchedProvider := registeredProvider.ApplyPatch(patch)
+	if err = pm.validateProvider(patchedProvider); err != nil {
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseMandatoryIeIncorrect, fmt.Sprintf(errMsg, err))
+	}
+
+	if err = patchedProvider.UpdateFuncs(*registeredProvider); err != nil {
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseMandatoryIeIncorrect, fmt.Sprintf(errMsg, err))
+	}
+
+	if err = pm.updateProvider(&patchedProvider, registeredProvider); err != nil {
+		return problemdetails.Send(ctx, http.StatusInternalServerError, problemdetails.CauseSystemFailure, fmt.Sprintf(errMsg, err))
+	}
+
+	go pm.sendEvent(patchedProvider, eventsapi.CAPIFEventAPIPROVIDERUPDATED)
+
+	if err = ctx.JSON(http.StatusOK, patchedProvider); err != nil {
+		// Something really bad happened, tell Echo that our handler failed
+		return err
+	}
+	return nil
 }
 
 func (pm *ProviderManager) checkIfProviderIsRegistered(registrationId string) (*provapi.APIProviderEnrolmentDetails, error) {
+	pm.lock.Lock()
+	defer pm.lock.Unlock()
+
 	registeredProvider, ok := pm.registeredProviders[registrationId]
 	if !ok {
 		return nil, fmt.Errorf("provider not onboarded")
@@ -191,25 +364,102 @@ func getProviderFromRequest(ctx echo.Context) (provapi.APIProviderEnrolmentDetai
 	return updatedProvider, nil
 }
 
-func (pm *ProviderManager) updateProvider(updatedProvider provapi.APIProviderEnrolmentDetails, registeredProvider *provapi.APIProviderEnrolmentDetails) error {
+func (pm *ProviderManager) updateProvider(updatedProvider *provapi.APIProviderEnrolmentDetails, registeredProvider *provapi.APIProviderEnrolmentDetails) error {
 	pm.lock.Lock()
 	defer pm.lock.Unlock()
 
-	if err := updatedProvider.UpdateFuncs(*registeredProvider); err == nil {
-		pm.registeredProviders[*updatedProvider.ApiProvDomId] = updatedProvider
-		return nil
-	} else {
+	if err := pm.issueCertificates(updatedProvider, registeredProvider); err != nil {
+		return err
+	}
+	pm.registeredProviders[*updatedProvider.ApiProvDomId] = *updatedProvider
+	pm.storeProvider(*updatedProvider)
+	return nil
+}
+
+func (pm *ProviderManager) validateProvider(provider provapi.APIProviderEnrolmentDetails) error {
+	if err := provider.Validate(); err != nil {
 		return err
 	}
+
+	if pm.certificateIssuer != nil && provider.ApiProvFuncs != nil {
+		for _, function := range *provider.ApiProvFuncs {
+			if _, err := certauthority.ParsePublicKey(function.RegInfo.ApiProvPubKey); err != nil {
+				return fmt.Errorf("apiProvFuncs contains function with invalid regInfo.apiProvPubKey, %s", err)
+			}
+		}
+	}
+	return nil
 }
 
-// This function wraps sending of an error in the Error format, and
-// handling the failure to marshal that.
-func sendCoreError(ctx echo.Context, code int, message string) error {
-	pd := common29122.ProblemDetails{
-		Cause:  &message,
-		Status: &code,
+// Issues certificates for the public keys of the provider's functions, if CAPIF core acts as a certificate authority.
+// Functions of the registered provider with the same public key keep their certificates. When the public key of a
+// function changes, its certificate is replaced and is no longer accepted as client certificate, see the clientauth
+// package.
+// Must be called with the lock held.
+func (pm *ProviderManager) issueCertificates(provider *provapi.APIProviderEnrolmentDetails, registeredProvider *provapi.APIProviderEnrolmentDetails) error {
+	if pm.certificateIssuer == nil || provider.ApiProvFuncs == nil {
+		return nil
+	}
+	for pos, function := range *provider.ApiProvFuncs {
+		regInfo := &(*provider.ApiProvFuncs)[pos].RegInfo
+		if registeredFunction := getFunction(registeredProvider, *function.ApiProvFuncId); registeredFunction != nil && registeredFunction.RegInfo.ApiProvPubKey == regInfo.ApiProvPubKey && registeredFunction.RegInfo.ApiProvCert != nil {
+			regInfo.ApiProvCert = registeredFunction.RegInfo.ApiProvCert
+			continue
+		}
+
+		regInfo.ApiProvCert = nil
+		// The public key has already been validated
+		publicKey, _ := certauthority.ParsePublicKey(regInfo.ApiProvPubKey)
+		certificate, err := pm.certificateIssuer.IssueCertificate(publicKey, *function.ApiProvFuncId)
+		if err != nil {
+			return fmt.Errorf("unable to issue certificate for function %s, %s", *function.ApiProvFuncId, err)
+		}
+		regInfo.ApiProvCert = &certificate
+	}
+	return nil
+}
+
+func getFunction(provider *provapi.APIProviderEnrolmentDetails, functionId string) *provapi.APIProviderFunctionDetails {
+	if provider == nil || provider.ApiProvFuncs == nil {
+		return nil
+	}
+	for _, function := range *provider.ApiProvFuncs {
+		if function.ApiProvFuncId != nil && *function.ApiProvFuncId == functionId {
+			return &function
+		}
+	}
+	return nil
+}
+
+func (pm *ProviderManager) storeProvider(provider provapi.APIProviderEnrolmentDetails) {
+	if err := pm.store.Put(providersBucket, *provider.ApiProvDomId, provider); err != nil {
+		log.Errorf("Unable to store provider %s due to %s", *provider.ApiProvDomId, err)
+	}
+}
+
+func (pm *ProviderManager) sendEvent(provider provapi.APIProviderEnrolmentDetails, eventType eventsapi.CAPIFEvent) {
+	event := eventsapi.EventNotification{
+		EventDetail: &eventsapi.CAPIFEventDetail{
+			ApiProviderDomInfo: &provider,
+		},
+		Events: eventType,
+	}
+	pm.eventChannel <- event
+}
+
+// The APIs published by the provider are no longer available when the provider is removed, so an unavailability
+// event is sent for each of them before the provider's deregistration event.
+func (pm *ProviderManager) sendDeregistrationEvents(provider provapi.APIProviderEnrolmentDetails, services []publishapi.ServiceAPIDescription) {
+	for _, service := range services {
+		apiIds := []string{*service.ApiId}
+		apis := []publishapi.ServiceAPIDescription{service}
+		pm.eventChannel <- eventsapi.EventNotification{
+			EventDetail: &eventsapi.CAPIFEventDetail{
+				ApiIds:                 &apiIds,
+				ServiceAPIDescriptions: &apis,
+			},
+			Events: eventsapi.CAPIFEventSERVICEAPIUNAVAILABLE,
+		}
 	}
-	err := ctx.JSON(code, pd)
-	return err
+	pm.sendEvent(provider, eventsapi.CAPIFEventAPIPROVIDERDEREGISTERED)
 }
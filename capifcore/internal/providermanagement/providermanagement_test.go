@@ -21,20 +21,36 @@
 package providermanagement
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/labstack/echo/v4"
 
+	certmocks "oransc.org/nonrtric/capifcore/internal/certauthority/mocks"
 	"oransc.org/nonrtric/capifcore/internal/common29122"
+	"oransc.org/nonrtric/capifcore/internal/eventsapi"
+	"oransc.org/nonrtric/capifcore/internal/providermanagement/mocks"
 	provapi "oransc.org/nonrtric/capifcore/internal/providermanagementapi"
+	publishapi "oransc.org/nonrtric/capifcore/internal/publishserviceapi"
+	"oransc.org/nonrtric/capifcore/internal/storage/storagetest"
+	"oransc.org/nonrtric/capifcore/problemdetails"
+	_ "oransc.org/nonrtric/capifcore/problemdetails/problemdetailstest"
 
 	"github.com/deepmap/oapi-codegen/pkg/middleware"
 	"github.com/deepmap/oapi-codegen/pkg/testutil"
+	"github.com/getkin/kin-openapi/openapi3filter"
 	echomiddleware "github.com/labstack/echo/v4/middleware"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 var (
@@ -50,7 +66,7 @@ var (
 )
 
 func TestFailedUpdateValidProviderWithNewFunction(t *testing.T) {
-	managerUnderTest, requestHandler := getEcho()
+	managerUnderTest, _, requestHandler := getEcho()
 
 	provider := getProvider()
 	provider.ApiProvDomId = &domainID
@@ -88,12 +104,12 @@ func TestFailedUpdateValidProviderWithNewFunction(t *testing.T) {
 	err := result.UnmarshalJsonToObject(&resultError)
 	assert.NoError(t, err, "error unmarshaling response")
 
-	assert.Contains(t, *resultError.Cause, "APIProviderEnrolmentDetails ApiProvDomId doesn't match path parameter")
+	assert.Contains(t, *resultError.Detail, "APIProviderEnrolmentDetails ApiProvDomId doesn't match path parameter")
 	assert.False(t, managerUnderTest.IsFunctionRegistered("AEF_id_new_func_as_AEF"))
 }
 
 func TestRegisterValidProvider(t *testing.T) {
-	managerUnderTest, requestHandler := getEcho()
+	managerUnderTest, eventChannel, requestHandler := getEcho()
 
 	newProvider := getProvider()
 
@@ -112,6 +128,13 @@ func TestRegisterValidProvider(t *testing.T) {
 	assert.Equal(t, "http://example.com/registrations/"+*resultProvider.ApiProvDomId, result.Recorder.Header().Get(echo.HeaderLocation))
 	assert.True(t, managerUnderTest.IsFunctionRegistered("APF_id_rApp_as_APF"))
 
+	if providerEvent, timeout := waitForEvent(eventChannel, 1*time.Second); timeout {
+		assert.Fail(t, "No event sent")
+	} else {
+		assert.Equal(t, eventsapi.CAPIFEventAPIPROVIDERREGISTERED, providerEvent.Events)
+		assert.Equal(t, resultProvider, *providerEvent.EventDetail.ApiProviderDomInfo)
+	}
+
 	// Register same provider again should result in Forbidden
 	result = testutil.NewRequest().Post("/registrations").WithJsonBody(newProvider).Go(t, requestHandler)
 	var errorObj common29122.ProblemDetails
@@ -119,11 +142,102 @@ func TestRegisterValidProvider(t *testing.T) {
 	err = result.UnmarshalBodyToObject(&errorObj)
 	assert.NoError(t, err, "error unmarshaling response")
 	assert.Equal(t, http.StatusForbidden, *errorObj.Status)
-	assert.Contains(t, *errorObj.Cause, "already registered")
+	assert.Contains(t, *errorObj.Detail, "already registered")
+}
+
+func TestRegisterProviderWithCertificates(t *testing.T) {
+	managerUnderTest, _, requestHandler := getEcho()
+	issuerMock := certmocks.CertificateIssuer{}
+	issuerMock.On("IssueCertificate", mock.Anything, mock.AnythingOfType("string")).Return("certificate", nil).Times(3)
+	managerUnderTest.certificateIssuer = &issuerMock
+
+	newProvider := getProvider()
+
+	// Register a provider with public keys that cannot be parsed, should get 400 with problem details
+	result := testutil.NewRequest().Post("/registrations").WithJsonBody(newProvider).Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusBadRequest, result.Code())
+	var errorObj common29122.ProblemDetails
+	err := result.UnmarshalBodyToObject(&errorObj)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Contains(t, *errorObj.Detail, "invalid regInfo.apiProvPubKey")
+	issuerMock.AssertNotCalled(t, "IssueCertificate", mock.Anything, mock.Anything)
+
+	// Register a provider with valid public keys, each function should get a certificate
+	for pos := range *newProvider.ApiProvFuncs {
+		(*newProvider.ApiProvFuncs)[pos].RegInfo.ApiProvPubKey = getPublicKey(t)
+	}
+	result = testutil.NewRequest().Post("/registrations").WithJsonBody(newProvider).Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusCreated, result.Code())
+	var resultProvider provapi.APIProviderEnrolmentDetails
+	err = result.UnmarshalBodyToObject(&resultProvider)
+	assert.NoError(t, err, "error unmarshaling response")
+	for _, function := range *resultProvider.ApiProvFuncs {
+		assert.Equal(t, "certificate", *function.RegInfo.ApiProvCert)
+		issuerMock.AssertCalled(t, "IssueCertificate", mock.Anything, *function.ApiProvFuncId)
+	}
+
+	// Re-key the AEF, only the AEF should get a new certificate
+	issuerMock.On("IssueCertificate", mock.Anything, funcIdAEF).Return("newCertificate", nil)
+	(*resultProvider.ApiProvFuncs)[2].RegInfo.ApiProvPubKey = getPublicKey(t)
+	result = testutil.NewRequest().Put("/registrations/"+domainID).WithJsonBody(resultProvider).Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusOK, result.Code())
+	err = result.UnmarshalBodyToObject(&resultProvider)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, "certificate", *(*resultProvider.ApiProvFuncs)[0].RegInfo.ApiProvCert)
+	assert.Equal(t, "certificate", *(*resultProvider.ApiProvFuncs)[1].RegInfo.ApiProvCert)
+	assert.Equal(t, "newCertificate", *(*resultProvider.ApiProvFuncs)[2].RegInfo.ApiProvCert)
+	issuerMock.AssertNumberOfCalls(t, "IssueCertificate", 4)
+}
+
+func TestRegisterProviderWhenCertificatesCannotBeIssued(t *testing.T) {
+	managerUnderTest, _, requestHandler := getEcho()
+	issuerMock := certmocks.CertificateIssuer{}
+	issuerMock.On("IssueCertificate", mock.Anything, mock.AnythingOfType("string")).Return("", errors.New("CA unavailable"))
+	managerUnderTest.certificateIssuer = &issuerMock
+
+	// Register a provider, should get 500 with problem details and the provider is not registered
+	newProvider := getProvider()
+	for pos := range *newProvider.ApiProvFuncs {
+		(*newProvider.ApiProvFuncs)[pos].RegInfo.ApiProvPubKey = getPublicKey(t)
+	}
+	result := testutil.NewRequest().Post("/registrations").WithJsonBody(newProvider).Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusInternalServerError, result.Code())
+	var errorObj common29122.ProblemDetails
+	err := result.UnmarshalBodyToObject(&errorObj)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, problemdetails.CauseSystemFailure, *errorObj.Cause)
+	assert.Contains(t, *errorObj.Detail, "CA unavailable")
+	assert.Empty(t, managerUnderTest.registeredProviders)
+
+	// Re-key a function of a registered provider, should get 500 with problem details and the provider is unchanged
+	certificate := "certificate"
+	provider := getProvider()
+	provider.ApiProvDomId = &domainID
+	(*provider.ApiProvFuncs)[0].ApiProvFuncId = &funcIdAPF
+	(*provider.ApiProvFuncs)[1].ApiProvFuncId = &funcIdAMF
+	(*provider.ApiProvFuncs)[2].ApiProvFuncId = &funcIdAEF
+	for pos := range *provider.ApiProvFuncs {
+		(*provider.ApiProvFuncs)[pos].RegInfo.ApiProvPubKey = getPublicKey(t)
+		(*provider.ApiProvFuncs)[pos].RegInfo.ApiProvCert = &certificate
+	}
+	managerUnderTest.registeredProviders[domainID] = provider
+	updatedProvider := getProvider()
+	updatedProvider.ApiProvDomId = &domainID
+	updatedFuncs := append([]provapi.APIProviderFunctionDetails{}, *provider.ApiProvFuncs...)
+	updatedFuncs[2].RegInfo.ApiProvPubKey = getPublicKey(t)
+	updatedProvider.ApiProvFuncs = &updatedFuncs
+	result = testutil.NewRequest().Put("/registrations/"+domainID).WithJsonBody(updatedProvider).Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusInternalServerError, result.Code())
+	assert.Equal(t, provider, managerUnderTest.registeredProviders[domainID])
 }
 
 func TestUpdateValidProviderWithNewFunction(t *testing.T) {
-	managerUnderTest, requestHandler := getEcho()
+	managerUnderTest, eventChannel, requestHandler := getEcho()
 
 	provider := getProvider()
 	provider.ApiProvDomId = &domainID
@@ -164,10 +278,17 @@ func TestUpdateValidProviderWithNewFunction(t *testing.T) {
 	assert.Equal(t, *(*resultProvider.ApiProvFuncs)[3].ApiProvFuncId, "AEF_id_new_func_as_AEF")
 	assert.Empty(t, resultProvider.FailReason)
 	assert.True(t, managerUnderTest.IsFunctionRegistered("AEF_id_new_func_as_AEF"))
+
+	if providerEvent, timeout := waitForEvent(eventChannel, 1*time.Second); timeout {
+		assert.Fail(t, "No event sent")
+	} else {
+		assert.Equal(t, eventsapi.CAPIFEventAPIPROVIDERUPDATED, providerEvent.Events)
+		assert.Equal(t, newDomainInfo, *providerEvent.EventDetail.ApiProviderDomInfo.ApiProvDomInfo)
+	}
 }
 
 func TestUpdateValidProviderWithDeletedFunction(t *testing.T) {
-	managerUnderTest, requestHandler := getEcho()
+	managerUnderTest, _, requestHandler := getEcho()
 
 	provider := getProvider()
 	provider.ApiProvDomId = &domainID
@@ -199,7 +320,7 @@ func TestUpdateValidProviderWithDeletedFunction(t *testing.T) {
 }
 
 func TestUpdateMissingFunction(t *testing.T) {
-	managerUnderTest, requestHandler := getEcho()
+	managerUnderTest, _, requestHandler := getEcho()
 
 	provider := getProvider()
 	provider.ApiProvDomId = &domainID
@@ -223,16 +344,133 @@ func TestUpdateMissingFunction(t *testing.T) {
 	err := result.UnmarshalBodyToObject(&errorObj)
 	assert.NoError(t, err, "error unmarshaling response")
 	assert.Equal(t, http.StatusBadRequest, *errorObj.Status)
-	assert.Contains(t, *errorObj.Cause, funcIdAPF)
-	assert.Contains(t, *errorObj.Cause, "not registered")
+	assert.Contains(t, *errorObj.Detail, funcIdAPF)
+	assert.Contains(t, *errorObj.Detail, "not registered")
+}
+
+func TestModifyProviderDomainInfoAndFunctions(t *testing.T) {
+	managerUnderTest, _, requestHandler := getEcho()
+
+	provider := getProvider()
+	provider.ApiProvDomId = &domainID
+	(*provider.ApiProvFuncs)[0].ApiProvFuncId = &funcIdAPF
+	(*provider.ApiProvFuncs)[1].ApiProvFuncId = &funcIdAMF
+	(*provider.ApiProvFuncs)[2].ApiProvFuncId = &funcIdAEF
+	managerUnderTest.registeredProviders[domainID] = provider
+
+	// Only change the domain info
+	newDomainInfo := "New domain info"
+	patch := provapi.APIProviderEnrolmentDetailsPatch{
+		ApiProvDomInfo: &newDomainInfo,
+	}
+
+	result := testutil.NewRequest().Patch("/registrations/"+domainID).WithJsonBody(patch).WithContentType("application/merge-patch+json").Go(t, requestHandler)
+
+	var resultProvider provapi.APIProviderEnrolmentDetails
+	assert.Equal(t, http.StatusOK, result.Code())
+	err := result.UnmarshalBodyToObject(&resultProvider)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, newDomainInfo, *resultProvider.ApiProvDomInfo)
+	assert.Equal(t, "sec", resultProvider.RegSec)
+	assert.Len(t, *resultProvider.ApiProvFuncs, 3)
+	assert.Equal(t, newDomainInfo, *managerUnderTest.registeredProviders[domainID].ApiProvDomInfo)
+
+	// Replace the functions, removing the AMF and adding a new AEF
+	newFuncInfoAEF := "new func as AEF"
+	patchedFuncs := []provapi.APIProviderFunctionDetails{
+		(*provider.ApiProvFuncs)[0],
+		(*provider.ApiProvFuncs)[2],
+		{
+			ApiProvFuncInfo: &newFuncInfoAEF,
+			ApiProvFuncRole: provapi.ApiProviderFuncRoleAEF,
+			RegInfo: provapi.RegistrationInformation{
+				ApiProvPubKey: "key",
+			},
+		},
+	}
+	patch = provapi.APIProviderEnrolmentDetailsPatch{
+		ApiProvFuncs: &patchedFuncs,
+	}
+
+	result = testutil.NewRequest().Patch("/registrations/"+domainID).WithJsonBody(patch).WithContentType("application/merge-patch+json").Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusOK, result.Code())
+	err = result.UnmarshalBodyToObject(&resultProvider)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, newDomainInfo, *resultProvider.ApiProvDomInfo)
+	assert.Len(t, *resultProvider.ApiProvFuncs, 3)
+	assert.Equal(t, "AEF_id_new_func_as_AEF", *(*resultProvider.ApiProvFuncs)[2].ApiProvFuncId)
+	assert.True(t, managerUnderTest.IsFunctionRegistered("AEF_id_new_func_as_AEF"))
+	assert.False(t, managerUnderTest.IsFunctionRegistered(funcIdAMF))
+}
+
+func TestFailedModifyProvider(t *testing.T) {
+	managerUnderTest, _, requestHandler := getEcho()
+
+	newDomainInfo := "New domain info"
+	patch := provapi.APIProviderEnrolmentDetailsPatch{
+		ApiProvDomInfo: &newDomainInfo,
+	}
+
+	// Provider not registered
+	result := testutil.NewRequest().Patch("/registrations/"+domainID).WithJsonBody(patch).WithContentType("application/merge-patch+json").Go(t, requestHandler)
+
+	var errorObj common29122.ProblemDetails
+	assert.Equal(t, http.StatusNotFound, result.Code())
+	err := result.UnmarshalBodyToObject(&errorObj)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Contains(t, *errorObj.Detail, "provider not onboarded")
+
+	provider := getProvider()
+	provider.ApiProvDomId = &domainID
+	(*provider.ApiProvFuncs)[0].ApiProvFuncId = &funcIdAPF
+	(*provider.ApiProvFuncs)[1].ApiProvFuncId = &funcIdAMF
+	(*provider.ApiProvFuncs)[2].ApiProvFuncId = &funcIdAEF
+	managerUnderTest.registeredProviders[domainID] = provider
+
+	// Function not registered for the provider
+	otherId := "otherId"
+	patchedFuncs := []provapi.APIProviderFunctionDetails{
+		{
+			ApiProvFuncId:   &otherId,
+			ApiProvFuncRole: provapi.ApiProviderFuncRoleAEF,
+			RegInfo: provapi.RegistrationInformation{
+				ApiProvPubKey: "key",
+			},
+		},
+	}
+	patch = provapi.APIProviderEnrolmentDetailsPatch{
+		ApiProvFuncs: &patchedFuncs,
+	}
+
+	result = testutil.NewRequest().Patch("/registrations/"+domainID).WithJsonBody(patch).WithContentType("application/merge-patch+json").Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusBadRequest, result.Code())
+	err = result.UnmarshalBodyToObject(&errorObj)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Contains(t, *errorObj.Detail, otherId)
+	assert.Contains(t, *errorObj.Detail, "not registered")
+	assert.True(t, managerUnderTest.IsFunctionRegistered(funcIdAMF))
 }
 
 func TestDeleteProvider(t *testing.T) {
-	managerUnderTest, requestHandler := getEcho()
+	managerUnderTest, eventChannel, requestHandler := getEcho()
+	apiId := "apiId"
+	publishedServices := []publishapi.ServiceAPIDescription{
+		{
+			ApiId:   &apiId,
+			ApiName: "api",
+		},
+	}
+	servicePublisherMock := mocks.ServicePublisher{}
+	servicePublisherMock.On("UnpublishServices", funcIdAPF).Return(publishedServices)
+	managerUnderTest.SetServicePublisher(&servicePublisherMock)
 
 	provider := getProvider()
 	provider.ApiProvDomId = &domainID
 	(*provider.ApiProvFuncs)[0].ApiProvFuncId = &funcIdAPF
+	(*provider.ApiProvFuncs)[1].ApiProvFuncId = &funcIdAMF
+	(*provider.ApiProvFuncs)[2].ApiProvFuncId = &funcIdAEF
 	managerUnderTest.registeredProviders[domainID] = provider
 	assert.True(t, managerUnderTest.IsFunctionRegistered(funcIdAPF))
 
@@ -240,9 +478,42 @@ func TestDeleteProvider(t *testing.T) {
 
 	assert.Equal(t, http.StatusNoContent, result.Code())
 	assert.False(t, managerUnderTest.IsFunctionRegistered(funcIdAPF))
+	servicePublisherMock.AssertCalled(t, "UnpublishServices", funcIdAPF)
+
+	if serviceEvent, timeout := waitForEvent(eventChannel, 1*time.Second); timeout {
+		assert.Fail(t, "No event sent")
+	} else {
+		assert.Equal(t, eventsapi.CAPIFEventSERVICEAPIUNAVAILABLE, serviceEvent.Events)
+		assert.Equal(t, apiId, (*serviceEvent.EventDetail.ApiIds)[0])
+		assert.Equal(t, publishedServices, *serviceEvent.EventDetail.ServiceAPIDescriptions)
+	}
+	if providerEvent, timeout := waitForEvent(eventChannel, 1*time.Second); timeout {
+		assert.Fail(t, "No event sent")
+	} else {
+		assert.Equal(t, eventsapi.CAPIFEventAPIPROVIDERDEREGISTERED, providerEvent.Events)
+		assert.Equal(t, provider, *providerEvent.EventDetail.ApiProviderDomInfo)
+	}
+}
+
+func TestRegisteredProvidersAreLoadedFromStore(t *testing.T) {
+	managerUnderTest, _, requestHandler := getEcho()
+
+	result := testutil.NewRequest().Post("/registrations").WithJsonBody(getProvider()).Go(t, requestHandler)
+	assert.Equal(t, http.StatusCreated, result.Code())
+
+	restartedManager := NewProviderManager(nil, nil, managerUnderTest.store)
+	assert.True(t, restartedManager.IsFunctionRegistered(funcIdAPF))
+	assert.Equal(t, []string{funcIdAEF}, restartedManager.GetAefsForPublisher(funcIdAPF))
+
+	result = testutil.NewRequest().Delete("/registrations/"+domainID).Go(t, requestHandler)
+	assert.Equal(t, http.StatusNoContent, result.Code())
+
+	restartedManager = NewProviderManager(nil, nil, managerUnderTest.store)
+	assert.False(t, restartedManager.IsFunctionRegistered(funcIdAPF))
 }
+
 func TestProviderHandlingValidation(t *testing.T) {
-	_, requestHandler := getEcho()
+	_, _, requestHandler := getEcho()
 
 	newProvider := provapi.APIProviderEnrolmentDetails{}
 
@@ -254,12 +525,12 @@ func TestProviderHandlingValidation(t *testing.T) {
 	err := result.UnmarshalBodyToObject(&problemDetails)
 	assert.NoError(t, err, "error unmarshaling response")
 	assert.Equal(t, http.StatusBadRequest, *problemDetails.Status)
-	assert.Contains(t, *problemDetails.Cause, "missing")
-	assert.Contains(t, *problemDetails.Cause, "regSec")
+	assert.Contains(t, *problemDetails.Detail, "missing")
+	assert.Contains(t, *problemDetails.Detail, "regSec")
 }
 
 func TestGetExposedFunctionsForPublishingFunction(t *testing.T) {
-	managerUnderTest := NewProviderManager()
+	managerUnderTest := NewProviderManager(nil, nil, storagetest.NewStore())
 
 	provider := getProvider()
 	provider.ApiProvDomId = &domainID
@@ -274,6 +545,20 @@ func TestGetExposedFunctionsForPublishingFunction(t *testing.T) {
 	assert.Equal(t, funcIdAEF, exposedFuncs[0])
 }
 
+func TestGetProviderDomain(t *testing.T) {
+	managerUnderTest := NewProviderManager(nil, nil, storagetest.NewStore())
+
+	provider := getProvider()
+	provider.ApiProvDomId = &domainID
+	(*provider.ApiProvFuncs)[0].ApiProvFuncId = &funcIdAPF
+	(*provider.ApiProvFuncs)[1].ApiProvFuncId = &funcIdAMF
+	(*provider.ApiProvFuncs)[2].ApiProvFuncId = &funcIdAEF
+	managerUnderTest.registeredProviders[domainID] = provider
+
+	assert.Equal(t, domainID, managerUnderTest.GetProviderDomain(funcIdAEF))
+	assert.Equal(t, "", managerUnderTest.GetProviderDomain("unregisteredFunction"))
+}
+
 func getProvider() provapi.APIProviderEnrolmentDetails {
 	testFuncs := []provapi.APIProviderFunctionDetails{
 		{
@@ -339,7 +624,15 @@ func getOtherProvider() provapi.APIProviderEnrolmentDetails {
 
 }
 
-func getEcho() (*ProviderManager, *echo.Echo) {
+func getPublicKey(t *testing.T) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	assert.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func getEcho() (*ProviderManager, chan eventsapi.EventNotification, *echo.Echo) {
 	swagger, err := provapi.GetSwagger()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error loading swagger spec\n: %s", err)
@@ -348,12 +641,26 @@ func getEcho() (*ProviderManager, *echo.Echo) {
 
 	swagger.Servers = nil
 
-	pm := NewProviderManager()
+	eventChannel := make(chan eventsapi.EventNotification)
+	pm := NewProviderManager(nil, eventChannel, storagetest.NewStore())
 
 	e := echo.New()
 	e.Use(echomiddleware.Logger())
+	// PATCH requests use the merge patch content type, which the request validator must decode as JSON
+	openapi3filter.RegisterBodyDecoder("application/merge-patch+json", openapi3filter.RegisteredBodyDecoder("application/json"))
 	e.Use(middleware.OapiRequestValidator(swagger))
 
 	provapi.RegisterHandlers(e, pm)
-	return pm, e
+	return pm, eventChannel, e
+}
+
+// waitForEvent waits for the channel to receive an event for the specified max timeout.
+// Returns true if waiting timed out.
+func waitForEvent(ch chan eventsapi.EventNotification, timeout time.Duration) (*eventsapi.EventNotification, bool) {
+	select {
+	case event := <-ch:
+		return &event, false // completed normally
+	case <-time.After(timeout):
+		return nil, true // timed out
+	}
 }
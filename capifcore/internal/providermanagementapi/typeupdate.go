@@ -30,6 +30,9 @@ import (
 var uuidFunc = getUUID
 
 func (ed *APIProviderEnrolmentDetails) UpdateFuncs(registeredProvider APIProviderEnrolmentDetails) error {
+	if ed.ApiProvFuncs == nil {
+		return nil
+	}
 	for pos, function := range *ed.ApiProvFuncs {
 		if function.ApiProvFuncId == nil {
 			(*ed.ApiProvFuncs)[pos].ApiProvFuncId = getFuncId(function.ApiProvFuncRole, function.ApiProvFuncInfo)
@@ -42,6 +45,19 @@ func (ed *APIProviderEnrolmentDetails) UpdateFuncs(registeredProvider APIProvide
 	return nil
 }
 
+// Returns a copy of the provider where the attributes present in the patch replace the current ones. Attributes not
+// present in the patch are left unchanged.
+func (ed *APIProviderEnrolmentDetails) ApplyPatch(patch APIProviderEnrolmentDetailsPatch) APIProviderEnrolmentDetails {
+	patchedProvider := *ed
+	if patch.ApiProvDomInfo != nil {
+		patchedProvider.ApiProvDomInfo = patch.ApiProvDomInfo
+	}
+	if patch.ApiProvFuncs != nil {
+		patchedProvider.ApiProvFuncs = patch.ApiProvFuncs
+	}
+	return patchedProvider
+}
+
 func (ed *APIProviderEnrolmentDetails) PrepareNewProvider() {
 	ed.ApiProvDomId = ed.getDomainId()
 
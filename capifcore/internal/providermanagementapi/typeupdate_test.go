@@ -81,3 +81,28 @@ func TestUpdateFuncs(t *testing.T) {
 	assert.Equal(t, funcIdAEF, *(*providerUnderTest.ApiProvFuncs)[1].ApiProvFuncId)
 	assert.Equal(t, "AEF_id_func_info", *(*providerUnderTest.ApiProvFuncs)[2].ApiProvFuncId)
 }
+
+func TestApplyPatch(t *testing.T) {
+	registeredProvider := getProvider()
+
+	newDomainInfo := "new domain info"
+	patchedProvider := registeredProvider.ApplyPatch(APIProviderEnrolmentDetailsPatch{
+		ApiProvDomInfo: &newDomainInfo,
+	})
+
+	assert.Equal(t, newDomainInfo, *patchedProvider.ApiProvDomInfo)
+	assert.Equal(t, registeredProvider.ApiProvFuncs, patchedProvider.ApiProvFuncs)
+	assert.Equal(t, registeredProvider.RegSec, patchedProvider.RegSec)
+	assert.NotEqual(t, newDomainInfo, *registeredProvider.ApiProvDomInfo)
+
+	patchedFuncs := []APIProviderFunctionDetails{
+		(*registeredProvider.ApiProvFuncs)[0],
+	}
+	patchedProvider = registeredProvider.ApplyPatch(APIProviderEnrolmentDetailsPatch{
+		ApiProvFuncs: &patchedFuncs,
+	})
+
+	assert.Equal(t, registeredProvider.ApiProvDomInfo, patchedProvider.ApiProvDomInfo)
+	assert.Len(t, *patchedProvider.ApiProvFuncs, 1)
+	assert.Len(t, *registeredProvider.ApiProvFuncs, 3)
+}
@@ -5,6 +5,8 @@ package mocks
 import (
 	mock "github.com/stretchr/testify/mock"
 
+	publishservice "oransc.org/nonrtric/capifcore/internal/publishservice"
+
 	publishserviceapi "oransc.org/nonrtric/capifcore/internal/publishserviceapi"
 )
 
@@ -45,13 +47,59 @@ func (_m *PublishRegister) GetAllowedPublishedServices(invokerApiList []publishs
 	return r0
 }
 
-// IsAPIPublished provides a mock function with given fields: aefId, path
-func (_m *PublishRegister) IsAPIPublished(aefId string, path string) bool {
-	ret := _m.Called(aefId, path)
+// GetPublishedService provides a mock function with given fields: apiId
+func (_m *PublishRegister) GetPublishedService(apiId string) *publishserviceapi.ServiceAPIDescription {
+	ret := _m.Called(apiId)
+
+	var r0 *publishserviceapi.ServiceAPIDescription
+	if rf, ok := ret.Get(0).(func(string) *publishserviceapi.ServiceAPIDescription); ok {
+		r0 = rf(apiId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*publishserviceapi.ServiceAPIDescription)
+		}
+	}
+
+	return r0
+}
+
+// GetPublishingFunction provides a mock function with given fields: apiId
+func (_m *PublishRegister) GetPublishingFunction(apiId string) string {
+	ret := _m.Called(apiId)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(string) string); ok {
+		r0 = rf(apiId)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// GetServiceVisibility provides a mock function with given fields: apiId
+func (_m *PublishRegister) GetServiceVisibility(apiId string) *publishservice.ServiceVisibility {
+	ret := _m.Called(apiId)
+
+	var r0 *publishservice.ServiceVisibility
+	if rf, ok := ret.Get(0).(func(string) *publishservice.ServiceVisibility); ok {
+		r0 = rf(apiId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*publishservice.ServiceVisibility)
+		}
+	}
+
+	return r0
+}
+
+// IsAPIPublished provides a mock function with given fields: aefId, apiName
+func (_m *PublishRegister) IsAPIPublished(aefId string, apiName string) bool {
+	ret := _m.Called(aefId, apiName)
 
 	var r0 bool
 	if rf, ok := ret.Get(0).(func(string, string) bool); ok {
-		r0 = rf(aefId, path)
+		r0 = rf(aefId, apiName)
 	} else {
 		r0 = ret.Get(0).(bool)
 	}
@@ -31,43 +31,82 @@ import (
 	echo "github.com/labstack/echo/v4"
 	"k8s.io/utils/strings/slices"
 
-	"oransc.org/nonrtric/capifcore/internal/common29122"
 	"oransc.org/nonrtric/capifcore/internal/eventsapi"
 	publishapi "oransc.org/nonrtric/capifcore/internal/publishserviceapi"
 
 	"oransc.org/nonrtric/capifcore/internal/helmmanagement"
 	"oransc.org/nonrtric/capifcore/internal/providermanagement"
+	"oransc.org/nonrtric/capifcore/internal/storage"
+	"oransc.org/nonrtric/capifcore/problemdetails"
+	"oransc.org/nonrtric/capifcore/validation"
 
 	log "github.com/sirupsen/logrus"
 )
 
+const publishedServicesBucket = "publishedServices"
+
 //go:generate mockery --name PublishRegister
 type PublishRegister interface {
-	// Checks if the provided API is published.
+	// Checks if the API with the provided name is published by the provided AEF.
 	// Returns true if the provided API has been published, false otherwise.
-	IsAPIPublished(aefId, path string) bool
+	IsAPIPublished(aefId, apiName string) bool
 	// Gets all published APIs.
 	// Returns a list of all APIs that has been published.
 	GetAllPublishedServices() []publishapi.ServiceAPIDescription
+	// Gets the published APIs that are requested in the provided list. An API is requested by its id, or by its name
+	// if the requested API has no id.
+	// Returns a list of the requested APIs that have been published.
 	GetAllowedPublishedServices(invokerApiList []publishapi.ServiceAPIDescription) []publishapi.ServiceAPIDescription
+	// Gets the published API with the provided API id.
+	// Returns the published API, or nil if no API with the provided id has been published.
+	GetPublishedService(apiId string) *publishapi.ServiceAPIDescription
+	// Gets the id of the API publishing function that has published the API with the provided API id.
+	// Returns the id, or an empty string if no API with the provided id has been published.
+	GetPublishingFunction(apiId string) string
+	// Gets the visibility rules that the provider has set for the API with the provided id.
+	// Returns the rules, or nil if the provider has not set any rules for the API.
+	GetServiceVisibility(apiId string) *ServiceVisibility
+}
+
+type UnpublishHandler interface {
+	// Removes what is kept for the API with the provided API id when it is unpublished.
+	RemoveApi(apiId string)
 }
 
 type PublishService struct {
 	publishedServices map[string][]publishapi.ServiceAPIDescription
+	serviceVisibility map[string]ServiceVisibility
 	serviceRegister   providermanagement.ServiceRegister
 	helmManager       helmmanagement.HelmManager
 	eventChannel      chan<- eventsapi.EventNotification
+	unpublishHandlers []UnpublishHandler
+	store             storage.Store
 	lock              sync.Mutex
 }
 
 // Creates a service that implements both the PublishRegister and the publishserviceapi.ServerInterface interfaces.
-func NewPublishService(serviceRegister providermanagement.ServiceRegister, hm helmmanagement.HelmManager, eventChannel chan<- eventsapi.EventNotification) *PublishService {
-	return &PublishService{
+// Services published in the provided store are loaded at creation.
+func NewPublishService(serviceRegister providermanagement.ServiceRegister, hm helmmanagement.HelmManager, eventChannel chan<- eventsapi.EventNotification, store storage.Store) *PublishService {
+	ps := &PublishService{
 		helmManager:       hm,
 		publishedServices: make(map[string][]publishapi.ServiceAPIDescription),
+		serviceVisibility: make(map[string]ServiceVisibility),
 		serviceRegister:   serviceRegister,
 		eventChannel:      eventChannel,
+		store:             store,
 	}
+	if err := storage.Load(store, publishedServicesBucket, ps.publishedServices); err != nil {
+		log.Errorf("Unable to load published services due to %s", err)
+	}
+	if err := storage.Load(store, serviceVisibilityBucket, ps.serviceVisibility); err != nil {
+		log.Errorf("Unable to load service visibility due to %s", err)
+	}
+	return ps
+}
+
+// Adds a handler that is called when an API is unpublished.
+func (ps *PublishService) AddUnpublishHandler(handler UnpublishHandler) {
+	ps.unpublishHandlers = append(ps.unpublishHandlers, handler)
 }
 
 func (ps *PublishService) getAllAefIds() []string {
@@ -83,11 +122,24 @@ func (ps *PublishService) getAllAefIds() []string {
 	return allIds
 }
 
-func (ps *PublishService) IsAPIPublished(aefId, path string) bool {
-	return slices.Contains(ps.getAllAefIds(), aefId)
+func (ps *PublishService) IsAPIPublished(aefId, apiName string) bool {
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+
+	for _, descriptions := range ps.publishedServices {
+		for _, description := range descriptions {
+			if description.ApiName == apiName && description.GetAefProfileById(&aefId) != nil {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 func (ps *PublishService) GetAllPublishedServices() []publishapi.ServiceAPIDescription {
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+
 	publishedDescriptions := []publishapi.ServiceAPIDescription{}
 	for _, descriptions := range ps.publishedServices {
 		publishedDescriptions = append(publishedDescriptions, descriptions...)
@@ -95,12 +147,57 @@ func (ps *PublishService) GetAllPublishedServices() []publishapi.ServiceAPIDescr
 	return publishedDescriptions
 }
 
+// Removes all APIs published by the provided API publishing function, uninstalling their Helm charts.
+// Returns the removed APIs.
+func (ps *PublishService) UnpublishServices(apfId string) []publishapi.ServiceAPIDescription {
+	ps.lock.Lock()
+	descriptions := ps.publishedServices[apfId]
+	delete(ps.publishedServices, apfId)
+	if err := ps.store.Delete(publishedServicesBucket, apfId); err != nil {
+		log.Errorf("Unable to remove stored services published by %s due to %s", apfId, err)
+	}
+	for _, description := range descriptions {
+		ps.removeServiceVisibility(*description.ApiId)
+	}
+	ps.lock.Unlock()
+
+	for _, description := range descriptions {
+		ps.uninstallHelmChart(description)
+		ps.removeApi(*description.ApiId)
+	}
+	return descriptions
+}
+
 func (ps *PublishService) GetAllowedPublishedServices(apiListRequestedServices []publishapi.ServiceAPIDescription) []publishapi.ServiceAPIDescription {
 	apiListAllPublished := ps.GetAllPublishedServices()
 	allowedPublishedServices := join(apiListAllPublished, apiListRequestedServices)
 	return allowedPublishedServices
 }
 
+func (ps *PublishService) GetPublishedService(apiId string) *publishapi.ServiceAPIDescription {
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+
+	for _, descriptions := range ps.publishedServices {
+		if _, description := getServiceDescription(apiId, descriptions); description != nil {
+			return description
+		}
+	}
+	return nil
+}
+
+func (ps *PublishService) GetPublishingFunction(apiId string) string {
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+
+	for apfId, descriptions := range ps.publishedServices {
+		if _, description := getServiceDescription(apiId, descriptions); description != nil {
+			return apfId
+		}
+	}
+	return ""
+}
+
 func join(a, b []publishapi.ServiceAPIDescription) []publishapi.ServiceAPIDescription {
 	var result []publishapi.ServiceAPIDescription
 
@@ -110,7 +207,7 @@ func join(a, b []publishapi.ServiceAPIDescription) []publishapi.ServiceAPIDescri
 
 	for _, itemA := range a {
 		for _, itemB := range b {
-			if itemA.ApiName == itemB.ApiName {
+			if isRequested(itemA, itemB) {
 				result = append(result, itemA)
 				break
 			}
@@ -119,6 +216,15 @@ func join(a, b []publishapi.ServiceAPIDescription) []publishapi.ServiceAPIDescri
 	return result
 }
 
+// Checks if the published API is the requested API. The API is requested by its id, or by its name if the requested API
+// has no id.
+func isRequested(published, requested publishapi.ServiceAPIDescription) bool {
+	if requested.ApiId != nil {
+		return published.ApiId != nil && *published.ApiId == *requested.ApiId
+	}
+	return published.ApiName == requested.ApiName
+}
+
 // Retrieve all published APIs.
 func (ps *PublishService) GetApfIdServiceApis(ctx echo.Context, apfId string) error {
 	ps.lock.Lock()
@@ -134,7 +240,7 @@ func (ps *PublishService) GetApfIdServiceApis(ctx echo.Context, apfId string) er
 	} else {
 		if !ps.serviceRegister.IsPublishingFunctionRegistered(apfId) {
 			errorMsg := fmt.Sprintf("Unable to get the service due to %s api is only available for publishers", apfId)
-			return sendCoreError(ctx, http.StatusNotFound, errorMsg)
+			return problemdetails.Send(ctx, http.StatusNotFound, problemdetails.CauseContextNotFound, errorMsg)
 		}
 
 		serviceDescriptions = []publishapi.ServiceAPIDescription{}
@@ -153,19 +259,19 @@ func (ps *PublishService) PostApfIdServiceApis(ctx echo.Context, apfId string) e
 	errorMsg := "Unable to publish the service due to %s "
 	err := ctx.Bind(&newServiceAPIDescription)
 	if err != nil {
-		return sendCoreError(ctx, http.StatusBadRequest, fmt.Sprintf(errorMsg, "invalid format for service "+apfId))
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseInvalidMsgFormat, fmt.Sprintf(errorMsg, "invalid format for service "+apfId))
 	}
 
 	if !ps.serviceRegister.IsPublishingFunctionRegistered(apfId) {
-		return sendCoreError(ctx, http.StatusForbidden, fmt.Sprintf(errorMsg, "api is only available for publishers "+apfId))
+		return problemdetails.Send(ctx, http.StatusForbidden, problemdetails.CauseRequestNotAuthorized, fmt.Sprintf(errorMsg, "api is only available for publishers "+apfId))
 	}
 
 	if err := ps.isServicePublished(newServiceAPIDescription); err != nil {
-		return sendCoreError(ctx, http.StatusForbidden, fmt.Sprintf(errorMsg, err))
+		return problemdetails.Send(ctx, http.StatusForbidden, problemdetails.CauseModificationNotAllowed, fmt.Sprintf(errorMsg, err))
 	}
 
 	if err := newServiceAPIDescription.Validate(); err != nil {
-		return sendCoreError(ctx, http.StatusBadRequest, fmt.Sprintf(errorMsg, err))
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseMandatoryIeIncorrect, fmt.Sprintf(errorMsg, err))
 	}
 	ps.lock.Lock()
 	defer ps.lock.Unlock()
@@ -173,7 +279,7 @@ func (ps *PublishService) PostApfIdServiceApis(ctx echo.Context, apfId string) e
 	registeredFuncs := ps.serviceRegister.GetAefsForPublisher(apfId)
 	for _, profile := range *newServiceAPIDescription.AefProfiles {
 		if !slices.Contains(registeredFuncs, profile.AefId) {
-			return sendCoreError(ctx, http.StatusNotFound, fmt.Sprintf(errorMsg, fmt.Sprintf("function %s not registered", profile.AefId)))
+			return problemdetails.Send(ctx, http.StatusNotFound, problemdetails.CauseContextNotFound, fmt.Sprintf(errorMsg, fmt.Sprintf("function %s not registered", profile.AefId)))
 		}
 	}
 
@@ -191,6 +297,7 @@ func (ps *PublishService) PostApfIdServiceApis(ctx echo.Context, apfId string) e
 	} else {
 		ps.publishedServices[apfId] = append([]publishapi.ServiceAPIDescription{}, newServiceAPIDescription)
 	}
+	ps.storePublishedServices(apfId)
 
 	uri := ctx.Request().Host + ctx.Request().URL.String()
 	ctx.Response().Header().Set(echo.HeaderLocation, ctx.Scheme()+`://`+path.Join(uri, *newServiceAPIDescription.ApiId))
@@ -219,7 +326,7 @@ func (ps *PublishService) installHelmChart(newServiceAPIDescription publishapi.S
 	if (len(info) == 5) && (ps.helmManager != nil) {
 		err := ps.helmManager.InstallHelmChart(info[1], info[2], info[3], info[4])
 		if err != nil {
-			return true, sendCoreError(ctx, http.StatusBadRequest, fmt.Sprintf("Unable to install Helm chart %s due to: %s", info[3], err.Error()))
+			return true, problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseUnspecifiedMsgFailure, fmt.Sprintf("Unable to install Helm chart %s due to: %s", info[3], err.Error()))
 		}
 		log.Debug("Installed service: ", newServiceAPIDescription.ApiId)
 	}
@@ -232,21 +339,37 @@ func (ps *PublishService) DeleteApfIdServiceApisServiceApiId(ctx echo.Context, a
 	if ok {
 		pos, description := getServiceDescription(serviceApiId, serviceDescriptions)
 		if description != nil {
-			info := strings.Split(*description.Description, ",")
-			if (len(info) == 5) && (ps.helmManager != nil) {
-				ps.helmManager.UninstallHelmChart(info[1], info[3])
-				log.Debug("Deleted service: ", serviceApiId)
-			}
+			ps.uninstallHelmChart(*description)
 			ps.lock.Lock()
 			ps.publishedServices[string(apfId)] = removeServiceDescription(pos, serviceDescriptions)
+			ps.storePublishedServices(apfId)
+			ps.removeServiceVisibility(serviceApiId)
 			ps.lock.Unlock()
+			ps.removeApi(serviceApiId)
 			go ps.sendEvent(*description, eventsapi.CAPIFEventSERVICEAPIUNAVAILABLE)
 		}
 	}
 	return ctx.NoContent(http.StatusNoContent)
 }
 
+func (ps *PublishService) removeApi(apiId string) {
+	for _, handler := range ps.unpublishHandlers {
+		handler.RemoveApi(apiId)
+	}
+}
+
 // Retrieve a published service API.
+func (ps *PublishService) uninstallHelmChart(description publishapi.ServiceAPIDescription) {
+	if ps.helmManager == nil || description.Description == nil {
+		return
+	}
+	info := strings.Split(*description.Description, ",")
+	if len(info) == 5 {
+		ps.helmManager.UninstallHelmChart(info[1], info[3])
+		log.Debug("Deleted service: ", *description.ApiId)
+	}
+}
+
 func (ps *PublishService) GetApfIdServiceApisServiceApiId(ctx echo.Context, apfId string, serviceApiId string) error {
 	ps.lock.Lock()
 	serviceDescriptions, ok := ps.publishedServices[apfId]
@@ -255,7 +378,7 @@ func (ps *PublishService) GetApfIdServiceApisServiceApiId(ctx echo.Context, apfI
 	if ok {
 		_, serviceDescription := getServiceDescription(serviceApiId, serviceDescriptions)
 		if serviceDescription == nil {
-			return ctx.NoContent(http.StatusNotFound)
+			return problemdetails.Send(ctx, http.StatusNotFound, problemdetails.CauseContextNotFound, fmt.Sprintf("Unable to get service due to service %s not published", serviceApiId))
 		}
 		err := ctx.JSON(http.StatusOK, serviceDescription)
 		if err != nil {
@@ -265,7 +388,7 @@ func (ps *PublishService) GetApfIdServiceApisServiceApiId(ctx echo.Context, apfI
 
 		return nil
 	}
-	return ctx.NoContent(http.StatusNotFound)
+	return problemdetails.Send(ctx, http.StatusNotFound, problemdetails.CauseContextNotFound, fmt.Sprintf("Unable to get service due to no services published by %s", apfId))
 }
 
 func getServiceDescription(serviceApiId string, descriptions []publishapi.ServiceAPIDescription) (int, *publishapi.ServiceAPIDescription) {
@@ -286,8 +409,44 @@ func removeServiceDescription(i int, a []publishapi.ServiceAPIDescription) []pub
 }
 
 // Modify an existing published service API.
+// Only the attributes present in the request are changed.
 func (ps *PublishService) ModifyIndAPFPubAPI(ctx echo.Context, apfId string, serviceApiId string) error {
-	return ctx.NoContent(http.StatusNotImplemented)
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+	errMsg := "Unable to update service due to %s."
+
+	pos, publishedService, err := ps.checkIfServiceIsPublished(apfId, serviceApiId)
+	if err != nil {
+		return problemdetails.Send(ctx, http.StatusNotFound, problemdetails.CauseContextNotFound, fmt.Sprintf(errMsg, err))
+	}
+
+	var patch publishapi.ServiceAPIDescriptionPatch
+	if err = validation.BindMergePatch(ctx, &patch); err != nil {
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseInvalidMsgFormat, fmt.Sprintf(errMsg, "invalid format for service patch"))
+	}
+
+	patchedService := publishedService.ApplyPatch(patch)
+	if err = patchedService.Validate(); err != nil {
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseMandatoryIeIncorrect, fmt.Sprintf(errMsg, err))
+	}
+
+	if patch.AefProfiles != nil {
+		if err = ps.checkProfilesRegistered(apfId, *patch.AefProfiles); err != nil {
+			return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseMandatoryIeIncorrect, fmt.Sprintf(errMsg, err))
+		}
+	}
+
+	ps.publishedServices[apfId][pos] = patchedService
+	ps.storePublishedServices(apfId)
+
+	go ps.sendEvent(patchedService, eventsapi.CAPIFEventSERVICEAPIUPDATE)
+
+	err = ctx.JSON(http.StatusOK, patchedService)
+	if err != nil {
+		// Something really bad happened, tell Echo that our handler failed
+		return err
+	}
+	return nil
 }
 
 // Update a published service API.
@@ -298,29 +457,30 @@ func (ps *PublishService) PutApfIdServiceApisServiceApiId(ctx echo.Context, apfI
 
 	pos, publishedService, err := ps.checkIfServiceIsPublished(apfId, serviceApiId)
 	if err != nil {
-		return sendCoreError(ctx, http.StatusBadRequest, fmt.Sprintf(errMsg, err))
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseContextNotFound, fmt.Sprintf(errMsg, err))
 	}
 
 	updatedServiceDescription, err := getServiceFromRequest(ctx)
 	if err != nil {
-		return sendCoreError(ctx, http.StatusBadRequest, fmt.Sprintf(errMsg, err))
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseInvalidMsgFormat, fmt.Sprintf(errMsg, err))
 	}
 
 	// Additional validation for PUT
 	if (updatedServiceDescription.ApiId == nil) || (*updatedServiceDescription.ApiId != serviceApiId) {
 		errDetail := "ServiceAPIDescription ApiId doesn't match path parameter"
-		return sendCoreError(ctx, http.StatusBadRequest, fmt.Sprintf(errMsg, errDetail))
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseMandatoryIeIncorrect, fmt.Sprintf(errMsg, errDetail))
 	}
 
 	err = ps.checkProfilesRegistered(apfId, *updatedServiceDescription.AefProfiles)
 	if err != nil {
-		return sendCoreError(ctx, http.StatusBadRequest, fmt.Sprintf(errMsg, err))
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseMandatoryIeIncorrect, fmt.Sprintf(errMsg, err))
 	}
 
 	ps.updateDescription(&updatedServiceDescription, &publishedService)
 
 	publishedService.AefProfiles = updatedServiceDescription.AefProfiles
 	ps.publishedServices[apfId][pos] = publishedService
+	ps.storePublishedServices(apfId)
 
 	err = ctx.JSON(http.StatusOK, publishedService)
 	if err != nil {
@@ -373,6 +533,13 @@ func (ps *PublishService) sendEvent(service publishapi.ServiceAPIDescription, ev
 	ps.eventChannel <- event
 }
 
+// Must be called with the lock held.
+func (ps *PublishService) storePublishedServices(apfId string) {
+	if err := ps.store.Put(publishedServicesBucket, apfId, ps.publishedServices[apfId]); err != nil {
+		log.Errorf("Unable to store services published by %s due to %s", apfId, err)
+	}
+}
+
 func (ps *PublishService) checkProfilesRegistered(apfId string, updatedProfiles []publishapi.AefProfile) error {
 	registeredFuncs := ps.serviceRegister.GetAefsForPublisher(apfId)
 	for _, profile := range updatedProfiles {
@@ -382,14 +549,3 @@ func (ps *PublishService) checkProfilesRegistered(apfId string, updatedProfiles
 	}
 	return nil
 }
-
-// This function wraps sending of an error in the Error format, and
-// handling the failure to marshal that.
-func sendCoreError(ctx echo.Context, code int, message string) error {
-	pd := common29122.ProblemDetails{
-		Cause:  &message,
-		Status: &code,
-	}
-	err := ctx.JSON(code, pd)
-	return err
-}
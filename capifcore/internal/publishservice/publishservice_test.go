@@ -39,9 +39,12 @@ import (
 	"oransc.org/nonrtric/capifcore/internal/helmmanagement"
 	helmMocks "oransc.org/nonrtric/capifcore/internal/helmmanagement/mocks"
 	serviceMocks "oransc.org/nonrtric/capifcore/internal/providermanagement/mocks"
+	"oransc.org/nonrtric/capifcore/internal/storage/storagetest"
+	"oransc.org/nonrtric/capifcore/problemdetails"
 
 	"github.com/deepmap/oapi-codegen/pkg/middleware"
 	"github.com/deepmap/oapi-codegen/pkg/testutil"
+	"github.com/getkin/kin-openapi/openapi3filter"
 	echomiddleware "github.com/labstack/echo/v4/middleware"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -64,7 +67,7 @@ func TestUnregisteredService(t *testing.T) {
 	err := result.UnmarshalJsonToObject(&resultError)
 	assert.NoError(t, err, "error unmarshaling response")
 
-	assert.Contains(t, *resultError.Cause, "api is only available for publishers")
+	assert.Contains(t, *resultError.Detail, "api is only available for publishers")
 	assert.Equal(t, http.StatusNotFound, *resultError.Status)
 }
 
@@ -137,16 +140,20 @@ func TestPublishUnpublishService(t *testing.T) {
 	var resultError common29122.ProblemDetails
 	err = result.UnmarshalJsonToObject(&resultError)
 	assert.NoError(t, err, "error unmarshaling response")
-	assert.Contains(t, *resultError.Cause, "already published")
+	assert.Contains(t, *resultError.Detail, "already published")
 	assert.Equal(t, http.StatusForbidden, *resultError.Status)
 
 	// Delete the service
 	helmManagerMock.On("UninstallHelmChart", mock.Anything, mock.Anything).Return(nil)
+	unpublishHandlerMock := unpublishHandler{}
+	unpublishHandlerMock.On("RemoveApi", newApiId).Return()
+	serviceUnderTest.AddUnpublishHandler(&unpublishHandlerMock)
 
 	result = testutil.NewRequest().Delete("/"+apfId+"/service-apis/"+newApiId).Go(t, requestHandler)
 
 	assert.Equal(t, http.StatusNoContent, result.Code())
 	helmManagerMock.AssertCalled(t, "UninstallHelmChart", namespace, chartName)
+	unpublishHandlerMock.AssertCalled(t, "RemoveApi", newApiId)
 	assert.Empty(t, serviceUnderTest.getAllAefIds())
 
 	// Check no services published for a provider
@@ -160,6 +167,11 @@ func TestPublishUnpublishService(t *testing.T) {
 	}
 
 	assert.Equal(t, http.StatusNotFound, result.Code())
+	var problemDetails common29122.ProblemDetails
+	err = result.UnmarshalJsonToObject(&problemDetails)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, problemdetails.CauseContextNotFound, *problemDetails.Cause)
+	assert.Equal(t, common29122.Uri("/"+apfId+"/service-apis/"+newApiId), *problemDetails.Instance)
 
 	// Check no services published
 	result = testutil.NewRequest().Get("/"+apfId+"/service-apis").Go(t, requestHandler)
@@ -191,8 +203,8 @@ func TestPostUnpublishedServiceWithUnregisteredFunction(t *testing.T) {
 	var resultError common29122.ProblemDetails
 	err := result.UnmarshalJsonToObject(&resultError)
 	assert.NoError(t, err, "error unmarshaling response")
-	assert.Contains(t, *resultError.Cause, aefId)
-	assert.Contains(t, *resultError.Cause, "not registered")
+	assert.Contains(t, *resultError.Detail, aefId)
+	assert.Contains(t, *resultError.Detail, "not registered")
 	assert.Equal(t, http.StatusNotFound, *resultError.Status)
 }
 
@@ -242,7 +254,7 @@ func TestGetServices(t *testing.T) {
 }
 
 func TestGetPublishedServices(t *testing.T) {
-	serviceUnderTest := NewPublishService(nil, nil, nil)
+	serviceUnderTest := NewPublishService(nil, nil, nil, storagetest.NewStore())
 
 	profiles := make([]publishapi.AefProfile, 1)
 	serviceDescription := publishapi.ServiceAPIDescription{
@@ -258,8 +270,65 @@ func TestGetPublishedServices(t *testing.T) {
 	assert.Len(t, result, 2)
 }
 
+func TestUnpublishServicesForPublisher(t *testing.T) {
+	helmManagerMock := helmMocks.HelmManager{}
+	helmManagerMock.On("UninstallHelmChart", mock.Anything, mock.Anything).Return(nil)
+	serviceUnderTest := NewPublishService(nil, &helmManagerMock, nil, storagetest.NewStore())
+
+	apiId := "apiId"
+	description := "Description,namespace,repoName,chartName,releaseName"
+	serviceDescription := publishapi.ServiceAPIDescription{
+		ApiId:       &apiId,
+		Description: &description,
+	}
+	serviceUnderTest.publishedServices["publisher1"] = []publishapi.ServiceAPIDescription{
+		serviceDescription,
+	}
+	serviceUnderTest.publishedServices["publisher2"] = []publishapi.ServiceAPIDescription{
+		{},
+	}
+
+	unpublishHandlerMock := unpublishHandler{}
+	unpublishHandlerMock.On("RemoveApi", apiId).Return()
+	serviceUnderTest.AddUnpublishHandler(&unpublishHandlerMock)
+
+	result := serviceUnderTest.UnpublishServices("publisher1")
+	assert.Equal(t, []publishapi.ServiceAPIDescription{serviceDescription}, result)
+	assert.Len(t, serviceUnderTest.GetAllPublishedServices(), 1)
+	helmManagerMock.AssertCalled(t, "UninstallHelmChart", "namespace", "chartName")
+	unpublishHandlerMock.AssertCalled(t, "RemoveApi", apiId)
+
+	assert.Empty(t, serviceUnderTest.UnpublishServices("publisher1"))
+}
+
+func TestGetPublishedService(t *testing.T) {
+	serviceUnderTest := NewPublishService(nil, nil, nil, storagetest.NewStore())
+
+	apiId1 := "apiId1"
+	serviceDescription1 := getServiceAPIDescription("aefId", "api1", "Description")
+	serviceDescription1.ApiId = &apiId1
+	apiId2 := "apiId2"
+	serviceDescription2 := getServiceAPIDescription("aefId", "api2", "Description")
+	serviceDescription2.ApiId = &apiId2
+	serviceUnderTest.publishedServices["publisher1"] = []publishapi.ServiceAPIDescription{
+		serviceDescription1,
+	}
+	serviceUnderTest.publishedServices["publisher2"] = []publishapi.ServiceAPIDescription{
+		serviceDescription2,
+	}
+
+	result := serviceUnderTest.GetPublishedService(apiId2)
+	if assert.NotNil(t, result) {
+		assert.Equal(t, serviceDescription2, *result)
+	}
+	assert.Nil(t, serviceUnderTest.GetPublishedService("unknown"))
+
+	assert.Equal(t, "publisher2", serviceUnderTest.GetPublishingFunction(apiId2))
+	assert.Empty(t, serviceUnderTest.GetPublishingFunction("unknown"))
+}
+
 func TestGetAllowedServices(t *testing.T) {
-	serviceUnderTest := NewPublishService(nil, nil, nil)
+	serviceUnderTest := NewPublishService(nil, nil, nil, storagetest.NewStore())
 
 	aefProfiles1 := []publishapi.AefProfile{}
 	apiName1 := "api Name 1"
@@ -328,6 +397,27 @@ func TestGetAllowedServices(t *testing.T) {
 	assert.Len(t, result, 0)
 }
 
+func TestGetAllowedServicesById(t *testing.T) {
+	serviceUnderTest := NewPublishService(nil, nil, nil, storagetest.NewStore())
+
+	apiId1 := "apiId1"
+	apiId2 := "apiId2"
+	serviceDescription1 := getServiceAPIDescription("aefId", "api", "Description")
+	serviceDescription1.ApiId = &apiId1
+	serviceDescription2 := getServiceAPIDescription("otherAefId", "api", "Description")
+	serviceDescription2.ApiId = &apiId2
+	serviceUnderTest.publishedServices["publisher1"] = []publishapi.ServiceAPIDescription{serviceDescription1}
+	serviceUnderTest.publishedServices["publisher2"] = []publishapi.ServiceAPIDescription{serviceDescription2}
+
+	// APIs with the same name are told apart by their ids
+	result := serviceUnderTest.GetAllowedPublishedServices([]publishapi.ServiceAPIDescription{{ApiId: &apiId2, ApiName: "api"}})
+	assert.Equal(t, []publishapi.ServiceAPIDescription{serviceDescription2}, result)
+
+	unknownApiId := "unknownApiId"
+	result = serviceUnderTest.GetAllowedPublishedServices([]publishapi.ServiceAPIDescription{{ApiId: &unknownApiId, ApiName: "api"}})
+	assert.Len(t, result, 0)
+}
+
 func TestUpdateDescription(t *testing.T) {
 	apfId := "apfId"
 	serviceApiId := "serviceApiId"
@@ -391,7 +481,8 @@ func TestUpdateDescription(t *testing.T) {
 	assert.Equal(t, newDescription, *resultService.Description)
 	assert.Equal(t, newDomainName, *(*resultService.AefProfiles)[0].DomainName)
 	assert.Equal(t, "aefIdNew", (*resultService.AefProfiles)[1].AefId)
-	assert.True(t, serviceUnderTest.IsAPIPublished("aefIdNew", "path"))
+	assert.True(t, serviceUnderTest.IsAPIPublished("aefIdNew", apiName))
+	assert.False(t, serviceUnderTest.IsAPIPublished("aefIdNew", "otherApiName"))
 
 	if publishEvent, ok := waitForEvent(eventChannel, 1*time.Second); ok {
 		assert.Fail(t, "No event sent")
@@ -463,10 +554,108 @@ func TestFailedUpdateDescription(t *testing.T) {
 	err := result.UnmarshalJsonToObject(&resultError)
 	assert.NoError(t, err, "error unmarshaling response")
 
-	assert.Contains(t, *resultError.Cause, "ServiceAPIDescription ApiId doesn't match path parameter")
+	assert.Contains(t, *resultError.Detail, "ServiceAPIDescription ApiId doesn't match path parameter")
 	assert.Equal(t, http.StatusBadRequest, *resultError.Status)
 }
 
+func TestModifyService(t *testing.T) {
+	apfId := "apfId"
+	serviceApiId := "serviceApiId"
+	aefId := "aefId"
+	apiName := "apiName"
+	description := "description"
+
+	serviceRegisterMock := serviceMocks.ServiceRegister{}
+	serviceRegisterMock.On("GetAefsForPublisher", apfId).Return([]string{aefId, "aefIdNew"})
+	serviceUnderTest, eventChannel, requestHandler := getEcho(&serviceRegisterMock, nil)
+	serviceDescription := getServiceAPIDescription(aefId, apiName, description)
+	serviceDescription.ApiId = &serviceApiId
+	serviceUnderTest.publishedServices[apfId] = []publishapi.ServiceAPIDescription{serviceDescription}
+
+	// Only change the description
+	newDescription := "new description"
+	patch := publishapi.ServiceAPIDescriptionPatch{
+		Description: &newDescription,
+	}
+	result := testutil.NewRequest().Patch("/"+apfId+"/service-apis/"+serviceApiId).WithJsonBody(patch).WithContentType("application/merge-patch+json").Go(t, requestHandler)
+
+	var resultService publishapi.ServiceAPIDescription
+	assert.Equal(t, http.StatusOK, result.Code())
+	err := result.UnmarshalJsonToObject(&resultService)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, newDescription, *resultService.Description)
+	assert.Equal(t, apiName, resultService.ApiName)
+	assert.Equal(t, *serviceDescription.AefProfiles, *resultService.AefProfiles)
+	assert.Equal(t, newDescription, *serviceUnderTest.GetPublishedService(serviceApiId).Description)
+
+	if publishEvent, timeout := waitForEvent(eventChannel, 1*time.Second); timeout {
+		assert.Fail(t, "No event sent")
+	} else {
+		assert.Equal(t, serviceApiId, (*publishEvent.EventDetail.ApiIds)[0])
+		assert.Equal(t, eventsapi.CAPIFEventSERVICEAPIUPDATE, publishEvent.Events)
+	}
+
+	// Replace the AEF profiles
+	newProfiles := *getServiceAPIDescription("aefIdNew", apiName, description).AefProfiles
+	patch = publishapi.ServiceAPIDescriptionPatch{
+		AefProfiles: &newProfiles,
+	}
+	result = testutil.NewRequest().Patch("/"+apfId+"/service-apis/"+serviceApiId).WithJsonBody(patch).WithContentType("application/merge-patch+json").Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusOK, result.Code())
+	err = result.UnmarshalJsonToObject(&resultService)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, newDescription, *resultService.Description)
+	assert.Len(t, *resultService.AefProfiles, 1)
+	assert.True(t, serviceUnderTest.IsAPIPublished("aefIdNew", apiName))
+	assert.False(t, serviceUnderTest.IsAPIPublished(aefId, apiName))
+
+	if _, timeout := waitForEvent(eventChannel, 1*time.Second); timeout {
+		assert.Fail(t, "No event sent")
+	}
+}
+
+func TestFailedModifyService(t *testing.T) {
+	apfId := "apfId"
+	serviceApiId := "serviceApiId"
+	aefId := "aefId"
+
+	serviceRegisterMock := serviceMocks.ServiceRegister{}
+	serviceRegisterMock.On("GetAefsForPublisher", apfId).Return([]string{aefId})
+	serviceUnderTest, _, requestHandler := getEcho(&serviceRegisterMock, nil)
+
+	newDescription := "new description"
+	patch := publishapi.ServiceAPIDescriptionPatch{
+		Description: &newDescription,
+	}
+
+	// Modify a service that has not been published
+	result := testutil.NewRequest().Patch("/"+apfId+"/service-apis/"+serviceApiId).WithJsonBody(patch).WithContentType("application/merge-patch+json").Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusNotFound, result.Code())
+	var resultError common29122.ProblemDetails
+	err := result.UnmarshalJsonToObject(&resultError)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Contains(t, *resultError.Detail, "service must be published before updating it")
+
+	serviceDescription := getServiceAPIDescription(aefId, "apiName", "description")
+	serviceDescription.ApiId = &serviceApiId
+	serviceUnderTest.publishedServices[apfId] = []publishapi.ServiceAPIDescription{serviceDescription}
+
+	// Modify the service with a profile for a function that is not registered
+	newProfiles := *getServiceAPIDescription("otherAefId", "apiName", "description").AefProfiles
+	patch = publishapi.ServiceAPIDescriptionPatch{
+		AefProfiles: &newProfiles,
+	}
+	result = testutil.NewRequest().Patch("/"+apfId+"/service-apis/"+serviceApiId).WithJsonBody(patch).WithContentType("application/merge-patch+json").Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusBadRequest, result.Code())
+	err = result.UnmarshalJsonToObject(&resultError)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Contains(t, *resultError.Detail, "function otherAefId not registered")
+	assert.True(t, serviceUnderTest.IsAPIPublished(aefId, "apiName"))
+}
+
 func TestUpdateValidServiceWithDeletedFunction(t *testing.T) {
 	apfId := "apfId"
 	serviceApiId := "serviceApiId"
@@ -547,7 +736,7 @@ func TestUpdateValidServiceWithDeletedFunction(t *testing.T) {
 	err := result.UnmarshalJsonToObject(&resultService)
 	assert.NoError(t, err, "error unmarshaling response")
 	assert.Len(t, (*resultService.AefProfiles), 1)
-	assert.False(t, serviceUnderTest.IsAPIPublished("aefId", "path"))
+	assert.False(t, serviceUnderTest.IsAPIPublished("aefId", apiName))
 
 }
 
@@ -566,8 +755,8 @@ func TestPublishInvalidService(t *testing.T) {
 	var resultError common29122.ProblemDetails
 	err := result.UnmarshalJsonToObject(&resultError)
 	assert.NoError(t, err, "error unmarshaling response")
-	assert.Contains(t, *resultError.Cause, "missing")
-	assert.Contains(t, *resultError.Cause, "apiName")
+	assert.Contains(t, *resultError.Detail, "missing")
+	assert.Contains(t, *resultError.Detail, "apiName")
 	assert.Equal(t, http.StatusBadRequest, *resultError.Status)
 
 }
@@ -581,10 +770,12 @@ func getEcho(serviceRegister providermanagement.ServiceRegister, helmManager hel
 	swagger.Servers = nil
 
 	eventChannel := make(chan eventsapi.EventNotification)
-	ps := NewPublishService(serviceRegister, helmManager, eventChannel)
+	ps := NewPublishService(serviceRegister, helmManager, eventChannel, storagetest.NewStore())
 
 	e := echo.New()
 	e.Use(echomiddleware.Logger())
+	// PATCH requests use the merge patch content type, which the request validator must decode as JSON
+	openapi3filter.RegisterBodyDecoder("application/merge-patch+json", openapi3filter.RegisteredBodyDecoder("application/json"))
 	e.Use(middleware.OapiRequestValidator(swagger))
 
 	publishapi.RegisterHandlers(e, ps)
@@ -632,3 +823,11 @@ func waitForEvent(ch chan eventsapi.EventNotification, timeout time.Duration) (*
 		return nil, true // timed out
 	}
 }
+
+type unpublishHandler struct {
+	mock.Mock
+}
+
+func (h *unpublishHandler) RemoveApi(apiId string) {
+	h.Called(apiId)
+}
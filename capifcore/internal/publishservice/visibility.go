@@ -0,0 +1,166 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package publishservice
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	echo "github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/utils/strings/slices"
+
+	"oransc.org/nonrtric/capifcore/problemdetails"
+)
+
+const serviceVisibilityBucket = "serviceVisibility"
+
+// Rules set by the provider of a published API on which invokers may discover the API, in addition to the invokers
+// that have been granted the API and the provider domains it is shared with. This is not part of the 3GPP API.
+type ServiceVisibility struct {
+	// Invokers that may discover the API.
+	AllowedInvokers []string `json:"allowedInvokers,omitempty"`
+	// Invokers that may not discover the API, even if they have been granted it.
+	DeniedInvokers []string `json:"deniedInvokers,omitempty"`
+}
+
+func (sv ServiceVisibility) IsAllowed(invokerId string) bool {
+	return slices.Contains(sv.AllowedInvokers, invokerId)
+}
+
+func (sv ServiceVisibility) IsDenied(invokerId string) bool {
+	return slices.Contains(sv.DeniedInvokers, invokerId)
+}
+
+func (ps *PublishService) GetServiceVisibility(apiId string) *ServiceVisibility {
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+
+	if visibility, ok := ps.serviceVisibility[apiId]; ok {
+		return &visibility
+	}
+	return nil
+}
+
+// Retrieves the visibility rules of a published API. An API without rules gets empty rules.
+func (ps *PublishService) GetServiceApiVisibility(ctx echo.Context) error {
+	errMsg := "Unable to get service visibility due to %s"
+	apfId := ctx.Param("apfId")
+	serviceApiId := ctx.Param("serviceApiId")
+
+	ps.lock.Lock()
+	_, _, err := ps.checkIfServiceIsPublished(apfId, serviceApiId)
+	visibility := ps.serviceVisibility[serviceApiId]
+	ps.lock.Unlock()
+	if err != nil {
+		return problemdetails.Send(ctx, http.StatusNotFound, problemdetails.CauseContextNotFound, fmt.Sprintf(errMsg, "service is not published"))
+	}
+
+	err = ctx.JSON(http.StatusOK, visibility)
+	if err != nil {
+		// Something really bad happened, tell Echo that our handler failed
+		return err
+	}
+	return nil
+}
+
+// Replaces the visibility rules of a published API.
+func (ps *PublishService) PutServiceApiVisibility(ctx echo.Context) error {
+	errMsg := "Unable to update service visibility due to %s"
+	apfId := ctx.Param("apfId")
+	serviceApiId := ctx.Param("serviceApiId")
+
+	var visibility ServiceVisibility
+	if err := ctx.Bind(&visibility); err != nil {
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseInvalidMsgFormat, fmt.Sprintf(errMsg, "invalid format for service visibility"))
+	}
+
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+
+	if _, _, err := ps.checkIfServiceIsPublished(apfId, serviceApiId); err != nil {
+		return problemdetails.Send(ctx, http.StatusNotFound, problemdetails.CauseContextNotFound, fmt.Sprintf(errMsg, err))
+	}
+
+	ps.serviceVisibility[serviceApiId] = visibility
+	if err := ps.store.Put(serviceVisibilityBucket, serviceApiId, visibility); err != nil {
+		log.Errorf("Unable to store visibility of service %s due to %s", serviceApiId, err)
+	}
+
+	err := ctx.JSON(http.StatusOK, visibility)
+	if err != nil {
+		// Something really bad happened, tell Echo that our handler failed
+		return err
+	}
+	return nil
+}
+
+// Adds the operations that get and replace the visibility rules of a published API to the specification of the API,
+// so that their requests are validated like the requests of the 3GPP operations.
+func AddVisibilityOperations(swagger *openapi3.T) {
+	pathItem := swagger.Paths["/{apfId}/service-apis/{serviceApiId}"]
+	if pathItem == nil || pathItem.Get == nil {
+		return
+	}
+	parameters := openapi3.Parameters{}
+	for _, parameter := range pathItem.Get.Parameters {
+		if parameter.Value != nil && parameter.Value.In == openapi3.ParameterInPath {
+			parameters = append(parameters, parameter)
+		}
+	}
+	responses := openapi3.Responses{}
+	for status, response := range pathItem.Get.Responses {
+		if status != "200" {
+			responses[status] = response
+		}
+	}
+	invokersSchema := openapi3.NewArraySchema().WithItems(openapi3.NewStringSchema())
+	visibilitySchema := openapi3.NewObjectSchema().WithProperty("allowedInvokers", invokersSchema).WithProperty("deniedInvokers", invokersSchema)
+	responses["200"] = &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("OK.").WithJSONSchema(visibilitySchema)}
+
+	swagger.Paths["/{apfId}/service-apis/{serviceApiId}/visibility"] = &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Description: "Retrieves the visibility rules of a published API.",
+			OperationID: "GetServiceApiVisibility",
+			Parameters:  parameters,
+			Responses:   responses,
+		},
+		Put: &openapi3.Operation{
+			Description: "Replaces the visibility rules of a published API.",
+			OperationID: "PutServiceApiVisibility",
+			Parameters:  parameters,
+			RequestBody: &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().WithRequired(true).WithJSONSchema(visibilitySchema)},
+			Responses:   responses,
+		},
+	}
+}
+
+// Must be called with the lock held.
+func (ps *PublishService) removeServiceVisibility(apiId string) {
+	if _, ok := ps.serviceVisibility[apiId]; !ok {
+		return
+	}
+	delete(ps.serviceVisibility, apiId)
+	if err := ps.store.Delete(serviceVisibilityBucket, apiId); err != nil {
+		log.Errorf("Unable to remove stored visibility of service %s due to %s", apiId, err)
+	}
+}
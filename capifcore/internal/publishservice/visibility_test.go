@@ -0,0 +1,125 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package publishservice
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/deepmap/oapi-codegen/pkg/testutil"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"oransc.org/nonrtric/capifcore/internal/common29122"
+	publishapi "oransc.org/nonrtric/capifcore/internal/publishserviceapi"
+	"oransc.org/nonrtric/capifcore/internal/storage/storagetest"
+	"oransc.org/nonrtric/capifcore/problemdetails"
+	"oransc.org/nonrtric/capifcore/validation"
+)
+
+func TestServiceVisibility(t *testing.T) {
+	apfId := "apfId"
+	apiId := "apiId"
+	serviceUnderTest := NewPublishService(nil, nil, nil, storagetest.NewStore())
+	serviceDescription := getServiceAPIDescription("aefId", "api", "description")
+	serviceDescription.ApiId = &apiId
+	serviceUnderTest.publishedServices[apfId] = []publishapi.ServiceAPIDescription{serviceDescription}
+	requestHandler := getVisibilityEcho(serviceUnderTest)
+	visibilityUri := "/published-apis/v1/" + apfId + "/service-apis/" + apiId + "/visibility"
+
+	// A published API without rules has empty rules
+	result := testutil.NewRequest().Get(visibilityUri).Go(t, requestHandler)
+	assert.Equal(t, http.StatusOK, result.Code())
+	var resultVisibility ServiceVisibility
+	err := result.UnmarshalJsonToObject(&resultVisibility)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, ServiceVisibility{}, resultVisibility)
+	assert.Nil(t, serviceUnderTest.GetServiceVisibility(apiId))
+
+	visibility := ServiceVisibility{
+		AllowedInvokers: []string{"allowedInvoker"},
+		DeniedInvokers:  []string{"deniedInvoker"},
+	}
+	result = testutil.NewRequest().Put(visibilityUri).WithJsonBody(visibility).Go(t, requestHandler)
+	assert.Equal(t, http.StatusOK, result.Code())
+	assert.Equal(t, &visibility, serviceUnderTest.GetServiceVisibility(apiId))
+	assert.True(t, serviceUnderTest.GetServiceVisibility(apiId).IsAllowed("allowedInvoker"))
+	assert.True(t, serviceUnderTest.GetServiceVisibility(apiId).IsDenied("deniedInvoker"))
+	assert.False(t, serviceUnderTest.GetServiceVisibility(apiId).IsDenied("allowedInvoker"))
+
+	result = testutil.NewRequest().Get(visibilityUri).Go(t, requestHandler)
+	assert.Equal(t, http.StatusOK, result.Code())
+	err = result.UnmarshalJsonToObject(&resultVisibility)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, visibility, resultVisibility)
+
+	// The rules are removed together with the API
+	serviceUnderTest.UnpublishServices(apfId)
+	assert.Nil(t, serviceUnderTest.GetServiceVisibility(apiId))
+}
+
+func TestServiceVisibilityOfUnpublishedService(t *testing.T) {
+	serviceUnderTest := NewPublishService(nil, nil, nil, storagetest.NewStore())
+	requestHandler := getVisibilityEcho(serviceUnderTest)
+	visibilityUri := "/published-apis/v1/apfId/service-apis/apiId/visibility"
+
+	result := testutil.NewRequest().Get(visibilityUri).Go(t, requestHandler)
+	assert.Equal(t, http.StatusNotFound, result.Code())
+
+	result = testutil.NewRequest().Put(visibilityUri).WithJsonBody(ServiceVisibility{}).Go(t, requestHandler)
+	assert.Equal(t, http.StatusNotFound, result.Code())
+	var resultError common29122.ProblemDetails
+	err := result.UnmarshalJsonToObject(&resultError)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, problemdetails.CauseContextNotFound, *resultError.Cause)
+	assert.Contains(t, *resultError.Detail, "must be published")
+	assert.Nil(t, serviceUnderTest.GetServiceVisibility("apiId"))
+}
+
+func TestServiceVisibilityIsValidated(t *testing.T) {
+	serviceUnderTest := NewPublishService(nil, nil, nil, storagetest.NewStore())
+	requestHandler := getVisibilityEcho(serviceUnderTest)
+
+	result := testutil.NewRequest().Put("/published-apis/v1/apfId/service-apis/apiId/visibility").WithJsonBody(map[string]any{"allowedInvokers": "invokerId"}).Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusBadRequest, result.Code())
+	var resultError common29122.ProblemDetails
+	err := result.UnmarshalJsonToObject(&resultError)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, "/allowedInvokers", (*resultError.InvalidParams)[0].Param)
+}
+
+// The visibility operations are validated against the specification they are added to.
+func getVisibilityEcho(ps *PublishService) *echo.Echo {
+	swagger, err := publishapi.GetSwagger()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading swagger spec\n: %s", err)
+		os.Exit(1)
+	}
+	AddVisibilityOperations(swagger)
+
+	e := echo.New()
+	group := validation.NewGroup(e, swagger, "/published-apis/v1", validation.ResponseValidationReject)
+	group.GET("/:apfId/service-apis/:serviceApiId/visibility", ps.GetServiceApiVisibility)
+	group.PUT("/:apfId/service-apis/:serviceApiId/visibility", ps.PutServiceApiVisibility)
+	return e
+}
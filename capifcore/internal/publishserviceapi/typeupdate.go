@@ -26,3 +26,31 @@ func (sd *ServiceAPIDescription) PrepareNewService() {
 	apiName := "api_id_" + strings.ReplaceAll(sd.ApiName, " ", "_")
 	sd.ApiId = &apiName
 }
+
+// Returns a copy of the service where the attributes present in the patch replace the current ones. Attributes not
+// present in the patch are left unchanged.
+func (sd *ServiceAPIDescription) ApplyPatch(patch ServiceAPIDescriptionPatch) ServiceAPIDescription {
+	patchedService := *sd
+	if patch.AefProfiles != nil {
+		patchedService.AefProfiles = patch.AefProfiles
+	}
+	if patch.ApiSuppFeats != nil {
+		patchedService.ApiSuppFeats = patch.ApiSuppFeats
+	}
+	if patch.CcfId != nil {
+		patchedService.CcfId = patch.CcfId
+	}
+	if patch.Description != nil {
+		patchedService.Description = patch.Description
+	}
+	if patch.PubApiPath != nil {
+		patchedService.PubApiPath = patch.PubApiPath
+	}
+	if patch.ServiceAPICategory != nil {
+		patchedService.ServiceAPICategory = patch.ServiceAPICategory
+	}
+	if patch.ShareableInfo != nil {
+		patchedService.ShareableInfo = patch.ShareableInfo
+	}
+	return patchedService
+}
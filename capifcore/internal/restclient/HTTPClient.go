@@ -60,6 +60,11 @@ func Post(url string, body []byte, header map[string]string, client HTTPClient)
 	return err
 }
 
+func Delete(url string, header map[string]string, client HTTPClient) error {
+	_, err := do(http.MethodDelete, url, nil, header, client)
+	return err
+}
+
 func do(method string, url string, body []byte, header map[string]string, client HTTPClient) ([]byte, error) {
 	if req, reqErr := http.NewRequest(method, url, nil); reqErr == nil {
 		if len(header) > 0 {
@@ -98,6 +98,33 @@ func TestPostOk(t *testing.T) {
 	clientMock.AssertNumberOfCalls(t, "Do", 1)
 }
 
+func TestDeleteOk(t *testing.T) {
+	assertions := require.New(t)
+	clientMock := mocks.HTTPClient{}
+
+	clientMock.On("Do", mock.Anything).Return(&http.Response{
+		StatusCode: http.StatusNoContent,
+		Body:       io.NopCloser(bytes.NewReader([]byte(""))),
+	}, nil)
+
+	headers := map[string]string{
+		"Authorization": "Bearer token",
+	}
+	if err := Delete("http://localhost:9990/resource", headers, &clientMock); err != nil {
+		t.Errorf("Delete() error = %v, did not want error", err)
+	}
+	var actualRequest *http.Request
+	clientMock.AssertCalled(t, "Do", mock.MatchedBy(func(req *http.Request) bool {
+		actualRequest = req
+		return true
+	}))
+	assertions.Equal(http.MethodDelete, actualRequest.Method)
+	assertions.Equal("localhost:9990", actualRequest.URL.Host)
+	assertions.Equal("/resource", actualRequest.URL.Path)
+	assertions.Equal("Bearer token", actualRequest.Header.Get("Authorization"))
+	clientMock.AssertNumberOfCalls(t, "Do", 1)
+}
+
 func Test_doErrorCases(t *testing.T) {
 	assertions := require.New(t)
 	type args struct {
@@ -0,0 +1,97 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package routinginfoapi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+func (ri RoutingInfo) Validate() error {
+	if len(ri.RoutingRules) == 0 {
+		return errors.New("RoutingInfo missing required routingRules")
+	}
+
+	for i, rule := range ri.RoutingRules {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("RoutingInfo has invalid routing rule at index %d, err=%s", i, err)
+		}
+	}
+	return nil
+}
+
+func (rr RoutingRule) Validate() error {
+	if len(strings.TrimSpace(rr.AefProfile.AefId)) == 0 {
+		return errors.New("RoutingRule missing required aefProfile aefId")
+	}
+
+	if rr.Ipv4AddrRanges != nil {
+		for i, addrRange := range *rr.Ipv4AddrRanges {
+			if addrRange.Start == nil || addrRange.End == nil {
+				return fmt.Errorf("RoutingRule has invalid ipv4AddrRanges at index %d, err=missing start or end", i)
+			}
+			if err := validateAddressRange(string(*addrRange.Start), string(*addrRange.End), net.IPv4len); err != nil {
+				return fmt.Errorf("RoutingRule has invalid ipv4AddrRanges at index %d, err=%s", i, err)
+			}
+		}
+	}
+
+	if rr.Ipv6AddrRanges != nil {
+		for i, addrRange := range *rr.Ipv6AddrRanges {
+			if err := validateAddressRange(string(addrRange.Start), string(addrRange.End), net.IPv6len); err != nil {
+				return fmt.Errorf("RoutingRule has invalid ipv6AddrRanges at index %d, err=%s", i, err)
+			}
+		}
+	}
+	return nil
+}
+
+func validateAddressRange(start, end string, addrLen int) error {
+	startIp := parseIp(start, addrLen)
+	if startIp == nil {
+		return fmt.Errorf("invalid start address %s", start)
+	}
+	endIp := parseIp(end, addrLen)
+	if endIp == nil {
+		return fmt.Errorf("invalid end address %s", end)
+	}
+	if bytes.Compare(startIp, endIp) > 0 {
+		return errors.New("end address is before start address")
+	}
+	return nil
+}
+
+func parseIp(addr string, addrLen int) net.IP {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return nil
+	}
+	if addrLen == net.IPv4len {
+		return ip.To4()
+	}
+	if ip.To4() != nil {
+		return nil
+	}
+	return ip
+}
@@ -0,0 +1,96 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package routinginfoapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"oransc.org/nonrtric/capifcore/internal/common"
+	"oransc.org/nonrtric/capifcore/internal/common29571"
+	"oransc.org/nonrtric/capifcore/internal/publishserviceapi"
+)
+
+func TestValidateRoutingInfo(t *testing.T) {
+	routingInfoUnderTest := RoutingInfo{}
+
+	err := routingInfoUnderTest.Validate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "missing")
+		assert.Contains(t, err.Error(), "routingRules")
+	}
+
+	routingInfoUnderTest.RoutingRules = []RoutingRule{{}}
+	err = routingInfoUnderTest.Validate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "invalid routing rule at index 0")
+		assert.Contains(t, err.Error(), "aefId")
+	}
+
+	routingInfoUnderTest.RoutingRules = []RoutingRule{{AefProfile: publishserviceapi.AefProfile{AefId: "aefId"}}}
+	assert.Nil(t, routingInfoUnderTest.Validate())
+}
+
+func TestValidateRoutingRule(t *testing.T) {
+	ruleUnderTest := RoutingRule{}
+
+	err := ruleUnderTest.Validate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "missing")
+		assert.Contains(t, err.Error(), "aefId")
+	}
+
+	ruleUnderTest.AefProfile.AefId = "aefId"
+	ruleUnderTest.Ipv4AddrRanges = &[]common.Ipv4AddressRange{getIpv4AddressRange("10.0.0.1", "")}
+	err = ruleUnderTest.Validate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "invalid ipv4AddrRanges at index 0")
+		assert.Contains(t, err.Error(), "invalid end address")
+	}
+
+	ruleUnderTest.Ipv4AddrRanges = &[]common.Ipv4AddressRange{getIpv4AddressRange("10.0.0.10", "10.0.0.1")}
+	err = ruleUnderTest.Validate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "invalid ipv4AddrRanges at index 0")
+		assert.Contains(t, err.Error(), "end address is before start address")
+	}
+
+	ruleUnderTest.Ipv4AddrRanges = &[]common.Ipv4AddressRange{getIpv4AddressRange("10.0.0.1", "10.0.0.10")}
+	ruleUnderTest.Ipv6AddrRanges = &[]Ipv6AddressRange{{Start: "10.0.0.1", End: "2001:db8::ff"}}
+	err = ruleUnderTest.Validate()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "invalid ipv6AddrRanges at index 0")
+		assert.Contains(t, err.Error(), "invalid start address")
+	}
+
+	ruleUnderTest.Ipv6AddrRanges = &[]Ipv6AddressRange{{Start: "2001:db8::1", End: "2001:db8::ff"}}
+	assert.Nil(t, ruleUnderTest.Validate())
+}
+
+func getIpv4AddressRange(start, end string) common.Ipv4AddressRange {
+	startAddr := common29571.Ipv4Addr(start)
+	endAddr := common29571.Ipv4Addr(end)
+	return common.Ipv4AddressRange{
+		Start: &startAddr,
+		End:   &endAddr,
+	}
+}
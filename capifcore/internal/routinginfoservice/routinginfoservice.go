@@ -0,0 +1,251 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package routinginfoservice
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	echo "github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+
+	"oransc.org/nonrtric/capifcore/internal/eventsapi"
+	"oransc.org/nonrtric/capifcore/internal/publishservice"
+	publishapi "oransc.org/nonrtric/capifcore/internal/publishserviceapi"
+	"oransc.org/nonrtric/capifcore/internal/routinginfoapi"
+	"oransc.org/nonrtric/capifcore/internal/storage"
+	"oransc.org/nonrtric/capifcore/problemdetails"
+)
+
+const routingInfoBucket = "routingInfo"
+
+type RoutingInfoService struct {
+	// Routing information per API id.
+	routingInfo     map[string]routinginfoapi.RoutingInfo
+	publishRegister publishservice.PublishRegister
+	eventChannel    chan<- eventsapi.EventNotification
+	store           storage.Store
+	lock            sync.Mutex
+}
+
+// Creates a service that implements the routinginfoapi.ServerInterface interface.
+// Routing information kept in the provided store is loaded at creation.
+func NewRoutingInfoService(publishRegister publishservice.PublishRegister, eventChannel chan<- eventsapi.EventNotification, store storage.Store) *RoutingInfoService {
+	ris := &RoutingInfoService{
+		routingInfo:     make(map[string]routinginfoapi.RoutingInfo),
+		publishRegister: publishRegister,
+		eventChannel:    eventChannel,
+		store:           store,
+	}
+	if err := storage.Load(store, routingInfoBucket, ris.routingInfo); err != nil {
+		log.Errorf("Unable to load routing information due to %s", err)
+	}
+	return ris
+}
+
+// Retrieves the routing rules of the API that apply to the AEF.
+func (ris *RoutingInfoService) GetServiceApisServiceApiId(ctx echo.Context, serviceApiId string, params routinginfoapi.GetServiceApisServiceApiIdParams) error {
+	errMsg := "Unable to get routing information due to %s"
+
+	ris.lock.Lock()
+	routingInfo, ok := ris.routingInfo[serviceApiId]
+	ris.lock.Unlock()
+
+	if !ok {
+		return problemdetails.Send(ctx, http.StatusNotFound, problemdetails.CauseContextNotFound, fmt.Sprintf(errMsg, fmt.Sprintf("no routing information for api %s", serviceApiId)))
+	}
+
+	aefRules := []routinginfoapi.RoutingRule{}
+	for _, rule := range routingInfo.RoutingRules {
+		if rule.AefProfile.AefId == params.AefId {
+			aefRules = append(aefRules, rule)
+		}
+	}
+	if len(aefRules) == 0 {
+		return problemdetails.Send(ctx, http.StatusNotFound, problemdetails.CauseContextNotFound, fmt.Sprintf(errMsg, fmt.Sprintf("no routing rules for aef %s", params.AefId)))
+	}
+
+	err := ctx.JSON(http.StatusOK, routinginfoapi.RoutingInfo{RoutingRules: aefRules})
+	if err != nil {
+		// Something really bad happened, tell Echo that our handler failed
+		return err
+	}
+
+	return nil
+}
+
+// Creates or replaces the routing information of a published API. This operation is not part of the 3GPP API, it
+// lets the provider of the API configure how the API is routed to its AEFs.
+func (ris *RoutingInfoService) PutServiceApisServiceApiId(ctx echo.Context) error {
+	errMsg := "Unable to store routing information due to %s"
+
+	serviceApiId := ctx.Param("serviceApiId")
+
+	var routingInfo routinginfoapi.RoutingInfo
+	if err := ctx.Bind(&routingInfo); err != nil {
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseInvalidMsgFormat, fmt.Sprintf(errMsg, "invalid format for routing information"))
+	}
+
+	if err := routingInfo.Validate(); err != nil {
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseMandatoryIeIncorrect, fmt.Sprintf(errMsg, err))
+	}
+
+	publishedApi := ris.publishRegister.GetPublishedService(serviceApiId)
+	if publishedApi == nil {
+		return problemdetails.Send(ctx, http.StatusNotFound, problemdetails.CauseContextNotFound, fmt.Sprintf(errMsg, fmt.Sprintf("api %s not published", serviceApiId)))
+	}
+	for _, rule := range routingInfo.RoutingRules {
+		if !isExposedBy(publishedApi, rule.AefProfile.AefId) {
+			return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseMandatoryIeIncorrect, fmt.Sprintf(errMsg, fmt.Sprintf("aef %s does not expose api %s", rule.AefProfile.AefId, serviceApiId)))
+		}
+	}
+
+	created := ris.setRoutingInfo(serviceApiId, routingInfo)
+
+	go ris.sendEvent(serviceApiId, routingInfo, eventsapi.CAPIFEventAPITOPOLOGYHIDINGCREATED)
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	err := ctx.JSON(status, routingInfo)
+	if err != nil {
+		// Something really bad happened, tell Echo that our handler failed
+		return err
+	}
+
+	return nil
+}
+
+// Removes the routing information of an API. This operation is not part of the 3GPP API.
+func (ris *RoutingInfoService) DeleteServiceApisServiceApiId(ctx echo.Context) error {
+	serviceApiId := ctx.Param("serviceApiId")
+
+	if routingInfo, ok := ris.deleteRoutingInfo(serviceApiId); ok {
+		go ris.sendEvent(serviceApiId, routingInfo, eventsapi.CAPIFEventAPITOPOLOGYHIDINGREVOKED)
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// Removes the routing information of an API that is unpublished.
+func (ris *RoutingInfoService) RemoveApi(apiId string) {
+	if routingInfo, ok := ris.deleteRoutingInfo(apiId); ok {
+		go ris.sendEvent(apiId, routingInfo, eventsapi.CAPIFEventAPITOPOLOGYHIDINGREVOKED)
+	}
+}
+
+// Adds the operations that store and remove routing information to the specification of the API, so that their
+// requests are validated like the requests of the 3GPP operation.
+func AddProviderOperations(swagger *openapi3.T) {
+	pathItem := swagger.Paths["/service-apis/{serviceApiId}"]
+	if pathItem == nil || pathItem.Get == nil {
+		return
+	}
+	parameters := openapi3.Parameters{}
+	for _, parameter := range pathItem.Get.Parameters {
+		if parameter.Value != nil && parameter.Value.In == openapi3.ParameterInPath {
+			parameters = append(parameters, parameter)
+		}
+	}
+	putResponses := openapi3.Responses{}
+	deleteResponses := openapi3.Responses{}
+	for status, response := range pathItem.Get.Responses {
+		if status != "200" {
+			putResponses[status] = response
+			deleteResponses[status] = response
+		}
+	}
+	routingInfoSchema := swagger.Components.Schemas["RoutingInfo"]
+	putResponses["200"] = pathItem.Get.Responses["200"]
+	putResponses["201"] = &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("Created.").WithJSONSchemaRef(routingInfoSchema)}
+	deleteResponses["204"] = &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription("No Content.")}
+
+	pathItem.Put = &openapi3.Operation{
+		Description: "Creates or replaces the API routing information.",
+		OperationID: "PutServiceApisServiceApiId",
+		Parameters:  parameters,
+		RequestBody: &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().WithRequired(true).WithJSONSchemaRef(routingInfoSchema)},
+		Responses:   putResponses,
+	}
+	pathItem.Delete = &openapi3.Operation{
+		Description: "Removes the API routing information.",
+		OperationID: "DeleteServiceApisServiceApiId",
+		Parameters:  parameters,
+		Responses:   deleteResponses,
+	}
+}
+
+func (ris *RoutingInfoService) setRoutingInfo(apiId string, routingInfo routinginfoapi.RoutingInfo) bool {
+	ris.lock.Lock()
+	defer ris.lock.Unlock()
+
+	_, exists := ris.routingInfo[apiId]
+	ris.routingInfo[apiId] = routingInfo
+	if err := ris.store.Put(routingInfoBucket, apiId, routingInfo); err != nil {
+		log.Errorf("Unable to store routing information for %s due to %s", apiId, err)
+	}
+	return !exists
+}
+
+func (ris *RoutingInfoService) deleteRoutingInfo(apiId string) (routinginfoapi.RoutingInfo, bool) {
+	ris.lock.Lock()
+	defer ris.lock.Unlock()
+
+	routingInfo, ok := ris.routingInfo[apiId]
+	if !ok {
+		return routinginfoapi.RoutingInfo{}, false
+	}
+	delete(ris.routingInfo, apiId)
+	if err := ris.store.Delete(routingInfoBucket, apiId); err != nil {
+		log.Errorf("Unable to remove stored routing information for %s due to %s", apiId, err)
+	}
+	return routingInfo, true
+}
+
+func isExposedBy(api *publishapi.ServiceAPIDescription, aefId string) bool {
+	if api.AefProfiles == nil {
+		return false
+	}
+	for _, profile := range *api.AefProfiles {
+		if profile.AefId == aefId {
+			return true
+		}
+	}
+	return false
+}
+
+func (ris *RoutingInfoService) sendEvent(apiId string, routingInfo routinginfoapi.RoutingInfo, eventType eventsapi.CAPIFEvent) {
+	apiIds := []string{apiId}
+	event := eventsapi.EventNotification{
+		EventDetail: &eventsapi.CAPIFEventDetail{
+			ApiIds: &apiIds,
+			ApiTopoHide: &eventsapi.TopologyHiding{
+				ApiId:        apiId,
+				RoutingRules: routingInfo.RoutingRules,
+			},
+		},
+		Events: eventType,
+	}
+	ris.eventChannel <- event
+}
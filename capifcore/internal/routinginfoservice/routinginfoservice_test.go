@@ -0,0 +1,310 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package routinginfoservice
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/deepmap/oapi-codegen/pkg/middleware"
+	"github.com/deepmap/oapi-codegen/pkg/testutil"
+	"github.com/labstack/echo/v4"
+	echomiddleware "github.com/labstack/echo/v4/middleware"
+	"github.com/stretchr/testify/assert"
+
+	"oransc.org/nonrtric/capifcore/internal/common"
+	"oransc.org/nonrtric/capifcore/internal/common29122"
+	"oransc.org/nonrtric/capifcore/internal/common29571"
+	"oransc.org/nonrtric/capifcore/internal/eventsapi"
+	"oransc.org/nonrtric/capifcore/internal/publishservice"
+	publishmocks "oransc.org/nonrtric/capifcore/internal/publishservice/mocks"
+	"oransc.org/nonrtric/capifcore/internal/publishserviceapi"
+	"oransc.org/nonrtric/capifcore/internal/routinginfoapi"
+	"oransc.org/nonrtric/capifcore/internal/storage/storagetest"
+	"oransc.org/nonrtric/capifcore/validation"
+)
+
+func TestPutAndGetRoutingInfo(t *testing.T) {
+	apiId := "apiId"
+	publishRegisterMock := publishmocks.PublishRegister{}
+	publishRegisterMock.On("GetPublishedService", apiId).Return(getPublishedApi(apiId, "aefId1", "aefId2"))
+	requestHandler, serviceUnderTest, eventChannel := getEcho(&publishRegisterMock)
+
+	routingInfo := routinginfoapi.RoutingInfo{
+		RoutingRules: []routinginfoapi.RoutingRule{
+			getRoutingRule("aefId1", "10.0.0.1", "10.0.0.127"),
+			getRoutingRule("aefId2", "10.0.0.128", "10.0.0.255"),
+			getRoutingRule("aefId1", "10.0.1.1", "10.0.1.255"),
+		},
+	}
+	result := testutil.NewRequest().Put("/service-apis/"+apiId).WithJsonBody(routingInfo).Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusCreated, result.Code())
+	var resultInfo routinginfoapi.RoutingInfo
+	err := result.UnmarshalJsonToObject(&resultInfo)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, routingInfo, resultInfo)
+	assert.Equal(t, routingInfo, serviceUnderTest.routingInfo[apiId])
+	assertTopologyHidingEvent(t, eventChannel, eventsapi.CAPIFEventAPITOPOLOGYHIDINGCREATED, apiId, routingInfo.RoutingRules)
+
+	result = testutil.NewRequest().Get("/service-apis/"+apiId+"?aef-id=aefId1").Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusOK, result.Code())
+	err = result.UnmarshalJsonToObject(&resultInfo)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, []routinginfoapi.RoutingRule{routingInfo.RoutingRules[0], routingInfo.RoutingRules[2]}, resultInfo.RoutingRules)
+
+	// Replacing the routing information gives 200
+	routingInfo.RoutingRules = routingInfo.RoutingRules[1:2]
+	result = testutil.NewRequest().Put("/service-apis/"+apiId).WithJsonBody(routingInfo).Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusOK, result.Code())
+	assertTopologyHidingEvent(t, eventChannel, eventsapi.CAPIFEventAPITOPOLOGYHIDINGCREATED, apiId, routingInfo.RoutingRules)
+
+	result = testutil.NewRequest().Get("/service-apis/"+apiId+"?aef-id=aefId1").Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusNotFound, result.Code())
+	var problemDetails common29122.ProblemDetails
+	err = result.UnmarshalJsonToObject(&problemDetails)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Contains(t, *problemDetails.Detail, "no routing rules for aef aefId1")
+}
+
+func TestPutRoutingInfoFailures(t *testing.T) {
+	apiId := "apiId"
+	publishRegisterMock := publishmocks.PublishRegister{}
+	publishRegisterMock.On("GetPublishedService", apiId).Return(nil)
+	requestHandler, _, _ := getEcho(&publishRegisterMock)
+
+	result := testutil.NewRequest().Put("/service-apis/"+apiId).WithJsonBody(routinginfoapi.RoutingInfo{}).Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusBadRequest, result.Code())
+	var problemDetails common29122.ProblemDetails
+	err := result.UnmarshalJsonToObject(&problemDetails)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Contains(t, *problemDetails.Detail, "RoutingInfo missing required routingRules")
+
+	routingInfo := routinginfoapi.RoutingInfo{
+		RoutingRules: []routinginfoapi.RoutingRule{getRoutingRule("aefId", "10.0.0.1", "10.0.0.255")},
+	}
+	result = testutil.NewRequest().Put("/service-apis/"+apiId).WithJsonBody(routingInfo).Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusNotFound, result.Code())
+	err = result.UnmarshalJsonToObject(&problemDetails)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Contains(t, *problemDetails.Detail, "api apiId not published")
+}
+
+func TestPutRoutingInfoForAefNotExposingApi(t *testing.T) {
+	apiId := "apiId"
+	publishRegisterMock := publishmocks.PublishRegister{}
+	publishRegisterMock.On("GetPublishedService", apiId).Return(getPublishedApi(apiId, "aefId"))
+	requestHandler, serviceUnderTest, _ := getEcho(&publishRegisterMock)
+
+	routingInfo := routinginfoapi.RoutingInfo{
+		RoutingRules: []routinginfoapi.RoutingRule{
+			getRoutingRule("aefId", "10.0.0.1", "10.0.0.127"),
+			getRoutingRule("otherAefId", "10.0.0.128", "10.0.0.255"),
+		},
+	}
+	result := testutil.NewRequest().Put("/service-apis/"+apiId).WithJsonBody(routingInfo).Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusBadRequest, result.Code())
+	var problemDetails common29122.ProblemDetails
+	err := result.UnmarshalJsonToObject(&problemDetails)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Contains(t, *problemDetails.Detail, "aef otherAefId does not expose api apiId")
+	assert.Empty(t, serviceUnderTest.routingInfo)
+}
+
+func TestDeleteRoutingInfo(t *testing.T) {
+	apiId := "apiId"
+	requestHandler, serviceUnderTest, eventChannel := getEcho(nil)
+	routingInfo := routinginfoapi.RoutingInfo{
+		RoutingRules: []routinginfoapi.RoutingRule{getRoutingRule("aefId", "10.0.0.1", "10.0.0.255")},
+	}
+	serviceUnderTest.routingInfo[apiId] = routingInfo
+
+	result := testutil.NewRequest().Delete("/service-apis/"+apiId).Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusNoContent, result.Code())
+	assert.Empty(t, serviceUnderTest.routingInfo)
+	assertTopologyHidingEvent(t, eventChannel, eventsapi.CAPIFEventAPITOPOLOGYHIDINGREVOKED, apiId, routingInfo.RoutingRules)
+
+	result = testutil.NewRequest().Get("/service-apis/"+apiId+"?aef-id=aefId").Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusNotFound, result.Code())
+	var problemDetails common29122.ProblemDetails
+	err := result.UnmarshalJsonToObject(&problemDetails)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Contains(t, *problemDetails.Detail, "no routing information for api apiId")
+}
+
+func TestRoutingInfoIsRemovedWhenApiIsUnpublished(t *testing.T) {
+	apiId := "apiId"
+	_, serviceUnderTest, eventChannel := getEcho(nil)
+	routingInfo := routinginfoapi.RoutingInfo{
+		RoutingRules: []routinginfoapi.RoutingRule{getRoutingRule("aefId", "10.0.0.1", "10.0.0.255")},
+	}
+	serviceUnderTest.routingInfo[apiId] = routingInfo
+
+	serviceUnderTest.RemoveApi(apiId)
+
+	assert.Empty(t, serviceUnderTest.routingInfo)
+	assertTopologyHidingEvent(t, eventChannel, eventsapi.CAPIFEventAPITOPOLOGYHIDINGREVOKED, apiId, routingInfo.RoutingRules)
+
+	// Nothing is revoked for an API without routing information
+	serviceUnderTest.RemoveApi(apiId)
+
+	if _, timedOut := waitForEvent(eventChannel, 100*time.Millisecond); !timedOut {
+		assert.Fail(t, "Unexpected event sent")
+	}
+}
+
+func TestProviderOperationsAreValidated(t *testing.T) {
+	apiId := "apiId"
+	publishRegisterMock := publishmocks.PublishRegister{}
+	publishRegisterMock.On("GetPublishedService", apiId).Return(getPublishedApi(apiId, "aefId"))
+	swagger, err := routinginfoapi.GetSwagger()
+	assert.NoError(t, err)
+	AddProviderOperations(swagger)
+	eventChannel := make(chan eventsapi.EventNotification, 10)
+	ris := NewRoutingInfoService(&publishRegisterMock, eventChannel, storagetest.NewStore())
+	e := echo.New()
+	group := validation.NewGroup(e, swagger, "/capif-routing-info/v1", validation.ResponseValidationReject)
+	group.PUT("/service-apis/:serviceApiId", ris.PutServiceApisServiceApiId)
+	group.DELETE("/service-apis/:serviceApiId", ris.DeleteServiceApisServiceApiId)
+
+	routingInfo := routinginfoapi.RoutingInfo{
+		RoutingRules: []routinginfoapi.RoutingRule{getRoutingRule("aefId", "10.0.0.1", "10.0.0.255")},
+	}
+	result := testutil.NewRequest().Put("/capif-routing-info/v1/service-apis/"+apiId).WithJsonBody(routingInfo).Go(t, e)
+
+	assert.Equal(t, http.StatusCreated, result.Code())
+
+	result = testutil.NewRequest().Put("/capif-routing-info/v1/service-apis/"+apiId).WithJsonBody(map[string]any{"routingRules": "notAList"}).Go(t, e)
+
+	assert.Equal(t, http.StatusBadRequest, result.Code())
+	var problemDetails common29122.ProblemDetails
+	err = result.UnmarshalJsonToObject(&problemDetails)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Contains(t, *problemDetails.Detail, "Request does not match the API specification")
+
+	result = testutil.NewRequest().Delete("/capif-routing-info/v1/service-apis/"+apiId).Go(t, e)
+
+	assert.Equal(t, http.StatusNoContent, result.Code())
+}
+
+func TestRoutingInfoIsLoadedFromStore(t *testing.T) {
+	apiId := "apiId"
+	publishRegisterMock := publishmocks.PublishRegister{}
+	publishRegisterMock.On("GetPublishedService", apiId).Return(getPublishedApi(apiId, "aefId"))
+	requestHandler, serviceUnderTest, eventChannel := getEcho(&publishRegisterMock)
+	routingInfo := routinginfoapi.RoutingInfo{
+		RoutingRules: []routinginfoapi.RoutingRule{getRoutingRule("aefId", "10.0.0.1", "10.0.0.255")},
+	}
+	testutil.NewRequest().Put("/service-apis/"+apiId).WithJsonBody(routingInfo).Go(t, requestHandler)
+	waitForEvent(eventChannel, 1*time.Second)
+
+	restartedService := NewRoutingInfoService(&publishRegisterMock, eventChannel, serviceUnderTest.store)
+
+	assert.Equal(t, serviceUnderTest.routingInfo, restartedService.routingInfo)
+}
+
+func assertTopologyHidingEvent(t *testing.T, eventChannel chan eventsapi.EventNotification, eventType eventsapi.CAPIFEvent, apiId string, wantRules []routinginfoapi.RoutingRule) {
+	if topologyEvent, timedOut := waitForEvent(eventChannel, 1*time.Second); timedOut {
+		assert.Fail(t, "No event sent")
+	} else {
+		assert.Equal(t, eventType, topologyEvent.Events)
+		assert.Equal(t, []string{apiId}, *topologyEvent.EventDetail.ApiIds)
+		assert.Equal(t, apiId, topologyEvent.EventDetail.ApiTopoHide.ApiId)
+		assert.Equal(t, wantRules, topologyEvent.EventDetail.ApiTopoHide.RoutingRules)
+	}
+}
+
+func getEcho(publishRegister publishservice.PublishRegister) (*echo.Echo, *RoutingInfoService, chan eventsapi.EventNotification) {
+	swagger, err := routinginfoapi.GetSwagger()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading swagger spec\n: %s", err)
+		os.Exit(1)
+	}
+
+	swagger.Servers = nil
+
+	eventChannel := make(chan eventsapi.EventNotification)
+	ris := NewRoutingInfoService(publishRegister, eventChannel, storagetest.NewStore())
+
+	e := echo.New()
+	e.Use(echomiddleware.Logger())
+	group := e.Group("/service-apis")
+	group.Use(middleware.OapiRequestValidator(swagger))
+
+	routinginfoapi.RegisterHandlers(e, ris)
+	e.PUT("/service-apis/:serviceApiId", ris.PutServiceApisServiceApiId)
+	e.DELETE("/service-apis/:serviceApiId", ris.DeleteServiceApisServiceApiId)
+	return e, ris, eventChannel
+}
+
+func getPublishedApi(apiId string, aefIds ...string) *publishserviceapi.ServiceAPIDescription {
+	profiles := []publishserviceapi.AefProfile{}
+	for _, aefId := range aefIds {
+		profiles = append(profiles, publishserviceapi.AefProfile{AefId: aefId})
+	}
+	return &publishserviceapi.ServiceAPIDescription{
+		ApiId:       &apiId,
+		AefProfiles: &profiles,
+	}
+}
+
+func getRoutingRule(aefId, start, end string) routinginfoapi.RoutingRule {
+	startAddr := common29571.Ipv4Addr(start)
+	endAddr := common29571.Ipv4Addr(end)
+	return routinginfoapi.RoutingRule{
+		AefProfile: publishserviceapi.AefProfile{
+			AefId: aefId,
+			Versions: []publishserviceapi.Version{
+				{
+					ApiVersion: "v1",
+				},
+			},
+		},
+		Ipv4AddrRanges: &[]common.Ipv4AddressRange{
+			{
+				Start: &startAddr,
+				End:   &endAddr,
+			},
+		},
+	}
+}
+
+// waitForEvent waits for the channel to receive an event for the specified max timeout.
+// Returns true if waiting timed out.
+func waitForEvent(ch chan eventsapi.EventNotification, timeout time.Duration) (*eventsapi.EventNotification, bool) {
+	select {
+	case event := <-ch:
+		return &event, false // completed normally
+	case <-time.After(timeout):
+		return nil, true // timed out
+	}
+}
@@ -0,0 +1,79 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package securityapi
+
+import (
+	"errors"
+	"strings"
+)
+
+// The scope of an access token request, "3gpp#<aefId>:<apiName>[,<apiName>...][;<aefId>:<apiName>[,<apiName>...]...]".
+type Scope []AefScope
+
+// The APIs of an AEF in the scope of an access token request.
+type AefScope struct {
+	AefId    string
+	ApiNames []string
+}
+
+var errMalformedScope = errors.New("Malformed scope")
+
+// Parses the scope of an access token request.
+// Returns an error if the scope does not follow the grammar of the scope.
+func ParseScope(scope string) (Scope, error) {
+	prefix, aefScopes, found := strings.Cut(scope, "#")
+	if !found {
+		return nil, errMalformedScope
+	}
+	if prefix != "3gpp" {
+		return nil, errors.New("Scope should start with 3gpp")
+	}
+
+	parsedScope := Scope{}
+	for _, aefScope := range strings.Split(aefScopes, ";") {
+		aefId, apiNames, found := strings.Cut(aefScope, ":")
+		if !found || !isScopeToken(aefId) {
+			return nil, errMalformedScope
+		}
+		parsedAefScope := AefScope{AefId: aefId}
+		for _, apiName := range strings.Split(apiNames, ",") {
+			if !isScopeToken(apiName) {
+				return nil, errMalformedScope
+			}
+			parsedAefScope.ApiNames = append(parsedAefScope.ApiNames, apiName)
+		}
+		parsedScope = append(parsedScope, parsedAefScope)
+	}
+	return parsedScope, nil
+}
+
+// Checks that the value is not empty and does not contain any of the separators of the scope or whitespace.
+func isScopeToken(value string) bool {
+	return value != "" && !strings.ContainsAny(value, "#;:, \t\r\n")
+}
+
+func (s Scope) String() string {
+	aefScopes := []string{}
+	for _, aefScope := range s {
+		aefScopes = append(aefScopes, aefScope.AefId+":"+strings.Join(aefScope.ApiNames, ","))
+	}
+	return "3gpp#" + strings.Join(aefScopes, ";")
+}
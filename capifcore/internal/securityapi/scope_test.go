@@ -0,0 +1,49 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package securityapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseScope(t *testing.T) {
+	scope, err := ParseScope("3gpp#aefId1:apiName1,apiName2;aefId2:apiName3")
+
+	assert.NoError(t, err)
+	assert.Equal(t, Scope{
+		{AefId: "aefId1", ApiNames: []string{"apiName1", "apiName2"}},
+		{AefId: "aefId2", ApiNames: []string{"apiName3"}},
+	}, scope)
+	assert.Equal(t, "3gpp#aefId1:apiName1,apiName2;aefId2:apiName3", scope.String())
+}
+
+func TestParseMalformedScope(t *testing.T) {
+	_, err := ParseScope("other#aefId:apiName")
+	assert.EqualError(t, err, "Scope should start with 3gpp")
+
+	for _, scope := range []string{"", "3gpp", "3gpp#", "3gpp#aefId", "3gpp#aefId:", "3gpp#:apiName", "3gpp#aefId:apiName;",
+		"3gpp#aefId:apiName,", "3gpp#aefId:,apiName", "3gpp#aefId:apiName:apiName", "3gpp#aefId:api#Name", "3gpp#aefId:api Name"} {
+		_, err := ParseScope(scope)
+		assert.EqualError(t, err, "Malformed scope", scope)
+	}
+}
@@ -39,19 +39,8 @@ func (tokenReq AccessTokenReq) Validate() (bool, AccessTokenErr) {
 
 	//3gpp#aefId1:apiName1,apiName2,…apiNameX;aefId2:apiName1,apiName2,…apiNameY;…aefIdN:apiName1,apiName2,…apiNameZ
 	if tokenReq.Scope != nil && *tokenReq.Scope != "" {
-		scope := strings.Split(*tokenReq.Scope, "#")
-		if len(scope) < 2 {
-			return false, createAccessTokenError(AccessTokenErrErrorInvalidScope, "Malformed scope")
-		}
-		if scope[0] != "3gpp" {
-			return false, createAccessTokenError(AccessTokenErrErrorInvalidScope, "Scope should start with 3gpp")
-		}
-		aefList := strings.Split(scope[1], ";")
-		for _, aef := range aefList {
-			apiList := strings.Split(aef, ":")
-			if len(apiList) < 2 {
-				return false, createAccessTokenError(AccessTokenErrErrorInvalidScope, "Malformed scope")
-			}
+		if _, err := ParseScope(*tokenReq.Scope); err != nil {
+			return false, createAccessTokenError(AccessTokenErrErrorInvalidScope, err.Error())
 		}
 	}
 	return true, AccessTokenErr{}
@@ -0,0 +1,103 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package security
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"oransc.org/nonrtric/capifcore/internal/aefsecurityapi"
+	"oransc.org/nonrtric/capifcore/problemdetails"
+)
+
+// Checks that the invoker has been authenticated by the CAPIF core function, i.e. that it is onboarded and has a
+// security context.
+func (s *Security) PostCheckAuthentication(ctx echo.Context) error {
+	var checkReq aefsecurityapi.CheckAuthenticationReq
+
+	errMsg := "Unable to check authentication due to %s"
+
+	if err := ctx.Bind(&checkReq); err != nil {
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseInvalidMsgFormat, fmt.Sprintf(errMsg, "invalid format for authentication check request"))
+	}
+
+	if checkReq.ApiInvokerId == "" {
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseMandatoryIeMissing, fmt.Sprintf(errMsg, "CheckAuthenticationReq missing required apiInvokerId"))
+	}
+
+	if !s.invokerRegister.IsInvokerRegistered(checkReq.ApiInvokerId) {
+		return problemdetails.Send(ctx, http.StatusNotFound, problemdetails.CauseContextNotFound, fmt.Sprintf(errMsg, fmt.Sprintf("invoker %s not registered", checkReq.ApiInvokerId)))
+	}
+
+	if !s.hasSecurityContext(checkReq.ApiInvokerId) {
+		return problemdetails.Send(ctx, http.StatusForbidden, problemdetails.CauseRequestNotAuthorized, fmt.Sprintf(errMsg, fmt.Sprintf("invoker %s not registered as trusted invoker", checkReq.ApiInvokerId)))
+	}
+
+	err := ctx.JSON(http.StatusOK, aefsecurityapi.CheckAuthenticationRsp{
+		SupportedFeatures: checkReq.SupportedFeatures,
+	})
+	if err != nil {
+		// Something really bad happened, tell Echo that our handler failed
+		return err
+	}
+
+	return nil
+}
+
+// Revokes the invoker's authorization to the APIs given in the revoke information. The invoker is notified about
+// the revocation.
+func (s *Security) PostRevokeAuthorization(ctx echo.Context) error {
+	var revokeReq aefsecurityapi.RevokeAuthorizationReq
+
+	errMsg := "Unable to revoke authorization due to %s"
+
+	if err := ctx.Bind(&revokeReq); err != nil {
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseInvalidMsgFormat, fmt.Sprintf(errMsg, "invalid format for revoke authorization request"))
+	}
+
+	if err := revokeReq.RevokeInfo.Validate(); err != nil {
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseMandatoryIeIncorrect, fmt.Sprintf(errMsg, err))
+	}
+
+	if !s.revokeAuthorization(revokeReq.RevokeInfo.ApiInvokerId, revokeReq.RevokeInfo) {
+		return problemdetails.Send(ctx, http.StatusNotFound, problemdetails.CauseContextNotFound, fmt.Sprintf(errMsg, fmt.Sprintf("invoker %s not registered as trusted invoker", revokeReq.RevokeInfo.ApiInvokerId)))
+	}
+
+	err := ctx.JSON(http.StatusOK, aefsecurityapi.RevokeAuthorizationRsp{
+		SupportedFeatures: revokeReq.SupportedFeatures,
+	})
+	if err != nil {
+		// Something really bad happened, tell Echo that our handler failed
+		return err
+	}
+
+	return nil
+}
+
+func (s *Security) hasSecurityContext(apiInvokerId string) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	_, ok := s.trustedInvokers[apiInvokerId]
+	return ok
+}
@@ -0,0 +1,223 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package security
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/deepmap/oapi-codegen/pkg/middleware"
+	"github.com/deepmap/oapi-codegen/pkg/testutil"
+	"github.com/labstack/echo/v4"
+	echomiddleware "github.com/labstack/echo/v4/middleware"
+	"github.com/stretchr/testify/assert"
+
+	"oransc.org/nonrtric/capifcore/internal/aefsecurityapi"
+	"oransc.org/nonrtric/capifcore/internal/common29122"
+	"oransc.org/nonrtric/capifcore/internal/eventsapi"
+	"oransc.org/nonrtric/capifcore/internal/invokermanagement"
+	invokermocks "oransc.org/nonrtric/capifcore/internal/invokermanagement/mocks"
+	"oransc.org/nonrtric/capifcore/internal/restclient"
+	"oransc.org/nonrtric/capifcore/internal/securityapi"
+	"oransc.org/nonrtric/capifcore/internal/storage/storagetest"
+	"oransc.org/nonrtric/capifcore/internal/websocketnotifier"
+)
+
+func TestCheckAuthentication(t *testing.T) {
+	invokerId := "invokerId"
+	invokerRegisterMock := invokermocks.InvokerRegister{}
+	invokerRegisterMock.On("IsInvokerRegistered", invokerId).Return(true)
+	invokerRegisterMock.On("IsInvokerRegistered", "unknownInvokerId").Return(false)
+	invokerRegisterMock.On("IsInvokerRegistered", "untrustedInvokerId").Return(true)
+	requestHandler, securityUnderTest, _ := getAefSecurityEcho(&invokerRegisterMock, nil)
+	securityUnderTest.trustedInvokers[invokerId] = getServiceSecurity("aefId", "apiId")
+
+	checkReq := aefsecurityapi.CheckAuthenticationReq{
+		ApiInvokerId:      invokerId,
+		SupportedFeatures: "0",
+	}
+	result := testutil.NewRequest().Post("/check-authentication").WithJsonBody(checkReq).Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusOK, result.Code())
+	var checkRsp aefsecurityapi.CheckAuthenticationRsp
+	err := result.UnmarshalJsonToObject(&checkRsp)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, checkReq.SupportedFeatures, checkRsp.SupportedFeatures)
+
+	type args struct {
+		invokerId string
+	}
+	tests := []struct {
+		name      string
+		args      args
+		wantCode  int
+		wantCause string
+	}{
+		{
+			name:      "Invoker not registered",
+			args:      args{invokerId: "unknownInvokerId"},
+			wantCode:  http.StatusNotFound,
+			wantCause: "invoker unknownInvokerId not registered",
+		},
+		{
+			name:      "Invoker without security context",
+			args:      args{invokerId: "untrustedInvokerId"},
+			wantCode:  http.StatusForbidden,
+			wantCause: "invoker untrustedInvokerId not registered as trusted invoker",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkReq.ApiInvokerId = tt.args.invokerId
+			result := testutil.NewRequest().Post("/check-authentication").WithJsonBody(checkReq).Go(t, requestHandler)
+
+			assert.Equal(t, tt.wantCode, result.Code())
+			var problemDetails common29122.ProblemDetails
+			err := result.UnmarshalJsonToObject(&problemDetails)
+			assert.NoError(t, err, "error unmarshaling response")
+			assert.Contains(t, *problemDetails.Detail, tt.wantCause)
+		})
+	}
+}
+
+func TestRevokeAuthorization(t *testing.T) {
+	aefId := "aefId"
+	apiId := "apiId"
+	invokerId := "invokerId"
+	notificationChannel := make(chan securityapi.SecurityNotification)
+	clientMock := NewTestClient(func(req *http.Request) *http.Response {
+		if req.URL.String() == "http://golang.cafe/" && req.Method == http.MethodPost {
+			var notification securityapi.SecurityNotification
+			body, _ := io.ReadAll(req.Body)
+			_ = json.Unmarshal(body, &notification)
+			notificationChannel <- notification
+			return &http.Response{
+				StatusCode: http.StatusNoContent,
+				Body:       io.NopCloser(bytes.NewBufferString(``)),
+				Header:     make(http.Header),
+			}
+		}
+		t.Error("Wrong call to client: ", req)
+		t.Fail()
+		return nil
+	})
+	requestHandler, securityUnderTest, eventChannel := getAefSecurityEcho(nil, clientMock)
+	securityUnderTest.trustedInvokers[invokerId] = getServiceSecurity(aefId, apiId)
+
+	revokeReq := aefsecurityapi.RevokeAuthorizationReq{
+		RevokeInfo: securityapi.SecurityNotification{
+			AefId:        &aefId,
+			ApiInvokerId: invokerId,
+			ApiIds:       []string{apiId},
+			Cause:        securityapi.CauseUNEXPECTEDREASON,
+		},
+		SupportedFeatures: "0",
+	}
+	result := testutil.NewRequest().Post("/revoke-authorization").WithJsonBody(revokeReq).Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusOK, result.Code())
+	var revokeRsp aefsecurityapi.RevokeAuthorizationRsp
+	err := result.UnmarshalJsonToObject(&revokeRsp)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, revokeReq.SupportedFeatures, revokeRsp.SupportedFeatures)
+	assert.False(t, securityUnderTest.hasSecurityContext(invokerId))
+
+	if revokedEvent, timedOut := waitForEvent(eventChannel, 1*time.Second); timedOut {
+		assert.Fail(t, "No event sent")
+	} else {
+		assert.Equal(t, eventsapi.CAPIFEventAPIINVOKERAUTHORIZATIONREVOKED, revokedEvent.Events)
+		assert.Equal(t, []string{invokerId}, *revokedEvent.EventDetail.ApiInvokerIds)
+		assert.Equal(t, []string{apiId}, *revokedEvent.EventDetail.ApiIds)
+	}
+
+	select {
+	case notification := <-notificationChannel:
+		assert.Equal(t, revokeReq.RevokeInfo, notification)
+	case <-time.After(1 * time.Second):
+		assert.Fail(t, "No security notification sent")
+	}
+
+	// Revoking an invoker without security context should give 404
+	result = testutil.NewRequest().Post("/revoke-authorization").WithJsonBody(revokeReq).Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusNotFound, result.Code())
+	var problemDetails common29122.ProblemDetails
+	err = result.UnmarshalJsonToObject(&problemDetails)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Contains(t, *problemDetails.Detail, "invoker invokerId not registered as trusted invoker")
+}
+
+func TestRevokeAuthorizationInvalidRevokeInfo(t *testing.T) {
+	requestHandler, _, _ := getAefSecurityEcho(nil, nil)
+
+	revokeReq := aefsecurityapi.RevokeAuthorizationReq{
+		RevokeInfo: securityapi.SecurityNotification{
+			ApiInvokerId: " ",
+			ApiIds:       []string{"apiId"},
+			Cause:        securityapi.CauseUNEXPECTEDREASON,
+		},
+		SupportedFeatures: "0",
+	}
+	result := testutil.NewRequest().Post("/revoke-authorization").WithJsonBody(revokeReq).Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusBadRequest, result.Code())
+	var problemDetails common29122.ProblemDetails
+	err := result.UnmarshalJsonToObject(&problemDetails)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Contains(t, *problemDetails.Detail, "SecurityNotification missing required ApiInvokerId")
+}
+
+func getAefSecurityEcho(invokerRegister invokermanagement.InvokerRegister, client restclient.HTTPClient) (*echo.Echo, *Security, chan eventsapi.EventNotification) {
+	swagger, err := aefsecurityapi.GetSwagger()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading swagger spec\n: %s", err)
+		os.Exit(1)
+	}
+
+	swagger.Servers = nil
+
+	eventChannel := make(chan eventsapi.EventNotification)
+	s := NewSecurity(nil, nil, invokerRegister, nil, nil, "invokerrealm", client, websocketnotifier.NewWebSocketNotifier(), eventChannel, storagetest.NewStore())
+
+	e := echo.New()
+	e.Use(echomiddleware.Logger())
+	e.Use(middleware.OapiRequestValidator(swagger))
+
+	aefsecurityapi.RegisterHandlers(e, s)
+	return e, s, eventChannel
+}
+
+// waitForEvent waits for the channel to receive an event for the specified max timeout.
+// Returns true if waiting timed out.
+func waitForEvent(ch chan eventsapi.EventNotification, timeout time.Duration) (*eventsapi.EventNotification, bool) {
+	select {
+	case event := <-ch:
+		return &event, false // completed normally
+	case <-time.After(timeout):
+		return nil, true // timed out
+	}
+}
@@ -0,0 +1,199 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package security
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+
+	"oransc.org/nonrtric/capifcore/internal/keycloak"
+	securityapi "oransc.org/nonrtric/capifcore/internal/securityapi"
+	"oransc.org/nonrtric/capifcore/problemdetails"
+)
+
+// The response of a token introspection, see RFC 7662.
+type IntrospectionResponse struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientId  string `json:"client_id,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	Exp       int64  `json:"exp,omitempty"`
+	Iat       int64  `json:"iat,omitempty"`
+	Sub       string `json:"sub,omitempty"`
+	Iss       string `json:"iss,omitempty"`
+	Jti       string `json:"jti,omitempty"`
+}
+
+// Gets the keys verifying the access tokens, as a JSON Web Key Set. This operation is not part of the 3GPP API.
+func (s *Security) GetJwks(ctx echo.Context) error {
+	if s.keycloak == nil {
+		return problemdetails.Send(ctx, http.StatusNotFound, problemdetails.CauseContextNotFound, "No authorization server configured")
+	}
+	jwks, err := s.keycloak.GetJwks(s.invokerRealm)
+	if err != nil {
+		return problemdetails.Send(ctx, http.StatusInternalServerError, problemdetails.CauseSystemFailure, fmt.Sprintf("Unable to get keys due to %s", err))
+	}
+
+	err = ctx.JSON(http.StatusOK, jwks)
+	if err != nil {
+		// Something really bad happened, tell Echo that our handler failed
+		return err
+	}
+
+	return nil
+}
+
+// Introspects an access token, see RFC 7662. The token is active if its signature is valid, it has not expired, it has
+// been granted a CAPIF scope by the token endpoint and its invoker still has a security context for the AEFs of the
+// scope. The "aef_id" form parameter requires the scope to cover that AEF, and the "api_id" form parameter requires the
+// scope and the security context to cover that API. This operation is not part of the 3GPP API.
+func (s *Security) PostIntrospect(ctx echo.Context) error {
+	token := ctx.FormValue("token")
+	if token == "" {
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseMandatoryIeMissing, "Unable to introspect token due to missing token")
+	}
+
+	response := IntrospectionResponse{}
+	if claims := s.getActiveTokenClaims(token, ctx.FormValue("aef_id"), ctx.FormValue("api_id")); claims != nil {
+		response = IntrospectionResponse{
+			Active:    true,
+			Scope:     claims.Scope,
+			ClientId:  claims.GetClientId(),
+			TokenType: "Bearer",
+			Exp:       claims.ExpiresAt,
+			Iat:       claims.IssuedAt,
+			Sub:       claims.Subject,
+			Iss:       claims.Issuer,
+			Jti:       claims.Id,
+		}
+	}
+
+	err := ctx.JSON(http.StatusOK, response)
+	if err != nil {
+		// Something really bad happened, tell Echo that our handler failed
+		return err
+	}
+
+	return nil
+}
+
+func (s *Security) getActiveTokenClaims(token, aefId, apiId string) *keycloak.TokenClaims {
+	if s.keycloak == nil {
+		return nil
+	}
+	claims, err := s.keycloak.VerifyToken(token, s.invokerRealm)
+	if err != nil {
+		log.Debugf("Inactive token due to %s", err)
+		return nil
+	}
+	// The scope is the one granted when the token was issued, as a token issued by Keycloak does not carry it
+	grantedScope, ok := s.getTokenScope(claims.Id)
+	if !ok {
+		log.Debugf("Inactive token due to no scope granted to token %s", claims.Id)
+		return nil
+	}
+	scope, err := securityapi.ParseScope(grantedScope)
+	if err != nil {
+		log.Debugf("Inactive token due to %s", err)
+		return nil
+	}
+	claims.Scope = grantedScope
+
+	invokerId := claims.GetClientId()
+	if !s.invokerRegister.IsInvokerRegistered(invokerId) {
+		return nil
+	}
+	apiName := ""
+	if apiId != "" {
+		api := s.publishRegister.GetPublishedService(apiId)
+		if api == nil {
+			return nil
+		}
+		apiName = api.ApiName
+	}
+	aefIds := getAefIds(scope)
+	if aefId != "" {
+		aefIds = []string{aefId}
+	}
+	for _, id := range aefIds {
+		if !isCoveredByScope(scope, id, apiName) {
+			return nil
+		}
+	}
+	if !s.isAuthorized(invokerId, aefIds, apiId) {
+		return nil
+	}
+	return claims
+}
+
+func getAefIds(scope securityapi.Scope) []string {
+	aefIds := []string{}
+	for _, aefScope := range scope {
+		aefIds = append(aefIds, aefScope.AefId)
+	}
+	return aefIds
+}
+
+// Checks if the scope covers the provided AEF, and the API with the provided name if given.
+func isCoveredByScope(scope securityapi.Scope, aefId, apiName string) bool {
+	for _, aefScope := range scope {
+		if aefScope.AefId != aefId {
+			continue
+		}
+		if apiName == "" {
+			return true
+		}
+		for _, name := range aefScope.ApiNames {
+			if name == apiName {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Checks that the invoker has a security context covering all the provided AEFs, and the provided API if given.
+func (s *Security) isAuthorized(invokerId string, aefIds []string, apiId string) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	serviceSecurity, ok := s.trustedInvokers[invokerId]
+	if !ok {
+		return false
+	}
+	for _, aefId := range aefIds {
+		if !hasSecurityInfo(serviceSecurity.SecurityInfo, aefId, apiId) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasSecurityInfo(securityInfo []securityapi.SecurityInformation, aefId, apiId string) bool {
+	for _, info := range securityInfo {
+		if info.AefId != nil && *info.AefId == aefId && (apiId == "" || (info.ApiId != nil && *info.ApiId == apiId)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,286 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package security
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/deepmap/oapi-codegen/pkg/testutil"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"oransc.org/nonrtric/capifcore/internal/common29122"
+	"oransc.org/nonrtric/capifcore/internal/eventsapi"
+	"oransc.org/nonrtric/capifcore/internal/invokermanagement"
+	invokermocks "oransc.org/nonrtric/capifcore/internal/invokermanagement/mocks"
+	"oransc.org/nonrtric/capifcore/internal/keycloak"
+	keycloackmocks "oransc.org/nonrtric/capifcore/internal/keycloak/mocks"
+	servicemocks "oransc.org/nonrtric/capifcore/internal/providermanagement/mocks"
+	"oransc.org/nonrtric/capifcore/internal/publishservice"
+	publishmocks "oransc.org/nonrtric/capifcore/internal/publishservice/mocks"
+	"oransc.org/nonrtric/capifcore/internal/publishserviceapi"
+	"oransc.org/nonrtric/capifcore/internal/storage/storagetest"
+	"oransc.org/nonrtric/capifcore/internal/websocketnotifier"
+)
+
+func TestIntrospectActiveToken(t *testing.T) {
+	invokerId := "invokerId"
+	aefId := "aefId"
+	apiId := "apiId"
+	claims := keycloak.TokenClaims{
+		Id:        "tokenId",
+		Issuer:    "capifcore",
+		Subject:   invokerId,
+		IssuedAt:  time.Now().Unix(),
+		ExpiresAt: time.Now().Add(time.Minute).Unix(),
+		ClientId:  invokerId,
+		Scope:     "3gpp#" + aefId + ":apiName",
+	}
+	accessMgmMock := keycloackmocks.AccessManagement{}
+	accessMgmMock.On("VerifyToken", "token", keycloak.DefaultInvokerRealm).Return(&claims, nil)
+	invokerRegisterMock := invokermocks.InvokerRegister{}
+	invokerRegisterMock.On("IsInvokerRegistered", invokerId).Return(true)
+	publishRegisterMock := publishmocks.PublishRegister{}
+	publishRegisterMock.On("GetPublishedService", apiId).Return(&publishserviceapi.ServiceAPIDescription{ApiId: &apiId, ApiName: "apiName"})
+	publishRegisterMock.On("GetPublishedService", "otherApiId").Return(&publishserviceapi.ServiceAPIDescription{ApiName: "otherApiName"})
+	publishRegisterMock.On("GetPublishedService", "unpublishedApiId").Return(nil)
+	requestHandler, securityUnderTest := getIntrospectionEcho(&publishRegisterMock, &invokerRegisterMock, &accessMgmMock)
+	serviceSecurity := getServiceSecurity(aefId, apiId)
+	serviceSecurity.SecurityInfo = append(serviceSecurity.SecurityInfo, getServiceSecurity(aefId, "otherApiId").SecurityInfo[0], getServiceSecurity("otherAefId", apiId).SecurityInfo[0])
+	securityUnderTest.trustedInvokers[invokerId] = serviceSecurity
+	securityUnderTest.tokenGrants["tokenId"] = tokenGrant{Scope: claims.Scope, ExpiresAt: claims.ExpiresAt}
+
+	response := introspect(t, requestHandler, url.Values{"token": {"token"}})
+
+	assert.True(t, response.Active)
+	assert.Equal(t, invokerId, response.ClientId)
+	assert.Equal(t, invokerId, response.Sub)
+	assert.Equal(t, claims.Scope, response.Scope)
+	assert.Equal(t, claims.ExpiresAt, response.Exp)
+	assert.Equal(t, claims.IssuedAt, response.Iat)
+	assert.Equal(t, "capifcore", response.Iss)
+	assert.Equal(t, "tokenId", response.Jti)
+	assert.Equal(t, "Bearer", response.TokenType)
+
+	// The requested AEF and API are covered by the security context
+	response = introspect(t, requestHandler, url.Values{"token": {"token"}, "aef_id": {aefId}, "api_id": {apiId}})
+	assert.True(t, response.Active)
+
+	// The requested API is covered by the security context, but not by the scope of the token
+	response = introspect(t, requestHandler, url.Values{"token": {"token"}, "aef_id": {aefId}, "api_id": {"otherApiId"}})
+	assert.False(t, response.Active)
+
+	// The requested API is not published
+	response = introspect(t, requestHandler, url.Values{"token": {"token"}, "aef_id": {aefId}, "api_id": {"unpublishedApiId"}})
+	assert.False(t, response.Active)
+
+	// The requested AEF is covered by the security context, but not by the scope of the token
+	response = introspect(t, requestHandler, url.Values{"token": {"token"}, "aef_id": {"otherAefId"}})
+	assert.False(t, response.Active)
+
+	// The requested AEF is covered by the scope of the token, but not by the security context
+	securityUnderTest.trustedInvokers[invokerId] = getServiceSecurity("otherAefId", apiId)
+	response = introspect(t, requestHandler, url.Values{"token": {"token"}, "aef_id": {aefId}})
+	assert.False(t, response.Active)
+	securityUnderTest.trustedInvokers[invokerId] = serviceSecurity
+
+	// The authorization of the invoker is revoked
+	securityUnderTest.RemoveInvoker(invokerId)
+	response = introspect(t, requestHandler, url.Values{"token": {"token"}})
+	assert.False(t, response.Active)
+	assert.Empty(t, response.ClientId)
+}
+
+func TestIntrospectInactiveToken(t *testing.T) {
+	invokerId := "invokerId"
+	claims := keycloak.TokenClaims{
+		Id:              "offboardedTokenId",
+		ExpiresAt:       time.Now().Add(time.Minute).Unix(),
+		AuthorizedParty: invokerId,
+	}
+	claimsWithoutGrant := keycloak.TokenClaims{
+		Id:              "tokenWithoutGrantId",
+		ExpiresAt:       time.Now().Add(time.Minute).Unix(),
+		AuthorizedParty: "registeredInvokerId",
+		Scope:           "3gpp#aefId:apiName",
+	}
+	accessMgmMock := keycloackmocks.AccessManagement{}
+	accessMgmMock.On("VerifyToken", "invalidToken", keycloak.DefaultInvokerRealm).Return(nil, errors.New("token is expired"))
+	accessMgmMock.On("VerifyToken", "offboardedToken", keycloak.DefaultInvokerRealm).Return(&claims, nil)
+	accessMgmMock.On("VerifyToken", "tokenWithoutGrant", keycloak.DefaultInvokerRealm).Return(&claimsWithoutGrant, nil)
+	invokerRegisterMock := invokermocks.InvokerRegister{}
+	invokerRegisterMock.On("IsInvokerRegistered", invokerId).Return(false)
+	invokerRegisterMock.On("IsInvokerRegistered", "registeredInvokerId").Return(true)
+	requestHandler, securityUnderTest := getIntrospectionEcho(nil, &invokerRegisterMock, &accessMgmMock)
+	securityUnderTest.trustedInvokers[invokerId] = getServiceSecurity("aefId", "apiId")
+	securityUnderTest.tokenGrants["offboardedTokenId"] = tokenGrant{Scope: "3gpp#aefId:apiName", ExpiresAt: claims.ExpiresAt}
+
+	// Token with invalid signature or expired
+	response := introspect(t, requestHandler, url.Values{"token": {"invalidToken"}})
+	assert.False(t, response.Active)
+
+	// Token of an offboarded invoker
+	response = introspect(t, requestHandler, url.Values{"token": {"offboardedToken"}})
+	assert.False(t, response.Active)
+	invokerRegisterMock.AssertCalled(t, "IsInvokerRegistered", invokerId)
+
+	// Token that has not been granted a scope by the token endpoint, even if it carries one
+	securityUnderTest.trustedInvokers["registeredInvokerId"] = getServiceSecurity("aefId", "apiId")
+	response = introspect(t, requestHandler, url.Values{"token": {"tokenWithoutGrant"}, "aef_id": {"aefId"}})
+	assert.False(t, response.Active)
+
+	// No authorization server to verify the token
+	requestHandler, _ = getIntrospectionEcho(nil, &invokerRegisterMock, nil)
+	response = introspect(t, requestHandler, url.Values{"token": {"invalidToken"}})
+	assert.False(t, response.Active)
+
+	// Missing token
+	result := testutil.NewRequest().Post("/introspect").WithContentType("application/x-www-form-urlencoded").WithBody([]byte(url.Values{}.Encode())).Go(t, requestHandler)
+	assert.Equal(t, http.StatusBadRequest, result.Code())
+	var problemDetails common29122.ProblemDetails
+	err := result.UnmarshalBodyToObject(&problemDetails)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Contains(t, *problemDetails.Detail, "missing token")
+}
+
+func TestIntrospectTokenIssuedByKeycloak(t *testing.T) {
+	invokerId := "invokerId"
+	aefId := "aefId"
+	apiId := "apiId"
+	// Keycloak gives the client in the azp claim and only puts its own client scopes in the scope claim
+	claims := keycloak.TokenClaims{
+		Id:              "keycloakTokenId",
+		Issuer:          "http://keycloak:8080/realms/invokerrealm",
+		Subject:         "serviceAccountUserId",
+		ExpiresAt:       time.Now().Add(time.Minute).Unix(),
+		AuthorizedParty: invokerId,
+		Scope:           "profile email",
+	}
+	accessMgmMock := keycloackmocks.AccessManagement{}
+	accessMgmMock.On("GetToken", keycloak.DefaultInvokerRealm, mock.Anything).Return(keycloak.Jwttoken{AccessToken: "keycloakToken", ExpiresIn: 60}, nil)
+	accessMgmMock.On("VerifyToken", "keycloakToken", keycloak.DefaultInvokerRealm).Return(&claims, nil)
+	invokerRegisterMock := invokermocks.InvokerRegister{}
+	invokerRegisterMock.On("IsInvokerRegistered", invokerId).Return(true)
+	invokerRegisterMock.On("VerifyInvokerSecret", invokerId, "secret").Return(true)
+	apiList := getApiList(aefId, apiId, "apiName")
+	invokerRegisterMock.On("GetInvokerApiList", invokerId).Return(&apiList)
+	serviceRegisterMock := servicemocks.ServiceRegister{}
+	serviceRegisterMock.On("IsFunctionRegistered", aefId).Return(true)
+	publishRegisterMock := publishmocks.PublishRegister{}
+	publishRegisterMock.On("IsAPIPublished", aefId, "apiName").Return(true)
+	publishRegisterMock.On("GetPublishedService", apiId).Return(&publishserviceapi.ServiceAPIDescription{ApiId: &apiId, ApiName: "apiName"})
+	tokenHandler, securityUnderTest := getEcho(&serviceRegisterMock, &publishRegisterMock, &invokerRegisterMock, nil, &accessMgmMock)
+	securityUnderTest.trustedInvokers[invokerId] = getServiceSecurity(aefId, apiId)
+	requestHandler := echo.New()
+	requestHandler.POST("/introspect", securityUnderTest.PostIntrospect)
+
+	data := url.Values{"client_id": {invokerId}, "client_secret": {"secret"}, "grant_type": {"client_credentials"}, "scope": {"3gpp#" + aefId + ":apiName"}}
+	result := testutil.NewRequest().Post("/securities/"+invokerId+"/token").WithContentType("application/x-www-form-urlencoded").WithBody([]byte(data.Encode())).Go(t, tokenHandler)
+	assert.Equal(t, http.StatusCreated, result.Code())
+
+	response := introspect(t, requestHandler, url.Values{"token": {"keycloakToken"}, "aef_id": {aefId}, "api_id": {apiId}})
+
+	assert.True(t, response.Active)
+	assert.Equal(t, invokerId, response.ClientId)
+	assert.Equal(t, "3gpp#"+aefId+":apiName", response.Scope)
+	assert.Equal(t, "keycloakTokenId", response.Jti)
+	var storedGrant tokenGrant
+	assert.NoError(t, securityUnderTest.store.ForEach(tokenGrantsBucket, func(_ string, value []byte) error {
+		return json.Unmarshal(value, &storedGrant)
+	}))
+	assert.Equal(t, tokenGrant{Scope: "3gpp#" + aefId + ":apiName", ExpiresAt: claims.ExpiresAt}, storedGrant)
+
+	// The scope is not granted for an API outside of it
+	response = introspect(t, requestHandler, url.Values{"token": {"keycloakToken"}, "aef_id": {"otherAefId"}})
+	assert.False(t, response.Active)
+}
+
+func TestGetJwks(t *testing.T) {
+	jwks := keycloak.Jwks{
+		Keys: []keycloak.Jwk{
+			{
+				Kty: "EC",
+				Kid: "kid",
+				Use: "sig",
+				Alg: "ES256",
+				Crv: "P-256",
+				X:   "x",
+				Y:   "y",
+			},
+		},
+	}
+	accessMgmMock := keycloackmocks.AccessManagement{}
+	accessMgmMock.On("GetJwks", keycloak.DefaultInvokerRealm).Return(jwks, nil)
+	requestHandler, _ := getIntrospectionEcho(nil, nil, &accessMgmMock)
+
+	result := testutil.NewRequest().Get("/.well-known/jwks.json").Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusOK, result.Code())
+	var resultJwks keycloak.Jwks
+	err := result.UnmarshalBodyToObject(&resultJwks)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, jwks, resultJwks)
+
+	// Keys not available
+	accessMgmMock = keycloackmocks.AccessManagement{}
+	accessMgmMock.On("GetJwks", mock.Anything).Return(keycloak.Jwks{}, errors.New("connection refused"))
+	requestHandler, _ = getIntrospectionEcho(nil, nil, &accessMgmMock)
+	result = testutil.NewRequest().Get("/.well-known/jwks.json").Go(t, requestHandler)
+	assert.Equal(t, http.StatusInternalServerError, result.Code())
+
+	// No authorization server
+	requestHandler, _ = getIntrospectionEcho(nil, nil, nil)
+	result = testutil.NewRequest().Get("/.well-known/jwks.json").Go(t, requestHandler)
+	assert.Equal(t, http.StatusNotFound, result.Code())
+}
+
+func introspect(t *testing.T, requestHandler *echo.Echo, data url.Values) IntrospectionResponse {
+	result := testutil.NewRequest().Post("/introspect").WithContentType("application/x-www-form-urlencoded").WithBody([]byte(data.Encode())).Go(t, requestHandler)
+	assert.Equal(t, http.StatusOK, result.Code())
+	var response IntrospectionResponse
+	err := result.UnmarshalBodyToObject(&response)
+	assert.NoError(t, err, "error unmarshaling response")
+	return response
+}
+
+func getIntrospectionEcho(publishRegister publishservice.PublishRegister, invokerRegister invokermanagement.InvokerRegister, km keycloak.AccessManagement) (*echo.Echo, *Security) {
+	clientMock := NewTestClient(func(req *http.Request) *http.Response {
+		return &http.Response{
+			StatusCode: http.StatusNoContent,
+			Body:       io.NopCloser(bytes.NewBuffer(nil)),
+			Header:     make(http.Header),
+		}
+	})
+	s := NewSecurity(nil, publishRegister, invokerRegister, nil, km, keycloak.DefaultInvokerRealm, clientMock, websocketnotifier.NewWebSocketNotifier(), make(chan eventsapi.EventNotification), storagetest.NewStore())
+
+	e := echo.New()
+	e.POST("/introspect", s.PostIntrospect)
+	e.GET("/.well-known/jwks.json", s.GetJwks)
+	return e, s
+}
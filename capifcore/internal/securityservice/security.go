@@ -22,42 +22,110 @@
 package security
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
 	"path"
-	"strings"
 	"sync"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	copystructure "github.com/mitchellh/copystructure"
+	log "github.com/sirupsen/logrus"
 	"k8s.io/utils/strings/slices"
 	"oransc.org/nonrtric/capifcore/internal/common29122"
 	securityapi "oransc.org/nonrtric/capifcore/internal/securityapi"
 
+	"oransc.org/nonrtric/capifcore/internal/accesscontrolpolicyservice"
+	"oransc.org/nonrtric/capifcore/internal/eventsapi"
 	"oransc.org/nonrtric/capifcore/internal/invokermanagement"
+	"oransc.org/nonrtric/capifcore/internal/invokermanagementapi"
 	"oransc.org/nonrtric/capifcore/internal/keycloak"
 	"oransc.org/nonrtric/capifcore/internal/providermanagement"
 	"oransc.org/nonrtric/capifcore/internal/publishservice"
+	publishapi "oransc.org/nonrtric/capifcore/internal/publishserviceapi"
+	"oransc.org/nonrtric/capifcore/internal/restclient"
+	"oransc.org/nonrtric/capifcore/internal/storage"
+	"oransc.org/nonrtric/capifcore/internal/websocketnotifier"
+	"oransc.org/nonrtric/capifcore/problemdetails"
 )
 
+const (
+	trustedInvokersBucket = "trustedInvokers"
+	tokenGrantsBucket     = "tokenGrants"
+)
+
+// The scope granted to an issued access token. The grants are kept by CAPIF core, as the tokens issued by Keycloak do
+// not carry the CAPIF scope.
+type tokenGrant struct {
+	Scope     string `json:"scope"`
+	ExpiresAt int64  `json:"expiresAt"`
+}
+
+//go:generate mockery --name SecurityRegister
+type SecurityRegister interface {
+	// Checks if the security context of the provided invoker has security information for the provided AEF.
+	// Returns true if the invoker has a security context with security information for the AEF, false otherwise.
+	HasSecurityInfoForAef(invokerId, aefId string) bool
+}
+
 type Security struct {
-	serviceRegister providermanagement.ServiceRegister
-	publishRegister publishservice.PublishRegister
-	invokerRegister invokermanagement.InvokerRegister
-	keycloak        keycloak.AccessManagement
-	trustedInvokers map[string]securityapi.ServiceSecurity
-	lock            sync.Mutex
+	serviceRegister             providermanagement.ServiceRegister
+	publishRegister             publishservice.PublishRegister
+	invokerRegister             invokermanagement.InvokerRegister
+	accessControlPolicyRegister accesscontrolpolicyservice.AccessControlPolicyRegister
+	keycloak                    keycloak.AccessManagement
+	invokerRealm                string
+	client                      restclient.HTTPClient
+	notifier                    *websocketnotifier.WebSocketNotifier
+	eventChannel                chan<- eventsapi.EventNotification
+	trustedInvokers             map[string]securityapi.ServiceSecurity
+	tokenGrants                 map[string]tokenGrant
+	store                       storage.Store
+	lock                        sync.Mutex
 }
 
-func NewSecurity(serviceRegister providermanagement.ServiceRegister, publishRegister publishservice.PublishRegister, invokerRegister invokermanagement.InvokerRegister, km keycloak.AccessManagement) *Security {
-	return &Security{
-		serviceRegister: serviceRegister,
-		publishRegister: publishRegister,
-		invokerRegister: invokerRegister,
-		keycloak:        km,
-		trustedInvokers: make(map[string]securityapi.ServiceSecurity),
+// Creates a service that implements both the securityapi.ServerInterface and the aefsecurityapi.ServerInterface
+// interfaces.
+// Security contexts and the scopes granted to access tokens kept in the provided store are loaded at creation.
+// The access control policy lists of the APIs are kept in sync with the security contexts of the invokers.
+// Invokers are notified through the provided client when their authorization is revoked, or over a websocket kept by
+// the provided notifier if the security context requests one.
+// The access tokens are issued and verified in the provided realm of the authorization server.
+func NewSecurity(serviceRegister providermanagement.ServiceRegister, publishRegister publishservice.PublishRegister, invokerRegister invokermanagement.InvokerRegister, accessControlPolicyRegister accesscontrolpolicyservice.AccessControlPolicyRegister, km keycloak.AccessManagement, invokerRealm string, client restclient.HTTPClient, notifier *websocketnotifier.WebSocketNotifier, eventChannel chan<- eventsapi.EventNotification, store storage.Store) *Security {
+	s := &Security{
+		serviceRegister:             serviceRegister,
+		publishRegister:             publishRegister,
+		invokerRegister:             invokerRegister,
+		accessControlPolicyRegister: accessControlPolicyRegister,
+		keycloak:                    km,
+		invokerRealm:                invokerRealm,
+		client:                      client,
+		notifier:                    notifier,
+		eventChannel:                eventChannel,
+		trustedInvokers:             make(map[string]securityapi.ServiceSecurity),
+		tokenGrants:                 make(map[string]tokenGrant),
+		store:                       store,
+	}
+	if err := storage.Load(store, trustedInvokersBucket, s.trustedInvokers); err != nil {
+		log.Errorf("Unable to load trusted invokers due to %s", err)
+	}
+	if err := storage.Load(store, tokenGrantsBucket, s.tokenGrants); err != nil {
+		log.Errorf("Unable to load token grants due to %s", err)
 	}
+	for _, serviceSecurity := range s.trustedInvokers {
+		notifier.RestoreSocket(serviceSecurity.WebsockNotifConfig)
+	}
+	return s
+}
+
+func (s *Security) HasSecurityInfoForAef(invokerId, aefId string) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	serviceSecurity, ok := s.trustedInvokers[invokerId]
+	return ok && hasSecurityInfo(serviceSecurity.SecurityInfo, aefId, "")
 }
 
 func (s *Security) PostSecuritiesSecurityIdToken(ctx echo.Context, securityId string) error {
@@ -65,7 +133,7 @@ func (s *Security) PostSecuritiesSecurityIdToken(ctx echo.Context, securityId st
 	accessTokenReq.GetAccessTokenReq(ctx)
 
 	if valid, err := accessTokenReq.Validate(); !valid {
-		return ctx.JSON(http.StatusBadRequest, err)
+		return sendAccessTokenError(ctx, http.StatusBadRequest, err.Error, *err.ErrorDescription)
 	}
 
 	if !s.invokerRegister.IsInvokerRegistered(accessTokenReq.ClientId) {
@@ -76,33 +144,40 @@ func (s *Security) PostSecuritiesSecurityIdToken(ctx echo.Context, securityId st
 		return sendAccessTokenError(ctx, http.StatusBadRequest, securityapi.AccessTokenErrErrorUnauthorizedClient, "Invoker secret not valid")
 	}
 
+	data := url.Values{"grant_type": {"client_credentials"}, "client_id": {accessTokenReq.ClientId}, "client_secret": {*accessTokenReq.ClientSecret}}
 	if accessTokenReq.Scope != nil && *accessTokenReq.Scope != "" {
-		scope := strings.Split(*accessTokenReq.Scope, "#")
-		aefList := strings.Split(scope[1], ";")
-		for _, aef := range aefList {
-			apiList := strings.Split(aef, ":")
-			if !s.serviceRegister.IsFunctionRegistered(apiList[0]) {
+		// The scope has already been validated
+		scope, _ := securityapi.ParseScope(*accessTokenReq.Scope)
+		for _, aefScope := range scope {
+			if !s.serviceRegister.IsFunctionRegistered(aefScope.AefId) {
 				return sendAccessTokenError(ctx, http.StatusBadRequest, securityapi.AccessTokenErrErrorInvalidScope, "AEF Function not registered")
 			}
-			for _, api := range strings.Split(apiList[1], ",") {
-				if !s.publishRegister.IsAPIPublished(apiList[0], api) {
+			for _, apiName := range aefScope.ApiNames {
+				if !s.publishRegister.IsAPIPublished(aefScope.AefId, apiName) {
 					return sendAccessTokenError(ctx, http.StatusBadRequest, securityapi.AccessTokenErrErrorInvalidScope, "API not published")
 				}
 			}
 		}
+		grantedScope := s.getAuthorizedScope(accessTokenReq.ClientId, scope)
+		if len(grantedScope) == 0 {
+			return sendAccessTokenError(ctx, http.StatusBadRequest, securityapi.AccessTokenErrErrorInvalidScope, "No API in scope authorized for invoker")
+		}
+		grantedScopeString := grantedScope.String()
+		accessTokenReq.Scope = &grantedScopeString
+		data.Set("scope", grantedScopeString)
 	}
-	data := url.Values{"grant_type": {"client_credentials"}, "client_id": {accessTokenReq.ClientId}, "client_secret": {*accessTokenReq.ClientSecret}}
-
-	var jwtToken keycloak.Jwttoken
-	var err error
 
-	if s.keycloak != nil {
-		jwtToken, err = s.keycloak.GetToken("invokerrealm", data)
-		if err != nil {
-			return sendAccessTokenError(ctx, http.StatusBadRequest, securityapi.AccessTokenErrErrorUnauthorizedClient, err.Error())
+	if s.keycloak == nil {
+		return problemdetails.Send(ctx, http.StatusInternalServerError, problemdetails.CauseSystemFailure, "Unable to issue token due to no authorization server configured")
+	}
+	jwtToken, err := s.keycloak.GetToken(s.invokerRealm, data)
+	if err != nil {
+		return sendAccessTokenError(ctx, http.StatusBadRequest, securityapi.AccessTokenErrErrorUnauthorizedClient, err.Error())
+	}
+	if accessTokenReq.Scope != nil && *accessTokenReq.Scope != "" {
+		if err := s.addTokenGrant(jwtToken.AccessToken, *accessTokenReq.Scope); err != nil {
+			return problemdetails.Send(ctx, http.StatusInternalServerError, problemdetails.CauseSystemFailure, fmt.Sprintf("Unable to issue token due to %s", err))
 		}
-	} else {
-		return sendAccessTokenError(ctx, http.StatusBadRequest, securityapi.AccessTokenErrErrorUnauthorizedClient, "keycloak is nil")
 	}
 
 	accessTokenResp := securityapi.AccessTokenRsp{
@@ -121,6 +196,82 @@ func (s *Security) PostSecuritiesSecurityIdToken(ctx echo.Context, securityId st
 	return nil
 }
 
+// Records the scope granted to the token, which introspection checks the token against. Grants of expired tokens are
+// removed.
+func (s *Security) addTokenGrant(token, scope string) error {
+	claims, err := s.keycloak.VerifyToken(token, s.invokerRealm)
+	if err != nil {
+		return err
+	}
+	if claims.Id == "" {
+		return fmt.Errorf("token has no id")
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	now := time.Now().Unix()
+	for tokenId, grant := range s.tokenGrants {
+		if grant.ExpiresAt < now {
+			delete(s.tokenGrants, tokenId)
+			if err := s.store.Delete(tokenGrantsBucket, tokenId); err != nil {
+				log.Errorf("Unable to remove stored grant of token %s due to %s", tokenId, err)
+			}
+		}
+	}
+	grant := tokenGrant{Scope: scope, ExpiresAt: claims.ExpiresAt}
+	s.tokenGrants[claims.Id] = grant
+	if err := s.store.Put(tokenGrantsBucket, claims.Id, grant); err != nil {
+		log.Errorf("Unable to store grant of token %s due to %s", claims.Id, err)
+	}
+	return nil
+}
+
+// Gets the scope granted to the token with the provided id. Returns false if no scope has been granted to the token.
+func (s *Security) getTokenScope(tokenId string) (string, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	grant, ok := s.tokenGrants[tokenId]
+	return grant.Scope, ok
+}
+
+// Gets the part of the scope that the invoker is authorized to, i.e. the APIs in the invoker's API list that are
+// covered by the invoker's security context.
+func (s *Security) getAuthorizedScope(invokerId string, scope securityapi.Scope) securityapi.Scope {
+	authorizedScope := securityapi.Scope{}
+	apiList := s.invokerRegister.GetInvokerApiList(invokerId)
+	s.lock.Lock()
+	serviceSecurity, ok := s.trustedInvokers[invokerId]
+	s.lock.Unlock()
+	if apiList == nil || !ok {
+		return authorizedScope
+	}
+
+	for _, aefScope := range scope {
+		authorizedAefScope := securityapi.AefScope{AefId: aefScope.AefId}
+		for _, apiName := range aefScope.ApiNames {
+			apiId := getApiId(*apiList, aefScope.AefId, apiName)
+			if apiId != "" && hasSecurityInfo(serviceSecurity.SecurityInfo, aefScope.AefId, apiId) {
+				authorizedAefScope.ApiNames = append(authorizedAefScope.ApiNames, apiName)
+			}
+		}
+		if len(authorizedAefScope.ApiNames) > 0 {
+			authorizedScope = append(authorizedScope, authorizedAefScope)
+		}
+	}
+	return authorizedScope
+}
+
+// Gets the id of the API with the provided name exposed by the provided AEF, or an empty string if the list has no
+// such API.
+func getApiId(apiList invokermanagementapi.APIList, aefId, apiName string) string {
+	for _, description := range apiList {
+		if description.ApiName == apiName && description.ApiId != nil && description.GetAefProfileById(&aefId) != nil {
+			return *description.ApiId
+		}
+	}
+	return ""
+}
+
 func (s *Security) DeleteTrustedInvokersApiInvokerId(ctx echo.Context, apiInvokerId string) error {
 	if _, ok := s.trustedInvokers[apiInvokerId]; ok {
 		s.deleteTrustedInvoker(apiInvokerId)
@@ -132,7 +283,18 @@ func (s *Security) DeleteTrustedInvokersApiInvokerId(ctx echo.Context, apiInvoke
 func (s *Security) deleteTrustedInvoker(apiInvokerId string) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
+	s.notifier.RemoveSocket(s.trustedInvokers[apiInvokerId].WebsockNotifConfig)
+	s.removeTrustedInvoker(apiInvokerId)
+}
+
+// Removes the security context, but leaves its websocket open.
+// Must be called with the lock held.
+func (s *Security) removeTrustedInvoker(apiInvokerId string) {
+	s.updateAccessControlPolicies(apiInvokerId, s.trustedInvokers[apiInvokerId].SecurityInfo, nil)
 	delete(s.trustedInvokers, apiInvokerId)
+	if err := s.store.Delete(trustedInvokersBucket, apiInvokerId); err != nil {
+		log.Errorf("Unable to remove stored security context for %s due to %s", apiInvokerId, err)
+	}
 }
 
 func (s *Security) GetTrustedInvokersApiInvokerId(ctx echo.Context, apiInvokerId string, params securityapi.GetTrustedInvokersApiInvokerIdParams) error {
@@ -146,7 +308,7 @@ func (s *Security) GetTrustedInvokersApiInvokerId(ctx echo.Context, apiInvokerId
 			}
 		}
 	} else {
-		return sendCoreError(ctx, http.StatusNotFound, fmt.Sprintf("invoker %s not registered as trusted invoker", apiInvokerId))
+		return problemdetails.Send(ctx, http.StatusNotFound, problemdetails.CauseContextNotFound, fmt.Sprintf("invoker %s not registered as trusted invoker", apiInvokerId))
 	}
 
 	return nil
@@ -185,20 +347,20 @@ func (s *Security) PutTrustedInvokersApiInvokerId(ctx echo.Context, apiInvokerId
 	errMsg := "Unable to update security context due to %s."
 
 	if !s.invokerRegister.IsInvokerRegistered(apiInvokerId) {
-		return sendCoreError(ctx, http.StatusBadRequest, "Unable to update security context due to Invoker not registered")
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseContextNotFound, "Unable to update security context due to Invoker not registered")
 	}
 	serviceSecurity, err := getServiceSecurityFromRequest(ctx)
 	if err != nil {
-		return sendCoreError(ctx, http.StatusBadRequest, fmt.Sprintf(errMsg, err))
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseInvalidMsgFormat, fmt.Sprintf(errMsg, err))
 	}
 
 	if err := serviceSecurity.Validate(); err != nil {
-		return sendCoreError(ctx, http.StatusBadRequest, fmt.Sprintf(errMsg, err))
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseMandatoryIeIncorrect, fmt.Sprintf(errMsg, err))
 	}
 
-	err = s.prepareNewSecurityContext(&serviceSecurity, apiInvokerId)
+	err = s.prepareNewSecurityContext(ctx, &serviceSecurity, apiInvokerId)
 	if err != nil {
-		return sendCoreError(ctx, http.StatusBadRequest, fmt.Sprintf(errMsg, err))
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseMandatoryIeIncorrect, fmt.Sprintf(errMsg, err))
 	}
 
 	uri := ctx.Request().Host + ctx.Request().URL.String()
@@ -222,7 +384,7 @@ func getServiceSecurityFromRequest(ctx echo.Context) (securityapi.ServiceSecurit
 	return serviceSecurity, nil
 }
 
-func (s *Security) prepareNewSecurityContext(newContext *securityapi.ServiceSecurity, apiInvokerId string) error {
+func (s *Security) prepareNewSecurityContext(ctx echo.Context, newContext *securityapi.ServiceSecurity, apiInvokerId string) error {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
@@ -231,7 +393,11 @@ func (s *Security) prepareNewSecurityContext(newContext *securityapi.ServiceSecu
 		return err
 	}
 
+	s.notifier.UpdateSocket(ctx, s.trustedInvokers[apiInvokerId].WebsockNotifConfig, newContext.WebsockNotifConfig)
+
+	s.updateAccessControlPolicies(apiInvokerId, s.trustedInvokers[apiInvokerId].SecurityInfo, newContext.SecurityInfo)
 	s.trustedInvokers[apiInvokerId] = *newContext
+	s.storeTrustedInvoker(*newContext, apiInvokerId)
 	return nil
 }
 
@@ -241,29 +407,78 @@ func (s *Security) PostTrustedInvokersApiInvokerIdDelete(ctx echo.Context, apiIn
 	errMsg := "Unable to revoke invoker due to %s"
 
 	if err := ctx.Bind(&notification); err != nil {
-		return sendCoreError(ctx, http.StatusBadRequest, fmt.Sprintf(errMsg, "invalid format for security notification"))
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseInvalidMsgFormat, fmt.Sprintf(errMsg, "invalid format for security notification"))
 	}
 
 	if err := notification.Validate(); err != nil {
-		return sendCoreError(ctx, http.StatusBadRequest, fmt.Sprintf(errMsg, err))
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseMandatoryIeIncorrect, fmt.Sprintf(errMsg, err))
 	}
 
-	if ss, ok := s.trustedInvokers[apiInvokerId]; ok {
-		securityInfoCopy := s.revokeTrustedInvoker(&ss, notification)
+	if !s.revokeAuthorization(apiInvokerId, notification) {
+		return problemdetails.Send(ctx, http.StatusNotFound, problemdetails.CauseContextNotFound, "the invoker is not register as a trusted invoker")
+	}
 
-		if len(securityInfoCopy) == 0 {
-			s.deleteTrustedInvoker(apiInvokerId)
-		} else {
-			ss.SecurityInfo = securityInfoCopy
-			s.updateTrustedInvoker(ss, apiInvokerId)
-		}
+	return ctx.NoContent(http.StatusNoContent)
 
+}
+
+// Revokes the invoker's authorization to the APIs given in the notification, and notifies the invoker about it.
+// Returns false if the invoker has no security context.
+func (s *Security) revokeAuthorization(apiInvokerId string, notification securityapi.SecurityNotification) bool {
+	s.lock.Lock()
+	ss, ok := s.trustedInvokers[apiInvokerId]
+	if !ok {
+		s.lock.Unlock()
+		return false
+	}
+
+	remainingSecurityInfo := s.revokeTrustedInvoker(&ss, notification)
+	if len(remainingSecurityInfo) == 0 {
+		s.removeTrustedInvoker(apiInvokerId)
 	} else {
-		return sendCoreError(ctx, http.StatusNotFound, "the invoker is not register as a trusted invoker")
+		updatedSecurity := ss
+		updatedSecurity.SecurityInfo = remainingSecurityInfo
+		s.setTrustedInvoker(updatedSecurity, apiInvokerId)
 	}
+	s.lock.Unlock()
 
-	return ctx.NoContent(http.StatusNoContent)
+	// The websocket of a removed security context is only closed once the invoker has been notified.
+	s.sendSecurityNotification(ss, notification)
+	if len(remainingSecurityInfo) == 0 {
+		s.notifier.RemoveSocket(ss.WebsockNotifConfig)
+	}
+
+	go s.sendRevokedEvent(apiInvokerId, notification)
+	return true
+}
+
+// Revokes the authorization of an offboarded invoker to all its APIs, removing its security context.
+func (s *Security) RemoveInvoker(apiInvokerId string) {
+	s.lock.Lock()
+	ss, ok := s.trustedInvokers[apiInvokerId]
+	if !ok {
+		s.lock.Unlock()
+		return
+	}
+	s.removeTrustedInvoker(apiInvokerId)
+	s.lock.Unlock()
+
+	apiIds := []string{}
+	for _, securityInfo := range ss.SecurityInfo {
+		if securityInfo.ApiId != nil && !slices.Contains(apiIds, *securityInfo.ApiId) {
+			apiIds = append(apiIds, *securityInfo.ApiId)
+		}
+	}
+	notification := securityapi.SecurityNotification{
+		ApiInvokerId: apiInvokerId,
+		ApiIds:       apiIds,
+		Cause:        securityapi.CauseUNEXPECTEDREASON,
+	}
 
+	// The websocket of the removed security context is only closed once the invoker has been notified.
+	s.sendSecurityNotification(ss, notification)
+	s.notifier.RemoveSocket(ss.WebsockNotifConfig)
+	go s.sendRevokedEvent(apiInvokerId, notification)
 }
 
 func (s *Security) revokeTrustedInvoker(ss *securityapi.ServiceSecurity, notification securityapi.SecurityNotification) []securityapi.SecurityInformation {
@@ -271,13 +486,18 @@ func (s *Security) revokeTrustedInvoker(ss *securityapi.ServiceSecurity, notific
 	data, _ := copystructure.Copy(ss.SecurityInfo)
 	securityInfoCopy, _ := data.([]securityapi.SecurityInformation)
 
-	for i, context := range ss.SecurityInfo {
-		if notification.AefId == context.AefId || slices.Contains(notification.ApiIds, *context.ApiId) {
-			securityInfoCopy = append(securityInfoCopy[:i], securityInfoCopy[i+1:]...)
+	// The listed APIs are revoked, limited to the given AEF if there is one. The access control policy lists follow the
+	// security info that remains, so only the entries matching both the APIs and the AEF may be removed.
+	remainingSecurityInfo := []securityapi.SecurityInformation{}
+	for _, context := range securityInfoCopy {
+		matchesAef := notification.AefId == nil || (context.AefId != nil && *notification.AefId == *context.AefId)
+		matchesApi := context.ApiId != nil && slices.Contains(notification.ApiIds, *context.ApiId)
+		if !matchesAef || !matchesApi {
+			remainingSecurityInfo = append(remainingSecurityInfo, context)
 		}
 	}
 
-	return securityInfoCopy
+	return remainingSecurityInfo
 
 }
 
@@ -287,17 +507,18 @@ func (s *Security) PostTrustedInvokersApiInvokerIdUpdate(ctx echo.Context, apiIn
 	errMsg := "Unable to update service security context due to %s"
 
 	if err := ctx.Bind(&serviceSecurity); err != nil {
-		return sendCoreError(ctx, http.StatusBadRequest, fmt.Sprintf(errMsg, "invalid format for service security context"))
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseInvalidMsgFormat, fmt.Sprintf(errMsg, "invalid format for service security context"))
 	}
 
 	if err := serviceSecurity.Validate(); err != nil {
-		return sendCoreError(ctx, http.StatusBadRequest, fmt.Sprintf(errMsg, err))
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseMandatoryIeIncorrect, fmt.Sprintf(errMsg, err))
 	}
 
-	if _, ok := s.trustedInvokers[apiInvokerId]; ok {
+	if trustedInvoker, ok := s.trustedInvokers[apiInvokerId]; ok {
+		s.notifier.UpdateSocket(ctx, trustedInvoker.WebsockNotifConfig, serviceSecurity.WebsockNotifConfig)
 		s.updateTrustedInvoker(serviceSecurity, apiInvokerId)
 	} else {
-		return sendCoreError(ctx, http.StatusNotFound, "the invoker is not register as a trusted invoker")
+		return problemdetails.Send(ctx, http.StatusNotFound, problemdetails.CauseContextNotFound, "the invoker is not register as a trusted invoker")
 	}
 
 	uri := ctx.Request().Host + ctx.Request().URL.String()
@@ -315,7 +536,118 @@ func (s *Security) PostTrustedInvokersApiInvokerIdUpdate(ctx echo.Context, apiIn
 func (s *Security) updateTrustedInvoker(serviceSecurity securityapi.ServiceSecurity, invokerId string) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
+	s.setTrustedInvoker(serviceSecurity, invokerId)
+}
+
+// Must be called with the lock held.
+func (s *Security) setTrustedInvoker(serviceSecurity securityapi.ServiceSecurity, invokerId string) {
+	s.updateAccessControlPolicies(invokerId, s.trustedInvokers[invokerId].SecurityInfo, serviceSecurity.SecurityInfo)
+	s.trustedInvokers[invokerId] = serviceSecurity
+	s.storeTrustedInvoker(serviceSecurity, invokerId)
+}
+
+// Adds security information for the API to the security context of the invoker, if the invoker has one. Each exposing
+// function of the API gets the first of its security methods selected, functions without security methods are left out.
+func (s *Security) GrantApiAccess(invokerId string, api publishapi.ServiceAPIDescription) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	serviceSecurity, ok := s.trustedInvokers[invokerId]
+	if !ok || api.ApiId == nil || api.AefProfiles == nil {
+		return
+	}
+	securityInfo := append([]securityapi.SecurityInformation{}, serviceSecurity.SecurityInfo...)
+	for _, profile := range *api.AefProfiles {
+		if profile.SecurityMethods == nil || len(*profile.SecurityMethods) == 0 || hasSecurityInfo(securityInfo, profile.AefId, *api.ApiId) {
+			continue
+		}
+		aefId := profile.AefId
+		apiId := *api.ApiId
+		securityMethods := *profile.SecurityMethods
+		securityInfo = append(securityInfo, securityapi.SecurityInformation{
+			AefId:               &aefId,
+			ApiId:               &apiId,
+			PrefSecurityMethods: securityMethods,
+			SelSecurityMethod:   &securityMethods[0],
+		})
+	}
+	if len(securityInfo) == len(serviceSecurity.SecurityInfo) {
+		return
+	}
+
+	s.updateAccessControlPolicies(invokerId, serviceSecurity.SecurityInfo, securityInfo)
+	serviceSecurity.SecurityInfo = securityInfo
 	s.trustedInvokers[invokerId] = serviceSecurity
+	s.storeTrustedInvoker(serviceSecurity, invokerId)
+}
+
+func (s *Security) storeTrustedInvoker(serviceSecurity securityapi.ServiceSecurity, invokerId string) {
+	if err := s.store.Put(trustedInvokersBucket, invokerId, serviceSecurity); err != nil {
+		log.Errorf("Unable to store security context for %s due to %s", invokerId, err)
+	}
+}
+
+// Adds the invoker to the access control policy lists of the APIs it has been granted access to, and removes it from
+// the lists of the APIs it no longer has access to.
+func (s *Security) updateAccessControlPolicies(invokerId string, oldSecurityInfo, newSecurityInfo []securityapi.SecurityInformation) {
+	if s.accessControlPolicyRegister == nil {
+		return
+	}
+	oldApis := getApisPerAef(oldSecurityInfo)
+	newApis := getApisPerAef(newSecurityInfo)
+	for key, api := range oldApis {
+		if _, ok := newApis[key]; !ok {
+			s.accessControlPolicyRegister.RemoveInvokerPolicy(*api.ApiId, *api.AefId, invokerId)
+		}
+	}
+	for key, api := range newApis {
+		if _, ok := oldApis[key]; !ok {
+			s.accessControlPolicyRegister.AddInvokerPolicy(*api.ApiId, *api.AefId, invokerId)
+		}
+	}
+}
+
+func getApisPerAef(securityInfo []securityapi.SecurityInformation) map[string]securityapi.SecurityInformation {
+	apis := make(map[string]securityapi.SecurityInformation)
+	for _, info := range securityInfo {
+		if info.ApiId != nil && info.AefId != nil {
+			apis[*info.ApiId+"/"+*info.AefId] = info
+		}
+	}
+	return apis
+}
+
+func (s *Security) sendRevokedEvent(apiInvokerId string, notification securityapi.SecurityNotification) {
+	invokerIds := []string{apiInvokerId}
+	apiIds := notification.ApiIds
+	event := eventsapi.EventNotification{
+		EventDetail: &eventsapi.CAPIFEventDetail{
+			ApiIds:        &apiIds,
+			ApiInvokerIds: &invokerIds,
+		},
+		Events: eventsapi.CAPIFEventAPIINVOKERAUTHORIZATIONREVOKED,
+	}
+	s.eventChannel <- event
+}
+
+// Sends the notification over the websocket of the security context if it has one. Otherwise the notification is posted
+// to the notification destination of the security context in the background.
+func (s *Security) sendSecurityNotification(serviceSecurity securityapi.ServiceSecurity, notification securityapi.SecurityNotification) {
+	if socketId, ok := websocketnotifier.GetSocketId(serviceSecurity.WebsockNotifConfig); ok {
+		if err := s.notifier.Send(socketId, notification); err != nil {
+			log.Errorf("Unable to send security notification over websocket due to %s", err)
+		}
+		return
+	}
+	go s.postSecurityNotification(string(serviceSecurity.NotificationDestination), notification)
+}
+
+func (s *Security) postSecurityNotification(notificationDestination string, notification securityapi.SecurityNotification) {
+	body, _ := json.Marshal(notification)
+	header := map[string]string{"Content-Type": restclient.ContentTypeJSON}
+	if err := restclient.Post(notificationDestination, body, header, s.client); err != nil {
+		log.Errorf("Unable to send security notification to %s due to %s", notificationDestination, err)
+	}
 }
 
 func sendAccessTokenError(ctx echo.Context, code int, err securityapi.AccessTokenErrError, message string) error {
@@ -325,14 +657,3 @@ func sendAccessTokenError(ctx echo.Context, code int, err securityapi.AccessToke
 	}
 	return ctx.JSON(code, accessTokenErr)
 }
-
-// This function wraps sending of an error in the Error format, and
-// handling the failure to marshal that.
-func sendCoreError(ctx echo.Context, code int, message string) error {
-	pd := common29122.ProblemDetails{
-		Cause:  &message,
-		Status: &code,
-	}
-	err := ctx.JSON(code, pd)
-	return err
-}
@@ -21,28 +21,39 @@
 package security
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"os"
+	"sync"
 	"testing"
+	"time"
 
 	"oransc.org/nonrtric/capifcore/internal/common29122"
+	"oransc.org/nonrtric/capifcore/internal/eventsapi"
 	"oransc.org/nonrtric/capifcore/internal/keycloak"
 	"oransc.org/nonrtric/capifcore/internal/publishserviceapi"
 	"oransc.org/nonrtric/capifcore/internal/securityapi"
 
+	"oransc.org/nonrtric/capifcore/internal/accesscontrolpolicyservice"
 	"oransc.org/nonrtric/capifcore/internal/invokermanagement"
+	"oransc.org/nonrtric/capifcore/internal/invokermanagementapi"
 	"oransc.org/nonrtric/capifcore/internal/providermanagement"
 	"oransc.org/nonrtric/capifcore/internal/publishservice"
 
 	"github.com/labstack/echo/v4"
 
+	accesscontrolmocks "oransc.org/nonrtric/capifcore/internal/accesscontrolpolicyservice/mocks"
 	invokermocks "oransc.org/nonrtric/capifcore/internal/invokermanagement/mocks"
 	keycloackmocks "oransc.org/nonrtric/capifcore/internal/keycloak/mocks"
 	servicemocks "oransc.org/nonrtric/capifcore/internal/providermanagement/mocks"
 	publishmocks "oransc.org/nonrtric/capifcore/internal/publishservice/mocks"
+	"oransc.org/nonrtric/capifcore/internal/storage/storagetest"
+	"oransc.org/nonrtric/capifcore/internal/websocketnotifier"
+	_ "oransc.org/nonrtric/capifcore/problemdetails/problemdetailstest"
 
 	"github.com/deepmap/oapi-codegen/pkg/middleware"
 	"github.com/deepmap/oapi-codegen/pkg/testutil"
@@ -67,14 +78,18 @@ func TestPostSecurityIdTokenInvokerRegistered(t *testing.T) {
 	}
 	accessMgmMock := keycloackmocks.AccessManagement{}
 	accessMgmMock.On("GetToken", mock.AnythingOfType("string"), mock.AnythingOfType("map[string][]string")).Return(jwt, nil)
+	accessMgmMock.On("VerifyToken", jwt.AccessToken, keycloak.DefaultInvokerRealm).Return(&keycloak.TokenClaims{Id: "tokenId", ExpiresAt: time.Now().Add(time.Minute).Unix()}, nil)
 
-	requestHandler, _ := getEcho(&serviceRegisterMock, &publishRegisterMock, &invokerRegisterMock, &accessMgmMock)
+	requestHandler, securityUnderTest := getEcho(&serviceRegisterMock, &publishRegisterMock, &invokerRegisterMock, nil, &accessMgmMock)
 
 	data := url.Values{}
 	clientId := "id"
 	clientSecret := "secret"
 	aefId := "aefId"
 	path := "path"
+	apiList := getApiList(aefId, "apiId", path)
+	invokerRegisterMock.On("GetInvokerApiList", clientId).Return(&apiList)
+	securityUnderTest.trustedInvokers[clientId] = getServiceSecurity(aefId, "apiId")
 	data.Set("client_id", clientId)
 	data.Set("client_secret", clientSecret)
 	data.Set("grant_type", "client_credentials")
@@ -95,13 +110,17 @@ func TestPostSecurityIdTokenInvokerRegistered(t *testing.T) {
 	serviceRegisterMock.AssertCalled(t, "IsFunctionRegistered", aefId)
 	publishRegisterMock.AssertCalled(t, "IsAPIPublished", aefId, path)
 	accessMgmMock.AssertNumberOfCalls(t, "GetToken", 1)
+	accessMgmMock.AssertCalled(t, "GetToken", keycloak.DefaultInvokerRealm, mock.MatchedBy(func(data map[string][]string) bool {
+		return data["client_id"][0] == clientId && data["scope"][0] == "3gpp#"+aefId+":"+path
+	}))
+	assert.Equal(t, "3gpp#"+aefId+":"+path, securityUnderTest.tokenGrants["tokenId"].Scope)
 }
 
 func TestPostSecurityIdTokenInvokerNotRegistered(t *testing.T) {
 	invokerRegisterMock := invokermocks.InvokerRegister{}
 	invokerRegisterMock.On("IsInvokerRegistered", mock.AnythingOfType("string")).Return(false)
 
-	requestHandler, _ := getEcho(nil, nil, &invokerRegisterMock, nil)
+	requestHandler, _ := getEcho(nil, nil, &invokerRegisterMock, nil, nil)
 
 	data := url.Values{}
 	data.Set("client_id", "id")
@@ -126,7 +145,7 @@ func TestPostSecurityIdTokenInvokerSecretNotValid(t *testing.T) {
 	invokerRegisterMock.On("IsInvokerRegistered", mock.AnythingOfType("string")).Return(true)
 	invokerRegisterMock.On("VerifyInvokerSecret", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(false)
 
-	requestHandler, _ := getEcho(nil, nil, &invokerRegisterMock, nil)
+	requestHandler, _ := getEcho(nil, nil, &invokerRegisterMock, nil, nil)
 
 	data := url.Values{}
 	data.Set("client_id", "id")
@@ -153,7 +172,7 @@ func TestPostSecurityIdTokenFunctionNotRegistered(t *testing.T) {
 	serviceRegisterMock := servicemocks.ServiceRegister{}
 	serviceRegisterMock.On("IsFunctionRegistered", mock.AnythingOfType("string")).Return(false)
 
-	requestHandler, _ := getEcho(&serviceRegisterMock, nil, &invokerRegisterMock, nil)
+	requestHandler, _ := getEcho(&serviceRegisterMock, nil, &invokerRegisterMock, nil, nil)
 
 	data := url.Values{}
 	data.Set("client_id", "id")
@@ -182,7 +201,7 @@ func TestPostSecurityIdTokenAPINotPublished(t *testing.T) {
 	publishRegisterMock := publishmocks.PublishRegister{}
 	publishRegisterMock.On("IsAPIPublished", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(false)
 
-	requestHandler, _ := getEcho(&serviceRegisterMock, &publishRegisterMock, &invokerRegisterMock, nil)
+	requestHandler, _ := getEcho(&serviceRegisterMock, &publishRegisterMock, &invokerRegisterMock, nil, nil)
 
 	data := url.Values{}
 	data.Set("client_id", "id")
@@ -215,13 +234,16 @@ func TestPostSecurityIdTokenInvokerInvalidCredentials(t *testing.T) {
 	accessMgmMock := keycloackmocks.AccessManagement{}
 	accessMgmMock.On("GetToken", mock.AnythingOfType("string"), mock.AnythingOfType("map[string][]string")).Return(jwt, errors.New("invalid_credentials"))
 
-	requestHandler, _ := getEcho(&serviceRegisterMock, &publishRegisterMock, &invokerRegisterMock, &accessMgmMock)
+	requestHandler, securityUnderTest := getEcho(&serviceRegisterMock, &publishRegisterMock, &invokerRegisterMock, nil, &accessMgmMock)
 
 	data := url.Values{}
 	clientId := "id"
 	clientSecret := "secret"
 	aefId := "aefId"
 	path := "path"
+	apiList := getApiList(aefId, "apiId", path)
+	invokerRegisterMock.On("GetInvokerApiList", clientId).Return(&apiList)
+	securityUnderTest.trustedInvokers[clientId] = getServiceSecurity(aefId, "apiId")
 	data.Set("client_id", clientId)
 	data.Set("client_secret", clientSecret)
 	data.Set("grant_type", "client_credentials")
@@ -242,6 +264,111 @@ func TestPostSecurityIdTokenInvokerInvalidCredentials(t *testing.T) {
 	accessMgmMock.AssertNumberOfCalls(t, "GetToken", 1)
 }
 
+func TestPostSecurityIdTokenNoAuthorizationServer(t *testing.T) {
+	invokerRegisterMock := invokermocks.InvokerRegister{}
+	invokerRegisterMock.On("IsInvokerRegistered", mock.AnythingOfType("string")).Return(true)
+	invokerRegisterMock.On("VerifyInvokerSecret", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(true)
+	apiList := getApiList("aefId", "apiId", "path")
+	invokerRegisterMock.On("GetInvokerApiList", "id").Return(&apiList)
+	serviceRegisterMock := servicemocks.ServiceRegister{}
+	serviceRegisterMock.On("IsFunctionRegistered", mock.AnythingOfType("string")).Return(true)
+	publishRegisterMock := publishmocks.PublishRegister{}
+	publishRegisterMock.On("IsAPIPublished", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(true)
+
+	requestHandler, securityUnderTest := getEcho(&serviceRegisterMock, &publishRegisterMock, &invokerRegisterMock, nil, nil)
+	securityUnderTest.trustedInvokers["id"] = getServiceSecurity("aefId", "apiId")
+
+	data := url.Values{}
+	data.Set("client_id", "id")
+	data.Set("client_secret", "secret")
+	data.Set("grant_type", "client_credentials")
+	data.Set("scope", "3gpp#aefId:path")
+
+	result := testutil.NewRequest().Post("/securities/invokerId/token").WithContentType("application/x-www-form-urlencoded").WithBody([]byte(data.Encode())).Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusInternalServerError, result.Code())
+	var problemDetails common29122.ProblemDetails
+	err := result.UnmarshalBodyToObject(&problemDetails)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Contains(t, *problemDetails.Detail, "no authorization server configured")
+}
+
+func TestPostSecurityIdTokenMalformedScope(t *testing.T) {
+	requestHandler, _ := getEcho(nil, nil, nil, nil, nil)
+
+	for _, scope := range []string{"3gpp#", "3gpp#aefId:", "3gpp#:apiName", "3gpp#aefId:apiName;", "3gpp#aefId:apiName,", "3gpp#aefId:apiName:apiName", "3gpp#aefId:apiName#"} {
+		data := url.Values{}
+		data.Set("client_id", "id")
+		data.Set("client_secret", "secret")
+		data.Set("grant_type", "client_credentials")
+		data.Set("scope", scope)
+
+		result := testutil.NewRequest().Post("/securities/invokerId/token").WithContentType("application/x-www-form-urlencoded").WithBody([]byte(data.Encode())).Go(t, requestHandler)
+
+		assert.Equal(t, http.StatusBadRequest, result.Code(), scope)
+		var errDetails securityapi.AccessTokenErr
+		err := result.UnmarshalBodyToObject(&errDetails)
+		assert.NoError(t, err, "error unmarshaling response")
+		assert.Equal(t, securityapi.AccessTokenErrErrorInvalidScope, errDetails.Error, scope)
+	}
+}
+
+func TestPostSecurityIdTokenGrantsAuthorizedApis(t *testing.T) {
+	clientId := "id"
+	invokerRegisterMock := invokermocks.InvokerRegister{}
+	invokerRegisterMock.On("IsInvokerRegistered", clientId).Return(true)
+	invokerRegisterMock.On("VerifyInvokerSecret", clientId, "secret").Return(true)
+	apiList := append(getApiList("aefId", "apiId", "apiName"), getApiList("aefId", "otherApiId", "otherApiName")...)
+	apiList = append(apiList, getApiList("otherAefId", "apiIdOfOtherAef", "apiName")...)
+	invokerRegisterMock.On("GetInvokerApiList", clientId).Return(&apiList)
+	serviceRegisterMock := servicemocks.ServiceRegister{}
+	serviceRegisterMock.On("IsFunctionRegistered", mock.AnythingOfType("string")).Return(true)
+	publishRegisterMock := publishmocks.PublishRegister{}
+	publishRegisterMock.On("IsAPIPublished", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(true)
+	accessMgmMock := keycloackmocks.AccessManagement{}
+	accessMgmMock.On("GetToken", keycloak.DefaultInvokerRealm, mock.Anything).Return(keycloak.Jwttoken{AccessToken: "token", ExpiresIn: 300}, nil)
+	accessMgmMock.On("VerifyToken", "token", keycloak.DefaultInvokerRealm).Return(&keycloak.TokenClaims{Id: "tokenId", ExpiresAt: time.Now().Add(time.Minute).Unix()}, nil)
+
+	requestHandler, securityUnderTest := getEcho(&serviceRegisterMock, &publishRegisterMock, &invokerRegisterMock, nil, &accessMgmMock)
+	// The security context covers all APIs in the API list but the other API
+	serviceSecurity := getServiceSecurity("aefId", "apiId")
+	serviceSecurity.SecurityInfo = append(serviceSecurity.SecurityInfo, getServiceSecurity("otherAefId", "apiIdOfOtherAef").SecurityInfo...)
+	securityUnderTest.trustedInvokers[clientId] = serviceSecurity
+
+	data := url.Values{}
+	data.Set("client_id", clientId)
+	data.Set("client_secret", "secret")
+	data.Set("grant_type", "client_credentials")
+	// The API not in the API list of the invoker is not granted either
+	data.Set("scope", "3gpp#aefId:apiName,otherApiName,notListedApiName;otherAefId:apiName")
+
+	result := testutil.NewRequest().Post("/securities/invokerId/token").WithContentType("application/x-www-form-urlencoded").WithBody([]byte(data.Encode())).Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusCreated, result.Code())
+	var resultResponse securityapi.AccessTokenRsp
+	err := result.UnmarshalBodyToObject(&resultResponse)
+	assert.NoError(t, err, "error unmarshaling response")
+	grantedScope := "3gpp#aefId:apiName;otherAefId:apiName"
+	assert.Equal(t, grantedScope, *resultResponse.Scope)
+	accessMgmMock.AssertCalled(t, "GetToken", keycloak.DefaultInvokerRealm, mock.MatchedBy(func(data map[string][]string) bool {
+		return data["scope"][0] == grantedScope
+	}))
+	assert.Equal(t, grantedScope, securityUnderTest.tokenGrants["tokenId"].Scope)
+	publishRegisterMock.AssertCalled(t, "IsAPIPublished", "aefId", "notListedApiName")
+
+	// No API in the scope is authorized
+	delete(securityUnderTest.trustedInvokers, clientId)
+	result = testutil.NewRequest().Post("/securities/invokerId/token").WithContentType("application/x-www-form-urlencoded").WithBody([]byte(data.Encode())).Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusBadRequest, result.Code())
+	var errDetails securityapi.AccessTokenErr
+	err = result.UnmarshalBodyToObject(&errDetails)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, securityapi.AccessTokenErrErrorInvalidScope, errDetails.Error)
+	assert.Equal(t, "No API in scope authorized for invoker", *errDetails.ErrorDescription)
+	accessMgmMock.AssertNumberOfCalls(t, "GetToken", 1)
+}
+
 func TestPutTrustedInvokerSuccessfully(t *testing.T) {
 	invokerRegisterMock := invokermocks.InvokerRegister{}
 	invokerRegisterMock.On("IsInvokerRegistered", mock.AnythingOfType("string")).Return(true)
@@ -262,10 +389,12 @@ func TestPutTrustedInvokerSuccessfully(t *testing.T) {
 	}
 	publishRegisterMock := publishmocks.PublishRegister{}
 	publishRegisterMock.On("GetAllPublishedServices").Return(publishedServices)
+	invokerId := "invokerId"
+	accessControlPolicyRegisterMock := accesscontrolmocks.AccessControlPolicyRegister{}
+	accessControlPolicyRegisterMock.On("AddInvokerPolicy", apiId, aefId, invokerId).Return()
 
-	requestHandler, _ := getEcho(nil, &publishRegisterMock, &invokerRegisterMock, nil)
+	requestHandler, _ := getEcho(nil, &publishRegisterMock, &invokerRegisterMock, &accessControlPolicyRegisterMock, nil)
 
-	invokerId := "invokerId"
 	serviceSecurityUnderTest := getServiceSecurity(aefId, apiId)
 	serviceSecurityUnderTest.SecurityInfo[0].ApiId = &apiId
 
@@ -282,13 +411,14 @@ func TestPutTrustedInvokerSuccessfully(t *testing.T) {
 		assert.Equal(t, *security.SelSecurityMethod, publishserviceapi.SecurityMethodPKI)
 	}
 	invokerRegisterMock.AssertCalled(t, "IsInvokerRegistered", invokerId)
+	accessControlPolicyRegisterMock.AssertCalled(t, "AddInvokerPolicy", apiId, aefId, invokerId)
 }
 
 func TestPutTrustedInkoverNotRegistered(t *testing.T) {
 	invokerRegisterMock := invokermocks.InvokerRegister{}
 	invokerRegisterMock.On("IsInvokerRegistered", mock.AnythingOfType("string")).Return(false)
 
-	requestHandler, _ := getEcho(nil, nil, &invokerRegisterMock, nil)
+	requestHandler, _ := getEcho(nil, nil, &invokerRegisterMock, nil, nil)
 
 	invokerId := "invokerId"
 	serviceSecurityUnderTest := getServiceSecurity("aefId", "apiId")
@@ -301,7 +431,7 @@ func TestPutTrustedInkoverNotRegistered(t *testing.T) {
 	err := result.UnmarshalBodyToObject(&problemDetails)
 	assert.NoError(t, err, "error unmarshaling response")
 	assert.Equal(t, &badRequest, problemDetails.Status)
-	assert.Contains(t, *problemDetails.Cause, "Invoker not registered")
+	assert.Contains(t, *problemDetails.Detail, "Invoker not registered")
 	invokerRegisterMock.AssertCalled(t, "IsInvokerRegistered", invokerId)
 }
 
@@ -309,7 +439,7 @@ func TestPutTrustedInkoverInvalidInputServiceSecurity(t *testing.T) {
 	invokerRegisterMock := invokermocks.InvokerRegister{}
 	invokerRegisterMock.On("IsInvokerRegistered", mock.AnythingOfType("string")).Return(true)
 
-	requestHandler, _ := getEcho(nil, nil, &invokerRegisterMock, nil)
+	requestHandler, _ := getEcho(nil, nil, &invokerRegisterMock, nil, nil)
 
 	invokerId := "invokerId"
 	notificationUrl := "url"
@@ -324,7 +454,7 @@ func TestPutTrustedInkoverInvalidInputServiceSecurity(t *testing.T) {
 	err := result.UnmarshalBodyToObject(&problemDetails)
 	assert.NoError(t, err, "error unmarshaling response")
 	assert.Equal(t, &badRequest, problemDetails.Status)
-	assert.Contains(t, *problemDetails.Cause, "ServiceSecurity has invalid notificationDestination")
+	assert.Contains(t, *problemDetails.Detail, "ServiceSecurity has invalid notificationDestination")
 	invokerRegisterMock.AssertCalled(t, "IsInvokerRegistered", invokerId)
 }
 
@@ -349,7 +479,7 @@ func TestPutTrustedInvokerInterfaceDetailsNotNil(t *testing.T) {
 	publishRegisterMock := publishmocks.PublishRegister{}
 	publishRegisterMock.On("GetAllPublishedServices").Return(publishedServices)
 
-	requestHandler, _ := getEcho(nil, &publishRegisterMock, &invokerRegisterMock, nil)
+	requestHandler, _ := getEcho(nil, &publishRegisterMock, &invokerRegisterMock, nil, nil)
 
 	invokerId := "invokerId"
 	serviceSecurityUnderTest := getServiceSecurity(aefId, apiId)
@@ -400,7 +530,7 @@ func TestPutTrustedInvokerNotFoundSecurityMethod(t *testing.T) {
 	accessMgmMock := keycloackmocks.AccessManagement{}
 	accessMgmMock.On("AddClient", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(nil)
 
-	requestHandler, _ := getEcho(nil, &publishRegisterMock, &invokerRegisterMock, &accessMgmMock)
+	requestHandler, _ := getEcho(nil, &publishRegisterMock, &invokerRegisterMock, nil, &accessMgmMock)
 
 	invokerId := "invokerId"
 	serviceSecurityUnderTest := getServiceSecurity("aefId", "apiId")
@@ -413,21 +543,23 @@ func TestPutTrustedInvokerNotFoundSecurityMethod(t *testing.T) {
 	err := result.UnmarshalBodyToObject(&problemDetails)
 	assert.NoError(t, err, "error unmarshaling response")
 	assert.Equal(t, &badRequest, problemDetails.Status)
-	assert.Contains(t, *problemDetails.Cause, "not found")
-	assert.Contains(t, *problemDetails.Cause, "security method")
+	assert.Contains(t, *problemDetails.Detail, "not found")
+	assert.Contains(t, *problemDetails.Detail, "security method")
 	invokerRegisterMock.AssertCalled(t, "IsInvokerRegistered", invokerId)
 }
 
 func TestDeleteSecurityContext(t *testing.T) {
-
-	requestHandler, securityUnderTest := getEcho(nil, nil, nil, nil)
-
 	aefId := "aefId"
 	apiId := "apiId"
+	invokerId := "invokerId"
+	accessControlPolicyRegisterMock := accesscontrolmocks.AccessControlPolicyRegister{}
+	accessControlPolicyRegisterMock.On("RemoveInvokerPolicy", apiId, aefId, invokerId).Return()
+
+	requestHandler, securityUnderTest := getEcho(nil, nil, nil, &accessControlPolicyRegisterMock, nil)
+
 	serviceSecurityUnderTest := getServiceSecurity(aefId, apiId)
 	serviceSecurityUnderTest.SecurityInfo[0].ApiId = &apiId
 
-	invokerId := "invokerId"
 	securityUnderTest.trustedInvokers[invokerId] = serviceSecurityUnderTest
 
 	// Delete the security context
@@ -436,11 +568,12 @@ func TestDeleteSecurityContext(t *testing.T) {
 	assert.Equal(t, http.StatusNoContent, result.Code())
 	_, ok := securityUnderTest.trustedInvokers[invokerId]
 	assert.False(t, ok)
+	accessControlPolicyRegisterMock.AssertCalled(t, "RemoveInvokerPolicy", apiId, aefId, invokerId)
 }
 
 func TestGetSecurityContextByInvokerId(t *testing.T) {
 
-	requestHandler, securityUnderTest := getEcho(nil, nil, nil, nil)
+	requestHandler, securityUnderTest := getEcho(nil, nil, nil, nil, nil)
 
 	aefId := "aefId"
 	apiId := "apiId"
@@ -490,8 +623,10 @@ func TestGetSecurityContextByInvokerId(t *testing.T) {
 }
 
 func TestUpdateTrustedInvoker(t *testing.T) {
+	// No access control policies are changed, as the update keeps access to the same APIs
+	accessControlPolicyRegisterMock := accesscontrolmocks.AccessControlPolicyRegister{}
 
-	requestHandler, securityUnderTest := getEcho(nil, nil, nil, nil)
+	requestHandler, securityUnderTest := getEcho(nil, nil, nil, &accessControlPolicyRegisterMock, nil)
 
 	aefId := "aefId"
 	apiId := "apiId"
@@ -531,8 +666,8 @@ func TestUpdateTrustedInvoker(t *testing.T) {
 	err = result.UnmarshalBodyToObject(&problemDetails)
 	assert.NoError(t, err, "error unmarshaling response")
 	assert.Equal(t, http.StatusBadRequest, *problemDetails.Status)
-	assert.Contains(t, *problemDetails.Cause, "missing")
-	assert.Contains(t, *problemDetails.Cause, "notificationDestination")
+	assert.Contains(t, *problemDetails.Detail, "missing")
+	assert.Contains(t, *problemDetails.Detail, "notificationDestination")
 
 	// Update a service security that has not been registered, should get 404 with problem details
 	missingId := "1"
@@ -542,8 +677,8 @@ func TestUpdateTrustedInvoker(t *testing.T) {
 	err = result.UnmarshalBodyToObject(&problemDetails)
 	assert.NoError(t, err, "error unmarshaling response")
 	assert.Equal(t, http.StatusNotFound, *problemDetails.Status)
-	assert.Contains(t, *problemDetails.Cause, "not register")
-	assert.Contains(t, *problemDetails.Cause, "trusted invoker")
+	assert.Contains(t, *problemDetails.Detail, "not register")
+	assert.Contains(t, *problemDetails.Detail, "trusted invoker")
 }
 
 func TestRevokeAuthorizationToInvoker(t *testing.T) {
@@ -557,8 +692,10 @@ func TestRevokeAuthorizationToInvoker(t *testing.T) {
 		ApiIds:       []string{apiId},
 		Cause:        securityapi.CauseUNEXPECTEDREASON,
 	}
+	accessControlPolicyRegisterMock := accesscontrolmocks.AccessControlPolicyRegister{}
+	accessControlPolicyRegisterMock.On("RemoveInvokerPolicy", mock.Anything, aefId, invokerId).Return()
 
-	requestHandler, securityUnderTest := getEcho(nil, nil, nil, nil)
+	requestHandler, securityUnderTest := getEcho(nil, nil, nil, &accessControlPolicyRegisterMock, nil)
 
 	serviceSecurityTest := getServiceSecurity(aefId, apiId)
 	serviceSecurityTest.SecurityInfo[0].ApiId = &apiId
@@ -581,6 +718,9 @@ func TestRevokeAuthorizationToInvoker(t *testing.T) {
 
 	assert.Equal(t, http.StatusNoContent, result.Code())
 	assert.Equal(t, 1, len(securityUnderTest.trustedInvokers[invokerId].SecurityInfo))
+	assert.Equal(t, apiIdTwo, *securityUnderTest.trustedInvokers[invokerId].SecurityInfo[0].ApiId)
+	accessControlPolicyRegisterMock.AssertCalled(t, "RemoveInvokerPolicy", apiId, aefId, invokerId)
+	accessControlPolicyRegisterMock.AssertNotCalled(t, "RemoveInvokerPolicy", apiIdTwo, aefId, invokerId)
 
 	notification.ApiIds = []string{apiIdTwo}
 	// Revoke apiIdTwo
@@ -589,9 +729,111 @@ func TestRevokeAuthorizationToInvoker(t *testing.T) {
 	assert.Equal(t, http.StatusNoContent, result.Code())
 	_, ok := securityUnderTest.trustedInvokers[invokerId]
 	assert.False(t, ok)
+	accessControlPolicyRegisterMock.AssertCalled(t, "RemoveInvokerPolicy", apiIdTwo, aefId, invokerId)
+}
+
+func TestConcurrentRevocationsAreAllApplied(t *testing.T) {
+	aefId := "aefId"
+	invokerId := "invokerId"
+	_, securityUnderTest := getEcho(nil, nil, nil, nil, nil)
+
+	serviceSecurity := getServiceSecurity(aefId, "apiId0")
+	apiIds := []string{"apiId0"}
+	for i := 1; i < 10; i++ {
+		apiId := fmt.Sprintf("apiId%d", i)
+		apiIds = append(apiIds, apiId)
+		serviceSecurity.SecurityInfo = append(serviceSecurity.SecurityInfo, getServiceSecurity(aefId, apiId).SecurityInfo...)
+	}
+	securityUnderTest.trustedInvokers[invokerId] = serviceSecurity
+
+	// Each API is revoked by its own request, none of the revocations may be lost
+	var wg sync.WaitGroup
+	for _, apiId := range apiIds[1:] {
+		wg.Add(1)
+		go func(apiId string) {
+			defer wg.Done()
+			securityUnderTest.revokeAuthorization(invokerId, securityapi.SecurityNotification{
+				ApiInvokerId: invokerId,
+				ApiIds:       []string{apiId},
+				Cause:        securityapi.CauseUNEXPECTEDREASON,
+			})
+		}(apiId)
+	}
+	wg.Wait()
+
+	securityUnderTest.lock.Lock()
+	defer securityUnderTest.lock.Unlock()
+	securityInfo := securityUnderTest.trustedInvokers[invokerId].SecurityInfo
+	if assert.Len(t, securityInfo, 1) {
+		assert.Equal(t, "apiId0", *securityInfo[0].ApiId)
+	}
 }
 
-func getEcho(serviceRegister providermanagement.ServiceRegister, publishRegister publishservice.PublishRegister, invokerRegister invokermanagement.InvokerRegister, keycloakMgm keycloak.AccessManagement) (*echo.Echo, *Security) {
+func TestRemoveInvoker(t *testing.T) {
+	aefId := "aefId"
+	apiId := "apiId"
+	invokerId := "invokerId"
+
+	accessControlPolicyRegisterMock := accesscontrolmocks.AccessControlPolicyRegister{}
+	accessControlPolicyRegisterMock.On("RemoveInvokerPolicy", mock.Anything, aefId, invokerId).Return()
+
+	_, securityUnderTest := getEcho(nil, nil, nil, &accessControlPolicyRegisterMock, nil)
+	securityUnderTest.trustedInvokers[invokerId] = getServiceSecurity(aefId, apiId)
+
+	securityUnderTest.RemoveInvoker(invokerId)
+
+	_, ok := securityUnderTest.trustedInvokers[invokerId]
+	assert.False(t, ok)
+	accessControlPolicyRegisterMock.AssertCalled(t, "RemoveInvokerPolicy", apiId, aefId, invokerId)
+
+	// Removing an invoker without security context is ignored
+	securityUnderTest.RemoveInvoker("otherInvokerId")
+}
+
+func TestGrantApiAccess(t *testing.T) {
+	aefId := "aefId"
+	apiId := "apiId"
+	invokerId := "invokerId"
+	grantedAefId := "grantedAefId"
+	grantedApiId := "grantedApiId"
+	aefProfile := getAefProfile(grantedAefId)
+	aefProfile.SecurityMethods = &[]publishserviceapi.SecurityMethod{
+		publishserviceapi.SecurityMethodPKI,
+	}
+	grantedApi := publishserviceapi.ServiceAPIDescription{
+		ApiId:       &grantedApiId,
+		AefProfiles: &[]publishserviceapi.AefProfile{aefProfile},
+	}
+
+	accessControlPolicyRegisterMock := accesscontrolmocks.AccessControlPolicyRegister{}
+	accessControlPolicyRegisterMock.On("AddInvokerPolicy", grantedApiId, grantedAefId, invokerId).Return()
+	accessControlPolicyRegisterMock.On("RemoveInvokerPolicy", mock.Anything, mock.Anything, mock.Anything).Return()
+
+	_, securityUnderTest := getEcho(nil, nil, nil, &accessControlPolicyRegisterMock, nil)
+	securityUnderTest.trustedInvokers[invokerId] = getServiceSecurity(aefId, apiId)
+
+	securityUnderTest.GrantApiAccess(invokerId, grantedApi)
+
+	securityInfo := securityUnderTest.trustedInvokers[invokerId].SecurityInfo
+	assert.Len(t, securityInfo, 2)
+	assert.Equal(t, apiId, *securityInfo[0].ApiId)
+	assert.Equal(t, grantedApiId, *securityInfo[1].ApiId)
+	assert.Equal(t, grantedAefId, *securityInfo[1].AefId)
+	assert.Equal(t, publishserviceapi.SecurityMethodPKI, *securityInfo[1].SelSecurityMethod)
+	accessControlPolicyRegisterMock.AssertCalled(t, "AddInvokerPolicy", grantedApiId, grantedAefId, invokerId)
+	accessControlPolicyRegisterMock.AssertNotCalled(t, "RemoveInvokerPolicy", mock.Anything, mock.Anything, mock.Anything)
+
+	// Granting access to an API that is already in the security context is ignored
+	securityUnderTest.GrantApiAccess(invokerId, grantedApi)
+	assert.Len(t, securityUnderTest.trustedInvokers[invokerId].SecurityInfo, 2)
+
+	// Granting access to an invoker without security context is ignored
+	securityUnderTest.GrantApiAccess("otherInvokerId", grantedApi)
+	_, ok := securityUnderTest.trustedInvokers["otherInvokerId"]
+	assert.False(t, ok)
+}
+
+func getEcho(serviceRegister providermanagement.ServiceRegister, publishRegister publishservice.PublishRegister, invokerRegister invokermanagement.InvokerRegister, accessControlPolicyRegister accesscontrolpolicyservice.AccessControlPolicyRegister, keycloakMgm keycloak.AccessManagement) (*echo.Echo, *Security) {
 	swagger, err := securityapi.GetSwagger()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error loading swagger spec\n: %s", err)
@@ -600,7 +842,14 @@ func getEcho(serviceRegister providermanagement.ServiceRegister, publishRegister
 
 	swagger.Servers = nil
 
-	s := NewSecurity(serviceRegister, publishRegister, invokerRegister, keycloakMgm)
+	clientMock := NewTestClient(func(req *http.Request) *http.Response {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(bytes.NewBufferString(`{}`)),
+			Header:     make(http.Header),
+		}
+	})
+	s := NewSecurity(serviceRegister, publishRegister, invokerRegister, accessControlPolicyRegister, keycloakMgm, keycloak.DefaultInvokerRealm, clientMock, websocketnotifier.NewWebSocketNotifier(), make(chan eventsapi.EventNotification), storagetest.NewStore())
 
 	e := echo.New()
 	e.Use(echomiddleware.Logger())
@@ -625,6 +874,16 @@ func getServiceSecurity(aefId string, apiId string) securityapi.ServiceSecurity
 	}
 }
 
+func getApiList(aefId, apiId, apiName string) invokermanagementapi.APIList {
+	return invokermanagementapi.APIList{
+		{
+			ApiId:       &apiId,
+			ApiName:     apiName,
+			AefProfiles: &[]publishserviceapi.AefProfile{getAefProfile(aefId)},
+		},
+	}
+}
+
 func getAefProfile(aefId string) publishserviceapi.AefProfile {
 	return publishserviceapi.AefProfile{
 		AefId: aefId,
@@ -639,3 +898,16 @@ func getAefProfile(aefId string) publishserviceapi.AefProfile {
 		},
 	}
 }
+
+type RoundTripFunc func(req *http.Request) *http.Response
+
+func (f RoundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req), nil
+}
+
+// NewTestClient returns *http.Client with Transport replaced to avoid making real calls
+func NewTestClient(fn RoundTripFunc) *http.Client {
+	return &http.Client{
+		Transport: RoundTripFunc(fn),
+	}
+}
@@ -0,0 +1,84 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package storage
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// Keeps all values in memory, i.e. nothing survives a restart.
+type MemoryStore struct {
+	buckets map[string]map[string][]byte
+	lock    sync.Mutex
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]map[string][]byte),
+	}
+}
+
+func (ms *MemoryStore) Put(bucket, key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	ms.lock.Lock()
+	defer ms.lock.Unlock()
+
+	if _, ok := ms.buckets[bucket]; !ok {
+		ms.buckets[bucket] = make(map[string][]byte)
+	}
+	ms.buckets[bucket][key] = data
+	return nil
+}
+
+func (ms *MemoryStore) Delete(bucket, key string) error {
+	ms.lock.Lock()
+	defer ms.lock.Unlock()
+
+	if values, ok := ms.buckets[bucket]; ok {
+		delete(values, key)
+	}
+	return nil
+}
+
+func (ms *MemoryStore) ForEach(bucket string, fn func(key string, value []byte) error) error {
+	ms.lock.Lock()
+	values := make(map[string][]byte, len(ms.buckets[bucket]))
+	for key, value := range ms.buckets[bucket] {
+		values[key] = value
+	}
+	ms.lock.Unlock()
+
+	for key, value := range values {
+		if err := fn(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ms *MemoryStore) Close() error {
+	return nil
+}
@@ -0,0 +1,69 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	BackendMemory = "memory"
+	BackendBolt   = "bolt"
+)
+
+type Store interface {
+	// Stores the provided value under the provided key in the provided bucket, replacing any earlier value.
+	// Returns an error if the value could not be stored.
+	Put(bucket, key string, value interface{}) error
+	// Removes the value stored under the provided key in the provided bucket.
+	// Returns an error if the value could not be removed.
+	Delete(bucket, key string) error
+	// Calls the provided function with the key and the serialized value of all values stored in the provided bucket.
+	// Returns the first error returned by the provided function, or an error if the bucket could not be read.
+	ForEach(bucket string, fn func(key string, value []byte) error) error
+	// Releases the resources held by the store.
+	Close() error
+}
+
+// Creates a store for the provided backend. The path is only used by backends that keep their data in a file.
+func NewStore(backend, path string) (Store, error) {
+	switch backend {
+	case "", BackendMemory:
+		return NewMemoryStore(), nil
+	case BackendBolt:
+		return NewBoltStore(path)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %s", backend)
+	}
+}
+
+// Loads all values stored in the provided bucket into the provided map.
+func Load[T any](store Store, bucket string, values map[string]T) error {
+	return store.ForEach(bucket, func(key string, value []byte) error {
+		var v T
+		if err := json.Unmarshal(value, &v); err != nil {
+			return fmt.Errorf("invalid value stored for %s in %s, err=%s", key, bucket, err)
+		}
+		values[key] = v
+		return nil
+	})
+}
@@ -0,0 +1,117 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type value struct {
+	Name string `json:"name"`
+}
+
+func TestStores(t *testing.T) {
+	boltStore, err := NewBoltStore(filepath.Join(t.TempDir(), "test.db"))
+	assert.NoError(t, err)
+	defer boltStore.Close()
+
+	tests := []struct {
+		name  string
+		store Store
+	}{
+		{
+			name:  "Memory store",
+			store: NewMemoryStore(),
+		},
+		{
+			name:  "Bolt store",
+			store: boltStore,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := map[string]value{}
+			assert.NoError(t, Load(tt.store, "bucket", values))
+			assert.Empty(t, values)
+
+			assert.NoError(t, tt.store.Put("bucket", "key1", value{Name: "first"}))
+			assert.NoError(t, tt.store.Put("bucket", "key2", value{Name: "second"}))
+			assert.NoError(t, tt.store.Put("otherBucket", "key1", value{Name: "other"}))
+			assert.NoError(t, tt.store.Put("bucket", "key1", value{Name: "updated"}))
+
+			assert.NoError(t, Load(tt.store, "bucket", values))
+			assert.Equal(t, map[string]value{"key1": {Name: "updated"}, "key2": {Name: "second"}}, values)
+
+			assert.NoError(t, tt.store.Delete("bucket", "key2"))
+			assert.NoError(t, tt.store.Delete("bucket", "unknown"))
+			assert.NoError(t, tt.store.Delete("unknownBucket", "key1"))
+
+			values = map[string]value{}
+			assert.NoError(t, Load(tt.store, "bucket", values))
+			assert.Equal(t, map[string]value{"key1": {Name: "updated"}}, values)
+		})
+	}
+}
+
+func TestBoltStoreKeepsValuesWhenReopened(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	storeUnderTest, err := NewBoltStore(path)
+	assert.NoError(t, err)
+	assert.NoError(t, storeUnderTest.Put("bucket", "key", value{Name: "kept"}))
+	assert.NoError(t, storeUnderTest.Close())
+
+	storeUnderTest, err = NewBoltStore(path)
+	assert.NoError(t, err)
+	defer storeUnderTest.Close()
+	values := map[string]value{}
+	assert.NoError(t, Load(storeUnderTest, "bucket", values))
+	assert.Equal(t, map[string]value{"key": {Name: "kept"}}, values)
+}
+
+func TestLoadInvalidValue(t *testing.T) {
+	storeUnderTest := NewMemoryStore()
+	assert.NoError(t, storeUnderTest.Put("bucket", "key", "not a struct"))
+
+	err := Load(storeUnderTest, "bucket", map[string]value{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid value stored for key in bucket")
+}
+
+func TestNewStore(t *testing.T) {
+	store, err := NewStore("", "")
+	assert.NoError(t, err)
+	assert.IsType(t, &MemoryStore{}, store)
+
+	store, err = NewStore(BackendBolt, filepath.Join(t.TempDir(), "test.db"))
+	assert.NoError(t, err)
+	assert.IsType(t, &BoltStore{}, store)
+	store.Close()
+
+	_, err = NewStore(BackendBolt, "")
+	assert.Error(t, err)
+
+	_, err = NewStore("unknown", "")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown storage backend")
+}
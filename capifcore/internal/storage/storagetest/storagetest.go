@@ -0,0 +1,51 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package storagetest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"oransc.org/nonrtric/capifcore/internal/storage"
+)
+
+// Environment variable used to select the storage backend that the unit tests run against.
+const BackendEnv = "CAPIF_TEST_STORAGE"
+
+// Creates an empty store of the backend selected by the CAPIF_TEST_STORAGE environment variable. The in-memory backend
+// is used if the variable is not set. File based backends get a new file in a temporary directory.
+func NewStore() storage.Store {
+	backend := os.Getenv(BackendEnv)
+	path := ""
+	if backend != "" && backend != storage.BackendMemory {
+		dir, err := os.MkdirTemp("", "capifcore")
+		if err != nil {
+			panic(fmt.Sprintf("Unable to create directory for test storage, err=%s", err))
+		}
+		path = filepath.Join(dir, "capifcore.db")
+	}
+	store, err := storage.NewStore(backend, path)
+	if err != nil {
+		panic(fmt.Sprintf("Unable to create test storage, err=%s", err))
+	}
+	return store
+}
@@ -0,0 +1,273 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package websocketnotifier
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/websocket"
+
+	"oransc.org/nonrtric/capifcore/internal/common29122"
+	"oransc.org/nonrtric/capifcore/problemdetails"
+)
+
+// The path that clients connect to, followed by the id of the socket.
+const SocketsPath = "/capif-notifications/v1/websockets"
+
+// Notifications sent before the client has connected are kept until it connects. When this many are kept, further
+// notifications are rejected until the client connects.
+const maxPendingNotifications = 100
+
+const writeTimeout = 10 * time.Second
+
+// The lock of a socket guards its connection and pending notifications, and is held while writing to the connection so
+// that notifications over other sockets are not held up by a slow client.
+type socket struct {
+	conn    *websocket.Conn
+	pending [][]byte
+	removed bool
+	lock    sync.Mutex
+}
+
+// Keeps the websockets that notifications are pushed over, for clients that cannot expose a callback URL of their own.
+type WebSocketNotifier struct {
+	sockets map[string]*socket
+	lock    sync.Mutex
+}
+
+func NewWebSocketNotifier() *WebSocketNotifier {
+	return &WebSocketNotifier{
+		sockets: make(map[string]*socket),
+	}
+}
+
+// Registers the handler that the clients connect to their sockets through. The connections are not part of any CAPIF
+// API, so they are not validated.
+func (n *WebSocketNotifier) RegisterHandler(e *echo.Echo) {
+	e.GET(SocketsPath+"/:socketId", n.connect)
+}
+
+// Checks if the configuration requests that notifications are sent over a websocket.
+func IsSocketRequested(config *common29122.WebsockNotifConfig) bool {
+	return config != nil && config.RequestWebsocketUri != nil && *config.RequestWebsocketUri
+}
+
+// Gets the id of the socket set up for the configuration.
+// Returns false if no socket has been set up, then notifications are sent to the notification destination instead.
+func GetSocketId(config *common29122.WebsockNotifConfig) (string, bool) {
+	if !IsSocketRequested(config) || config.WebsocketUri == nil {
+		return "", false
+	}
+	return path.Base(string(*config.WebsocketUri)), true
+}
+
+// Sets up a new socket if the configuration requests one. The URI that the client shall connect to is set in the
+// configuration, it is built from the address that the request was sent to.
+func (n *WebSocketNotifier) SetUpSocket(ctx echo.Context, config *common29122.WebsockNotifConfig) {
+	if !IsSocketRequested(config) {
+		return
+	}
+
+	socketId := uuid.NewString()
+	n.lock.Lock()
+	n.sockets[socketId] = &socket{}
+	n.lock.Unlock()
+
+	scheme := "ws"
+	if ctx.Scheme() == "https" {
+		scheme = "wss"
+	}
+	uri := common29122.Link(scheme + "://" + ctx.Request().Host + SocketsPath + "/" + socketId)
+	config.WebsocketUri = &uri
+}
+
+// Sets up the socket of an updated configuration. A socket already set up for the current configuration is kept if
+// the updated configuration still requests one, and removed otherwise.
+func (n *WebSocketNotifier) UpdateSocket(ctx echo.Context, current, updated *common29122.WebsockNotifConfig) {
+	if !IsSocketRequested(updated) {
+		n.RemoveSocket(current)
+		return
+	}
+	if _, ok := GetSocketId(current); ok {
+		updated.WebsocketUri = current.WebsocketUri
+		return
+	}
+	n.SetUpSocket(ctx, updated)
+}
+
+// Makes the socket of a configuration kept in a store available again, so that the client can reconnect to it.
+func (n *WebSocketNotifier) RestoreSocket(config *common29122.WebsockNotifConfig) {
+	if socketId, ok := GetSocketId(config); ok {
+		n.lock.Lock()
+		defer n.lock.Unlock()
+		if _, exists := n.sockets[socketId]; !exists {
+			n.sockets[socketId] = &socket{}
+		}
+	}
+}
+
+// Removes the socket of the configuration, if it has one. A connected client is disconnected.
+func (n *WebSocketNotifier) RemoveSocket(config *common29122.WebsockNotifConfig) {
+	socketId, ok := GetSocketId(config)
+	if !ok {
+		return
+	}
+
+	n.lock.Lock()
+	s, exists := n.sockets[socketId]
+	delete(n.sockets, socketId)
+	n.lock.Unlock()
+	if !exists {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.removed = true
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+}
+
+func (n *WebSocketNotifier) getSocket(socketId string) (*socket, bool) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	s, ok := n.sockets[socketId]
+	return s, ok
+}
+
+// Sends the notification as JSON over the socket. If the client has not connected yet, or the connection fails, the
+// notification is sent when the client connects. Returns an error if the notification can neither be sent nor kept
+// until the client connects, then nothing has been delivered.
+func (n *WebSocketNotifier) Send(socketId string, notification interface{}) error {
+	message, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	s, ok := n.getSocket(socketId)
+	if !ok {
+		return fmt.Errorf("no socket with id %s", socketId)
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.conn != nil {
+		if err = write(s.conn, message); err == nil {
+			return nil
+		}
+		log.Warnf("Unable to send notification over socket %s due to %s, it is sent when the client reconnects", socketId, err)
+		s.conn.Close()
+		s.conn = nil
+	}
+	return s.addPending(socketId, message)
+}
+
+// Must be called with the lock of the socket held.
+func (s *socket) addPending(socketId string, message []byte) error {
+	if len(s.pending) >= maxPendingNotifications {
+		return fmt.Errorf("client not connected to socket %s and %d notifications already pending", socketId, len(s.pending))
+	}
+	s.pending = append(s.pending, message)
+	return nil
+}
+
+func (n *WebSocketNotifier) connect(ctx echo.Context) error {
+	socketId := ctx.Param("socketId")
+	if _, ok := n.getSocket(socketId); !ok {
+		return problemdetails.Send(ctx, http.StatusNotFound, problemdetails.CauseContextNotFound, fmt.Sprintf("Unable to connect to websocket due to no socket with id %s", socketId))
+	}
+
+	// The server is used instead of the handler, as the handler rejects clients that do not send an origin header.
+	server := websocket.Server{
+		Handler: func(conn *websocket.Conn) {
+			if !n.attach(socketId, conn) {
+				return
+			}
+			defer n.detach(socketId, conn)
+			// Notifications are only sent to the client, anything it sends is discarded. Reading detects when the
+			// socket is closed.
+			var message []byte
+			for websocket.Message.Receive(conn, &message) == nil {
+			}
+		},
+	}
+	server.ServeHTTP(ctx.Response(), ctx.Request())
+	return nil
+}
+
+// Attaches the connection to the socket and sends the pending notifications over it. A previous connection to the
+// socket is closed. Returns false if the socket has been removed, or if the pending notifications cannot be sent. The
+// notifications that have not been sent are then kept until the client reconnects.
+func (n *WebSocketNotifier) attach(socketId string, conn *websocket.Conn) bool {
+	s, ok := n.getSocket(socketId)
+	if !ok {
+		conn.Close()
+		return false
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.removed {
+		conn.Close()
+		return false
+	}
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+	for len(s.pending) > 0 {
+		if err := write(conn, s.pending[0]); err != nil {
+			log.Errorf("Unable to send pending notification over socket %s due to %s", socketId, err)
+			conn.Close()
+			return false
+		}
+		s.pending = s.pending[1:]
+	}
+	s.pending = nil
+	s.conn = conn
+	return true
+}
+
+func (n *WebSocketNotifier) detach(socketId string, conn *websocket.Conn) {
+	if s, ok := n.getSocket(socketId); ok {
+		s.lock.Lock()
+		if s.conn == conn {
+			s.conn = nil
+		}
+		s.lock.Unlock()
+	}
+	conn.Close()
+}
+
+func write(conn *websocket.Conn, message []byte) error {
+	if err := conn.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil {
+		return err
+	}
+	return websocket.Message.Send(conn, string(message))
+}
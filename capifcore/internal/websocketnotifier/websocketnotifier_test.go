@@ -0,0 +1,266 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package websocketnotifier
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/websocket"
+
+	"oransc.org/nonrtric/capifcore/internal/common29122"
+)
+
+func TestSetUpSocket(t *testing.T) {
+	notifierUnderTest := NewWebSocketNotifier()
+
+	// No socket is set up unless requested
+	notifierUnderTest.SetUpSocket(getContext("http"), nil)
+	requestWebsocketUri := false
+	config := common29122.WebsockNotifConfig{
+		RequestWebsocketUri: &requestWebsocketUri,
+	}
+	notifierUnderTest.SetUpSocket(getContext("http"), &config)
+	assert.Nil(t, config.WebsocketUri)
+	_, ok := GetSocketId(&config)
+	assert.False(t, ok)
+	assert.Empty(t, notifierUnderTest.sockets)
+
+	requestWebsocketUri = true
+	notifierUnderTest.SetUpSocket(getContext("http"), &config)
+	assert.Regexp(t, "^ws://example.com"+SocketsPath+"/[0-9a-f-]+$", *config.WebsocketUri)
+	socketId, ok := GetSocketId(&config)
+	assert.True(t, ok)
+	assert.Contains(t, notifierUnderTest.sockets, socketId)
+
+	secureConfig := common29122.WebsockNotifConfig{
+		RequestWebsocketUri: &requestWebsocketUri,
+	}
+	notifierUnderTest.SetUpSocket(getContext("https"), &secureConfig)
+	assert.True(t, strings.HasPrefix(string(*secureConfig.WebsocketUri), "wss://example.com"+SocketsPath))
+	assert.NotEqual(t, config.WebsocketUri, secureConfig.WebsocketUri)
+
+	notifierUnderTest.RemoveSocket(&config)
+	assert.NotContains(t, notifierUnderTest.sockets, socketId)
+}
+
+func TestUpdateSocket(t *testing.T) {
+	notifierUnderTest := NewWebSocketNotifier()
+	requestWebsocketUri := true
+	current := common29122.WebsockNotifConfig{
+		RequestWebsocketUri: &requestWebsocketUri,
+	}
+	notifierUnderTest.SetUpSocket(getContext("http"), &current)
+
+	// The socket is kept when the update still requests one
+	updated := common29122.WebsockNotifConfig{
+		RequestWebsocketUri: &requestWebsocketUri,
+	}
+	notifierUnderTest.UpdateSocket(getContext("http"), &current, &updated)
+	assert.Equal(t, current.WebsocketUri, updated.WebsocketUri)
+	assert.Len(t, notifierUnderTest.sockets, 1)
+
+	// The socket is removed when the update no longer requests one
+	notifierUnderTest.UpdateSocket(getContext("http"), &updated, nil)
+	assert.Empty(t, notifierUnderTest.sockets)
+
+	// A socket is set up when the update requests one for the first time
+	updated.WebsocketUri = nil
+	notifierUnderTest.UpdateSocket(getContext("http"), nil, &updated)
+	assert.NotNil(t, updated.WebsocketUri)
+	assert.Len(t, notifierUnderTest.sockets, 1)
+}
+
+func TestSendNotifications(t *testing.T) {
+	notifierUnderTest, server := getServer()
+	defer server.Close()
+
+	requestWebsocketUri := true
+	config := common29122.WebsockNotifConfig{
+		RequestWebsocketUri: &requestWebsocketUri,
+	}
+	notifierUnderTest.SetUpSocket(getContext("http"), &config)
+	socketId, _ := GetSocketId(&config)
+
+	// Notifications sent before the client connects are kept until it does
+	assert.NoError(t, notifierUnderTest.Send(socketId, common29122.TestNotification{Subscription: "pending"}))
+
+	conn := dial(t, server, socketId)
+	defer conn.Close()
+	assert.Equal(t, common29122.Link("pending"), receive(t, conn).Subscription)
+
+	assert.NoError(t, notifierUnderTest.Send(socketId, common29122.TestNotification{Subscription: "connected"}))
+	assert.Equal(t, common29122.Link("connected"), receive(t, conn).Subscription)
+
+	// The client is disconnected when the socket is removed
+	notifierUnderTest.RemoveSocket(&config)
+	var notification common29122.TestNotification
+	assert.Error(t, websocket.JSON.Receive(conn, &notification))
+	assert.Error(t, notifierUnderTest.Send(socketId, common29122.TestNotification{Subscription: "removed"}))
+}
+
+func TestSlowSocketDoesNotHoldUpOtherSockets(t *testing.T) {
+	notifierUnderTest, server := getServer()
+	defer server.Close()
+
+	requestWebsocketUri := true
+	slowConfig := common29122.WebsockNotifConfig{
+		RequestWebsocketUri: &requestWebsocketUri,
+	}
+	notifierUnderTest.SetUpSocket(getContext("http"), &slowConfig)
+	slowSocketId, _ := GetSocketId(&slowConfig)
+	config := common29122.WebsockNotifConfig{
+		RequestWebsocketUri: &requestWebsocketUri,
+	}
+	notifierUnderTest.SetUpSocket(getContext("http"), &config)
+	socketId, _ := GetSocketId(&config)
+	conn := dial(t, server, socketId)
+	defer conn.Close()
+
+	// A write to the slow socket is in progress
+	slowSocket, _ := notifierUnderTest.getSocket(slowSocketId)
+	slowSocket.lock.Lock()
+	defer slowSocket.lock.Unlock()
+
+	sent := make(chan error, 1)
+	go func() {
+		sent <- notifierUnderTest.Send(socketId, common29122.TestNotification{Subscription: "other"})
+	}()
+	select {
+	case err := <-sent:
+		assert.NoError(t, err)
+	case <-time.After(1 * time.Second):
+		assert.Fail(t, "Notification held up by another socket")
+	}
+	assert.Equal(t, common29122.Link("other"), receive(t, conn).Subscription)
+}
+
+func TestPendingNotificationsAreKeptWhenTheyCannotBeSent(t *testing.T) {
+	notifierUnderTest, server := getServer()
+	defer server.Close()
+
+	requestWebsocketUri := true
+	otherConfig := common29122.WebsockNotifConfig{
+		RequestWebsocketUri: &requestWebsocketUri,
+	}
+	notifierUnderTest.SetUpSocket(getContext("http"), &otherConfig)
+	otherSocketId, _ := GetSocketId(&otherConfig)
+	brokenConn := dial(t, server, otherSocketId)
+	brokenConn.Close()
+
+	config := common29122.WebsockNotifConfig{
+		RequestWebsocketUri: &requestWebsocketUri,
+	}
+	notifierUnderTest.SetUpSocket(getContext("http"), &config)
+	socketId, _ := GetSocketId(&config)
+	assert.NoError(t, notifierUnderTest.Send(socketId, common29122.TestNotification{Subscription: "pending"}))
+
+	// The connection fails before the pending notification has been sent
+	assert.False(t, notifierUnderTest.attach(socketId, brokenConn))
+
+	conn := dial(t, server, socketId)
+	defer conn.Close()
+	assert.Equal(t, common29122.Link("pending"), receive(t, conn).Subscription)
+}
+
+func TestNotificationsAreRejectedWhenTooManyArePending(t *testing.T) {
+	notifierUnderTest, server := getServer()
+	defer server.Close()
+
+	requestWebsocketUri := true
+	config := common29122.WebsockNotifConfig{
+		RequestWebsocketUri: &requestWebsocketUri,
+	}
+	notifierUnderTest.SetUpSocket(getContext("http"), &config)
+	socketId, _ := GetSocketId(&config)
+	for i := 0; i < maxPendingNotifications; i++ {
+		assert.NoError(t, notifierUnderTest.Send(socketId, common29122.TestNotification{Subscription: common29122.Link(fmt.Sprint(i))}))
+	}
+
+	// Nothing is delivered, the pending notifications are kept
+	err := notifierUnderTest.Send(socketId, common29122.TestNotification{Subscription: "rejected"})
+	assert.ErrorContains(t, err, "notifications already pending")
+
+	conn := dial(t, server, socketId)
+	defer conn.Close()
+	assert.Equal(t, common29122.Link("0"), receive(t, conn).Subscription)
+}
+
+func TestRestoreSocket(t *testing.T) {
+	notifierUnderTest, server := getServer()
+	defer server.Close()
+
+	requestWebsocketUri := true
+	uri := common29122.Link("ws://example.com" + SocketsPath + "/socketId")
+	config := common29122.WebsockNotifConfig{
+		RequestWebsocketUri: &requestWebsocketUri,
+		WebsocketUri:        &uri,
+	}
+	notifierUnderTest.RestoreSocket(&config)
+
+	conn := dial(t, server, "socketId")
+	defer conn.Close()
+	assert.NoError(t, notifierUnderTest.Send("socketId", common29122.TestNotification{Subscription: "restored"}))
+	assert.Equal(t, common29122.Link("restored"), receive(t, conn).Subscription)
+}
+
+func TestConnectToUnknownSocket(t *testing.T) {
+	_, server := getServer()
+	defer server.Close()
+
+	result, err := http.Get(server.URL + SocketsPath + "/unknown")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, result.StatusCode)
+}
+
+func getServer() (*WebSocketNotifier, *httptest.Server) {
+	notifier := NewWebSocketNotifier()
+	e := echo.New()
+	notifier.RegisterHandler(e)
+	return notifier, httptest.NewServer(e)
+}
+
+func getContext(scheme string) echo.Context {
+	req := httptest.NewRequest(http.MethodPost, scheme+"://example.com/subscriptions", nil)
+	return echo.New().NewContext(req, httptest.NewRecorder())
+}
+
+func dial(t *testing.T, server *httptest.Server, socketId string) *websocket.Conn {
+	uri := "ws" + strings.TrimPrefix(server.URL, "http") + SocketsPath + "/" + socketId
+	conn, err := websocket.Dial(uri, "", server.URL)
+	if err != nil {
+		t.Fatalf("Unable to connect to socket due to %s", err)
+	}
+	return conn
+}
+
+func receive(t *testing.T, conn *websocket.Conn) common29122.TestNotification {
+	var notification common29122.TestNotification
+	assert.NoError(t, conn.SetReadDeadline(time.Now().Add(1*time.Second)))
+	assert.NoError(t, websocket.JSON.Receive(conn, &notification))
+	return notification
+}
@@ -0,0 +1,130 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package problemdetails
+
+import (
+	"encoding/json"
+	"net/http"
+
+	echo "github.com/labstack/echo/v4"
+
+	"oransc.org/nonrtric/capifcore/internal/common29122"
+)
+
+// The machine-readable causes of a problem. The protocol error causes are defined in 3GPP TS 29.500, table 5.2.7.2-1,
+// which 3GPP TS 29.122 refers to, and the application error causes in 3GPP TS 29.122, clause 5.2.6.
+const (
+	CauseInvalidApi                   = "INVALID_API"
+	CauseInvalidMsgFormat             = "INVALID_MSG_FORMAT"
+	CauseInvalidQueryParam            = "INVALID_QUERY_PARAM"
+	CauseMandatoryQueryParamMissing   = "MANDATORY_QUERY_PARAM_MISSING"
+	CauseMandatoryIeIncorrect         = "MANDATORY_IE_INCORRECT"
+	CauseMandatoryIeMissing           = "MANDATORY_IE_MISSING"
+	CauseUnspecifiedMsgFailure        = "UNSPECIFIED_MSG_FAILURE"
+	CauseModificationNotAllowed       = "MODIFICATION_NOT_ALLOWED"
+	CauseRequestNotAuthorized         = "REQUEST_NOT_AUTHORIZED"
+	CauseResourceUriStructureNotFound = "RESOURCE_URI_STRUCTURE_NOT_FOUND"
+	CauseContextNotFound              = "CONTEXT_NOT_FOUND"
+	CauseSubscriptionNotFound         = "SUBSCRIPTION_NOT_FOUND"
+	CauseUnsupportedMediaType         = "UNSUPPORTED_MEDIA_TYPE"
+	CauseSystemFailure                = "SYSTEM_FAILURE"
+)
+
+// The media type of problem details, as defined in IETF RFC 7807 and used by the CAPIF APIs.
+const MIMEApplicationProblemJSON = "application/problem+json"
+
+// The problem type of all problems. As defined in IETF RFC 7807, the title of such a problem is the HTTP status phrase.
+const TypeAboutBlank = "about:blank"
+
+// Creates the problem details for a failed request. An empty cause is left out, since not all statuses have a cause
+// defined in the specifications.
+func New(request *http.Request, status int, cause, detail string, invalidParams ...common29122.InvalidParam) common29122.ProblemDetails {
+	problemType := common29122.Uri(TypeAboutBlank)
+	title := http.StatusText(status)
+	pd := common29122.ProblemDetails{
+		Type:   &problemType,
+		Title:  &title,
+		Status: &status,
+	}
+	if cause != "" {
+		pd.Cause = &cause
+	}
+	if detail != "" {
+		pd.Detail = &detail
+	}
+	if request != nil {
+		instance := common29122.Uri(request.URL.Path)
+		pd.Instance = &instance
+	}
+	if len(invalidParams) > 0 {
+		pd.InvalidParams = &invalidParams
+	}
+	return pd
+}
+
+// Sends the problem details of a failed request as the response.
+func Send(ctx echo.Context, status int, cause, detail string, invalidParams ...common29122.InvalidParam) error {
+	body, err := json.Marshal(New(ctx.Request(), status, cause, detail, invalidParams...))
+	if err != nil {
+		return err
+	}
+	return ctx.Blob(status, MIMEApplicationProblemJSON, body)
+}
+
+// Sends a problem reported by another CAPIF service as the response. The cause, detail and invalid parameters of the
+// reported problem are kept, a body that is not a problem becomes the detail and the cause is derived from the status.
+func Forward(ctx echo.Context, status int, body []byte) error {
+	var reported common29122.ProblemDetails
+	if err := json.Unmarshal(body, &reported); err != nil || (reported.Cause == nil && reported.Detail == nil) {
+		return Send(ctx, status, CauseForStatus(status), string(body))
+	}
+	cause := CauseForStatus(status)
+	if reported.Cause != nil {
+		cause = *reported.Cause
+	}
+	detail := ""
+	if reported.Detail != nil {
+		detail = *reported.Detail
+	}
+	var invalidParams []common29122.InvalidParam
+	if reported.InvalidParams != nil {
+		invalidParams = *reported.InvalidParams
+	}
+	return Send(ctx, status, cause, detail, invalidParams...)
+}
+
+// Gets the cause of an error that is only known by its status.
+func CauseForStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return CauseUnspecifiedMsgFailure
+	case http.StatusForbidden, http.StatusConflict:
+		return CauseModificationNotAllowed
+	case http.StatusNotFound:
+		return CauseContextNotFound
+	case http.StatusUnsupportedMediaType:
+		return CauseUnsupportedMediaType
+	}
+	if status >= http.StatusInternalServerError {
+		return CauseSystemFailure
+	}
+	return ""
+}
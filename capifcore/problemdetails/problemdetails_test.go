@@ -0,0 +1,125 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package problemdetails
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/deepmap/oapi-codegen/pkg/testutil"
+	echo "github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"oransc.org/nonrtric/capifcore/internal/common29122"
+)
+
+func TestSend(t *testing.T) {
+	e := echo.New()
+	e.GET("/things/:thingId", func(ctx echo.Context) error {
+		reason := "must be set"
+		return Send(ctx, http.StatusBadRequest, CauseMandatoryIeMissing, "Unable to get thing due to missing name", common29122.InvalidParam{Param: "name", Reason: &reason})
+	})
+
+	result := testutil.NewRequest().Get("/things/thing?limit=1").Go(t, e)
+
+	assert.Equal(t, http.StatusBadRequest, result.Code())
+	assert.Equal(t, MIMEApplicationProblemJSON, result.Recorder.Header().Get(echo.HeaderContentType))
+	var problemDetails common29122.ProblemDetails
+	err := result.UnmarshalJsonToObject(&problemDetails)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, common29122.Uri(TypeAboutBlank), *problemDetails.Type)
+	assert.Equal(t, "Bad Request", *problemDetails.Title)
+	assert.Equal(t, http.StatusBadRequest, *problemDetails.Status)
+	assert.Equal(t, CauseMandatoryIeMissing, *problemDetails.Cause)
+	assert.Equal(t, "Unable to get thing due to missing name", *problemDetails.Detail)
+	assert.Equal(t, common29122.Uri("/things/thing"), *problemDetails.Instance)
+	assert.Equal(t, "name", (*problemDetails.InvalidParams)[0].Param)
+	assert.Equal(t, "must be set", *(*problemDetails.InvalidParams)[0].Reason)
+}
+
+func TestNewWithoutCause(t *testing.T) {
+	problemDetails := New(nil, http.StatusMethodNotAllowed, "", "Method not allowed")
+
+	assert.Nil(t, problemDetails.Cause)
+	assert.Nil(t, problemDetails.Instance)
+	assert.Nil(t, problemDetails.InvalidParams)
+	assert.Equal(t, "Method Not Allowed", *problemDetails.Title)
+}
+
+func TestForward(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		wantCause  string
+		wantDetail string
+	}{
+		{
+			name:       "Problem is kept",
+			status:     http.StatusForbidden,
+			body:       `{"cause":"MODIFICATION_NOT_ALLOWED","detail":"Invoker already onboarded","instance":"/onboardedInvokers","status":403}`,
+			wantCause:  CauseModificationNotAllowed,
+			wantDetail: "Invoker already onboarded",
+		},
+		{
+			name:       "Cause is derived from status",
+			status:     http.StatusNotFound,
+			body:       `{"detail":"No such provider","status":404}`,
+			wantCause:  CauseContextNotFound,
+			wantDetail: "No such provider",
+		},
+		{
+			name:       "Body is not a problem",
+			status:     http.StatusBadGateway,
+			body:       "upstream failed",
+			wantCause:  CauseSystemFailure,
+			wantDetail: "upstream failed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			recorder := httptest.NewRecorder()
+			ctx := e.NewContext(httptest.NewRequest(http.MethodPost, "/api-invoker-management/v1/onboardedInvokers", nil), recorder)
+
+			err := Forward(ctx, tt.status, []byte(tt.body))
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, recorder.Code)
+			var problemDetails common29122.ProblemDetails
+			err = json.Unmarshal(recorder.Body.Bytes(), &problemDetails)
+			assert.NoError(t, err, "error unmarshaling response")
+			assert.Equal(t, tt.wantCause, *problemDetails.Cause)
+			assert.Equal(t, tt.wantDetail, *problemDetails.Detail)
+			assert.Equal(t, common29122.Uri("/api-invoker-management/v1/onboardedInvokers"), *problemDetails.Instance)
+		})
+	}
+}
+
+func TestCauseForStatus(t *testing.T) {
+	assert.Equal(t, CauseUnspecifiedMsgFailure, CauseForStatus(http.StatusBadRequest))
+	assert.Equal(t, CauseModificationNotAllowed, CauseForStatus(http.StatusForbidden))
+	assert.Equal(t, CauseContextNotFound, CauseForStatus(http.StatusNotFound))
+	assert.Equal(t, CauseSystemFailure, CauseForStatus(http.StatusServiceUnavailable))
+	assert.Empty(t, CauseForStatus(http.StatusUnauthorized))
+}
@@ -0,0 +1,38 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+// Package problemdetailstest lets the responses of the tests that are problem details be unmarshaled like any other
+// JSON response. Import it for its side effect in the tests that check problem details.
+package problemdetailstest
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/deepmap/oapi-codegen/pkg/testutil"
+
+	"oransc.org/nonrtric/capifcore/problemdetails"
+)
+
+func init() {
+	testutil.RegisterResponseHandler(problemdetails.MIMEApplicationProblemJSON, func(_ string, r io.Reader, obj interface{}, _ bool) error {
+		return json.NewDecoder(r).Decode(obj)
+	})
+}
@@ -0,0 +1,343 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package validation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/deepmap/oapi-codegen/pkg/middleware"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	echo "github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+
+	"oransc.org/nonrtric/capifcore/internal/common29122"
+	"oransc.org/nonrtric/capifcore/problemdetails"
+)
+
+func init() {
+	// PATCH requests use the merge patch content type, which the request validator must decode as JSON
+	openapi3filter.RegisterBodyDecoder("application/merge-patch+json", openapi3filter.RegisteredBodyDecoder("application/json"))
+}
+
+// How responses that violate the API specification are handled.
+type ResponseValidation string
+
+const (
+	// Responses are not validated.
+	ResponseValidationOff ResponseValidation = "off"
+	// Responses that violate the specification are logged, but sent as they are.
+	ResponseValidationLog ResponseValidation = "log"
+	// Responses that violate the specification are replaced by an internal server error.
+	ResponseValidationReject ResponseValidation = "reject"
+)
+
+// Parses a response validation mode, an empty mode means no validation.
+func ParseResponseValidation(mode string) (ResponseValidation, error) {
+	switch ResponseValidation(mode) {
+	case "", ResponseValidationOff:
+		return ResponseValidationOff, nil
+	case ResponseValidationLog, ResponseValidationReject:
+		return ResponseValidation(mode), nil
+	}
+	return "", fmt.Errorf("invalid response validation %s, must be one of off, log or reject", mode)
+}
+
+// Creates a group for the API with the provided base URL, validating the requests to the API against the provided
+// specification. The API handlers must be registered on the returned group, routes registered directly on the Echo
+// instance are not validated. Requests that violate the specification are rejected with ProblemDetails listing the
+// invalid parameters. Depending on the response validation mode, the responses are validated as well.
+func NewGroup(e *echo.Echo, swagger *openapi3.T, baseURL string, responseValidation ResponseValidation) *echo.Group {
+	// The paths of the specification are relative to the base URL of the API
+	swagger.Servers = openapi3.Servers{{URL: baseURL}}
+	makeEnumerationsExtensible(swagger)
+	router, err := gorillamux.NewRouter(swagger)
+	if err != nil {
+		log.Fatalf("Error creating router for %s\n: %s", baseURL, err)
+	}
+
+	middlewares := []echo.MiddlewareFunc{requestValidator(router)}
+	if responseValidation == ResponseValidationLog || responseValidation == ResponseValidationReject {
+		middlewares = append(middlewares, responseValidator(router, responseValidation))
+	}
+	return e.Group(baseURL, middlewares...)
+}
+
+// Decodes the merge patch in the body of a PATCH request into the provided patch. The body is decoded directly, as Echo
+// does not bind the application/merge-patch+json content type.
+func BindMergePatch(ctx echo.Context, patch interface{}) error {
+	return json.NewDecoder(ctx.Request().Body).Decode(patch)
+}
+
+// The enumerations of TS 29.222 are extensible, any string is allowed in addition to the enumerated values. The
+// generated specifications only keep the enumerated values, so the enumerations are removed from the string schemas.
+func makeEnumerationsExtensible(swagger *openapi3.T) {
+	visited := map[*openapi3.Schema]bool{}
+	for _, schemaRef := range swagger.Components.Schemas {
+		removeStringEnumerations(schemaRef, visited)
+	}
+	for _, pathItem := range swagger.Paths {
+		for _, parameter := range pathItem.Parameters {
+			removeParameterEnumerations(parameter, visited)
+		}
+		for _, operation := range pathItem.Operations() {
+			for _, parameter := range operation.Parameters {
+				removeParameterEnumerations(parameter, visited)
+			}
+			if operation.RequestBody != nil && operation.RequestBody.Value != nil {
+				for _, mediaType := range operation.RequestBody.Value.Content {
+					removeStringEnumerations(mediaType.Schema, visited)
+				}
+			}
+			for _, response := range operation.Responses {
+				if response.Value != nil {
+					for _, mediaType := range response.Value.Content {
+						removeStringEnumerations(mediaType.Schema, visited)
+					}
+				}
+			}
+		}
+	}
+}
+
+func removeParameterEnumerations(parameter *openapi3.ParameterRef, visited map[*openapi3.Schema]bool) {
+	if parameter.Value == nil {
+		return
+	}
+	removeStringEnumerations(parameter.Value.Schema, visited)
+	for _, mediaType := range parameter.Value.Content {
+		removeStringEnumerations(mediaType.Schema, visited)
+	}
+}
+
+func removeStringEnumerations(schemaRef *openapi3.SchemaRef, visited map[*openapi3.Schema]bool) {
+	if schemaRef == nil || schemaRef.Value == nil || visited[schemaRef.Value] {
+		return
+	}
+	schema := schemaRef.Value
+	visited[schema] = true
+	if schema.Type == openapi3.TypeString {
+		schema.Enum = nil
+	}
+	for _, property := range schema.Properties {
+		removeStringEnumerations(property, visited)
+	}
+	for _, schemaRefs := range []openapi3.SchemaRefs{schema.OneOf, schema.AnyOf, schema.AllOf} {
+		for _, subSchema := range schemaRefs {
+			removeStringEnumerations(subSchema, visited)
+		}
+	}
+	removeStringEnumerations(schema.Not, visited)
+	removeStringEnumerations(schema.Items, visited)
+	removeStringEnumerations(schema.AdditionalProperties, visited)
+}
+
+func getOptions() *openapi3filter.Options {
+	return &openapi3filter.Options{
+		MultiError:            true,
+		IncludeResponseStatus: true,
+		// Clients are authenticated by CAPIF Core, not by the validator
+		AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+	}
+}
+
+func requestValidator(router routers.Router) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			route, pathParams, err := router.FindRoute(ctx.Request())
+			if err != nil {
+				if errors.Is(err, routers.ErrMethodNotAllowed) {
+					return problemdetails.Send(ctx, http.StatusMethodNotAllowed, "", "Method not allowed")
+				}
+				return problemdetails.Send(ctx, http.StatusNotFound, problemdetails.CauseResourceUriStructureNotFound, "Resource not found")
+			}
+
+			input := &openapi3filter.RequestValidationInput{
+				Request:    ctx.Request(),
+				PathParams: pathParams,
+				Route:      route,
+				Options:    getOptions(),
+			}
+			if err = openapi3filter.ValidateRequest(getContext(ctx), input); err != nil {
+				return problemdetails.Send(ctx, http.StatusBadRequest, getRequestCause(err), "Request does not match the API specification", getInvalidParams(err)...)
+			}
+			return next(ctx)
+		}
+	}
+}
+
+// Responses are kept until they have been validated.
+type bufferedWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedWriter) WriteHeader(status int) {
+	w.status = status
+}
+
+func (w *bufferedWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(b)
+}
+
+func responseValidator(router routers.Router, responseValidation ResponseValidation) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			writer := ctx.Response().Writer
+			buffer := &bufferedWriter{ResponseWriter: writer}
+			ctx.Response().Writer = buffer
+			err := next(ctx)
+			ctx.Response().Writer = writer
+			if err != nil || buffer.status == 0 {
+				// Nothing has been written, the error is written by Echo
+				return err
+			}
+
+			if violations := validateResponse(ctx, router, buffer); violations != nil {
+				log.Warnf("Response to %s %s does not match the API specification: %s", ctx.Request().Method, ctx.Request().URL.Path, violations)
+				if responseValidation == ResponseValidationReject {
+					return writeInvalidResponseError(ctx.Request(), writer, violations)
+				}
+			}
+			writer.WriteHeader(buffer.status)
+			_, err = writer.Write(buffer.body.Bytes())
+			return err
+		}
+	}
+}
+
+// Validates the buffered response. Returns the error of the validation, nil if the response is valid.
+func validateResponse(ctx echo.Context, router routers.Router, buffer *bufferedWriter) error {
+	route, pathParams, err := router.FindRoute(ctx.Request())
+	if err != nil {
+		return err
+	}
+	input := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: &openapi3filter.RequestValidationInput{
+			Request:    ctx.Request(),
+			PathParams: pathParams,
+			Route:      route,
+		},
+		Status:  buffer.status,
+		Header:  buffer.Header(),
+		Body:    io.NopCloser(bytes.NewReader(buffer.body.Bytes())),
+		Options: getOptions(),
+	}
+	return openapi3filter.ValidateResponse(getContext(ctx), input)
+}
+
+// The response has already been committed in Echo, so the error is written directly.
+func writeInvalidResponseError(request *http.Request, writer http.ResponseWriter, violations error) error {
+	status := http.StatusInternalServerError
+	pd := problemdetails.New(request, status, problemdetails.CauseSystemFailure, "Response does not match the API specification", getInvalidParams(violations)...)
+	writer.Header().Del(echo.HeaderContentLength)
+	writer.Header().Set(echo.HeaderContentType, problemdetails.MIMEApplicationProblemJSON)
+	writer.WriteHeader(status)
+	return json.NewEncoder(writer).Encode(pd)
+}
+
+// Pass the Echo context into the validator, so that any callbacks which it invokes make it available.
+func getContext(ctx echo.Context) context.Context {
+	return context.WithValue(ctx.Request().Context(), middleware.EchoContextKey, ctx)
+}
+
+// Gets the cause of a request that does not match the specification from its first violation.
+func getRequestCause(err error) string {
+	switch e := err.(type) {
+	case openapi3.MultiError:
+		if len(e) > 0 {
+			return getRequestCause(e[0])
+		}
+	case *openapi3filter.RequestError:
+		if e.Parameter != nil {
+			if e.Parameter.In == openapi3.ParameterInQuery {
+				return problemdetails.CauseInvalidQueryParam
+			}
+			return problemdetails.CauseUnspecifiedMsgFailure
+		}
+		var parseErr *openapi3filter.ParseError
+		if errors.As(e.Err, &parseErr) {
+			return problemdetails.CauseInvalidMsgFormat
+		}
+	}
+	return problemdetails.CauseMandatoryIeIncorrect
+}
+
+// Gets the invalid parameters of a validation error. Parameters are given by their name, and attributes of the body
+// by their JSON pointer.
+func getInvalidParams(err error) []common29122.InvalidParam {
+	invalidParams := []common29122.InvalidParam{}
+	switch e := err.(type) {
+	case openapi3.MultiError:
+		for _, validationErr := range e {
+			invalidParams = append(invalidParams, getInvalidParams(validationErr)...)
+		}
+	case *openapi3filter.RequestError:
+		if e.Parameter != nil {
+			invalidParams = append(invalidParams, getInvalidParam(e.Parameter.Name, getReason(e.Reason, e.Err)))
+		} else if e.Err != nil {
+			invalidParams = append(invalidParams, getInvalidParams(e.Err)...)
+		} else {
+			invalidParams = append(invalidParams, getInvalidParam("body", e.Reason))
+		}
+	case *openapi3filter.ResponseError:
+		if e.Err != nil {
+			invalidParams = append(invalidParams, getInvalidParams(e.Err)...)
+		} else {
+			invalidParams = append(invalidParams, getInvalidParam("response", e.Reason))
+		}
+	case *openapi3.SchemaError:
+		invalidParams = append(invalidParams, getInvalidParam("/"+strings.Join(e.JSONPointer(), "/"), e.Reason))
+	default:
+		invalidParams = append(invalidParams, getInvalidParam("body", err.Error()))
+	}
+	return invalidParams
+}
+
+func getReason(reason string, err error) string {
+	if schemaError, ok := err.(*openapi3.SchemaError); ok {
+		return schemaError.Reason
+	}
+	if err != nil {
+		return err.Error()
+	}
+	return reason
+}
+
+func getInvalidParam(param, reason string) common29122.InvalidParam {
+	return common29122.InvalidParam{
+		Param:  param,
+		Reason: &reason,
+	}
+}
@@ -0,0 +1,257 @@
+// -
+//   ========================LICENSE_START=================================
+//   O-RAN-SC
+//   %%
+//   Copyright (C) 2026: OpenInfra Foundation Europe
+//   %%
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+//   ========================LICENSE_END===================================
+//
+
+package validation
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/deepmap/oapi-codegen/pkg/testutil"
+	"github.com/getkin/kin-openapi/openapi3"
+	echo "github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"oransc.org/nonrtric/capifcore/internal/common29122"
+	"oransc.org/nonrtric/capifcore/problemdetails"
+	_ "oransc.org/nonrtric/capifcore/problemdetails/problemdetailstest"
+)
+
+const testSpec = `
+openapi: 3.0.0
+info:
+  title: Test API
+  version: "1.0"
+paths:
+  /things:
+    get:
+      parameters:
+        - name: limit
+          in: query
+          required: true
+          schema:
+            type: integer
+      responses:
+        '200':
+          description: The things
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: '#/components/schemas/Thing'
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Thing'
+      responses:
+        '201':
+          description: The created thing
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Thing'
+  /things/{thingId}:
+    get:
+      parameters:
+        - name: thingId
+          in: path
+          required: true
+          schema:
+            type: string
+      responses:
+        '200':
+          description: The thing
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Thing'
+        '404':
+          description: The thing does not exist
+          content:
+            application/problem+json:
+              schema:
+                $ref: '#/components/schemas/ProblemDetails'
+components:
+  schemas:
+    ProblemDetails:
+      type: object
+      properties:
+        cause:
+          type: string
+        detail:
+          type: string
+    Thing:
+      type: object
+      required:
+        - name
+      properties:
+        name:
+          type: string
+        size:
+          type: integer
+        format:
+          type: string
+          enum:
+            - JSON
+`
+
+type thing struct {
+	Name   interface{} `json:"name,omitempty"`
+	Size   interface{} `json:"size,omitempty"`
+	Format string      `json:"format,omitempty"`
+}
+
+func TestRequestsAreValidated(t *testing.T) {
+	requestHandler := getEcho(t, ResponseValidationOff, nil)
+
+	// A valid request, the format is extensible
+	result := testutil.NewRequest().Post("/test/v1/things").WithJsonBody(thing{Name: "thing", Format: "OTHER"}).Go(t, requestHandler)
+	assert.Equal(t, http.StatusCreated, result.Code())
+
+	// Invalid attributes of the body
+	result = testutil.NewRequest().Post("/test/v1/things").WithJsonBody(thing{Size: "big"}).Go(t, requestHandler)
+	assert.Equal(t, http.StatusBadRequest, result.Code())
+	var problemDetails common29122.ProblemDetails
+	err := result.UnmarshalBodyToObject(&problemDetails)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, http.StatusBadRequest, *problemDetails.Status)
+	assert.Equal(t, problemdetails.CauseMandatoryIeIncorrect, *problemDetails.Cause)
+	assert.Equal(t, "Request does not match the API specification", *problemDetails.Detail)
+	assert.Equal(t, common29122.Uri("/test/v1/things"), *problemDetails.Instance)
+	assert.ElementsMatch(t, []string{"/name", "/size"}, getParams(*problemDetails.InvalidParams))
+	for _, invalidParam := range *problemDetails.InvalidParams {
+		if invalidParam.Param == "/name" {
+			assert.Equal(t, `property "name" is missing`, *invalidParam.Reason)
+		} else {
+			assert.Equal(t, "Field must be set to integer or not be present", *invalidParam.Reason)
+		}
+	}
+
+	// Invalid parameter
+	result = testutil.NewRequest().Get("/test/v1/things?limit=many").Go(t, requestHandler)
+	assert.Equal(t, http.StatusBadRequest, result.Code())
+	err = result.UnmarshalBodyToObject(&problemDetails)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, problemdetails.CauseInvalidQueryParam, *problemDetails.Cause)
+	assert.Equal(t, "limit", (*problemDetails.InvalidParams)[0].Param)
+
+	// Unknown resource and method
+	result = testutil.NewRequest().Get("/test/v1/other").Go(t, requestHandler)
+	assert.Equal(t, http.StatusNotFound, result.Code())
+	problemDetails = common29122.ProblemDetails{}
+	err = result.UnmarshalBodyToObject(&problemDetails)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, problemdetails.CauseResourceUriStructureNotFound, *problemDetails.Cause)
+	result = testutil.NewRequest().Delete("/test/v1/things").Go(t, requestHandler)
+	assert.Equal(t, http.StatusMethodNotAllowed, result.Code())
+}
+
+func TestInvalidResponsesAreRejected(t *testing.T) {
+	invalidThings := []thing{{Size: 1}}
+	requestHandler := getEcho(t, ResponseValidationReject, invalidThings)
+
+	result := testutil.NewRequest().Get("/test/v1/things?limit=1").Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusInternalServerError, result.Code())
+	assert.Equal(t, problemdetails.MIMEApplicationProblemJSON, result.Recorder.Header().Get(echo.HeaderContentType))
+	var problemDetails common29122.ProblemDetails
+	err := result.UnmarshalBodyToObject(&problemDetails)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, problemdetails.CauseSystemFailure, *problemDetails.Cause)
+	assert.Equal(t, "Response does not match the API specification", *problemDetails.Detail)
+	assert.Equal(t, []string{"/0/name"}, getParams(*problemDetails.InvalidParams))
+
+	// Valid responses are sent as they are
+	requestHandler = getEcho(t, ResponseValidationReject, []thing{{Name: "thing"}})
+	result = testutil.NewRequest().Get("/test/v1/things?limit=1").Go(t, requestHandler)
+	assert.Equal(t, http.StatusOK, result.Code())
+	assert.JSONEq(t, `[{"name":"thing"}]`, string(result.Recorder.Body.Bytes()))
+}
+
+func TestErrorResponsesAreNotRejected(t *testing.T) {
+	requestHandler := getEcho(t, ResponseValidationReject, nil)
+
+	result := testutil.NewRequest().Get("/test/v1/things/thing").Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusNotFound, result.Code())
+	assert.Equal(t, problemdetails.MIMEApplicationProblemJSON, result.Recorder.Header().Get(echo.HeaderContentType))
+	var problemDetails common29122.ProblemDetails
+	err := result.UnmarshalBodyToObject(&problemDetails)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, problemdetails.CauseContextNotFound, *problemDetails.Cause)
+	assert.Equal(t, "Unable to get thing due to thing not found", *problemDetails.Detail)
+}
+
+func TestInvalidResponsesAreLogged(t *testing.T) {
+	invalidThings := []thing{{Size: 1}}
+	requestHandler := getEcho(t, ResponseValidationLog, invalidThings)
+
+	result := testutil.NewRequest().Get("/test/v1/things?limit=1").Go(t, requestHandler)
+
+	assert.Equal(t, http.StatusOK, result.Code())
+	assert.JSONEq(t, `[{"size":1}]`, string(result.Recorder.Body.Bytes()))
+}
+
+func TestParseResponseValidation(t *testing.T) {
+	responseValidation, err := ParseResponseValidation("")
+	assert.NoError(t, err)
+	assert.Equal(t, ResponseValidationOff, responseValidation)
+
+	responseValidation, err = ParseResponseValidation("reject")
+	assert.NoError(t, err)
+	assert.Equal(t, ResponseValidationReject, responseValidation)
+
+	_, err = ParseResponseValidation("strict")
+	assert.ErrorContains(t, err, "invalid response validation strict")
+}
+
+func getParams(invalidParams []common29122.InvalidParam) []string {
+	params := []string{}
+	for _, invalidParam := range invalidParams {
+		params = append(params, invalidParam.Param)
+	}
+	return params
+}
+
+func getEcho(t *testing.T, responseValidation ResponseValidation, things []thing) *echo.Echo {
+	swagger, err := openapi3.NewLoader().LoadFromData([]byte(testSpec))
+	assert.NoError(t, err)
+
+	e := echo.New()
+	group := NewGroup(e, swagger, "/test/v1", responseValidation)
+	group.GET("/things", func(ctx echo.Context) error {
+		return ctx.JSON(http.StatusOK, things)
+	})
+	group.GET("/things/:thingId", func(ctx echo.Context) error {
+		return problemdetails.Send(ctx, http.StatusNotFound, problemdetails.CauseContextNotFound, "Unable to get thing due to thing not found")
+	})
+	group.POST("/things", func(ctx echo.Context) error {
+		var newThing thing
+		if err := ctx.Bind(&newThing); err != nil {
+			return err
+		}
+		return ctx.JSON(http.StatusCreated, newThing)
+	})
+	return e
+}
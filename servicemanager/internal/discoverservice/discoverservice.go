@@ -25,6 +25,7 @@ import (
 	"fmt"
 	"net/http"
 
+	"oransc.org/nonrtric/capifcore/problemdetails"
 	"oransc.org/nonrtric/servicemanager/internal/common29122"
 	discoverapi "oransc.org/nonrtric/servicemanager/internal/discoverserviceapi"
 
@@ -68,14 +69,13 @@ func (ds *DiscoverService) GetAllServiceAPIs(ctx echo.Context, params discoverap
 	if err != nil {
 		msg := err.Error()
 		log.Errorf("error on GetAllServiceAPIsWithResponse %s", msg)
-		return sendCoreError(ctx, http.StatusInternalServerError, msg)
+		return problemdetails.Send(ctx, http.StatusInternalServerError, problemdetails.CauseSystemFailure, msg)
 	}
 
 	if rsp.StatusCode() != http.StatusOK {
-		msg := string(rsp.Body)
 		log.Errorf("GetAllServiceAPIs status code %d", rsp.StatusCode())
 		log.Errorf("GetAllServiceAPIs error %s", string(rsp.Body))
-		return sendCoreError(ctx, rsp.StatusCode(), msg)
+		return problemdetails.Forward(ctx, rsp.StatusCode(), rsp.Body)
 	}
 
 	rspDiscoveredAPIs := *rsp.JSON200
@@ -85,15 +85,3 @@ func (ds *DiscoverService) GetAllServiceAPIs(ctx echo.Context, params discoverap
 	}
 	return nil
 }
-
-
-// This function wraps sending of an error in the Error format, and
-// handling the failure to marshal that.
-func sendCoreError(ctx echo.Context, code int, message string) error {
-	pd := common29122.ProblemDetails{
-		Cause:  &message,
-		Status: &code,
-	}
-	err := ctx.JSON(code, pd)
-	return err
-}
@@ -21,6 +21,7 @@
 package discoverservice
 
 import (
+	"encoding/base64"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -54,6 +55,7 @@ import (
 	"oransc.org/nonrtric/servicemanager/internal/publishservice"
 
 	"oransc.org/nonrtric/capifcore"
+	_ "oransc.org/nonrtric/capifcore/problemdetails/problemdetailstest"
 	mockKong "oransc.org/nonrtric/servicemanager/mockkong"
 )
 
@@ -98,7 +100,7 @@ func setupTest() error {
 	mockKongControlPlanePort := parsedMockKongURL.Port()
 
 	eCapifWeb = echo.New()
-	capifcore.RegisterHandlers(eCapifWeb, nil, nil)
+	capifcore.RegisterHandlers(eCapifWeb, nil, nil, nil, nil, false)
 	capifServer = httptest.NewServer(eCapifWeb)
 
 	// Parse the server URL
@@ -395,6 +397,32 @@ func TestOnboardInvoker(t *testing.T) {
 	assert.Equal(t, newInvoker.NotificationDestination, resultInvoker.NotificationDestination)
 	assert.Equal(t, newInvoker.OnboardingInformation.ApiInvokerPublicKey, resultInvoker.OnboardingInformation.ApiInvokerPublicKey)
 	assert.Equal(t, "http://example.com/api-invoker-management/v1/onboardedInvokers/"+*resultInvoker.ApiInvokerId, result.Recorder.Header().Get(echo.HeaderLocation))
+
+	// The invoker is granted the published APIs through access requests to CAPIF Core, so that it can discover them
+	// The invoker authenticates with the onboarding secret given by CAPIF Core, and the publishing function with the
+	// registration secret of its provider.
+	invokerSecret := *resultInvoker.OnboardingInformation.OnboardingSecret
+	apfId := "APF_id_rApp_Kong_as_APF"
+	grantApiAccess(t, invokerId, invokerSecret, apfId, "sec", "api_id_apiName1")
+	grantApiAccess(t, invokerId, invokerSecret, apfId, "sec", "api_id_apiName2")
+}
+
+func grantApiAccess(t *testing.T, invokerId, invokerSecret, apfId, regSec, apiId string) {
+	result := testutil.NewRequest().Post("/access-requests/v1/invokers/"+invokerId+"/requests").WithHeader(echo.HeaderAuthorization, getBasicAuthorization(invokerId, invokerSecret)).WithJsonBody(map[string]string{"apiId": apiId}).Go(t, eCapifWeb)
+	assert.Equal(t, http.StatusCreated, result.Code())
+
+	var accessRequest struct {
+		Id string `json:"id"`
+	}
+	err := result.UnmarshalBodyToObject(&accessRequest)
+	assert.NoError(t, err, "error unmarshaling response")
+
+	result = testutil.NewRequest().Post("/access-requests/v1/providers/"+apfId+"/requests/"+accessRequest.Id+"/approve").WithHeader(echo.HeaderAuthorization, getBasicAuthorization(apfId, regSec)).Go(t, eCapifWeb)
+	assert.Equal(t, http.StatusOK, result.Code())
+}
+
+func getBasicAuthorization(clientId, secret string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(clientId+":"+secret))
 }
 
 func TestGetAllServiceAPIs(t *testing.T) {
@@ -441,8 +469,8 @@ func TestGetAllServiceAPIsWhenMissingProvider(t *testing.T) {
 
 	notFound := http.StatusNotFound
 	assert.Equal(t, &notFound, problemDetails.Status)
-	assert.Contains(t, *problemDetails.Cause, invokerId)
-	assert.Contains(t, *problemDetails.Cause, "not registered")
+	assert.Contains(t, *problemDetails.Detail, invokerId)
+	assert.Contains(t, *problemDetails.Detail, "not registered")
 }
 
 func TestFilterApiName(t *testing.T) {
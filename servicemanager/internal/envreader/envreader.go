@@ -116,6 +116,7 @@ func logConfig(myEnv map[string]string, envFile string) {
 	log.Infof("CAPIF_PORT %s", myEnv["CAPIF_PORT"])
 	log.Infof("LOG_LEVEL %s", myEnv["LOG_LEVEL"])
 	log.Infof("SERVICE_MANAGER_PORT %s", myEnv["SERVICE_MANAGER_PORT"])
+	log.Infof("RESPONSE_VALIDATION %s", myEnv["RESPONSE_VALIDATION"])
 	log.Infof("TEST_SERVICE_IPV4 %s", myEnv["TEST_SERVICE_IPV4"])
 	log.Infof("TEST_SERVICE_PORT %s", myEnv["TEST_SERVICE_PORT"])
 }
@@ -21,11 +21,15 @@
 package invokermanagement
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"path"
 
+	"oransc.org/nonrtric/capifcore/problemdetails"
+	"oransc.org/nonrtric/capifcore/validation"
 	"oransc.org/nonrtric/servicemanager/internal/common29122"
 	invokerapi "oransc.org/nonrtric/servicemanager/internal/invokermanagementapi"
 
@@ -55,7 +59,7 @@ func (im *InvokerManager) PostOnboardedInvokers(ctx echo.Context) error {
 	var newInvoker invokerapi.APIInvokerEnrolmentDetails
 	errMsg := "Unable to onboard invoker due to %s"
 	if err := ctx.Bind(&newInvoker); err != nil {
-		return sendCoreError(ctx, http.StatusBadRequest, fmt.Sprintf(errMsg, "invalid format for invoker"))
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseInvalidMsgFormat, fmt.Sprintf(errMsg, "invalid format for invoker"))
 	}
 
 	capifcoreUrl := fmt.Sprintf("%s://%s:%d/api-invoker-management/v1/", im.CapifProtocol, im.CapifIPv4, im.CapifPort)
@@ -79,13 +83,13 @@ func (im *InvokerManager) PostOnboardedInvokers(ctx echo.Context) error {
 	if err != nil {
 		msg := err.Error()
 		log.Errorf("error on PostOnboardedInvokersWithResponse %s", msg)
-		return sendCoreError(ctx, http.StatusInternalServerError, msg)
+		return problemdetails.Send(ctx, http.StatusInternalServerError, problemdetails.CauseSystemFailure, msg)
 	}
 
 	if rspInvoker.StatusCode() != http.StatusCreated {
 		msg := string(rspInvoker.Body)
 		log.Errorf("error on PostOnboardedInvokersWithResponse %s", msg)
-		return sendCoreError(ctx, rspInvoker.StatusCode(), msg)
+		return problemdetails.Forward(ctx, rspInvoker.StatusCode(), rspInvoker.Body)
 	}
 
 	rspAPIProviderEnrolmentDetails := *rspInvoker.JSON201
@@ -124,7 +128,7 @@ func (im *InvokerManager) DeleteOnboardedInvokersOnboardingId(ctx echo.Context,
 	if err != nil {
 		msg := err.Error()
 		log.Errorf("error on DeleteOnboardedInvokersOnboardingId %s", msg)
-		return sendCoreError(ctx, http.StatusInternalServerError, msg)
+		return problemdetails.Send(ctx, http.StatusInternalServerError, problemdetails.CauseSystemFailure, msg)
 	}
 
 	return ctx.NoContent(http.StatusNoContent)
@@ -138,7 +142,7 @@ func (im *InvokerManager) PutOnboardedInvokersOnboardingId(ctx echo.Context, onb
 	var invoker invokerapi.APIInvokerEnrolmentDetails
 	errMsg := "Unable to update invoker due to %s"
 	if err := ctx.Bind(&invoker); err != nil {
-		return sendCoreError(ctx, http.StatusBadRequest, fmt.Sprintf(errMsg, "invalid format for invoker"))
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseInvalidMsgFormat, fmt.Sprintf(errMsg, "invalid format for invoker"))
 	}
 
 	capifcoreUrl := fmt.Sprintf("%s://%s:%d/api-invoker-management/v1/", im.CapifProtocol, im.CapifIPv4, im.CapifPort)
@@ -165,8 +169,7 @@ func (im *InvokerManager) PutOnboardedInvokersOnboardingId(ctx echo.Context, onb
 	}
 
 	if rspInvoker.StatusCode() != http.StatusOK {
-		msg := string(rspInvoker.Body)
-		return sendCoreError(ctx, rspInvoker.StatusCode(), msg)
+		return problemdetails.Forward(ctx, rspInvoker.StatusCode(), rspInvoker.Body)
 	}
 
 	rspAPIProviderEnrolmentDetails := *rspInvoker.JSON200
@@ -183,17 +186,51 @@ func (im *InvokerManager) PutOnboardedInvokersOnboardingId(ctx echo.Context, onb
 	return nil
 }
 
+// Modifies an individual API invoker details. Only the attributes present in the request are changed.
 func (im *InvokerManager) ModifyIndApiInvokeEnrolment(ctx echo.Context, onboardingId string) error {
-	return ctx.NoContent(http.StatusNotImplemented)
-}
+	log.Tracef("entering ModifyIndApiInvokeEnrolment onboardingId %s", onboardingId)
+
+	var invokerPatch invokerapi.APIInvokerEnrolmentDetailsPatch
+	errMsg := "Unable to update invoker due to %s"
+	if err := validation.BindMergePatch(ctx, &invokerPatch); err != nil {
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseInvalidMsgFormat, fmt.Sprintf(errMsg, "invalid format for invoker patch"))
+	}
+
+	capifcoreUrl := fmt.Sprintf("%s://%s:%d/api-invoker-management/v1/", im.CapifProtocol, im.CapifIPv4, im.CapifPort)
+	client, err := invokerapi.NewClientWithResponses(capifcoreUrl)
+	if err != nil {
+		return err
+	}
+
+	var (
+		ctxHandler context.Context
+		cancel     context.CancelFunc
+	)
+	ctxHandler, cancel = context.WithCancel(context.Background())
+	defer cancel()
 
-// This function wraps sending of an error in the Error format, and
-// handling the failure to marshal that.
-func sendCoreError(ctx echo.Context, code int, message string) error {
-	pd := common29122.ProblemDetails{
-		Cause:  &message,
-		Status: &code,
+	body, err := json.Marshal(invokerPatch)
+	if err != nil {
+		return err
+	}
+
+	var rspInvoker *invokerapi.ModifyIndApiInvokeEnrolmentResponse
+	rspInvoker, err = client.ModifyIndApiInvokeEnrolmentWithBodyWithResponse(ctxHandler, onboardingId, "application/merge-patch+json", bytes.NewReader(body))
+
+	if err != nil {
+		// Something really bad happened, tell Echo that our handler failed
+		return err
+	}
+
+	if rspInvoker.StatusCode() != http.StatusOK {
+		return problemdetails.Forward(ctx, rspInvoker.StatusCode(), rspInvoker.Body)
 	}
-	err := ctx.JSON(code, pd)
-	return err
+
+	err = ctx.JSON(http.StatusOK, *rspInvoker.JSON200)
+	if err != nil {
+		// Something really bad happened, tell Echo that our handler failed
+		return err
+	}
+
+	return nil
 }
@@ -46,6 +46,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"oransc.org/nonrtric/capifcore"
+	_ "oransc.org/nonrtric/capifcore/problemdetails/problemdetailstest"
 	mockKong "oransc.org/nonrtric/servicemanager/mockkong"
 )
 
@@ -90,7 +91,7 @@ func setupTest() error {
 	mockKongControlPlanePort := parsedMockKongURL.Port()
 
 	eCapifWeb = echo.New()
-	capifcore.RegisterHandlers(eCapifWeb, nil, nil)
+	capifcore.RegisterHandlers(eCapifWeb, nil, nil, nil, nil, false)
 	capifServer = httptest.NewServer(eCapifWeb)
 
 	// Parse the server URL
@@ -351,7 +352,7 @@ func TestOnboardInvoker(t *testing.T) {
 	assert.NoError(t, err, "error unmarshaling response")
 
 	assert.Equal(t, http.StatusForbidden, *problemDetails.Status)
-	assert.Contains(t, *problemDetails.Cause, "already onboarded")
+	assert.Contains(t, *problemDetails.Detail, "already onboarded")
 
 	// Onboard an invoker missing required NotificationDestination, should get 400 with problem details
 	invalidInvoker := invokermanagementapi.APIInvokerEnrolmentDetails{
@@ -366,8 +367,8 @@ func TestOnboardInvoker(t *testing.T) {
 	assert.NoError(t, err, "error unmarshaling response")
 
 	assert.Equal(t, http.StatusBadRequest, *problemDetails.Status)
-	assert.Contains(t, *problemDetails.Cause, "missing")
-	assert.Contains(t, *problemDetails.Cause, "NotificationDestination")
+	assert.Contains(t, *problemDetails.Detail, "missing")
+	assert.Contains(t, *problemDetails.Detail, "NotificationDestination")
 
 	// Onboard an invoker missing required OnboardingInformation.ApiInvokerPublicKey, should get 400 with problem details
 	invalidInvoker = invokermanagementapi.APIInvokerEnrolmentDetails{
@@ -381,8 +382,8 @@ func TestOnboardInvoker(t *testing.T) {
 	assert.NoError(t, err, "error unmarshaling response")
 
 	assert.Equal(t, http.StatusBadRequest, *problemDetails.Status)
-	assert.Contains(t, *problemDetails.Cause, "missing")
-	assert.Contains(t, *problemDetails.Cause, "OnboardingInformation.ApiInvokerPublicKey")
+	assert.Contains(t, *problemDetails.Detail, "missing")
+	assert.Contains(t, *problemDetails.Detail, "OnboardingInformation.ApiInvokerPublicKey")
 }
 
 func TestDeleteInvoker(t *testing.T) {
@@ -439,8 +440,8 @@ func TestUpdateInvoker(t *testing.T) {
 	assert.NoError(t, err, "error unmarshaling response")
 
 	assert.Equal(t, http.StatusBadRequest, *problemDetails.Status)
-	assert.Contains(t, *problemDetails.Cause, "missing")
-	assert.Contains(t, *problemDetails.Cause, "NotificationDestination")
+	assert.Contains(t, *problemDetails.Detail, "missing")
+	assert.Contains(t, *problemDetails.Detail, "NotificationDestination")
 
 	// Update with an invoker missing required OnboardingInformation.ApiInvokerPublicKey, should get 400 with problem details
 	invalidInvoker.NotificationDestination = "http://golang.org/"
@@ -452,8 +453,8 @@ func TestUpdateInvoker(t *testing.T) {
 	assert.NoError(t, err, "error unmarshaling response")
 
 	assert.Equal(t, http.StatusBadRequest, *problemDetails.Status)
-	assert.Contains(t, *problemDetails.Cause, "missing")
-	assert.Contains(t, *problemDetails.Cause, "OnboardingInformation.ApiInvokerPublicKey")
+	assert.Contains(t, *problemDetails.Detail, "missing")
+	assert.Contains(t, *problemDetails.Detail, "OnboardingInformation.ApiInvokerPublicKey")
 
 	// Update with an invoker with other ApiInvokerId than the one provided in the URL, should get 400 with problem details
 	invalidId := "1"
@@ -467,7 +468,22 @@ func TestUpdateInvoker(t *testing.T) {
 	assert.NoError(t, err, "error unmarshaling response")
 
 	assert.Equal(t, http.StatusBadRequest, *problemDetails.Status)
-	assert.Contains(t, *problemDetails.Cause, "APIInvokerEnrolmentDetails ApiInvokerId doesn't match path parameter")
+	assert.Contains(t, *problemDetails.Detail, "APIInvokerEnrolmentDetails ApiInvokerId doesn't match path parameter")
+
+	// Modify only the notification destination of the invoker, should return 200 with the other details unchanged
+	patchedNotifURL := common29122.Uri("http://golang.org/patched")
+	invokerPatch := invokermanagementapi.APIInvokerEnrolmentDetailsPatch{
+		NotificationDestination: &patchedNotifURL,
+	}
+	result = testutil.NewRequest().Patch("/api-invoker-management/v1/onboardedInvokers/"+invokerId).WithJsonBody(invokerPatch).WithContentType("application/merge-patch+json").Go(t, eServiceManager)
+	assert.Equal(t, http.StatusOK, result.Code())
+
+	err = result.UnmarshalBodyToObject(&resultInvoker)
+	assert.NoError(t, err, "error unmarshaling response")
+
+	assert.Equal(t, invokerId, *resultInvoker.ApiInvokerId)
+	assert.Equal(t, patchedNotifURL, resultInvoker.NotificationDestination)
+	assert.Equal(t, newPublicKey, resultInvoker.OnboardingInformation.ApiInvokerPublicKey)
 
 	// Update an invoker that has not been onboarded, should get 404 with problem details
 	missingId := "1"
@@ -479,8 +495,8 @@ func TestUpdateInvoker(t *testing.T) {
 	assert.NoError(t, err, "error unmarshaling response")
 
 	assert.Equal(t, http.StatusNotFound, *problemDetails.Status)
-	assert.Contains(t, *problemDetails.Cause, "not been onboarded")
-	assert.Contains(t, *problemDetails.Cause, "invoker")
+	assert.Contains(t, *problemDetails.Detail, "not been onboarded")
+	assert.Contains(t, *problemDetails.Detail, "invoker")
 	capifCleanUp()
 }
 
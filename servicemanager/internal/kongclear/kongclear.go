@@ -113,7 +113,7 @@ func DeleteRoutes(kongAdminApiUrl string, offset string, tags string) error {
 
 	for _, route := range routes {
 		if areServiceManagerTags(route.Tags) {
-			if err := deleteRoute(kongAdminApiUrl, route.Name); err != nil {
+			if err := DeleteRoute(kongAdminApiUrl, route.Name); err != nil {
 				return err
 			}
 		}
@@ -157,7 +157,7 @@ func DeleteServices(kongAdminApiUrl string, offset string, tags string) error {
 
 	for _, service := range services {
 		if areServiceManagerTags(service.Tags) {
-			if err := deleteService(kongAdminApiUrl, service.Name); err != nil {
+			if err := DeleteService(kongAdminApiUrl, service.Name); err != nil {
 				return err
 			}
 		}
@@ -174,6 +174,59 @@ func DeleteServices(kongAdminApiUrl string, offset string, tags string) error {
 	return nil
 }
 
+// Lists the ServiceManager routes with the provided tags.
+func ListRoutes(kongAdminApiUrl string, tags string) ([]KongRoute, error) {
+	var routes []KongRoute
+	offset := ""
+	for {
+		routesPage, nextOffset, err := listRoutes(kongAdminApiUrl + "routes?" + getListParams(offset, tags).Encode())
+		if err != nil {
+			return nil, err
+		}
+		for _, route := range routesPage {
+			if areServiceManagerTags(route.Tags) {
+				routes = append(routes, route)
+			}
+		}
+		if nextOffset == "" {
+			return routes, nil
+		}
+		offset = nextOffset
+	}
+}
+
+// Lists the ServiceManager services with the provided tags.
+func ListServices(kongAdminApiUrl string, tags string) ([]KongService, error) {
+	var services []KongService
+	offset := ""
+	for {
+		servicesPage, nextOffset, err := listServices(kongAdminApiUrl + "services?" + getListParams(offset, tags).Encode())
+		if err != nil {
+			return nil, err
+		}
+		for _, service := range servicesPage {
+			if areServiceManagerTags(service.Tags) {
+				services = append(services, service)
+			}
+		}
+		if nextOffset == "" {
+			return services, nil
+		}
+		offset = nextOffset
+	}
+}
+
+func getListParams(offset string, tags string) url.Values {
+	params := url.Values{}
+	if offset != "" {
+		params.Add("offset", offset)
+	}
+	if tags != "" {
+		params.Add("tags", tags)
+	}
+	return params
+}
+
 func listRoutes(kongRoutesApiUrl string) ([]KongRoute, string, error) {
 	log.Debugf("List kong routes from %s", kongRoutesApiUrl)
 	client := resty.New()
@@ -260,7 +313,8 @@ func areServiceManagerTags(tags []string) bool {
 	return true
 }
 
-func deleteRoute(kongAdminApiUrl string, routeID string) error {
+// Deletes the Kong route with the provided id or name.
+func DeleteRoute(kongAdminApiUrl string, routeID string) error {
 	log.Debugf("delete kong route %s", routeID)
 	client := resty.New()
 	resp, err := client.R().Delete(kongAdminApiUrl + "routes/" + routeID)
@@ -278,7 +332,8 @@ func deleteRoute(kongAdminApiUrl string, routeID string) error {
 	return nil
 }
 
-func deleteService(kongAdminApiUrl string, serviceID string) error {
+// Deletes the Kong service with the provided id or name.
+func DeleteService(kongAdminApiUrl string, serviceID string) error {
 	log.Debugf("delete kong service %s", serviceID)
 	client := resty.New()
 	resp, err := client.R().Delete(kongAdminApiUrl + "services/" + serviceID)
@@ -295,3 +350,33 @@ func deleteService(kongAdminApiUrl string, serviceID string) error {
 	log.Infof("kong service %s deleted successfully", serviceID)
 	return nil
 }
+
+// Gives the Kong route with the provided id a new name.
+func RenameRoute(kongAdminApiUrl string, routeID string, name string) error {
+	return rename(kongAdminApiUrl+"routes/"+routeID, name)
+}
+
+// Gives the Kong service with the provided id a new name.
+func RenameService(kongAdminApiUrl string, serviceID string, name string) error {
+	return rename(kongAdminApiUrl+"services/"+serviceID, name)
+}
+
+func rename(kongEntityUrl string, name string) error {
+	log.Debugf("rename kong entity %s to %s", kongEntityUrl, name)
+	client := resty.New()
+	resp, err := client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(map[string]string{"name": name}).
+		Patch(kongEntityUrl)
+
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		err := fmt.Errorf("failed to rename %s, status code %d", kongEntityUrl, resp.StatusCode())
+		return err
+	}
+
+	return nil
+}
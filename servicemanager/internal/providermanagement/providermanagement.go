@@ -21,7 +21,9 @@
 package providermanagement
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"path"
@@ -29,6 +31,8 @@ import (
 	echo "github.com/labstack/echo/v4"
 	log "github.com/sirupsen/logrus"
 
+	"oransc.org/nonrtric/capifcore/problemdetails"
+	"oransc.org/nonrtric/capifcore/validation"
 	"oransc.org/nonrtric/servicemanager/internal/common29122"
 	provapi "oransc.org/nonrtric/servicemanager/internal/providermanagementapi"
 )
@@ -55,7 +59,7 @@ func (pm *ProviderManager) PostRegistrations(ctx echo.Context) error {
 	newProvider, err := getProviderFromRequest(ctx)
 	if err != nil {
 		errMsg := "Unable to register provider due to %s"
-		return sendCoreError(ctx, http.StatusBadRequest, fmt.Sprintf(errMsg, err))
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseInvalidMsgFormat, fmt.Sprintf(errMsg, err))
 	}
 
 	capifcoreUrl := fmt.Sprintf("%s://%s:%d/api-provider-management/v1/", pm.CapifProtocol, pm.CapifIPv4, pm.CapifPort)
@@ -79,7 +83,7 @@ func (pm *ProviderManager) PostRegistrations(ctx echo.Context) error {
 	if (err != nil) || (rspProvider.StatusCode() != http.StatusCreated) {
 		msg := string(rspProvider.Body)
 		log.Errorf("error on PostRegistrationsWithResponse %s", msg)
-		return sendCoreError(ctx, rspProvider.StatusCode(), msg)
+		return problemdetails.Forward(ctx, rspProvider.StatusCode(), rspProvider.Body)
 	}
 
 	rspAPIProviderEnrolmentDetails := *rspProvider.JSON201
@@ -116,7 +120,7 @@ func (pm *ProviderManager) DeleteRegistrationsRegistrationId(ctx echo.Context, r
 	if (err != nil) || (rspProvider.StatusCode() != http.StatusNoContent) {
 		msg := string(rspProvider.Body)
 		log.Errorf("error on DeleteRegistrationsRegistrationIdWithResponse %s", msg)
-		return sendCoreError(ctx, rspProvider.StatusCode(), msg)
+		return problemdetails.Forward(ctx, rspProvider.StatusCode(), rspProvider.Body)
 	}
 	return ctx.NoContent(http.StatusNoContent)
 }
@@ -128,7 +132,7 @@ func (pm *ProviderManager) PutRegistrationsRegistrationId(ctx echo.Context, regi
 	if err != nil {
 		msg := "Unable to register provider due to %s"
 		log.Errorf("error on getProviderFromRequest %s", msg)
-		return sendCoreError(ctx, http.StatusBadRequest, fmt.Sprintf(msg, err))
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseInvalidMsgFormat, fmt.Sprintf(msg, err))
 	}
 
 	capifcoreUrl := fmt.Sprintf("%s://%s:%d/api-provider-management/v1/", pm.CapifProtocol, pm.CapifIPv4, pm.CapifPort)
@@ -152,13 +156,13 @@ func (pm *ProviderManager) PutRegistrationsRegistrationId(ctx echo.Context, regi
 	if err != nil {
 		msg := err.Error()
 		log.Errorf("error on PutRegistrationsRegistrationIdWithResponse %s", msg)
-		return sendCoreError(ctx, http.StatusInternalServerError, msg)
+		return problemdetails.Send(ctx, http.StatusInternalServerError, problemdetails.CauseSystemFailure, msg)
 	}
 
 	if rspProvider.StatusCode() != http.StatusOK {
 		msg := string(rspProvider.Body)
 		log.Errorf("error on PutRegistrationsRegistrationIdWithResponse %s", msg)
-		return sendCoreError(ctx, rspProvider.StatusCode(), msg)
+		return problemdetails.Forward(ctx, rspProvider.StatusCode(), rspProvider.Body)
 	}
 
 	rspAPIProviderEnrolmentDetails := *rspProvider.JSON200
@@ -173,8 +177,54 @@ func (pm *ProviderManager) PutRegistrationsRegistrationId(ctx echo.Context, regi
 	return nil
 }
 
+// Modifies an existing API provider domain. Only the attributes present in the request are changed.
 func (pm *ProviderManager) ModifyIndApiProviderEnrolment(ctx echo.Context, registrationId string) error {
-	return ctx.NoContent(http.StatusNotImplemented)
+	log.Tracef("entering ModifyIndApiProviderEnrolment registrationId %s", registrationId)
+
+	var providerPatch provapi.APIProviderEnrolmentDetailsPatch
+	if err := validation.BindMergePatch(ctx, &providerPatch); err != nil {
+		errMsg := "Unable to update provider due to %s"
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseInvalidMsgFormat, fmt.Sprintf(errMsg, "invalid format for provider patch"))
+	}
+
+	capifcoreUrl := fmt.Sprintf("%s://%s:%d/api-provider-management/v1/", pm.CapifProtocol, pm.CapifIPv4, pm.CapifPort)
+	client, err := provapi.NewClientWithResponses(capifcoreUrl)
+	if err != nil {
+		return err
+	}
+
+	var (
+		ctxHandler context.Context
+		cancel     context.CancelFunc
+	)
+	ctxHandler, cancel = context.WithCancel(context.Background())
+	defer cancel()
+
+	body, err := json.Marshal(providerPatch)
+	if err != nil {
+		return err
+	}
+
+	var rspProvider *provapi.ModifyIndApiProviderEnrolmentResponse
+	rspProvider, err = client.ModifyIndApiProviderEnrolmentWithBodyWithResponse(ctxHandler, registrationId, "application/merge-patch+json", bytes.NewReader(body))
+
+	if err != nil {
+		msg := err.Error()
+		log.Errorf("error on ModifyIndApiProviderEnrolmentWithBodyWithResponse %s", msg)
+		return problemdetails.Send(ctx, http.StatusInternalServerError, problemdetails.CauseSystemFailure, msg)
+	}
+
+	if rspProvider.StatusCode() != http.StatusOK {
+		msg := string(rspProvider.Body)
+		log.Errorf("error on ModifyIndApiProviderEnrolmentWithBodyWithResponse %s", msg)
+		return problemdetails.Forward(ctx, rspProvider.StatusCode(), rspProvider.Body)
+	}
+
+	if err := ctx.JSON(http.StatusOK, *rspProvider.JSON200); err != nil {
+		// Something really bad happened, tell Echo that our handler failed
+		return err
+	}
+	return nil
 }
 
 func getProviderFromRequest(ctx echo.Context) (provapi.APIProviderEnrolmentDetails, error) {
@@ -185,14 +235,3 @@ func getProviderFromRequest(ctx echo.Context) (provapi.APIProviderEnrolmentDetai
 	}
 	return updatedProvider, nil
 }
-
-// This function wraps sending of an error in the Error format, and
-// handling the failure to marshal that.
-func sendCoreError(ctx echo.Context, code int, message string) error {
-	pd := common29122.ProblemDetails{
-		Cause:  &message,
-		Status: &code,
-	}
-	err := ctx.JSON(code, pd)
-	return err
-}
@@ -44,6 +44,8 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"oransc.org/nonrtric/capifcore"
+	"oransc.org/nonrtric/capifcore/problemdetails"
+	_ "oransc.org/nonrtric/capifcore/problemdetails/problemdetailstest"
 	"oransc.org/nonrtric/servicemanager/mockkong"
 )
 
@@ -97,7 +99,7 @@ func setupTest() error {
 	mockKongControlPlanePort := parsedMockKongURL.Port()
 
 	eCapifWeb = echo.New()
-	capifcore.RegisterHandlers(eCapifWeb, nil, nil)
+	capifcore.RegisterHandlers(eCapifWeb, nil, nil, nil, nil, false)
 	capifServer = httptest.NewServer(eCapifWeb)
 
 	// Parse the server URL
@@ -246,7 +248,9 @@ func TestRegisterValidProvider(t *testing.T) {
 		err = result.UnmarshalBodyToObject(&errorObj)
 		assert.NoError(t, err, "error unmarshaling response")
 		assert.Equal(t, http.StatusForbidden, *errorObj.Status)
-		assert.Contains(t, *errorObj.Cause, "already registered")
+		assert.Equal(t, problemdetails.CauseModificationNotAllowed, *errorObj.Cause)
+		assert.Equal(t, common29122.Uri("/api-provider-management/v1/registrations"), *errorObj.Instance)
+		assert.Contains(t, *errorObj.Detail, "already registered")
 	}
 }
 
@@ -284,6 +288,30 @@ func TestUpdateValidProviderWithNewFunction(t *testing.T) {
 	assert.Empty(t, resultProvider.FailReason)
 }
 
+func TestModifyProvider(t *testing.T) {
+	// Only change the domain info
+	newDomainInfo := "Patched domain info"
+	providerPatch := provapi.APIProviderEnrolmentDetailsPatch{
+		ApiProvDomInfo: &newDomainInfo,
+	}
+
+	result := testutil.NewRequest().Patch("/api-provider-management/v1/registrations/"+domainID).WithJsonBody(providerPatch).WithContentType("application/merge-patch+json").Go(t, eServiceManager)
+
+	var resultProvider provapi.APIProviderEnrolmentDetails
+	assert.Equal(t, http.StatusOK, result.Code())
+
+	err := result.UnmarshalBodyToObject(&resultProvider)
+	assert.NoError(t, err, "error unmarshaling response")
+
+	assert.Equal(t, newDomainInfo, *resultProvider.ApiProvDomInfo)
+	assert.Len(t, *resultProvider.ApiProvFuncs, 3)
+
+	// Modify a provider that is not registered
+	result = testutil.NewRequest().Patch("/api-provider-management/v1/registrations/unknownDomainId").WithJsonBody(providerPatch).WithContentType("application/merge-patch+json").Go(t, eServiceManager)
+
+	assert.Equal(t, http.StatusNotFound, result.Code())
+}
+
 func TestDeleteProvider(t *testing.T) {
 	provider := getProvider()
 	provider.ApiProvDomId = &domainID
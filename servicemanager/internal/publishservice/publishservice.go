@@ -21,7 +21,9 @@
 package publishservice
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"path"
@@ -29,6 +31,8 @@ import (
 	echo "github.com/labstack/echo/v4"
 	log "github.com/sirupsen/logrus"
 
+	"oransc.org/nonrtric/capifcore/problemdetails"
+	"oransc.org/nonrtric/capifcore/validation"
 	"oransc.org/nonrtric/servicemanager/internal/common29122"
 	publishapi "oransc.org/nonrtric/servicemanager/internal/publishserviceapi"
 )
@@ -109,13 +113,13 @@ func (ps *PublishService) PostApfIdServiceApis(ctx echo.Context, apfId string) e
 	if err != nil {
 		msg := err.Error()
 		log.Errorf("PostApfIdServiceApis, error on RegisterKong %s", msg)
-		return sendCoreError(ctx, statusCode, msg)
+		return problemdetails.Send(ctx, statusCode, problemdetails.CauseForStatus(statusCode), msg)
 	}
 	if  statusCode != http.StatusCreated {
 		// We can return with http.StatusForbidden if there is a http.StatusConflict detected by Kong
 		msg := "error detected by Kong"
 		log.Errorf(msg)
-		return sendCoreError(ctx, statusCode, msg)
+		return problemdetails.Send(ctx, statusCode, problemdetails.CauseForStatus(statusCode), msg)
 	}
 
 	bodyServiceAPIDescription := publishapi.PostApfIdServiceApisJSONRequestBody(newServiceAPIDescription)
@@ -127,7 +131,7 @@ func (ps *PublishService) PostApfIdServiceApis(ctx echo.Context, apfId string) e
 	if err != nil {
 		msg := err.Error()
 		log.Errorf("error on PostApfIdServiceApisWithResponse %s", msg)
-		return sendCoreError(ctx, http.StatusInternalServerError, msg)
+		return problemdetails.Send(ctx, http.StatusInternalServerError, problemdetails.CauseSystemFailure, msg)
 	}
 
 	if rsp.StatusCode() != http.StatusCreated {
@@ -137,7 +141,7 @@ func (ps *PublishService) PostApfIdServiceApis(ctx echo.Context, apfId string) e
 		if rsp.StatusCode() == http.StatusForbidden || rsp.StatusCode() == http.StatusBadRequest {
 			newServiceAPIDescription.UnregisterKong(ps.KongDomain, ps.KongProtocol, ps.KongControlPlaneIPv4, ps.KongControlPlanePort)
 		}
-		return sendCoreError(ctx, rsp.StatusCode(), msg)
+		return problemdetails.Forward(ctx, rsp.StatusCode(), rsp.Body)
 	}
 
 	rspServiceAPIDescription := *rsp.JSON201
@@ -179,13 +183,13 @@ func (ps *PublishService) DeleteApfIdServiceApisServiceApiId(ctx echo.Context, a
 	if err != nil {
 		msg := err.Error()
 		log.Errorf("error on GetApfIdServiceApisServiceApiIdWithResponse %s", msg)
-		return sendCoreError(ctx, http.StatusInternalServerError, msg)
+		return problemdetails.Send(ctx, http.StatusInternalServerError, problemdetails.CauseSystemFailure, msg)
 	}
 
 	statusCode := rsp.StatusCode()
 	if statusCode != http.StatusOK {
 		log.Debugf("GetApfIdServiceApisServiceApiIdWithResponse status %d", statusCode)
-		return ctx.NoContent(statusCode)
+		return problemdetails.Forward(ctx, statusCode, rsp.Body)
 	}
 
 	rspServiceAPIDescription := *rsp.JSON200
@@ -194,7 +198,7 @@ func (ps *PublishService) DeleteApfIdServiceApisServiceApiId(ctx echo.Context, a
 	if (err != nil) || (statusCode != http.StatusNoContent) {
 		msg := err.Error()
 		log.Errorf("error on UnregisterKong %s", msg)
-		return sendCoreError(ctx, statusCode, msg)
+		return problemdetails.Send(ctx, statusCode, problemdetails.CauseForStatus(statusCode), msg)
 	}
 
 	log.Trace("call DeleteApfIdServiceApisServiceApiIdWithResponse")
@@ -203,7 +207,7 @@ func (ps *PublishService) DeleteApfIdServiceApisServiceApiId(ctx echo.Context, a
 	if err != nil {
 		msg := err.Error()
 		log.Errorf("error on DeleteApfIdServiceApisServiceApiIdWithResponse %s", msg)
-		return sendCoreError(ctx, http.StatusInternalServerError, msg)
+		return problemdetails.Send(ctx, http.StatusInternalServerError, problemdetails.CauseSystemFailure, msg)
 	}
 
 	return ctx.NoContent(http.StatusNoContent)
@@ -232,14 +236,14 @@ func (ps *PublishService) GetApfIdServiceApis(ctx echo.Context, apfId string) er
 	if err != nil {
 		msg := err.Error()
 		log.Errorf("error on GetApfIdServiceApisWithResponse %s", msg)
-		return sendCoreError(ctx, http.StatusInternalServerError, msg)
+		return problemdetails.Send(ctx, http.StatusInternalServerError, problemdetails.CauseSystemFailure, msg)
 	}
 
 	if rsp.StatusCode() != http.StatusOK {
 		msg := string(rsp.Body)
 		log.Errorf("GetApfIdServiceApisWithResponse status %d", rsp.StatusCode())
 		log.Errorf("GetApfIdServiceApisWithResponse error %s", msg)
-		return sendCoreError(ctx, rsp.StatusCode(), msg)
+		return problemdetails.Forward(ctx, rsp.StatusCode(), rsp.Body)
 	}
 
 	rspServiceAPIDescriptions := *rsp.JSON200
@@ -273,12 +277,12 @@ func (ps *PublishService) GetApfIdServiceApisServiceApiId(ctx echo.Context, apfI
 	if err != nil {
 		msg := err.Error()
 		log.Errorf("error on GetApfIdServiceApisServiceApiIdWithResponse %s", msg)
-		return sendCoreError(ctx, http.StatusInternalServerError, msg)
+		return problemdetails.Send(ctx, http.StatusInternalServerError, problemdetails.CauseSystemFailure, msg)
 	}
 
 	statusCode := rsp.StatusCode()
 	if statusCode != http.StatusOK {
-		return ctx.NoContent(statusCode)
+		return problemdetails.Forward(ctx, statusCode, rsp.Body)
 	}
 
 	rspServiceAPIDescription := *rsp.JSON200
@@ -291,8 +295,130 @@ func (ps *PublishService) GetApfIdServiceApisServiceApiId(ctx echo.Context, apfI
 }
 
 // Modify an existing published service API.
+// When the AEF profiles are patched, Kong services and routes for the patched profiles are registered before the patch
+// is forwarded to the CAPIF core. The services and routes of the published profiles are removed once the patch has been
+// applied, and restored if it fails.
 func (ps *PublishService) ModifyIndAPFPubAPI(ctx echo.Context, apfId string, serviceApiId string) error {
-	return ctx.NoContent(http.StatusNotImplemented)
+	log.Tracef("entering ModifyIndAPFPubAPI apfId %s serviceApiId %s", apfId, serviceApiId)
+
+	var servicePatch publishapi.ServiceAPIDescriptionPatch
+	if err := validation.BindMergePatch(ctx, &servicePatch); err != nil {
+		return problemdetails.Send(ctx, http.StatusBadRequest, problemdetails.CauseInvalidMsgFormat, "invalid format for service patch")
+	}
+
+	capifcoreUrl := fmt.Sprintf("%s://%s:%d/published-apis/v1/", ps.CapifProtocol, ps.CapifIPv4, ps.CapifPort)
+	client, err := publishapi.NewClientWithResponses(capifcoreUrl)
+	if err != nil {
+		return err
+	}
+
+	var (
+		ctxHandler context.Context
+		cancel     context.CancelFunc
+	)
+	ctxHandler, cancel = context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		patchedServiceDescription *publishapi.ServiceAPIDescription
+		previousRegistration      *publishapi.KongRegistration
+	)
+	if servicePatch.AefProfiles != nil {
+		var rspPublished *publishapi.GetApfIdServiceApisServiceApiIdResponse
+		rspPublished, err = client.GetApfIdServiceApisServiceApiIdWithResponse(ctxHandler, apfId, serviceApiId)
+		if err != nil {
+			msg := err.Error()
+			log.Errorf("error on GetApfIdServiceApisServiceApiIdWithResponse %s", msg)
+			return problemdetails.Send(ctx, http.StatusInternalServerError, problemdetails.CauseSystemFailure, msg)
+		}
+		if rspPublished.StatusCode() != http.StatusOK {
+			log.Debugf("GetApfIdServiceApisServiceApiIdWithResponse status %d", rspPublished.StatusCode())
+			return problemdetails.Send(ctx, rspPublished.StatusCode(), problemdetails.CauseContextNotFound, "service must be published before updating it")
+		}
+
+		publishedServiceDescription := *rspPublished.JSON200
+		var statusCode int
+		patchedServiceDescription, previousRegistration, statusCode, err = ps.resyncKong(apfId, publishedServiceDescription, *servicePatch.AefProfiles)
+		if err != nil {
+			return problemdetails.Send(ctx, statusCode, problemdetails.CauseForStatus(statusCode), err.Error())
+		}
+		servicePatch.AefProfiles = patchedServiceDescription.AefProfiles
+	}
+
+	body, err := json.Marshal(servicePatch)
+	if err != nil {
+		ps.restoreKong(previousRegistration, patchedServiceDescription)
+		return err
+	}
+
+	var rsp *publishapi.ModifyIndAPFPubAPIResponse
+	rsp, err = client.ModifyIndAPFPubAPIWithBodyWithResponse(ctxHandler, apfId, serviceApiId, "application/merge-patch+json", bytes.NewReader(body))
+
+	if err != nil {
+		msg := err.Error()
+		log.Errorf("error on ModifyIndAPFPubAPIWithBodyWithResponse %s", msg)
+		ps.restoreKong(previousRegistration, patchedServiceDescription)
+		return problemdetails.Send(ctx, http.StatusInternalServerError, problemdetails.CauseSystemFailure, msg)
+	}
+
+	if rsp.StatusCode() != http.StatusOK {
+		log.Errorf("ModifyIndAPFPubAPIWithBodyWithResponse status code %d", rsp.StatusCode())
+		ps.restoreKong(previousRegistration, patchedServiceDescription)
+		return problemdetails.Forward(ctx, rsp.StatusCode(), rsp.Body)
+	}
+
+	if previousRegistration != nil {
+		if err = previousRegistration.Remove(); err != nil {
+			log.Errorf("error removing the previous Kong services and routes of %s: %v", serviceApiId, err)
+		}
+	}
+
+	err = ctx.JSON(http.StatusOK, *rsp.JSON200)
+	if err != nil {
+		return err // Tell Echo that our handler failed
+	}
+	return nil
+}
+
+// Sets the Kong services and routes of the published service aside, and registers new ones for the patched AEF
+// profiles. Returns the published service with the patched AEF profiles, pointing at the Kong data plane, and the
+// previous registration. If the new services and routes cannot be registered, the previous ones are restored.
+func (ps *PublishService) resyncKong(apfId string, publishedServiceDescription publishapi.ServiceAPIDescription, patchedProfiles []publishapi.AefProfile) (*publishapi.ServiceAPIDescription, *publishapi.KongRegistration, int, error) {
+	previousRegistration, statusCode, err := publishedServiceDescription.SetKongRegistrationAside(ps.KongProtocol, ps.KongControlPlaneIPv4, ps.KongControlPlanePort)
+	if err != nil {
+		log.Errorf("resyncKong, error on SetKongRegistrationAside %s", err.Error())
+		return nil, nil, statusCode, err
+	}
+
+	patchedServiceDescription := publishedServiceDescription
+	patchedServiceDescription.AefProfiles = &patchedProfiles
+	statusCode, err = patchedServiceDescription.RegisterKong(
+		ps.KongDomain,
+		ps.KongProtocol,
+		ps.KongControlPlaneIPv4,
+		ps.KongControlPlanePort,
+		ps.KongDataPlaneIPv4,
+		ps.KongDataPlanePort,
+		apfId)
+	if err == nil && statusCode != http.StatusCreated {
+		err = fmt.Errorf("error detected by Kong")
+	}
+	if err != nil {
+		log.Errorf("resyncKong, error on RegisterKong %s", err.Error())
+		ps.restoreKong(previousRegistration, &patchedServiceDescription)
+		return nil, nil, statusCode, err
+	}
+	return &patchedServiceDescription, previousRegistration, statusCode, nil
+}
+
+// Removes the Kong services and routes registered for the patched service, and restores the previous registration.
+func (ps *PublishService) restoreKong(previousRegistration *publishapi.KongRegistration, patchedServiceDescription *publishapi.ServiceAPIDescription) {
+	if previousRegistration == nil {
+		return
+	}
+	if err := previousRegistration.Restore(patchedServiceDescription); err != nil {
+		log.Errorf("error restoring the previous Kong services and routes %v", err)
+	}
 }
 
 // Update a published service API.
@@ -325,7 +451,7 @@ func (ps *PublishService) PutApfIdServiceApisServiceApiId(ctx echo.Context, apfI
 	if err != nil {
 		msg := err.Error()
 		log.Errorf("error on PutApfIdServiceApisServiceApiIdWithResponse %s", msg)
-		return sendCoreError(ctx, http.StatusInternalServerError, msg)
+		return problemdetails.Send(ctx, http.StatusInternalServerError, problemdetails.CauseSystemFailure, msg)
 	}
 
 	if rsp.StatusCode() != http.StatusOK {
@@ -333,8 +459,7 @@ func (ps *PublishService) PutApfIdServiceApisServiceApiId(ctx echo.Context, apfI
 		if rsp.StatusCode() == http.StatusBadRequest {
 			updatedServiceDescription.UnregisterKong(ps.KongDomain, ps.KongProtocol,ps.KongControlPlaneIPv4, ps.KongControlPlanePort)
 		}
-		msg := string(rsp.Body)
-		return sendCoreError(ctx, rsp.StatusCode(), msg)
+		return problemdetails.Forward(ctx, rsp.StatusCode(), rsp.Body)
 	}
 
 	rspServiceAPIDescription := *rsp.JSON200
@@ -359,14 +484,3 @@ func getServiceFromRequest(ctx echo.Context) (publishapi.ServiceAPIDescription,
 	}
 	return updatedServiceDescription, nil
 }
-
-// This function wraps sending of an error in the Error format, and
-// handling the failure to marshal that.
-func sendCoreError(ctx echo.Context, code int, message string) error {
-	pd := common29122.ProblemDetails{
-		Cause:  &message,
-		Status: &code,
-	}
-	err := ctx.JSON(code, pd)
-	return err
-}
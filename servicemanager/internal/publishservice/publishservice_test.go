@@ -45,6 +45,7 @@ import (
 	publishapi "oransc.org/nonrtric/servicemanager/internal/publishserviceapi"
 
 	"oransc.org/nonrtric/capifcore"
+	_ "oransc.org/nonrtric/capifcore/problemdetails/problemdetailstest"
 	mockKong "oransc.org/nonrtric/servicemanager/mockkong"
 )
 
@@ -89,7 +90,7 @@ func setupTest() error {
 	mockKongControlPlanePort := parsedMockKongURL.Port()
 
 	eCapifWeb = echo.New()
-	capifcore.RegisterHandlers(eCapifWeb, nil, nil)
+	capifcore.RegisterHandlers(eCapifWeb, nil, nil, nil, nil, false)
 	capifServer = httptest.NewServer(eCapifWeb)
 
 	// Parse the server URL
@@ -260,8 +261,8 @@ func TestPostUnpublishedServiceWithUnregisteredPublisher(t *testing.T) {
 	err := result.UnmarshalBodyToObject(&resultError)
 	assert.NoError(t, err, "error unmarshaling response")
 
-	assert.Contains(t, *resultError.Cause, apfId)
-	assert.Contains(t, *resultError.Cause, "api is only available for publishers")
+	assert.Contains(t, *resultError.Detail, apfId)
+	assert.Contains(t, *resultError.Detail, "api is only available for publishers")
 
 	aefId := "AEF_id_rApp_Kong_as_AEF"
 	namespace := "namespace"
@@ -294,8 +295,8 @@ func TestPostUnpublishedServiceWithUnregisteredPublisher(t *testing.T) {
 	err = result.UnmarshalBodyToObject(&resultError)
 	assert.NoError(t, err, "error unmarshaling response")
 
-	assert.Contains(t, *resultError.Cause, apfId)
-	assert.Contains(t, *resultError.Cause, "Unable to publish the service due to api is only available for publishers")
+	assert.Contains(t, *resultError.Detail, apfId)
+	assert.Contains(t, *resultError.Detail, "Unable to publish the service due to api is only available for publishers")
 }
 
 func TestRegisterValidProvider(t *testing.T) {
@@ -352,7 +353,7 @@ func TestPublishUnpublishServiceMissingInterface(t *testing.T) {
 	err = result.UnmarshalJsonToObject(&resultError)
 	assert.NoError(t, err, "error unmarshaling response")
 
-	assert.Contains(t, *resultError.Cause, "cannot read InterfaceDescriptions")
+	assert.Contains(t, *resultError.Detail, "cannot read InterfaceDescriptions")
 }
 
 
@@ -519,6 +520,79 @@ func TestPublishUnpublishVersionId(t *testing.T) {
 	assert.Equal(t, "~/helloworld-v1-id/port-30951-hash-04478a3a-d0ef-5a05-a575-db5ee2e33403/helloworld/v1/(?<helloworld-id>[a-zA-Z0-9]+([-_][a-zA-Z0-9]+)*)", resource.Uri)
 }
 
+func TestModifyService(t *testing.T) {
+	apfId := "APF_id_rApp_Kong_as_APF"
+	aefId := "AEF_id_rApp_Kong_as_AEF"
+
+	myEnv, myPorts, err := mockConfigReader.ReadDotEnv()
+	assert.Nil(t, err, "error reading env file")
+
+	testServiceIpv4 := common29122.Ipv4Addr(myEnv["TEST_SERVICE_IPV4"])
+	testServicePort := common29122.Port(myPorts["TEST_SERVICE_PORT"])
+
+	apiName := "helloworld-modify"
+	newServiceDescription := getServiceAPIDescription(aefId, apiName, "description", testServiceIpv4, testServicePort, "v1", "helloworld", "/helloworld")
+
+	// Publish a service for provider
+	result := testutil.NewRequest().Post("/published-apis/v1/"+apfId+"/service-apis").WithJsonBody(newServiceDescription).Go(t, eServiceManager)
+	assert.Equal(t, http.StatusCreated, result.Code())
+	newApiId := "api_id_" + apiName
+
+	// Only change the description, the AEF profiles are left as published
+	newDescription := "new description"
+	servicePatch := publishapi.ServiceAPIDescriptionPatch{
+		Description: &newDescription,
+	}
+	result = testutil.NewRequest().Patch("/published-apis/v1/"+apfId+"/service-apis/"+newApiId).WithJsonBody(servicePatch).WithContentType("application/merge-patch+json").Go(t, eServiceManager)
+	assert.Equal(t, http.StatusOK, result.Code())
+
+	var resultService publishapi.ServiceAPIDescription
+	err = result.UnmarshalJsonToObject(&resultService)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, newDescription, *resultService.Description)
+	resource := (*(*resultService.AefProfiles)[0].Versions[0].Resources)[0]
+	assert.Equal(t, "/helloworld-modify/port-30951-hash-04478a3a-d0ef-5a05-a575-db5ee2e33403/helloworld", resource.Uri)
+
+	// Change the resource, the Kong service and route are replaced
+	patchedProfiles := *getServiceAPIDescription(aefId, apiName, "description", testServiceIpv4, testServicePort, "v1", "hellomars", "/hellomars").AefProfiles
+	servicePatch = publishapi.ServiceAPIDescriptionPatch{
+		AefProfiles: &patchedProfiles,
+	}
+	result = testutil.NewRequest().Patch("/published-apis/v1/"+apfId+"/service-apis/"+newApiId).WithJsonBody(servicePatch).WithContentType("application/merge-patch+json").Go(t, eServiceManager)
+	assert.Equal(t, http.StatusOK, result.Code())
+
+	err = result.UnmarshalJsonToObject(&resultService)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, newDescription, *resultService.Description)
+	aefProfile := (*resultService.AefProfiles)[0]
+	interfaceDescription := (*aefProfile.InterfaceDescriptions)[0]
+	assert.Equal(t, common29122.Ipv4Addr(myEnv["KONG_DATA_PLANE_IPV4"]), *interfaceDescription.Ipv4Addr)
+	assert.Equal(t, common29122.Port(myPorts["KONG_DATA_PLANE_PORT"]), *interfaceDescription.Port)
+	resource = (*aefProfile.Versions[0].Resources)[0]
+	assert.Equal(t, "hellomars", resource.ResourceName)
+	assert.Equal(t, "/helloworld-modify/port-30951-hash-04478a3a-d0ef-5a05-a575-db5ee2e33403/hellomars", resource.Uri)
+
+	// Patch the profiles with an AEF that is not registered, the CAPIF core rejects the patch and the published
+	// resource is kept
+	unregisteredProfiles := *getServiceAPIDescription("unregisteredAefId", apiName, "description", testServiceIpv4, testServicePort, "v1", "hellomars", "/hellomars").AefProfiles
+	result = testutil.NewRequest().Patch("/published-apis/v1/"+apfId+"/service-apis/"+newApiId).WithJsonBody(publishapi.ServiceAPIDescriptionPatch{AefProfiles: &unregisteredProfiles}).WithContentType("application/merge-patch+json").Go(t, eServiceManager)
+	assert.Equal(t, http.StatusBadRequest, result.Code())
+
+	result = testutil.NewRequest().Get("/published-apis/v1/"+apfId+"/service-apis/"+newApiId).Go(t, eServiceManager)
+	assert.Equal(t, http.StatusOK, result.Code())
+	err = result.UnmarshalJsonToObject(&resultService)
+	assert.NoError(t, err, "error unmarshaling response")
+	assert.Equal(t, aefId, (*resultService.AefProfiles)[0].AefId)
+
+	// Modify a service that has not been published
+	result = testutil.NewRequest().Patch("/published-apis/v1/"+apfId+"/service-apis/unknownApiId").WithJsonBody(servicePatch).WithContentType("application/merge-patch+json").Go(t, eServiceManager)
+	assert.Equal(t, http.StatusNotFound, result.Code())
+
+	// Unpublish the service
+	result = testutil.NewRequest().Delete("/published-apis/v1/"+apfId+"/service-apis/"+newApiId).Go(t, eServiceManager)
+	assert.Equal(t, http.StatusNoContent, result.Code())
+}
+
 func TestPublishUnpublishServiceNoVersionWithId(t *testing.T) {
 	apfId := "APF_id_rApp_Kong_as_APF"
 
@@ -687,7 +687,7 @@ func (sd *ServiceAPIDescription) deleteKongRoutes(kongControlPlaneURL string) (i
 	profiles := *sd.AefProfiles
 	for _, profile := range profiles {
 		log.Debugf("deleteKongRoutes, AefId %s, ApiId %s", profile.AefId, *sd.ApiId)
-		tagToSearch := "aefId: " + profile.AefId + "," + "apiId: " + *sd.ApiId
+		tagToSearch := sd.getKongTagToSearch(profile)
 
 		err := kongclear.DeleteRoutes(kongControlPlaneURL, "", tagToSearch)
 		if err != nil {
@@ -703,3 +703,145 @@ func (sd *ServiceAPIDescription) deleteKongRoutes(kongControlPlaneURL string) (i
 	}
 	return http.StatusNoContent, nil
 }
+
+func (sd *ServiceAPIDescription) getKongTagToSearch(profile AefProfile) string {
+	return "aefId: " + profile.AefId + "," + "apiId: " + *sd.ApiId
+}
+
+// The Kong services and routes of a published service, set aside while the service is registered in Kong again.
+type KongRegistration struct {
+	kongControlPlaneURL string
+	services            []kongclear.KongService
+	routes              []kongclear.KongRoute
+}
+
+// Sets the Kong services and routes of the service aside, so that the service can be registered in Kong again while the
+// current services and routes keep serving requests. They are renamed to their ids, as a new registration uses the same
+// names for the resources that are kept. The previous registration is then removed with Remove, or taken back with
+// Restore.
+func (sd *ServiceAPIDescription) SetKongRegistrationAside(kongProtocol string, kongControlPlaneIPv4 common29122.Ipv4Addr, kongControlPlanePort common29122.Port) (*KongRegistration, int, error) {
+	log.Trace("entering SetKongRegistrationAside")
+
+	registration := &KongRegistration{
+		kongControlPlaneURL: fmt.Sprintf("%s://%s:%d/", kongProtocol, kongControlPlaneIPv4, kongControlPlanePort),
+	}
+	if sd.AefProfiles == nil {
+		return registration, http.StatusOK, nil
+	}
+	for _, profile := range *sd.AefProfiles {
+		tagToSearch := sd.getKongTagToSearch(profile)
+		routes, err := kongclear.ListRoutes(registration.kongControlPlaneURL, tagToSearch)
+		if err != nil {
+			log.Errorf("error listing Kong routes for AefId %s, ApiId %s: %v", profile.AefId, *sd.ApiId, err)
+			return nil, http.StatusInternalServerError, err
+		}
+		registration.routes = append(registration.routes, routes...)
+
+		services, err := kongclear.ListServices(registration.kongControlPlaneURL, tagToSearch)
+		if err != nil {
+			log.Errorf("error listing Kong services for AefId %s, ApiId %s: %v", profile.AefId, *sd.ApiId, err)
+			return nil, http.StatusInternalServerError, err
+		}
+		registration.services = append(registration.services, services...)
+	}
+
+	for _, route := range registration.routes {
+		if err := kongclear.RenameRoute(registration.kongControlPlaneURL, route.ID, route.ID); err != nil {
+			log.Errorf("error setting Kong route %s aside: %v", route.Name, err)
+			registration.restoreNames()
+			return nil, http.StatusInternalServerError, err
+		}
+	}
+	for _, service := range registration.services {
+		if err := kongclear.RenameService(registration.kongControlPlaneURL, service.ID, service.ID); err != nil {
+			log.Errorf("error setting Kong service %s aside: %v", service.Name, err)
+			registration.restoreNames()
+			return nil, http.StatusInternalServerError, err
+		}
+	}
+	return registration, http.StatusOK, nil
+}
+
+// Removes the Kong services and routes of the previous registration.
+func (kr *KongRegistration) Remove() error {
+	for _, route := range kr.routes {
+		if err := kongclear.DeleteRoute(kr.kongControlPlaneURL, route.ID); err != nil {
+			return err
+		}
+	}
+	for _, service := range kr.services {
+		if err := kongclear.DeleteService(kr.kongControlPlaneURL, service.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Removes the Kong services and routes that have been registered for the service since the previous registration was
+// set aside, and gives the previous services and routes back their names.
+func (kr *KongRegistration) Restore(sd *ServiceAPIDescription) error {
+	if sd != nil && sd.AefProfiles != nil {
+		for _, profile := range *sd.AefProfiles {
+			tagToSearch := sd.getKongTagToSearch(profile)
+			routes, err := kongclear.ListRoutes(kr.kongControlPlaneURL, tagToSearch)
+			if err != nil {
+				return err
+			}
+			for _, route := range routes {
+				if !kr.hasRoute(route.ID) {
+					if err := kongclear.DeleteRoute(kr.kongControlPlaneURL, route.ID); err != nil {
+						return err
+					}
+				}
+			}
+
+			services, err := kongclear.ListServices(kr.kongControlPlaneURL, tagToSearch)
+			if err != nil {
+				return err
+			}
+			for _, service := range services {
+				if !kr.hasService(service.ID) {
+					if err := kongclear.DeleteService(kr.kongControlPlaneURL, service.ID); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return kr.restoreNames()
+}
+
+func (kr *KongRegistration) restoreNames() error {
+	var restoreErr error
+	for _, service := range kr.services {
+		if err := kongclear.RenameService(kr.kongControlPlaneURL, service.ID, service.Name); err != nil {
+			log.Errorf("error restoring the name of Kong service %s: %v", service.Name, err)
+			restoreErr = err
+		}
+	}
+	for _, route := range kr.routes {
+		if err := kongclear.RenameRoute(kr.kongControlPlaneURL, route.ID, route.Name); err != nil {
+			log.Errorf("error restoring the name of Kong route %s: %v", route.Name, err)
+			restoreErr = err
+		}
+	}
+	return restoreErr
+}
+
+func (kr *KongRegistration) hasRoute(routeID string) bool {
+	for _, route := range kr.routes {
+		if route.ID == routeID {
+			return true
+		}
+	}
+	return false
+}
+
+func (kr *KongRegistration) hasService(serviceID string) bool {
+	for _, service := range kr.services {
+		if service.ID == serviceID {
+			return true
+		}
+	}
+	return false
+}
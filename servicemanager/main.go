@@ -26,7 +26,6 @@ import (
 
 	"github.com/getkin/kin-openapi/openapi3"
 
-	"github.com/deepmap/oapi-codegen/pkg/middleware"
 	echo "github.com/labstack/echo/v4"
 	echomiddleware "github.com/labstack/echo/v4/middleware"
 	log "github.com/sirupsen/logrus"
@@ -43,6 +42,9 @@ import (
 	"oransc.org/nonrtric/servicemanager/internal/invokermanagement"
 	"oransc.org/nonrtric/servicemanager/internal/providermanagement"
 	"oransc.org/nonrtric/servicemanager/internal/publishservice"
+
+	"oransc.org/nonrtric/capifcore/problemdetails"
+	"oransc.org/nonrtric/capifcore/validation"
 )
 
 func main() {
@@ -82,6 +84,11 @@ func registerHandlers(e *echo.Echo, myEnv map[string]string, myPorts map[string]
 	kongControlPlanePort := common29122.Port(myPorts["KONG_CONTROL_PLANE_PORT"])
 	kongDataPlaneIPv4 := common29122.Ipv4Addr(myEnv["KONG_DATA_PLANE_IPV4"])
 	kongDataPlanePort := common29122.Port(myPorts["KONG_DATA_PLANE_PORT"])
+	responseValidation, err := validation.ParseResponseValidation(myEnv["RESPONSE_VALIDATION"])
+	if err != nil {
+		log.Fatalf("error loading RESPONSE_VALIDATION from .env file: %v", err)
+		return err
+	}
 
 	var group *echo.Group
 
@@ -91,11 +98,9 @@ func registerHandlers(e *echo.Echo, myEnv map[string]string, myPorts map[string]
 		log.Fatalf("error loading ProviderManagement swagger spec\n: %v", err)
 		return err
 	}
-	providerManagerSwagger.Servers = nil
 	providerManager := providermanagement.NewProviderManager(capifProtocol, capifIPv4, capifPort)
-	group = e.Group("/api-provider-management/v1")
-	group.Use(middleware.OapiRequestValidator(providerManagerSwagger))
-	providermanagementapi.RegisterHandlersWithBaseURL(e, providerManager, "/api-provider-management/v1")
+	group = validation.NewGroup(e, providerManagerSwagger, "/api-provider-management/v1", responseValidation)
+	providermanagementapi.RegisterHandlers(group, providerManager)
 
 	// Register PublishService
 	publishServiceSwagger, err := publishserviceapi.GetSwagger()
@@ -103,16 +108,14 @@ func registerHandlers(e *echo.Echo, myEnv map[string]string, myPorts map[string]
 		log.Fatalf("error loading PublishService swagger spec\n: %v", err)
 		return err
 	}
-	publishServiceSwagger.Servers = nil
 	publishService := publishservice.NewPublishService(
 		kongDomain, kongProtocol,
 		kongControlPlaneIPv4, kongControlPlanePort,
 		kongDataPlaneIPv4, kongDataPlanePort,
 		capifProtocol, capifIPv4, capifPort)
 
-	group = e.Group("/published-apis/v1")
-	group.Use(middleware.OapiRequestValidator(publishServiceSwagger))
-	publishserviceapi.RegisterHandlersWithBaseURL(e, publishService, "/published-apis/v1")
+	group = validation.NewGroup(e, publishServiceSwagger, "/published-apis/v1", responseValidation)
+	publishserviceapi.RegisterHandlers(group, publishService)
 
 	// Register InvokerManagement
 	invokerManagerSwagger, err := invokermanagementapi.GetSwagger()
@@ -120,11 +123,9 @@ func registerHandlers(e *echo.Echo, myEnv map[string]string, myPorts map[string]
 		log.Fatalf("error loading InvokerManagement swagger spec\n: %v", err)
 		return err
 	}
-	invokerManagerSwagger.Servers = nil
 	invokerManager := invokermanagement.NewInvokerManager(capifProtocol, capifIPv4, capifPort)
-	group = e.Group("/api-invoker-management/v1")
-	group.Use(middleware.OapiRequestValidator(invokerManagerSwagger))
-	invokermanagementapi.RegisterHandlersWithBaseURL(e, invokerManager, "/api-invoker-management/v1")
+	group = validation.NewGroup(e, invokerManagerSwagger, "/api-invoker-management/v1", responseValidation)
+	invokermanagementapi.RegisterHandlers(group, invokerManager)
 
 	// Register DiscoverService
 	discoverServiceSwagger, err := discoverserviceapi.GetSwagger()
@@ -133,12 +134,10 @@ func registerHandlers(e *echo.Echo, myEnv map[string]string, myPorts map[string]
 		return err
 	}
 
-	discoverServiceSwagger.Servers = nil
 	discoverService := discoverservice.NewDiscoverService(capifProtocol, capifIPv4, capifPort)
 
-	group = e.Group("/service-apis/v1")
-	group.Use(middleware.OapiRequestValidator(discoverServiceSwagger))
-	discoverserviceapi.RegisterHandlersWithBaseURL(e, discoverService, "/service-apis/v1")
+	group = validation.NewGroup(e, discoverServiceSwagger, "/service-apis/v1", responseValidation)
+	discoverserviceapi.RegisterHandlers(group, discoverService)
 
 	e.GET("/", hello)
 	e.GET("/swagger/:apiName", getSwagger)
@@ -172,17 +171,10 @@ func getSwagger(c echo.Context) error {
 	case "discover":
 		swagger, err = discoverserviceapi.GetSwagger()
 	default:
-		return c.JSON(http.StatusBadRequest, getProblemDetails("Invalid API name "+api, http.StatusBadRequest))
+		return problemdetails.Send(c, http.StatusBadRequest, problemdetails.CauseInvalidApi, "Invalid API name "+api)
 	}
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, getProblemDetails("Unable to get swagger for API", http.StatusInternalServerError))
+		return problemdetails.Send(c, http.StatusInternalServerError, problemdetails.CauseSystemFailure, "Unable to get swagger for API")
 	}
 	return c.JSON(http.StatusOK, swagger)
 }
-
-func getProblemDetails(cause string, status int) common29122.ProblemDetails {
-	return common29122.ProblemDetails{
-		Cause:  &cause,
-		Status: &status,
-	}
-}
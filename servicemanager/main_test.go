@@ -83,7 +83,7 @@ func setupTest() error {
 	mockKongControlPlanePort := parsedMockKongURL.Port()
 
 	eCapifWeb = echo.New()
-	capifcore.RegisterHandlers(eCapifWeb, nil, nil)
+	capifcore.RegisterHandlers(eCapifWeb, nil, nil, nil, nil, false)
 	capifServer = httptest.NewServer(eCapifWeb)
 
 	// Parse the server URL
@@ -275,6 +275,6 @@ func TestGetSwagger(t *testing.T) {
 	var errorResponse common29122.ProblemDetails
 	err := result.UnmarshalJsonToObject(&errorResponse)
 	assert.Nil(t, err)
-	assert.Contains(t, *errorResponse.Cause, "Invalid API")
-	assert.Contains(t, *errorResponse.Cause, invalidApi)
+	assert.Contains(t, *errorResponse.Detail, "Invalid API")
+	assert.Contains(t, *errorResponse.Detail, invalidApi)
 }
\ No newline at end of file
@@ -245,6 +245,22 @@ func RegisterHandlers(e *echo.Echo) {
 		return c.String(http.StatusCreated, string(body))
 	})
 
+	e.POST("/services/api_id_helloworld-modify-helloworld-port-30951-hash-04478a3a-d0ef-5a05-a575-db5ee2e33403/routes", func(c echo.Context) error {
+		body, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return c.String(http.StatusInternalServerError, "Error reading request body")
+		}
+		return c.String(http.StatusCreated, string(body))
+	})
+
+	e.POST("/services/api_id_helloworld-modify-hellomars-port-30951-hash-04478a3a-d0ef-5a05-a575-db5ee2e33403/routes", func(c echo.Context) error {
+		body, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return c.String(http.StatusInternalServerError, "Error reading request body")
+		}
+		return c.String(http.StatusCreated, string(body))
+	})
+
 	e.POST("/services/api_id_apiName_helloworld-id/routes", func(c echo.Context) error {
 		body, err := io.ReadAll(c.Request().Body)
 		if err != nil {